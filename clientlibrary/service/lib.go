@@ -8,12 +8,14 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 
 	"github.com/anyproto/anytype-heart/core"
 	"github.com/anyproto/anytype-heart/core/event"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/logging"
 	"github.com/anyproto/anytype-heart/util/vcs"
@@ -35,10 +37,17 @@ func init() {
 
 	PanicHandler = mw.OnPanic
 	metrics.Service.InitWithKeys(metrics.DefaultInHouseKey)
+	if traceConfig := tracing.ConfigFromEnv(); traceConfig.Enabled() {
+		if _, err := tracing.Init(context.Background(), traceConfig); err != nil {
+			log.Errorf("failed to init tracing: %v", err)
+		}
+	}
 	registerClientCommandsHandler(
 		&ClientCommandsHandlerProxy{
 			client: mw,
 			interceptors: []func(ctx context.Context, req any, methodName string, actualCall func(ctx context.Context, req any) (any, error)) (any, error){
+				flushTracingInterceptor,
+				tracing.SharedRpcInterceptor,
 				metrics.SharedTraceInterceptor,
 				metrics.SharedLongMethodsInterceptor,
 			},
@@ -48,6 +57,20 @@ func init() {
 	}
 }
 
+// flushTracingInterceptor exports buffered spans after AppShutdown. The library has no exit hook and
+// the process could be killed after the shutdown, so the spans of the session would be lost otherwise
+func flushTracingInterceptor(ctx context.Context, req any, methodName string, actualCall func(ctx context.Context, req any) (any, error)) (any, error) {
+	resp, err := actualCall(ctx, req)
+	if methodName == "AppShutdown" {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if flushErr := tracing.Flush(flushCtx); flushErr != nil {
+			log.Errorf("failed to flush tracing: %v", flushErr)
+		}
+	}
+	return resp, err
+}
+
 func SetEventHandler(eh func(event *pb.Event)) {
 	mw.SetEventSender(event.NewCallbackSender(eh))
 }
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/template"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	coresb "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+var objectKeys = []string{bundle.RelationKeyId.String(), bundle.RelationKeyName.String(), bundle.RelationKeyType.String()}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func runSearch(n *node, out *output, args []string) error {
+	var typeKeys stringsFlag
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	fs.Var(&typeKeys, "type", "type key, e.g. page or task, could be repeated")
+	limit := fs.Int("limit", 100, "max number of objects")
+	_ = fs.Parse(args)
+
+	filters := []*model.BlockContentDataviewFilter{{
+		RelationKey: bundle.RelationKeySpaceId.String(),
+		Condition:   model.BlockContentDataviewFilter_Equal,
+		Value:       pbtypes.String(n.spaceId),
+	}}
+	if len(typeKeys) > 0 {
+		uniqueKeys := make([]string, 0, len(typeKeys))
+		for _, key := range typeKeys {
+			uniqueKeys = append(uniqueKeys, domain.MustUniqueKey(coresb.SmartBlockTypeObjectType, key).Marshal())
+		}
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: database.NestedRelationKey(bundle.RelationKeyType, bundle.RelationKeyUniqueKey),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(uniqueKeys),
+		})
+	}
+	resp, err := call(n, n.ObjectSearch, &pb.RpcObjectSearchRequest{
+		Filters:  filters,
+		FullText: strings.Join(fs.Args(), " "),
+		Limit:    int32(*limit),
+		Keys:     objectKeys,
+	})
+	if err != nil {
+		return err
+	}
+	return out.table(objectKeys, resp.Records)
+}
+
+func runCreate(n *node, out *output, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	typeKey := fs.String("type", bundle.TypeKeyPage.String(), "type key")
+	name := fs.String("name", "", "object name")
+	_ = fs.Parse(args)
+
+	body, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("read stdin: %w", err)
+	}
+	resp, err := call(n, n.ObjectCreate, &pb.RpcObjectCreateRequest{
+		SpaceId:             n.spaceId,
+		ObjectTypeUniqueKey: domain.MustUniqueKey(coresb.SmartBlockTypeObjectType, *typeKey).Marshal(),
+		Details: &types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeyName.String(): pbtypes.String(*name),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	n.markChanged(resp.ObjectId)
+	if err = pasteMarkdown(n, resp.ObjectId, string(body)); err != nil {
+		return err
+	}
+	return out.record([]string{bundle.RelationKeyId.String()}, map[string]any{bundle.RelationKeyId.String(): resp.ObjectId})
+}
+
+func runAppend(n *node, _ *output, args []string) error {
+	if len(args) != 1 {
+		return errors.New("object id is required")
+	}
+	body, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("read stdin: %w", err)
+	}
+	n.markChanged(args[0])
+	return pasteMarkdown(n, args[0], string(body))
+}
+
+// pasteMarkdown appends blocks parsed from markdown to the end of the object
+func pasteMarkdown(n *node, objectId, markdown string) error {
+	if strings.TrimSpace(markdown) == "" {
+		return nil
+	}
+	_, err := call(n, n.BlockPaste, &pb.RpcBlockPasteRequest{ContextId: objectId, TextSlot: markdown})
+	return err
+}
+
+// runSet sets details of the object, values are parsed as JSON and are used as strings otherwise
+func runSet(n *node, _ *output, args []string) error {
+	if len(args) < 2 {
+		return errors.New("object id and at least one key=value are required")
+	}
+	details := make([]*model.Detail, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		key, raw, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid detail: %s", arg)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		details = append(details, &model.Detail{Key: key, Value: pbtypes.ToValue(value)})
+	}
+	n.markChanged(args[0])
+	_, err := call(n, n.ObjectSetDetails, &pb.RpcObjectSetDetailsRequest{ContextId: args[0], Details: details})
+	return err
+}
+
+// runList prints objects of the set or collection with the visible relations of the view as columns
+func runList(n *node, out *output, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	viewId := fs.String("view", "", "view id, the first view is used by default")
+	limit := fs.Int("limit", 0, "max number of objects, all objects by default")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("set or collection id is required")
+	}
+	objectId := fs.Arg(0)
+
+	show, err := call(n, n.ObjectShow, &pb.RpcObjectShowRequest{ObjectId: objectId, SpaceId: n.spaceId})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := call(n, n.ObjectClose, &pb.RpcObjectCloseRequest{ObjectId: objectId, SpaceId: n.spaceId}); err != nil {
+			fmt.Fprintln(os.Stderr, "close object:", err)
+		}
+	}()
+
+	var dataview *model.BlockContentDataview
+	for _, b := range show.ObjectView.Blocks {
+		if b.Id == template.DataviewBlockId {
+			dataview = b.GetDataview()
+		}
+	}
+	if dataview == nil {
+		return errors.New("object is not a set or collection")
+	}
+	var view *model.BlockContentDataviewView
+	for _, v := range dataview.Views {
+		if *viewId == "" || v.Id == *viewId {
+			view = v
+			break
+		}
+	}
+	if view == nil {
+		return fmt.Errorf("view not found: %s", *viewId)
+	}
+	keys := []string{bundle.RelationKeyId.String()}
+	for _, rel := range view.Relations {
+		if rel.IsVisible && rel.Key != bundle.RelationKeyId.String() {
+			keys = append(keys, rel.Key)
+		}
+	}
+
+	req := &pb.RpcObjectSearchSubscribeRequest{
+		SubId: "cli-" + bson.NewObjectId().Hex(),
+		Filters: append([]*model.BlockContentDataviewFilter{{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(n.spaceId),
+		}}, view.Filters...),
+		Sorts:             view.Sorts,
+		Limit:             int64(*limit),
+		Keys:              keys,
+		NoDepSubscription: true,
+	}
+	if dataview.IsCollection {
+		req.CollectionId = objectId
+	} else {
+		for _, details := range show.ObjectView.Details {
+			if details.Id == objectId {
+				req.Source = pbtypes.GetStringList(details.Details, bundle.RelationKeySetOf.String())
+			}
+		}
+	}
+	resp, err := call(n, n.ObjectSearchSubscribe, req)
+	if err != nil {
+		return err
+	}
+	if _, err = call(n, n.ObjectSearchUnsubscribe, &pb.RpcObjectSearchUnsubscribeRequest{SubIds: []string{req.SubId}}); err != nil {
+		fmt.Fprintln(os.Stderr, "unsubscribe:", err)
+	}
+	return out.table(keys, resp.Records)
+}
+
+func runExport(n *node, out *output, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	path := fs.String("path", "", "directory to export to")
+	format := fs.String("format", "markdown", "markdown, protobuf or json")
+	zip := fs.Bool("zip", false, "write zip archive")
+	nested := fs.Bool("nested", false, "include linked objects")
+	files := fs.Bool("files", false, "include files")
+	_ = fs.Parse(args)
+	if *path == "" {
+		return errors.New("path is required")
+	}
+
+	req := &pb.RpcObjectListExportRequest{
+		SpaceId:       n.spaceId,
+		Path:          *path,
+		ObjectIds:     fs.Args(),
+		Zip:           *zip,
+		IncludeNested: *nested,
+		IncludeFiles:  *files,
+	}
+	switch *format {
+	case "markdown":
+		req.Format = model.Export_Markdown
+	case "protobuf":
+		req.Format = model.Export_Protobuf
+	case "json":
+		req.Format = model.Export_Protobuf
+		req.IsJson = true
+	default:
+		return fmt.Errorf("unknown export format: %s", *format)
+	}
+	resp, err := call(n, n.ObjectListExport, req)
+	if err != nil {
+		return err
+	}
+	return out.record([]string{"path", "succeed"}, map[string]any{"path": resp.Path, "succeed": int(resp.Succeed)})
+}
+
+func runImport(n *node, out *output, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	importType := fs.String("type", "markdown", "markdown, html, txt, csv, email, pb or plugin")
+	pluginName := fs.String("plugin", "", "name of the import plugin for the plugin type")
+	pluginParams := fs.String("params", "", "json params of the import plugin")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("at least one path is required")
+	}
+
+	req := &pb.RpcObjectImportRequest{
+		SpaceId:    n.spaceId,
+		Mode:       pb.RpcObjectImportRequest_IGNORE_ERRORS,
+		NoProgress: true,
+	}
+	paths := fs.Args()
+	switch *importType {
+	case "markdown":
+		req.Type = model.Import_Markdown
+		req.Params = &pb.RpcObjectImportRequestParamsOfMarkdownParams{MarkdownParams: &pb.RpcObjectImportRequestMarkdownParams{Path: paths}}
+	case "html":
+		req.Type = model.Import_Html
+		req.Params = &pb.RpcObjectImportRequestParamsOfHtmlParams{HtmlParams: &pb.RpcObjectImportRequestHtmlParams{Path: paths}}
+	case "txt":
+		req.Type = model.Import_Txt
+		req.Params = &pb.RpcObjectImportRequestParamsOfTxtParams{TxtParams: &pb.RpcObjectImportRequestTxtParams{Path: paths}}
+	case "csv":
+		req.Type = model.Import_Csv
+		req.Params = &pb.RpcObjectImportRequestParamsOfCsvParams{CsvParams: &pb.RpcObjectImportRequestCsvParams{
+			Path:                    paths,
+			UseFirstRowForRelations: true,
+		}}
+	case "email":
+		req.Type = model.Import_Email
+		req.Params = &pb.RpcObjectImportRequestParamsOfEmailParams{EmailParams: &pb.RpcObjectImportRequestEmailParams{Path: paths}}
+	case "pb":
+		req.Type = model.Import_Pb
+		req.Params = &pb.RpcObjectImportRequestParamsOfPbParams{PbParams: &pb.RpcObjectImportRequestPbParams{Path: paths}}
+	case "plugin":
+		req.Type = model.Import_Plugin
+		req.Params = &pb.RpcObjectImportRequestParamsOfPluginParams{PluginParams: &pb.RpcObjectImportRequestPluginParams{
+			Name:       *pluginName,
+			Path:       paths,
+			ParamsJson: *pluginParams,
+		}}
+	default:
+		return fmt.Errorf("unknown import type: %s", *importType)
+	}
+	resp, err := call(n, n.ObjectImport, req)
+	if err != nil {
+		return err
+	}
+	// ids of imported objects are not returned, so only the root collection is awaited
+	n.markChanged(resp.CollectionId)
+	return out.record([]string{"collectionId", "objectsCount"}, map[string]any{
+		"collectionId": resp.CollectionId,
+		"objectsCount": int(resp.ObjectsCount),
+	})
+}
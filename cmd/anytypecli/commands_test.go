@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type fakeCommands struct {
+	commands
+	search     func(req *pb.RpcObjectSearchRequest) []*types.Struct
+	setDetails []*pb.RpcObjectSetDetailsRequest
+	export     []*pb.RpcObjectListExportRequest
+}
+
+func (f *fakeCommands) ObjectSearch(_ context.Context, in *pb.RpcObjectSearchRequest, _ ...grpc.CallOption) (*pb.RpcObjectSearchResponse, error) {
+	return &pb.RpcObjectSearchResponse{Error: &pb.RpcObjectSearchResponseError{}, Records: f.search(in)}, nil
+}
+
+func (f *fakeCommands) ObjectSetDetails(_ context.Context, in *pb.RpcObjectSetDetailsRequest, _ ...grpc.CallOption) (*pb.RpcObjectSetDetailsResponse, error) {
+	f.setDetails = append(f.setDetails, in)
+	return &pb.RpcObjectSetDetailsResponse{Error: &pb.RpcObjectSetDetailsResponseError{}}, nil
+}
+
+func (f *fakeCommands) ObjectListExport(_ context.Context, in *pb.RpcObjectListExportRequest, _ ...grpc.CallOption) (*pb.RpcObjectListExportResponse, error) {
+	f.export = append(f.export, in)
+	return &pb.RpcObjectListExportResponse{Error: &pb.RpcObjectListExportResponseError{}, Path: in.Path, Succeed: 2}, nil
+}
+
+func newTestNode() (*node, *fakeCommands) {
+	fake := &fakeCommands{}
+	return &node{commands: fake, spaceId: "space1", close: func() {}}, fake
+}
+
+func TestRunSearch(t *testing.T) {
+	// given
+	n, fake := newTestNode()
+	var req *pb.RpcObjectSearchRequest
+	fake.search = func(in *pb.RpcObjectSearchRequest) []*types.Struct {
+		req = in
+		return []*types.Struct{{Fields: map[string]*types.Value{
+			bundle.RelationKeyId.String():   pbtypes.String("id1"),
+			bundle.RelationKeyName.String(): pbtypes.String("Release notes"),
+		}}}
+	}
+	var buf bytes.Buffer
+	out, err := newOutput(&buf, "tsv")
+	require.NoError(t, err)
+
+	// when
+	err = runSearch(n, out, []string{"-type", "task", "-type", "page", "-limit", "5", "release", "notes"})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "release notes", req.FullText)
+	assert.Equal(t, int32(5), req.Limit)
+	require.Len(t, req.Filters, 2)
+	assert.Equal(t, "space1", req.Filters[0].Value.GetStringValue())
+	assert.Equal(t, []string{"ot-task", "ot-page"}, pbtypes.GetStringListValue(req.Filters[1].Value))
+	assert.Equal(t, "id\tname\ttype\nid1\tRelease notes\t\n", buf.String())
+}
+
+func TestRunSet(t *testing.T) {
+	t.Run("values are parsed as json", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+
+		// when
+		err := runSet(n, nil, []string{"obj1", "name=Release 1.2", "done=true", "count=3", `tag=["a","b"]`})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, fake.setDetails, 1)
+		assert.Equal(t, "obj1", fake.setDetails[0].ContextId)
+		assert.Equal(t, []*model.Detail{
+			{Key: "name", Value: pbtypes.String("Release 1.2")},
+			{Key: "done", Value: pbtypes.Bool(true)},
+			{Key: "count", Value: pbtypes.Float64(3)},
+			{Key: "tag", Value: pbtypes.StringList([]string{"a", "b"})},
+		}, fake.setDetails[0].Details)
+		assert.Equal(t, []string{"obj1"}, n.changed)
+	})
+
+	t.Run("invalid detail", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+
+		// when
+		err := runSet(n, nil, []string{"obj1", "=value"})
+
+		// then
+		require.Error(t, err)
+		assert.Empty(t, fake.setDetails)
+	})
+}
+
+func TestRunExport(t *testing.T) {
+	t.Run("json format", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+		var buf bytes.Buffer
+		out, err := newOutput(&buf, "json")
+		require.NoError(t, err)
+
+		// when
+		err = runExport(n, out, []string{"-path", "/tmp/export", "-format", "json", "-zip", "obj1"})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, fake.export, 1)
+		assert.Equal(t, model.Export_Protobuf, fake.export[0].Format)
+		assert.True(t, fake.export[0].IsJson)
+		assert.True(t, fake.export[0].Zip)
+		assert.Equal(t, []string{"obj1"}, fake.export[0].ObjectIds)
+		assert.JSONEq(t, `[{"path": "/tmp/export", "succeed": 2}]`, buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+
+		// when
+		err := runExport(n, nil, []string{"-path", "/tmp/export", "-format", "pdf"})
+
+		// then
+		require.Error(t, err)
+		assert.Empty(t, fake.export)
+	})
+}
+
+func TestWaitSync(t *testing.T) {
+	syncPollInterval = time.Millisecond
+	syncStatus := func(id string, status domain.ObjectSyncStatus) *types.Struct {
+		return &types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeyId.String():         pbtypes.String(id),
+			bundle.RelationKeySyncStatus.String(): pbtypes.Int64(int64(status)),
+		}}
+	}
+
+	t.Run("wait until synced", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+		n.markChanged("obj1", "")
+		var polls int
+		fake.search = func(in *pb.RpcObjectSearchRequest) []*types.Struct {
+			assert.Equal(t, []string{"obj1"}, pbtypes.GetStringListValue(in.Filters[0].Value))
+			polls++
+			if polls < 3 {
+				return []*types.Struct{syncStatus("obj1", domain.ObjectSyncing)}
+			}
+			return []*types.Struct{syncStatus("obj1", domain.ObjectSynced)}
+		}
+
+		// when
+		err := n.waitSync(time.Minute)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, polls)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+		n.markChanged("obj1")
+		fake.search = func(in *pb.RpcObjectSearchRequest) []*types.Struct {
+			return []*types.Struct{syncStatus("obj1", domain.ObjectQueued)}
+		}
+
+		// when
+		err := n.waitSync(0)
+
+		// then
+		require.ErrorContains(t, err, "obj1")
+	})
+
+	t.Run("sync error", func(t *testing.T) {
+		// given
+		n, fake := newTestNode()
+		n.markChanged("obj1")
+		fake.search = func(in *pb.RpcObjectSearchRequest) []*types.Struct {
+			return []*types.Struct{syncStatus("obj1", domain.ObjectError)}
+		}
+
+		// when
+		err := n.waitSync(time.Minute)
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		// given
+		n, _ := newTestNode()
+
+		// when
+		err := n.waitSync(time.Minute)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestOptionalBool(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected bool
+	}{
+		{args: nil, expected: true},
+		{args: []string{"-wait-sync"}, expected: true},
+		{args: []string{"-wait-sync=false"}, expected: false},
+	} {
+		// given
+		var waitSync optionalBool
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Var(&waitSync, "wait-sync", "")
+
+		// when
+		err := fs.Parse(tc.args)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, waitSync.get(true), tc.args)
+	}
+	var waitSync optionalBool
+	assert.False(t, waitSync.get(false))
+}
@@ -0,0 +1,124 @@
+// Command anytypecli runs common operations against the running grpcserver or the node embedded into the process,
+// so scripts and CI pipelines don't need their own gRPC client.
+//
+//	ANYTYPE_MNEMONIC="..." anytypecli -data ./data create -name "Release 1.2" < notes.md
+//	anytypecli -addr 127.0.0.1:31007 -token $TOKEN -space $SPACE search "release notes"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type command struct {
+	usage string
+	run   func(n *node, out *output, args []string) error
+}
+
+var commandsByName = map[string]command{
+	"search": {usage: "search [-type key]... [-limit n] [query]", run: runSearch},
+	"create": {usage: "create [-type key] [-name name] < body.md", run: runCreate},
+	"append": {usage: "append <objectId> < blocks.md", run: runAppend},
+	"set":    {usage: "set <objectId> key=value...", run: runSet},
+	"list":   {usage: "list [-view id] [-limit n] <setOrCollectionId>", run: runList},
+	"export": {usage: "export -path dir [-format markdown|protobuf|json] [-zip] [-nested] [-files] [objectId...]", run: runExport},
+	"import": {usage: "import [-type markdown|html|txt|csv|email|pb|plugin] [-plugin name] [-params json] path...", run: runImport},
+}
+
+var commandOrder = []string{"search", "create", "append", "set", "list", "export", "import"}
+
+// optionalBool is a bool flag with the default depending on other flags
+type optionalBool struct {
+	value bool
+	isSet bool
+}
+
+func (b *optionalBool) String() string {
+	if b == nil || !b.isSet {
+		return ""
+	}
+	return strconv.FormatBool(b.value)
+}
+
+func (b *optionalBool) Set(v string) (err error) {
+	b.value, err = strconv.ParseBool(v)
+	b.isSet = true
+	return err
+}
+
+func (b *optionalBool) IsBoolFlag() bool {
+	return true
+}
+
+func (b *optionalBool) get(defaultValue bool) bool {
+	if !b.isSet {
+		return defaultValue
+	}
+	return b.value
+}
+
+func main() {
+	var (
+		cfg         nodeConfig
+		format      string
+		waitSync    optionalBool
+		syncTimeout time.Duration
+	)
+	flag.StringVar(&cfg.addr, "addr", os.Getenv("ANYTYPE_GRPC_ADDR"), "address of the running grpcserver, the node is started in-process if empty")
+	flag.StringVar(&cfg.dataDir, "data", os.Getenv("ANYTYPE_DATA_DIR"), "root path of the account data")
+	flag.StringVar(&cfg.token, "token", os.Getenv("ANYTYPE_TOKEN"), "session token of the running grpcserver")
+	flag.StringVar(&cfg.spaceId, "space", os.Getenv("ANYTYPE_SPACE_ID"), "space id, the personal space is used by default")
+	flag.StringVar(&format, "format", "tsv", "output format: tsv or json")
+	flag.Var(&waitSync, "wait-sync", "wait until changed objects are synced before logout, enabled by default for the embedded node")
+	flag.DurationVar(&syncTimeout, "sync-timeout", time.Minute, "max time to wait for sync")
+	flag.Usage = usage
+	flag.Parse()
+	// mnemonic is read from env only, so it doesn't leak to the process list
+	cfg.mnemonic = os.Getenv("ANYTYPE_MNEMONIC")
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commandsByName[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	out, err := newOutput(os.Stdout, format)
+	if err != nil {
+		fatal(err)
+	}
+
+	n, err := connect(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	err = cmd.run(n, out, flag.Args()[1:])
+	// the embedded node is stopped on logout, so changes are not synced until it's started again
+	if err == nil && waitSync.get(cfg.addr == "") {
+		err = n.waitSync(syncTimeout)
+	}
+	n.close()
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: anytypecli [flags] <command> [args]\n\ncommands:\n")
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %s\n", commandsByName[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nthe account is selected by ANYTYPE_MNEMONIC or -token\n\nflags:\n")
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
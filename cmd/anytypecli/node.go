@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/anyproto/anytype-heart/core"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/event"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pb/service"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	walletcore "github.com/anyproto/anytype-heart/pkg/lib/core"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/reflection"
+)
+
+// commands is the subset of ClientCommandsClient used by the tool, it's implemented by the gRPC client
+// and by the embedded middleware
+type commands interface {
+	WalletRecover(ctx context.Context, in *pb.RpcWalletRecoverRequest, opts ...grpc.CallOption) (*pb.RpcWalletRecoverResponse, error)
+	WalletCreateSession(ctx context.Context, in *pb.RpcWalletCreateSessionRequest, opts ...grpc.CallOption) (*pb.RpcWalletCreateSessionResponse, error)
+	WalletCloseSession(ctx context.Context, in *pb.RpcWalletCloseSessionRequest, opts ...grpc.CallOption) (*pb.RpcWalletCloseSessionResponse, error)
+	AccountSelect(ctx context.Context, in *pb.RpcAccountSelectRequest, opts ...grpc.CallOption) (*pb.RpcAccountSelectResponse, error)
+	AccountStop(ctx context.Context, in *pb.RpcAccountStopRequest, opts ...grpc.CallOption) (*pb.RpcAccountStopResponse, error)
+	ObjectSearch(ctx context.Context, in *pb.RpcObjectSearchRequest, opts ...grpc.CallOption) (*pb.RpcObjectSearchResponse, error)
+	ObjectCreate(ctx context.Context, in *pb.RpcObjectCreateRequest, opts ...grpc.CallOption) (*pb.RpcObjectCreateResponse, error)
+	ObjectShow(ctx context.Context, in *pb.RpcObjectShowRequest, opts ...grpc.CallOption) (*pb.RpcObjectShowResponse, error)
+	ObjectClose(ctx context.Context, in *pb.RpcObjectCloseRequest, opts ...grpc.CallOption) (*pb.RpcObjectCloseResponse, error)
+	ObjectSetDetails(ctx context.Context, in *pb.RpcObjectSetDetailsRequest, opts ...grpc.CallOption) (*pb.RpcObjectSetDetailsResponse, error)
+	ObjectSearchSubscribe(ctx context.Context, in *pb.RpcObjectSearchSubscribeRequest, opts ...grpc.CallOption) (*pb.RpcObjectSearchSubscribeResponse, error)
+	ObjectSearchUnsubscribe(ctx context.Context, in *pb.RpcObjectSearchUnsubscribeRequest, opts ...grpc.CallOption) (*pb.RpcObjectSearchUnsubscribeResponse, error)
+	ObjectListExport(ctx context.Context, in *pb.RpcObjectListExportRequest, opts ...grpc.CallOption) (*pb.RpcObjectListExportResponse, error)
+	ObjectImport(ctx context.Context, in *pb.RpcObjectImportRequest, opts ...grpc.CallOption) (*pb.RpcObjectImportResponse, error)
+	BlockPaste(ctx context.Context, in *pb.RpcBlockPasteRequest, opts ...grpc.CallOption) (*pb.RpcBlockPasteResponse, error)
+}
+
+var _ commands = service.ClientCommandsClient(nil)
+
+// embedded calls the middleware of the node running in the same process
+type embedded struct {
+	mw *core.Middleware
+}
+
+func (e embedded) WalletRecover(ctx context.Context, in *pb.RpcWalletRecoverRequest, _ ...grpc.CallOption) (*pb.RpcWalletRecoverResponse, error) {
+	return e.mw.WalletRecover(ctx, in), nil
+}
+
+func (e embedded) WalletCreateSession(ctx context.Context, in *pb.RpcWalletCreateSessionRequest, _ ...grpc.CallOption) (*pb.RpcWalletCreateSessionResponse, error) {
+	return e.mw.WalletCreateSession(ctx, in), nil
+}
+
+func (e embedded) WalletCloseSession(ctx context.Context, in *pb.RpcWalletCloseSessionRequest, _ ...grpc.CallOption) (*pb.RpcWalletCloseSessionResponse, error) {
+	return e.mw.WalletCloseSession(ctx, in), nil
+}
+
+func (e embedded) AccountSelect(ctx context.Context, in *pb.RpcAccountSelectRequest, _ ...grpc.CallOption) (*pb.RpcAccountSelectResponse, error) {
+	return e.mw.AccountSelect(ctx, in), nil
+}
+
+func (e embedded) AccountStop(ctx context.Context, in *pb.RpcAccountStopRequest, _ ...grpc.CallOption) (*pb.RpcAccountStopResponse, error) {
+	return e.mw.AccountStop(ctx, in), nil
+}
+
+func (e embedded) ObjectSearch(ctx context.Context, in *pb.RpcObjectSearchRequest, _ ...grpc.CallOption) (*pb.RpcObjectSearchResponse, error) {
+	return e.mw.ObjectSearch(ctx, in), nil
+}
+
+func (e embedded) ObjectCreate(ctx context.Context, in *pb.RpcObjectCreateRequest, _ ...grpc.CallOption) (*pb.RpcObjectCreateResponse, error) {
+	return e.mw.ObjectCreate(ctx, in), nil
+}
+
+func (e embedded) ObjectShow(ctx context.Context, in *pb.RpcObjectShowRequest, _ ...grpc.CallOption) (*pb.RpcObjectShowResponse, error) {
+	return e.mw.ObjectShow(ctx, in), nil
+}
+
+func (e embedded) ObjectClose(ctx context.Context, in *pb.RpcObjectCloseRequest, _ ...grpc.CallOption) (*pb.RpcObjectCloseResponse, error) {
+	return e.mw.ObjectClose(ctx, in), nil
+}
+
+func (e embedded) ObjectSetDetails(ctx context.Context, in *pb.RpcObjectSetDetailsRequest, _ ...grpc.CallOption) (*pb.RpcObjectSetDetailsResponse, error) {
+	return e.mw.ObjectSetDetails(ctx, in), nil
+}
+
+func (e embedded) ObjectSearchSubscribe(ctx context.Context, in *pb.RpcObjectSearchSubscribeRequest, _ ...grpc.CallOption) (*pb.RpcObjectSearchSubscribeResponse, error) {
+	return e.mw.ObjectSearchSubscribe(ctx, in), nil
+}
+
+func (e embedded) ObjectSearchUnsubscribe(ctx context.Context, in *pb.RpcObjectSearchUnsubscribeRequest, _ ...grpc.CallOption) (*pb.RpcObjectSearchUnsubscribeResponse, error) {
+	return e.mw.ObjectSearchUnsubscribe(ctx, in), nil
+}
+
+func (e embedded) ObjectListExport(ctx context.Context, in *pb.RpcObjectListExportRequest, _ ...grpc.CallOption) (*pb.RpcObjectListExportResponse, error) {
+	return e.mw.ObjectListExport(ctx, in), nil
+}
+
+func (e embedded) ObjectImport(ctx context.Context, in *pb.RpcObjectImportRequest, _ ...grpc.CallOption) (*pb.RpcObjectImportResponse, error) {
+	return e.mw.ObjectImport(ctx, in), nil
+}
+
+func (e embedded) BlockPaste(ctx context.Context, in *pb.RpcBlockPasteRequest, _ ...grpc.CallOption) (*pb.RpcBlockPasteResponse, error) {
+	return e.mw.BlockPaste(ctx, in), nil
+}
+
+type nodeConfig struct {
+	// addr of the running grpcserver, the node is started in-process when it's empty
+	addr     string
+	dataDir  string
+	mnemonic string
+	token    string
+	spaceId  string
+}
+
+type node struct {
+	commands
+	token   string
+	spaceId string
+	close   func()
+	// changed are ids of objects created or modified by the command, their sync is awaited before logout
+	changed []string
+}
+
+// connect connects to the running node or starts the embedded one, and logs in
+func connect(cfg nodeConfig) (*node, error) {
+	n := &node{spaceId: cfg.spaceId, token: cfg.token, close: func() {}}
+	if cfg.addr != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, cfg.addr, grpc.WithBlock(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s: %w", cfg.addr, err)
+		}
+		n.commands = service.NewClientCommandsClient(conn)
+		n.close = func() { conn.Close() }
+		if n.token != "" {
+			// the session is owned by the caller, the account is already running
+			if n.spaceId == "" {
+				n.close()
+				return nil, errors.New("space id is required when logging in with a session token")
+			}
+			return n, nil
+		}
+	} else {
+		if cfg.dataDir == "" {
+			return nil, errors.New("data dir is required to start the embedded node")
+		}
+		mw := core.New()
+		mw.SetEventSender(event.NewCallbackSender(func(*pb.Event) {}))
+		n.commands = embedded{mw: mw}
+	}
+	if cfg.mnemonic == "" {
+		n.close()
+		return nil, errors.New("mnemonic or session token is required")
+	}
+	if err := n.login(cfg); err != nil {
+		n.close()
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *node) login(cfg nodeConfig) error {
+	derived, err := walletcore.WalletAccountAt(cfg.mnemonic, 0)
+	if err != nil {
+		return fmt.Errorf("derive account: %w", err)
+	}
+	if _, err = call(n, n.WalletRecover, &pb.RpcWalletRecoverRequest{RootPath: cfg.dataDir, Mnemonic: cfg.mnemonic}); err != nil {
+		return err
+	}
+	session, err := call(n, n.WalletCreateSession, &pb.RpcWalletCreateSessionRequest{
+		Auth: &pb.RpcWalletCreateSessionRequestAuthOfMnemonic{Mnemonic: cfg.mnemonic},
+	})
+	if err != nil {
+		return err
+	}
+	n.token = session.Token
+
+	account, err := call(n, n.AccountSelect, &pb.RpcAccountSelectRequest{
+		Id:       derived.Identity.GetPublic().Account(),
+		RootPath: cfg.dataDir,
+	})
+	if err != nil {
+		n.logout(cfg.addr == "")
+		return err
+	}
+	if n.spaceId == "" {
+		n.spaceId = account.Account.GetInfo().GetAccountSpaceId()
+	}
+
+	closeConn := n.close
+	n.close = func() {
+		// the account of the running node could be used by other clients, so it's stopped only in the embedded node
+		n.logout(cfg.addr == "")
+		closeConn()
+	}
+	return nil
+}
+
+func (n *node) logout(stopAccount bool) {
+	if stopAccount {
+		if _, err := call(n, n.AccountStop, &pb.RpcAccountStopRequest{}); err != nil {
+			fmt.Fprintln(os.Stderr, "stop account:", err)
+		}
+	}
+	if _, err := call(n, n.WalletCloseSession, &pb.RpcWalletCloseSessionRequest{Token: n.token}); err != nil {
+		fmt.Fprintln(os.Stderr, "close session:", err)
+	}
+}
+
+func (n *node) markChanged(ids ...string) {
+	for _, id := range ids {
+		if id != "" {
+			n.changed = append(n.changed, id)
+		}
+	}
+}
+
+var syncPollInterval = time.Second
+
+// waitSync polls sync status of changed objects until all of them are synced, so the changes are not left
+// only in the local storage of the embedded node when it's stopped
+func (n *node) waitSync(timeout time.Duration) error {
+	if len(n.changed) == 0 {
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		pending, err := n.notSynced()
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("objects are not synced in %s: %s", timeout, strings.Join(pending, ", "))
+		}
+		time.Sleep(syncPollInterval)
+	}
+}
+
+// notSynced returns ids of changed objects that are not synced yet
+func (n *node) notSynced() ([]string, error) {
+	resp, err := call(n, n.ObjectSearch, &pb.RpcObjectSearchRequest{
+		Filters: []*model.BlockContentDataviewFilter{{
+			RelationKey: bundle.RelationKeyId.String(),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(n.changed),
+		}},
+		Keys: []string{bundle.RelationKeyId.String(), bundle.RelationKeySyncStatus.String(), bundle.RelationKeySyncError.String()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, rec := range resp.Records {
+		id := pbtypes.GetString(rec, bundle.RelationKeyId.String())
+		switch domain.ObjectSyncStatus(pbtypes.GetInt64(rec, bundle.RelationKeySyncStatus.String())) {
+		case domain.ObjectSynced:
+		case domain.ObjectError:
+			return nil, fmt.Errorf("sync of %s failed with error %d", id, pbtypes.GetInt64(rec, bundle.RelationKeySyncError.String()))
+		default:
+			pending = append(pending, id)
+		}
+	}
+	return pending, nil
+}
+
+func (n *node) context() context.Context {
+	if n.token == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "token", n.token)
+}
+
+// call runs the command and converts the error of the response to the go error
+func call[reqT, respT any](n *node, method func(context.Context, reqT, ...grpc.CallOption) (respT, error), req reqT) (respT, error) {
+	var nilResp respT
+	resp, err := method(n.context(), req)
+	if err != nil {
+		return nilResp, err
+	}
+	code, description, err := reflection.GetError(resp)
+	if err != nil {
+		return nilResp, err
+	}
+	if code != 0 {
+		return nilResp, fmt.Errorf("%T: code %d: %s", req, code, description)
+	}
+	return resp, nil
+}
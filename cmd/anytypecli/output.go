@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch format {
+	case "tsv":
+		return &output{w: w}, nil
+	case "json":
+		return &output{w: w, json: true}, nil
+	}
+	return nil, fmt.Errorf("unknown output format: %s", format)
+}
+
+// table writes the given keys of records as TSV with the header row or as JSON array of objects
+func (o *output) table(keys []string, records []*types.Struct) error {
+	if o.json {
+		rows := make([]map[string]any, 0, len(records))
+		for _, rec := range records {
+			row := make(map[string]any, len(keys))
+			for _, key := range keys {
+				if v := pbtypes.Get(rec, key); v != nil {
+					row[key] = pbtypes.ValueToInterface(v)
+				}
+			}
+			rows = append(rows, row)
+		}
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	w := csv.NewWriter(o.w)
+	w.Comma = '\t'
+	if err := w.Write(keys); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		row := make([]string, len(keys))
+		for i, key := range keys {
+			row[i] = formatValue(pbtypes.Get(rec, key))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// record writes the single record, e.g. the result of create command
+func (o *output) record(keys []string, rec map[string]any) error {
+	return o.table(keys, []*types.Struct{pbtypes.ToStruct(rec)})
+}
+
+// formatValue formats the detail value for the TSV cell, lists are joined by comma
+func formatValue(v *types.Value) string {
+	if v == nil {
+		return ""
+	}
+	switch k := v.Kind.(type) {
+	case *types.Value_StringValue:
+		return k.StringValue
+	case *types.Value_NumberValue:
+		return strconv.FormatFloat(k.NumberValue, 'f', -1, 64)
+	case *types.Value_BoolValue:
+		return strconv.FormatBool(k.BoolValue)
+	case *types.Value_ListValue:
+		items := make([]string, 0, len(k.ListValue.Values))
+		for _, item := range k.ListValue.Values {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ",")
+	case *types.Value_StructValue:
+		data, _ := json.Marshal(pbtypes.StructToMap(k.StructValue))
+		return string(data)
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestOutput_Table(t *testing.T) {
+	records := []*types.Struct{{Fields: map[string]*types.Value{
+		"id":   pbtypes.String("id1"),
+		"name": pbtypes.String("Tabs\tand \"quotes\""),
+		"tag":  pbtypes.StringList([]string{"a", "b"}),
+		"done": pbtypes.Bool(true),
+		"size": pbtypes.Float64(1.5),
+	}}}
+	keys := []string{"id", "name", "tag", "done", "size", "missing"}
+
+	t.Run("tsv", func(t *testing.T) {
+		// given
+		var buf bytes.Buffer
+		out, err := newOutput(&buf, "tsv")
+		require.NoError(t, err)
+
+		// when
+		err = out.table(keys, records)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "id\tname\ttag\tdone\tsize\tmissing\nid1\t\"Tabs\tand \"\"quotes\"\"\"\ta,b\ttrue\t1.5\t\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// given
+		var buf bytes.Buffer
+		out, err := newOutput(&buf, "json")
+		require.NoError(t, err)
+
+		// when
+		err = out.table(keys, records)
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id": "id1", "name": "Tabs\tand \"quotes\"", "tag": ["a", "b"], "done": true, "size": 1.5}]`, buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		// when
+		_, err := newOutput(&bytes.Buffer{}, "xml")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "", formatValue(nil))
+	assert.Equal(t, "3", formatValue(pbtypes.Int64(3)))
+	assert.Equal(t, "1,2", formatValue(pbtypes.IntList(1, 2)))
+	assert.Equal(t, `{"key":"value"}`, formatValue(pbtypes.Struct(&types.Struct{Fields: map[string]*types.Value{"key": pbtypes.String("value")}})))
+}
@@ -0,0 +1,65 @@
+//go:build !nogrpcserver && !_test
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/anyproto/any-sync/app"
+
+	"github.com/anyproto/anytype-heart/core"
+	"github.com/anyproto/anytype-heart/core/block/object/idresolver"
+	"github.com/anyproto/anytype-heart/core/event"
+	"github.com/anyproto/anytype-heart/core/event/eventlog"
+)
+
+// Events could be written as json lines to the file set by ANYTYPE_EVENT_LOG and served by the local API
+// at /v1/events when ANYTYPE_API_EVENTS=1. Events are observed only when one of them is enabled
+
+func eventLogOptionsFromEnv() eventlog.Options {
+	return eventlog.Options{
+		Filter: eventlog.Filter{
+			ContextIds: splitList(os.Getenv("ANYTYPE_EVENT_LOG_CONTEXTS")),
+			Types:      splitList(os.Getenv("ANYTYPE_EVENT_LOG_TYPES")),
+			SpaceIds:   splitList(os.Getenv("ANYTYPE_EVENT_LOG_SPACES")),
+		},
+		Anonymize: os.Getenv("ANYTYPE_EVENT_LOG_ANONYMIZE") == "1",
+	}
+}
+
+// newEventSender returns the grpc sender, it's wrapped to pass events to the stream if the stream is needed
+func newEventSender(mw *core.Middleware) (event.Sender, *eventlog.Stream) {
+	sender := event.NewGrpcSender()
+	logPath := os.Getenv("ANYTYPE_EVENT_LOG")
+	if logPath == "" && os.Getenv("ANYTYPE_API_EVENTS") != "1" {
+		return sender, nil
+	}
+	stream := eventlog.NewStream(func(objectId string) (string, error) {
+		a := mw.GetApp()
+		if a == nil {
+			return "", errors.New("account is not running")
+		}
+		return app.MustComponent[idresolver.Resolver](a).ResolveSpaceID(objectId)
+	})
+	if logPath != "" {
+		startEventLogFile(stream, logPath, eventLogOptionsFromEnv())
+	}
+	return event.NewObservedSender(sender, stream.Observe), stream
+}
+
+// startEventLogFile appends events to the file until the process exits
+func startEventLogFile(stream *eventlog.Stream, path string, opts eventlog.Options) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		log.Errorf("failed to open event log: %v", err)
+		return
+	}
+	go func() {
+		defer f.Close()
+		if err := stream.Serve(context.Background(), opts, f, nil); err != nil {
+			log.Errorf("event log stopped: %v", err)
+		}
+	}()
+}
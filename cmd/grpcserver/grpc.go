@@ -29,8 +29,9 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/anyproto/anytype-heart/core"
-	"github.com/anyproto/anytype-heart/core/event"
+	"github.com/anyproto/anytype-heart/core/api"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pb/service"
 	"github.com/anyproto/anytype-heart/pkg/lib/logging"
@@ -39,6 +40,7 @@ import (
 
 const defaultAddr = "127.0.0.1:31007"
 const defaultWebAddr = "127.0.0.1:31008"
+const defaultApiAddr = "127.0.0.1:31009"
 
 // do not change this, js client relies on this msg to ensure that server is up
 const grpcWebStartedMessagePrefix = "gRPC Web proxy started at: "
@@ -47,6 +49,10 @@ var log = logging.Logger("anytype-grpc-server")
 var commonOSSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGINT}
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "mcp" {
+		runMcp(os.Args[2:])
+		return
+	}
 	var addr string
 	var webaddr string
 	app.StartWarningAfter = time.Second * 5
@@ -86,7 +92,8 @@ func main() {
 	signal.Notify(signalChan, signals...)
 
 	var mw = core.New()
-	mw.SetEventSender(event.NewGrpcSender())
+	eventSender, eventStream := newEventSender(mw)
+	mw.SetEventSender(eventSender)
 
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -104,6 +111,14 @@ func main() {
 		streamInterceptors []grpc.StreamServerInterceptor
 	)
 
+	var shutdownTracing func(ctx context.Context) error
+	if traceConfig := tracing.ConfigFromEnv(); traceConfig.Enabled() {
+		if shutdownTracing, err = tracing.Init(context.Background(), traceConfig); err != nil {
+			log.Errorf("failed to init tracing: %v", err)
+		} else {
+			unaryInterceptors = append(unaryInterceptors, tracing.UnaryServerInterceptor)
+		}
+	}
 	if metrics.Enabled {
 		unaryInterceptors = append(unaryInterceptors, grpc_prometheus.UnaryServerInterceptor)
 	}
@@ -221,6 +236,33 @@ func main() {
 		}
 	}()
 
+	// the local API is disabled unless it's requested explicitly
+	apiAddr := os.Getenv("ANYTYPE_API_ADDR")
+	if apiAddr == "" && os.Getenv("ANYTYPE_API") == "1" {
+		apiAddr = defaultApiAddr
+	}
+	var apiServer *http.Server
+	if apiAddr != "" {
+		apiHandler := api.NewServer(mw)
+		if os.Getenv("ANYTYPE_MCP") == "1" {
+			apiHandler.EnableMcp(mcpConfigFromEnv())
+		}
+		if eventStream != nil && os.Getenv("ANYTYPE_API_EVENTS") == "1" {
+			apiHandler.EnableEvents(eventStream)
+		}
+		apiServer = &http.Server{
+			Addr:              apiAddr,
+			Handler:           apiHandler.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := apiServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Errorf("local api error: %v", err)
+			}
+		}()
+		fmt.Println("Local API started at: " + apiAddr)
+	}
+
 	startReportMemory(mw)
 
 	// do not change this, js client relies on this msg to ensure that server is up and parse address
@@ -236,7 +278,15 @@ func main() {
 		}
 		server.Stop()
 		proxy.Close()
+		if apiServer != nil {
+			apiServer.Close()
+		}
 		mw.AppShutdown(context.Background(), &pb.RpcAppShutdownRequest{})
+		if shutdownTracing != nil {
+			if err = shutdownTracing(context.Background()); err != nil {
+				log.Errorf("failed to flush traces: %v", err)
+			}
+		}
 		return
 	}
 }
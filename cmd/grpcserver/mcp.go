@@ -0,0 +1,95 @@
+//go:build !nogrpcserver && !_test
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/anyproto/anytype-heart/core"
+	"github.com/anyproto/anytype-heart/core/api"
+	"github.com/anyproto/anytype-heart/core/event"
+	"github.com/anyproto/anytype-heart/pb"
+	walletcore "github.com/anyproto/anytype-heart/pkg/lib/core"
+)
+
+// mcpConfigFromEnv reads the access restrictions of MCP clients
+func mcpConfigFromEnv() api.McpConfig {
+	return api.McpConfig{
+		SpaceIds: splitList(os.Getenv("ANYTYPE_MCP_SPACES")),
+		ReadOnly: os.Getenv("ANYTYPE_MCP_READ_ONLY") == "1",
+	}
+}
+
+// runMcp serves MCP over stdio for assistants that start the server as a subprocess. The node runs in-process,
+// the account is selected by ANYTYPE_MNEMONIC, stdout is reserved for the protocol messages
+func runMcp(args []string) {
+	config := mcpConfigFromEnv()
+	var spaces string
+	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
+	dataDir := fs.String("data", os.Getenv("ANYTYPE_DATA_DIR"), "root path of the account data")
+	fs.StringVar(&spaces, "spaces", strings.Join(config.SpaceIds, ","), "comma-separated ids of spaces available to the assistant, all spaces by default")
+	fs.BoolVar(&config.ReadOnly, "read-only", config.ReadOnly, "hide tools that change objects")
+	_ = fs.Parse(args)
+	config.SpaceIds = splitList(spaces)
+
+	mw := core.New()
+	mw.SetEventSender(event.NewCallbackSender(func(*pb.Event) {}))
+	if err := selectAccount(mw, *dataDir, os.Getenv("ANYTYPE_MNEMONIC")); err != nil {
+		fmt.Fprintln(os.Stderr, "mcp:", err)
+		os.Exit(1)
+	}
+	defer mw.AppShutdown(context.Background(), &pb.RpcAppShutdownRequest{})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), commonOSSignals...)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- api.NewServer(mw).ServeMcpStdio(ctx, config, os.Stdin, os.Stdout)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mcp:", err)
+		}
+	case <-ctx.Done():
+	}
+}
+
+func selectAccount(mw *core.Middleware, dataDir, mnemonic string) error {
+	if dataDir == "" || mnemonic == "" {
+		return errors.New("data dir and ANYTYPE_MNEMONIC are required")
+	}
+	derived, err := walletcore.WalletAccountAt(mnemonic, 0)
+	if err != nil {
+		return fmt.Errorf("derive account: %w", err)
+	}
+	ctx := context.Background()
+	recoverResp := mw.WalletRecover(ctx, &pb.RpcWalletRecoverRequest{RootPath: dataDir, Mnemonic: mnemonic})
+	if recoverResp.Error.Code != pb.RpcWalletRecoverResponseError_NULL {
+		return fmt.Errorf("recover wallet: %s", recoverResp.Error.Description)
+	}
+	selectResp := mw.AccountSelect(ctx, &pb.RpcAccountSelectRequest{
+		Id:       derived.Identity.GetPublic().Account(),
+		RootPath: dataDir,
+	})
+	if selectResp.Error.Code != pb.RpcAccountSelectResponseError_NULL {
+		return fmt.Errorf("select account: %s", selectResp.Error.Description)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -200,6 +200,12 @@ func (mw *Middleware) AccountEnableLocalNetworkSync(_ context.Context, req *pb.R
 
 func (mw *Middleware) AccountLocalLinkNewChallenge(ctx context.Context, request *pb.RpcAccountLocalLinkNewChallengeRequest) *pb.RpcAccountLocalLinkNewChallengeResponse {
 	info := getClientInfo(ctx)
+	if info.ProcessName == "" {
+		info.ProcessName = request.AppName
+	}
+	info.Scope = request.Scope
+	info.SpaceIds = request.SpaceIds
+	info.UnredactedEvents = request.UnredactedEvents
 
 	challengeId, err := mw.applicationService.LinkLocalStartNewChallenge(&info)
 	code := mapErrorCode(err,
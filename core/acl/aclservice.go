@@ -17,6 +17,7 @@ import (
 	"github.com/anyproto/any-sync/util/crypto"
 	"github.com/gogo/protobuf/types"
 	"github.com/ipfs/go-cid"
+	"go.uber.org/zap"
 
 	"github.com/anyproto/anytype-heart/core/anytype/account"
 	"github.com/anyproto/anytype-heart/core/domain"
@@ -48,6 +49,7 @@ type AccountPermissions struct {
 type AclService interface {
 	app.Component
 	GenerateInvite(ctx context.Context, spaceId string) (domain.InviteInfo, error)
+	// RevokeInvite revokes the current invite and invites of older versions, invites created with CreateInvite stay active
 	RevokeInvite(ctx context.Context, spaceId string) error
 	GetCurrentInvite(ctx context.Context, spaceId string) (domain.InviteInfo, error)
 	ViewInvite(ctx context.Context, inviteCid cid.Cid, inviteFileKey crypto.SymKey) (domain.InviteView, error)
@@ -61,10 +63,16 @@ type AclService interface {
 	Leave(ctx context.Context, spaceId string) (err error)
 	Remove(ctx context.Context, spaceId string, identities []crypto.PubKey) (err error)
 	ChangePermissions(ctx context.Context, spaceId string, perms []AccountPermissions) (err error)
+
+	// CreateInvite adds one more invite to the space, unlike GenerateInvite it doesn't replace the current one
+	CreateInvite(ctx context.Context, spaceId string, params domain.InviteParams) (domain.Invite, error)
+	// ListInvites returns invites created with CreateInvite and join requests made through any invite of the space
+	ListInvites(ctx context.Context, spaceId string) ([]domain.Invite, []domain.InviteJoin, error)
+	RevokeInviteById(ctx context.Context, spaceId string, inviteRecordId string) error
 }
 
 func New() AclService {
-	return &aclService{}
+	return &aclService{now: time.Now}
 }
 
 type aclService struct {
@@ -74,6 +82,8 @@ type aclService struct {
 	inviteService    inviteservice.InviteService
 	accountService   account.Service
 	coordClient      coordinatorclient.CoordinatorClient
+
+	now func() time.Time
 }
 
 func (a *aclService) Init(ap *app.App) (err error) {
@@ -157,10 +167,27 @@ func (a *aclService) RevokeInvite(ctx context.Context, spaceId string) error {
 	if err != nil {
 		return convertedOrSpaceErr(err)
 	}
-	cl := sp.CommonSpace().AclClient()
-	err = cl.RevokeAllInvites(ctx)
+	invites, err := a.inviteService.List(ctx, spaceId)
 	if err != nil {
-		return convertedOrAclRequestError(err)
+		return convertedOrInternalError("list invites", err)
+	}
+	scoped := make(map[string]struct{}, len(invites))
+	for _, invite := range invites {
+		scoped[invite.RecordId] = struct{}{}
+	}
+	acl := sp.CommonSpace().Acl()
+	acl.RLock()
+	inviteIds := acl.AclState().InviteIds()
+	acl.RUnlock()
+	cl := sp.CommonSpace().AclClient()
+	for _, inviteId := range inviteIds {
+		if _, ok := scoped[inviteId]; ok {
+			continue
+		}
+		err = cl.RevokeInvite(ctx, inviteId)
+		if err != nil && !errors.Is(err, list.ErrNoSuchInvite) {
+			return convertedOrAclRequestError(err)
+		}
 	}
 	err = a.inviteService.RemoveExisting(ctx, spaceId)
 	if err != nil {
@@ -340,6 +367,9 @@ func (a *aclService) Join(ctx context.Context, spaceId, networkId string, invite
 	if err != nil {
 		return convertedOrInternalError("get invite payload", err)
 	}
+	if invitePayload.ExpireDate > 0 && a.now().Unix() >= invitePayload.ExpireDate {
+		return inviteservice.ErrInviteExpired
+	}
 	inviteKey, err := crypto.UnmarshalEd25519PrivateKeyProto(invitePayload.InviteKey)
 	if err != nil {
 		return convertedOrInternalError("unmarshal invite key", err)
@@ -383,6 +413,9 @@ func (a *aclService) ViewInvite(ctx context.Context, inviteCid cid.Cid, inviteFi
 	if err != nil {
 		return domain.InviteView{}, convertedOrInternalError("view invite", err)
 	}
+	if res.IsExpired(a.now()) {
+		return domain.InviteView{}, inviteservice.ErrInviteExpired
+	}
 	inviteKey, err := crypto.UnmarshalEd25519PrivateKeyProto(res.InviteKey)
 	if err != nil {
 		return domain.InviteView{}, convertedOrInternalError("unmarshal invite key", err)
@@ -410,8 +443,11 @@ func (a *aclService) ViewInvite(ctx context.Context, inviteCid cid.Cid, inviteFi
 	return domain.InviteView{}, inviteservice.ErrInviteNotExists
 }
 
+// Accept approves the join request. NoPermissions means default permissions of the invite used for the request
 func (a *aclService) Accept(ctx context.Context, spaceId string, identity crypto.PubKey, permissions model.ParticipantPermissions) error {
-	validPerms := permissions == model.ParticipantPermissions_Reader || permissions == model.ParticipantPermissions_Writer
+	validPerms := permissions == model.ParticipantPermissions_Reader ||
+		permissions == model.ParticipantPermissions_Writer ||
+		permissions == model.ParticipantPermissions_NoPermissions
 	if !validPerms {
 		return ErrIncorrectPermissions
 	}
@@ -433,18 +469,39 @@ func (a *aclService) Accept(ctx context.Context, spaceId string, identity crypto
 			break
 		}
 	}
+	joins := collectInviteJoins(acl)
 	acl.RUnlock()
 	if recId == "" {
 		return fmt.Errorf("%w with identity: %s", ErrRequestNotExists, identity.Account())
 	}
-	cl := acceptSpace.CommonSpace().AclClient()
+	invite, found, err := a.requestInvite(ctx, spaceId, identity, joins)
+	if err != nil {
+		return err
+	}
+	if found {
+		if invite.IsExpired(a.now()) {
+			return inviteservice.ErrInviteExpired
+		}
+		if invite.IsExhausted() {
+			return inviteservice.ErrInviteExhausted
+		}
+		if invite.RevokedDate != 0 {
+			return inviteservice.ErrInviteRevoked
+		}
+		if permissions == model.ParticipantPermissions_NoPermissions {
+			permissions = invite.Permissions
+		}
+	}
 	var aclPerms list.AclPermissions
 	switch permissions {
 	case model.ParticipantPermissions_Reader:
 		aclPerms = list.AclPermissionsReader
 	case model.ParticipantPermissions_Writer:
 		aclPerms = list.AclPermissionsWriter
+	default:
+		return ErrIncorrectPermissions
 	}
+	cl := acceptSpace.CommonSpace().AclClient()
 	err = cl.AcceptRequest(ctx, list.RequestAcceptPayload{
 		RequestRecordId: recId,
 		Permissions:     aclPerms,
@@ -452,6 +509,11 @@ func (a *aclService) Accept(ctx context.Context, spaceId string, identity crypto
 	if err != nil {
 		return convertedOrAclRequestError(err)
 	}
+	if found && invite.MaxUses > 0 && invite.Uses+1 >= invite.MaxUses {
+		if err = a.RevokeInviteById(ctx, spaceId, invite.RecordId); err != nil {
+			log.Error("revoke exhausted invite", zap.String("inviteRecordId", invite.RecordId), zap.Error(err))
+		}
+	}
 	return nil
 }
 
@@ -542,3 +542,156 @@ func TestService_Leave(t *testing.T) {
 		require.True(t, errors.Is(err, ErrAclRequestFailed))
 	})
 }
+
+func prepareInviteAcl(t *testing.T, spaceId string, cmds ...string) (*list.AclTestExecutor, list.AclList, string) {
+	exec := list.NewAclExecutor(spaceId)
+	for _, cmd := range append([]string{"a.init::a", "a.invite::invId"}, cmds...) {
+		require.NoError(t, exec.Execute(cmd), cmd)
+	}
+	acl := exec.ActualAccounts()["a"].Acl
+	inviteIds := acl.AclState().InviteIds()
+	require.Len(t, inviteIds, 1)
+	return exec, mockSyncAcl{acl}, inviteIds[0]
+}
+
+func TestService_Accept(t *testing.T) {
+	const spaceId = "spaceId"
+	prepare := func(t *testing.T, invite domain.Invite) (*fixture, crypto.PubKey, string) {
+		fx := newFixture(t)
+		fx.now = func() time.Time { return time.Unix(100, 0) }
+		exec, acl, inviteId := prepareInviteAcl(t, spaceId, "b.join::invId")
+		invite.RecordId = inviteId
+		fx.mockSpaceService.EXPECT().Get(ctx, spaceId).Return(fx.mockClientSpace, nil)
+		fx.mockClientSpace.EXPECT().CommonSpace().Return(fx.mockCommonSpace)
+		fx.mockCommonSpace.EXPECT().Acl().Return(acl).AnyTimes()
+		fx.mockCommonSpace.EXPECT().AclClient().Return(fx.mockSpaceClient).AnyTimes()
+		fx.mockInviteService.EXPECT().List(ctx, spaceId).Return([]domain.Invite{invite}, nil)
+		return fx, exec.ActualAccounts()["b"].Keys.SignKey.GetPublic(), inviteId
+	}
+
+	t.Run("default permissions of invite", func(t *testing.T) {
+		// given
+		fx, identity, _ := prepare(t, domain.Invite{InviteParams: domain.InviteParams{Permissions: model.ParticipantPermissions_Writer}})
+		defer fx.finish(t)
+		fx.mockSpaceClient.EXPECT().AcceptRequest(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, payload list.RequestAcceptPayload) error {
+			require.Equal(t, list.AclPermissionsWriter, payload.Permissions)
+			return nil
+		})
+
+		// when
+		err := fx.Accept(ctx, spaceId, identity, model.ParticipantPermissions_NoPermissions)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("explicit permissions override invite ones", func(t *testing.T) {
+		// given
+		fx, identity, _ := prepare(t, domain.Invite{InviteParams: domain.InviteParams{Permissions: model.ParticipantPermissions_Writer}})
+		defer fx.finish(t)
+		fx.mockSpaceClient.EXPECT().AcceptRequest(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, payload list.RequestAcceptPayload) error {
+			require.Equal(t, list.AclPermissionsReader, payload.Permissions)
+			return nil
+		})
+
+		// when
+		err := fx.Accept(ctx, spaceId, identity, model.ParticipantPermissions_Reader)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("revoke invite when max uses reached", func(t *testing.T) {
+		// given
+		fx, identity, inviteId := prepare(t, domain.Invite{InviteParams: domain.InviteParams{Permissions: model.ParticipantPermissions_Reader, MaxUses: 1}})
+		defer fx.finish(t)
+		fx.mockSpaceClient.EXPECT().AcceptRequest(ctx, gomock.Any()).Return(nil)
+		fx.mockSpaceClient.EXPECT().RevokeInvite(ctx, inviteId).Return(nil)
+		fx.mockInviteService.EXPECT().Revoke(ctx, spaceId, inviteId).Return(nil)
+
+		// when
+		err := fx.Accept(ctx, spaceId, identity, model.ParticipantPermissions_NoPermissions)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("expired invite", func(t *testing.T) {
+		// given
+		fx, identity, _ := prepare(t, domain.Invite{InviteParams: domain.InviteParams{Permissions: model.ParticipantPermissions_Reader, ExpireDate: 50}})
+		defer fx.finish(t)
+
+		// when
+		err := fx.Accept(ctx, spaceId, identity, model.ParticipantPermissions_NoPermissions)
+
+		// then
+		require.ErrorIs(t, err, inviteservice.ErrInviteExpired)
+	})
+
+	t.Run("revoked invite", func(t *testing.T) {
+		// given
+		fx, identity, _ := prepare(t, domain.Invite{InviteParams: domain.InviteParams{Permissions: model.ParticipantPermissions_Reader}, RevokedDate: 50})
+		defer fx.finish(t)
+
+		// when
+		err := fx.Accept(ctx, spaceId, identity, model.ParticipantPermissions_NoPermissions)
+
+		// then
+		require.ErrorIs(t, err, inviteservice.ErrInviteRevoked)
+	})
+}
+
+func TestService_RevokeInvite(t *testing.T) {
+	t.Run("scoped invites are kept", func(t *testing.T) {
+		// given
+		const spaceId = "spaceId"
+		fx := newFixture(t)
+		defer fx.finish(t)
+		exec, _, scopedInviteId := prepareInviteAcl(t, spaceId)
+		require.NoError(t, exec.Execute("a.invite::legacyInvId"))
+		acl := mockSyncAcl{exec.ActualAccounts()["a"].Acl}
+		var legacyInviteId string
+		for _, id := range acl.AclState().InviteIds() {
+			if id != scopedInviteId {
+				legacyInviteId = id
+			}
+		}
+		fx.mockSpaceService.EXPECT().Get(ctx, spaceId).Return(fx.mockClientSpace, nil)
+		fx.mockClientSpace.EXPECT().CommonSpace().Return(fx.mockCommonSpace)
+		fx.mockCommonSpace.EXPECT().Acl().Return(acl)
+		fx.mockCommonSpace.EXPECT().AclClient().Return(fx.mockSpaceClient)
+		fx.mockInviteService.EXPECT().List(ctx, spaceId).Return([]domain.Invite{{RecordId: scopedInviteId}}, nil)
+		fx.mockSpaceClient.EXPECT().RevokeInvite(ctx, legacyInviteId).Return(nil)
+		fx.mockInviteService.EXPECT().RemoveExisting(ctx, spaceId).Return(nil)
+
+		// when
+		err := fx.RevokeInvite(ctx, spaceId)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestCollectInviteJoins(t *testing.T) {
+	// given
+	exec, acl, inviteId := prepareInviteAcl(t, "spaceId", "b.join::invId", "c.join::invId", "a.approve::b,r")
+	invites := []domain.Invite{{RecordId: inviteId}, {RecordId: "other"}}
+
+	// when
+	acl.RLock()
+	joins := collectInviteJoins(acl)
+	acl.RUnlock()
+	fillInviteUses(invites, joins)
+
+	// then
+	require.Len(t, joins, 2)
+	require.Equal(t, exec.ActualAccounts()["b"].Keys.SignKey.GetPublic().Account(), joins[0].Identity)
+	require.True(t, joins[0].Accepted)
+	require.Equal(t, exec.ActualAccounts()["c"].Keys.SignKey.GetPublic().Account(), joins[1].Identity)
+	require.False(t, joins[1].Accepted)
+	for _, join := range joins {
+		require.Equal(t, inviteId, join.InviteRecordId)
+	}
+	require.Equal(t, 1, invites[0].Uses)
+	require.Equal(t, 0, invites[1].Uses)
+}
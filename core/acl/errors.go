@@ -21,6 +21,7 @@ var (
 	ErrLimitReached         = errors.New("limit reached")
 	ErrDifferentNetwork     = errors.New("different network")
 	ErrInternal             = errors.New("internal error")
+	ErrInvalidInviteParams  = errors.New("invalid invite params")
 )
 
 var passthroughErrors = []error{
@@ -30,6 +31,8 @@ var passthroughErrors = []error{
 	inviteservice.ErrInviteGenerate,
 	inviteservice.ErrInviteRemove,
 	inviteservice.ErrInviteBadContent,
+	inviteservice.ErrInviteExpired,
+	inviteservice.ErrInviteExhausted,
 }
 
 func convertErrorOrReturn(err, otherErr error) error {
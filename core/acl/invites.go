@@ -0,0 +1,166 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anyproto/any-sync/commonspace/object/acl/aclrecordproto"
+	"github.com/anyproto/any-sync/commonspace/object/acl/list"
+	"github.com/anyproto/any-sync/util/crypto"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func (a *aclService) CreateInvite(ctx context.Context, spaceId string, params domain.InviteParams) (domain.Invite, error) {
+	if spaceId == a.accountService.PersonalSpaceID() {
+		return domain.Invite{}, ErrPersonalSpace
+	}
+	if params.Permissions != model.ParticipantPermissions_Reader && params.Permissions != model.ParticipantPermissions_Writer {
+		return domain.Invite{}, ErrIncorrectPermissions
+	}
+	if params.MaxUses < 0 {
+		return domain.Invite{}, fmt.Errorf("%w: negative max uses", ErrInvalidInviteParams)
+	}
+	if params.ExpireDate < 0 || (params.ExpireDate > 0 && params.ExpireDate <= a.now().Unix()) {
+		return domain.Invite{}, fmt.Errorf("%w: expire date is in the past", ErrInvalidInviteParams)
+	}
+	sp, err := a.spaceService.Get(ctx, spaceId)
+	if err != nil {
+		return domain.Invite{}, convertedOrSpaceErr(err)
+	}
+	aclClient := sp.CommonSpace().AclClient()
+	res, err := aclClient.GenerateInvite()
+	if err != nil {
+		return domain.Invite{}, convertedOrInternalError("couldn't generate acl invite", err)
+	}
+	var recordId string
+	invite, err := a.inviteService.GenerateWithParams(ctx, spaceId, res.InviteKey, params, func() (string, error) {
+		if err := aclClient.AddRecord(ctx, res.InviteRec); err != nil {
+			return "", convertedOrAclRequestError(err)
+		}
+		acl := sp.CommonSpace().Acl()
+		acl.RLock()
+		defer acl.RUnlock()
+		var err error
+		recordId, err = acl.AclState().GetInviteIdByPrivKey(res.InviteKey)
+		return recordId, err
+	})
+	if err != nil {
+		if recordId != "" {
+			// invite without metadata can't be listed, so it would be impossible to revoke it
+			if revokeErr := aclClient.RevokeInvite(ctx, recordId); revokeErr != nil {
+				log.Error("revoke invite", zap.String("inviteRecordId", recordId), zap.Error(revokeErr))
+			}
+		}
+		return domain.Invite{}, convertedOrInternalError("generate invite", err)
+	}
+	return invite, nil
+}
+
+func (a *aclService) ListInvites(ctx context.Context, spaceId string) ([]domain.Invite, []domain.InviteJoin, error) {
+	sp, err := a.spaceService.Get(ctx, spaceId)
+	if err != nil {
+		return nil, nil, convertedOrSpaceErr(err)
+	}
+	invites, err := a.inviteService.List(ctx, spaceId)
+	if err != nil {
+		return nil, nil, convertedOrInternalError("list invites", err)
+	}
+	acl := sp.CommonSpace().Acl()
+	acl.RLock()
+	joins := collectInviteJoins(acl)
+	acl.RUnlock()
+	fillInviteUses(invites, joins)
+	return invites, joins, nil
+}
+
+func (a *aclService) RevokeInviteById(ctx context.Context, spaceId string, inviteRecordId string) error {
+	sp, err := a.spaceService.Get(ctx, spaceId)
+	if err != nil {
+		return convertedOrSpaceErr(err)
+	}
+	cl := sp.CommonSpace().AclClient()
+	err = cl.RevokeInvite(ctx, inviteRecordId)
+	// invite could be already revoked together with all invites, we still need to mark it revoked
+	if err != nil && !errors.Is(err, list.ErrNoSuchInvite) {
+		return convertedOrAclRequestError(err)
+	}
+	err = a.inviteService.Revoke(ctx, spaceId, inviteRecordId)
+	if err != nil {
+		return convertedOrInternalError("revoke invite", err)
+	}
+	return nil
+}
+
+// requestInvite finds the invite used for the join request of identity. Requests made through the current invite
+// or through invites of older versions have no invite metadata
+func (a *aclService) requestInvite(ctx context.Context, spaceId string, identity crypto.PubKey, joins []domain.InviteJoin) (invite domain.Invite, found bool, err error) {
+	var inviteRecordId string
+	for _, join := range joins {
+		if join.Identity == identity.Account() {
+			// the latest request is the pending one
+			inviteRecordId = join.InviteRecordId
+		}
+	}
+	if inviteRecordId == "" {
+		return domain.Invite{}, false, nil
+	}
+	invites, err := a.inviteService.List(ctx, spaceId)
+	if err != nil {
+		return domain.Invite{}, false, convertedOrInternalError("list invites", err)
+	}
+	fillInviteUses(invites, joins)
+	for _, inv := range invites {
+		if inv.RecordId == inviteRecordId {
+			return inv, true, nil
+		}
+	}
+	return domain.Invite{}, false, nil
+}
+
+// collectInviteJoins returns join requests in order of acl records. It should be called under the acl lock
+func collectInviteJoins(acl list.AclList) []domain.InviteJoin {
+	var (
+		joins          []domain.InviteJoin
+		joinRecordIds  []string
+		acceptedJoinId = map[string]bool{}
+	)
+	for _, rec := range acl.Records() {
+		data, ok := rec.Model.(*aclrecordproto.AclData)
+		if !ok {
+			continue
+		}
+		for _, content := range data.GetAclContent() {
+			if join := content.GetAccountRequestJoin(); join != nil {
+				joins = append(joins, domain.InviteJoin{
+					Identity:       rec.Identity.Account(),
+					InviteRecordId: join.InviteRecordId,
+					RequestDate:    rec.Timestamp,
+				})
+				joinRecordIds = append(joinRecordIds, rec.Id)
+			}
+			if accept := content.GetAccountRequestAccept(); accept != nil {
+				acceptedJoinId[accept.RequestRecordId] = true
+			}
+		}
+	}
+	for i := range joins {
+		joins[i].Accepted = acceptedJoinId[joinRecordIds[i]]
+	}
+	return joins
+}
+
+func fillInviteUses(invites []domain.Invite, joins []domain.InviteJoin) {
+	uses := map[string]int{}
+	for _, join := range joins {
+		if join.Accepted {
+			uses[join.InviteRecordId]++
+		}
+	}
+	for i := range invites {
+		invites[i].Uses = uses[invites[i].RecordId]
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/anyproto/any-sync/app"
 	//nolint:misspell
@@ -53,10 +54,22 @@ type FileConfig interface {
 }
 
 type ConfigRequired struct {
-	HostAddr            string `json:",omitempty"`
-	CustomFileStorePath string `json:",omitempty"`
-	LegacyFileStorePath string `json:",omitempty"`
-	NetworkId           string `json:""` // in case this account was at least once connected to the network on this device, this field will be set to the network id
+	HostAddr            string         `json:",omitempty"`
+	CustomFileStorePath string         `json:",omitempty"`
+	LegacyFileStorePath string         `json:",omitempty"`
+	NetworkId           string         `json:""` // in case this account was at least once connected to the network on this device, this field will be set to the network id
+	StoragePolicy       *StoragePolicy `json:",omitempty"`
+}
+
+// StoragePolicy limits local storage used by files. Zero values disable corresponding rules
+type StoragePolicy struct {
+	MaxLocalCacheBytes     uint64 `json:",omitempty"`
+	OffloadNotOpenedDays   int    `json:",omitempty"`
+	OffloadLargerThanBytes uint64 `json:",omitempty"`
+}
+
+func (p StoragePolicy) IsEmpty() bool {
+	return p.MaxLocalCacheBytes == 0 && p.OffloadNotOpenedDays == 0 && p.OffloadLargerThanBytes == 0
 }
 
 type Config struct {
@@ -406,17 +419,42 @@ func (c *Config) GetQuic() quic.Config {
 }
 
 func (c *Config) ResetStoredNetworkId() error {
+	storagePolicyLock.Lock()
 	configCopy := c.ConfigRequired
+	storagePolicyLock.Unlock()
 	configCopy.NetworkId = ""
 	return WriteJsonConfig(c.GetConfigPath(), configCopy)
 }
 
 func (c *Config) PersistAccountNetworkId() error {
+	storagePolicyLock.Lock()
 	configCopy := c.ConfigRequired
+	storagePolicyLock.Unlock()
 	configCopy.NetworkId = c.NetworkId
 	return WriteJsonConfig(c.GetConfigPath(), configCopy)
 }
 
+// storagePolicyLock guards the storage policy, it is changed by rpc and read by the storage policy service.
+// Config is copied by value, so the lock is not a field of it
+var storagePolicyLock sync.Mutex
+
+func (c *Config) PersistStoragePolicy(policy StoragePolicy) error {
+	storagePolicyLock.Lock()
+	defer storagePolicyLock.Unlock()
+	c.StoragePolicy = &policy
+	configCopy := c.ConfigRequired
+	return WriteJsonConfig(c.GetConfigPath(), configCopy)
+}
+
+func (c *Config) GetStoragePolicy() StoragePolicy {
+	storagePolicyLock.Lock()
+	defer storagePolicyLock.Unlock()
+	if c.StoragePolicy == nil {
+		return StoragePolicy{}
+	}
+	return *c.StoragePolicy
+}
+
 func (c *Config) GetSpaceStorageMode() storage.SpaceStorageMode {
 	return c.SpaceStorageMode
 }
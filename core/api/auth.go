@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+type ctxKey int
+
+const appLinkCtxKey ctxKey = iota
+
+const (
+	scopeFull     = "full"
+	scopeReadOnly = "read_only"
+)
+
+var (
+	errUnauthorized = errors.New("missing or invalid app key")
+	errExpired      = errors.New("app key is expired")
+	errReadOnly     = errors.New("app key is read-only")
+	errSpaceDenied  = errors.New("space is not available to the app key")
+)
+
+type challengeRequest struct {
+	AppName string `json:"app_name"`
+	Scope   string `json:"scope"`
+	// SpaceIds limits the key to the given spaces
+	SpaceIds []string `json:"space_ids"`
+	// UnredactedEvents allows the key to read events with texts and details
+	UnredactedEvents bool `json:"unredacted_events"`
+}
+
+type challengeResponse struct {
+	ChallengeId string `json:"challenge_id"`
+}
+
+type tokenRequest struct {
+	ChallengeId string `json:"challenge_id"`
+	// Code is the challenge value shown to the user in the client app
+	Code string `json:"code"`
+}
+
+type tokenResponse struct {
+	AppKey string `json:"app_key"`
+}
+
+// createChallenge starts the local link challenge, the user approves the app by entering the code shown in the client app
+func (s *Server) createChallenge(w http.ResponseWriter, r *http.Request) {
+	var req challengeRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.AppName == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("app_name is required"))
+		return
+	}
+	scope, err := parseScope(req.Scope)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp := s.mw.AccountLocalLinkNewChallenge(r.Context(), &pb.RpcAccountLocalLinkNewChallengeRequest{
+		AppName:          req.AppName,
+		Scope:            scope,
+		SpaceIds:         req.SpaceIds,
+		UnredactedEvents: req.UnredactedEvents,
+	})
+	switch resp.Error.Code {
+	case pb.RpcAccountLocalLinkNewChallengeResponseError_NULL:
+		writeJSON(w, http.StatusCreated, challengeResponse{ChallengeId: resp.ChallengeId})
+	case pb.RpcAccountLocalLinkNewChallengeResponseError_ACCOUNT_IS_NOT_RUNNING:
+		writeError(w, http.StatusServiceUnavailable, errAccountNotRunning)
+	case pb.RpcAccountLocalLinkNewChallengeResponseError_TOO_MANY_REQUESTS:
+		writeError(w, http.StatusTooManyRequests, errors.New(resp.Error.Description))
+	default:
+		writeError(w, http.StatusInternalServerError, errors.New(resp.Error.Description))
+	}
+}
+
+func (s *Server) solveChallenge(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp := s.mw.AccountLocalLinkSolveChallenge(r.Context(), &pb.RpcAccountLocalLinkSolveChallengeRequest{
+		ChallengeId: req.ChallengeId,
+		Answer:      req.Code,
+	})
+	switch resp.Error.Code {
+	case pb.RpcAccountLocalLinkSolveChallengeResponseError_NULL:
+		// the API authorizes every request by the app key, so the gRPC session is not needed
+		if resp.SessionToken != "" {
+			s.mw.WalletCloseSession(r.Context(), &pb.RpcWalletCloseSessionRequest{Token: resp.SessionToken})
+		}
+		writeJSON(w, http.StatusCreated, tokenResponse{AppKey: resp.AppKey})
+	case pb.RpcAccountLocalLinkSolveChallengeResponseError_ACCOUNT_IS_NOT_RUNNING:
+		writeError(w, http.StatusServiceUnavailable, errAccountNotRunning)
+	case pb.RpcAccountLocalLinkSolveChallengeResponseError_INVALID_CHALLENGE_ID,
+		pb.RpcAccountLocalLinkSolveChallengeResponseError_INCORRECT_ANSWER:
+		writeError(w, http.StatusUnauthorized, errors.New(resp.Error.Description))
+	case pb.RpcAccountLocalLinkSolveChallengeResponseError_CHALLENGE_ATTEMPTS_EXCEEDED:
+		writeError(w, http.StatusTooManyRequests, errors.New(resp.Error.Description))
+	default:
+		writeError(w, http.StatusInternalServerError, errors.New(resp.Error.Description))
+	}
+}
+
+// authenticate checks the app key passed as a bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appKey, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || appKey == "" {
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		// the link is read on every request, so removed keys stop working immediately
+		link, err := s.readLink(appKey)
+		if errors.Is(err, errAccountNotRunning) {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		if err != nil {
+			log.Warnf("read app link: %v", err)
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		if link.ExpireAt > 0 && s.now().Unix() > link.ExpireAt {
+			writeError(w, http.StatusUnauthorized, errExpired)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(contextWithAppLink(r.Context(), link)))
+	})
+}
+
+func (s *Server) requireFullScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasFullScope(r.Context()) {
+			writeError(w, http.StatusForbidden, errReadOnly)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireSpace rejects requests to spaces the app key is not issued for
+func (s *Server) requireSpace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isSpaceAllowed(r.Context(), chi.URLParam(r, "spaceId")) {
+			writeError(w, http.StatusForbidden, errSpaceDenied)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func contextWithAppLink(ctx context.Context, link *wallet.AppLinkPayload) context.Context {
+	return context.WithValue(ctx, appLinkCtxKey, link)
+}
+
+func hasFullScope(ctx context.Context) bool {
+	link, ok := ctx.Value(appLinkCtxKey).(*wallet.AppLinkPayload)
+	return ok && model.AccountAuthLocalApiScope(link.Scope) == model.AccountAuth_Full
+}
+
+// allowedSpaces returns spaces the app key is issued for, all spaces are allowed when it's empty
+func allowedSpaces(ctx context.Context) []string {
+	if link, ok := ctx.Value(appLinkCtxKey).(*wallet.AppLinkPayload); ok {
+		return link.SpaceIds
+	}
+	return nil
+}
+
+func isSpaceAllowed(ctx context.Context, spaceId string) bool {
+	spaceIds := allowedSpaces(ctx)
+	return len(spaceIds) == 0 || slices.Contains(spaceIds, spaceId)
+}
+
+// restrictSpaces narrows the requested spaces down to spaces of the app key, all spaces of the key are used
+// when nothing is requested. It returns false when none of the requested spaces is available
+func restrictSpaces(ctx context.Context, spaceIds []string) ([]string, bool) {
+	keySpaceIds := allowedSpaces(ctx)
+	if len(keySpaceIds) == 0 {
+		return spaceIds, true
+	}
+	if len(spaceIds) == 0 {
+		return keySpaceIds, true
+	}
+	spaceIds = slices.DeleteFunc(slices.Clone(spaceIds), func(id string) bool {
+		return !slices.Contains(keySpaceIds, id)
+	})
+	return spaceIds, len(spaceIds) > 0
+}
+
+func allowsUnredactedEvents(ctx context.Context) bool {
+	link, ok := ctx.Value(appLinkCtxKey).(*wallet.AppLinkPayload)
+	return ok && link.UnredactedEvents
+}
+
+func (s *Server) readAppLink(appKey string) (*wallet.AppLinkPayload, error) {
+	a, err := s.app()
+	if err != nil {
+		return nil, err
+	}
+	return app.MustComponent[wallet.Wallet](a).ReadAppLink(appKey)
+}
+
+func parseScope(scope string) (model.AccountAuthLocalApiScope, error) {
+	switch scope {
+	case "", scopeFull:
+		return model.AccountAuth_Full, nil
+	case scopeReadOnly:
+		return model.AccountAuth_ReadOnly, nil
+	}
+	return 0, fmt.Errorf("unknown scope: %s", scope)
+}
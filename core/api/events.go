@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anyproto/anytype-heart/core/event/eventlog"
+)
+
+// EnableEvents serves events of the stream as json lines at /v1/events of the handler
+func (s *Server) EnableEvents(stream *eventlog.Stream) {
+	s.events = stream
+}
+
+var errUnredactedEvents = errors.New("app key can't read unredacted events")
+
+// parseEventOptions reads filters of the events request. Values of the filters are comma-separated,
+// parameters could also be repeated. Events are anonymized by default unless the app key allows unredacted events
+func parseEventOptions(r *http.Request) (eventlog.Options, error) {
+	query := r.URL.Query()
+	list := func(key string) []string {
+		var res []string
+		for _, value := range query[key] {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					res = append(res, item)
+				}
+			}
+		}
+		return res
+	}
+	opts := eventlog.Options{Filter: eventlog.Filter{
+		ContextIds: list("context_id"),
+		Types:      list("type"),
+		SpaceIds:   list("space_id"),
+	}}
+	opts.Anonymize = !allowsUnredactedEvents(r.Context())
+	if v := query.Get("anonymize"); v != "" {
+		anonymize, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid anonymize: %s", v)
+		}
+		if !anonymize && !allowsUnredactedEvents(r.Context()) {
+			return opts, errUnredactedEvents
+		}
+		opts.Anonymize = anonymize
+	}
+	return opts, nil
+}
+
+// streamEvents writes events until the client disconnects, only events sent after the request are written
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseEventOptions(r)
+	if errors.Is(err, errUnredactedEvents) {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var ok bool
+	if opts.Filter.SpaceIds, ok = restrictSpaces(r.Context(), opts.Filter.SpaceIds); !ok {
+		writeError(w, http.StatusForbidden, errSpaceDenied)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	_ = s.events.Serve(r.Context(), opts, w, flusher.Flush)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/event/eventlog"
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func TestParseEventOptions(t *testing.T) {
+	t.Run("filters", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/events?context_id=page1,page2&type=blockSetText&type=objectDetailsSet&space_id=space1&anonymize=true", nil)
+
+		opts, err := parseEventOptions(r)
+
+		require.NoError(t, err)
+		assert.Equal(t, eventlog.Options{
+			Filter: eventlog.Filter{
+				ContextIds: []string{"page1", "page2"},
+				Types:      []string{"blockSetText", "objectDetailsSet"},
+				SpaceIds:   []string{"space1"},
+			},
+			Anonymize: true,
+		}, opts)
+	})
+
+	t.Run("no filters", func(t *testing.T) {
+		opts, err := parseEventOptions(httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+
+		require.NoError(t, err)
+		assert.True(t, opts.IsEmpty())
+		assert.True(t, opts.Anonymize)
+	})
+
+	t.Run("unredacted events", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/events?anonymize=false", nil)
+
+		_, err := parseEventOptions(r)
+		require.ErrorIs(t, err, errUnredactedEvents)
+
+		link := &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full), UnredactedEvents: true}
+		opts, err := parseEventOptions(r.WithContext(contextWithAppLink(r.Context(), link)))
+		require.NoError(t, err)
+		assert.False(t, opts.Anonymize)
+
+		opts, err = parseEventOptions(httptest.NewRequest(http.MethodGet, "/v1/events", nil).WithContext(contextWithAppLink(r.Context(), link)))
+		require.NoError(t, err)
+		assert.False(t, opts.Anonymize)
+	})
+
+	t.Run("invalid anonymize", func(t *testing.T) {
+		_, err := parseEventOptions(httptest.NewRequest(http.MethodGet, "/v1/events?anonymize=maybe", nil))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestServer_StreamEvents(t *testing.T) {
+	t.Run("spaces of the app key", func(t *testing.T) {
+		// given
+		s := &Server{}
+		r := httptest.NewRequest(http.MethodGet, "/v1/events?space_id=space2", nil)
+		link := &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full), SpaceIds: []string{"space1"}}
+		r = r.WithContext(contextWithAppLink(r.Context(), link))
+		w := httptest.NewRecorder()
+
+		// when
+		s.streamEvents(w, r)
+
+		// then
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
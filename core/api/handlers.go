@@ -0,0 +1,625 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/globalsign/mgo/bson"
+	"github.com/go-chi/chi/v5"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block"
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/template"
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/converter/md"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/core/subscription"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	coresb "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+var (
+	errObjectNotFound = errors.New("object not found")
+	errNotAList       = errors.New("object is not a set or collection")
+	errViewNotFound   = errors.New("view not found")
+	errInvalidRequest = errors.New("invalid request")
+)
+
+type space struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type object struct {
+	Id       string         `json:"id"`
+	SpaceId  string         `json:"space_id"`
+	Name     string         `json:"name"`
+	TypeId   string         `json:"type_id"`
+	Details  map[string]any `json:"details"`
+	Markdown string         `json:"markdown,omitempty"`
+}
+
+type objectType struct {
+	Id   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type relation struct {
+	Id     string `json:"id"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Format string `json:"format"`
+}
+
+type searchRequest struct {
+	Query string `json:"query"`
+	// Types is the list of type keys, e.g. "page" or "task"
+	Types  []string `json:"types"`
+	Offset int      `json:"offset"`
+	Limit  int      `json:"limit"`
+}
+
+type createObjectRequest struct {
+	// Type is the type key, page is used by default
+	Type      string         `json:"type"`
+	Name      string         `json:"name"`
+	IconEmoji string         `json:"icon_emoji"`
+	Details   map[string]any `json:"details"`
+	Markdown  string         `json:"markdown"`
+}
+
+type updateObjectRequest struct {
+	Name      *string        `json:"name"`
+	IconEmoji *string        `json:"icon_emoji"`
+	Details   map[string]any `json:"details"`
+	// Markdown replaces the whole body of the object when set
+	Markdown *string `json:"markdown"`
+}
+
+func (s *Server) listSpaces(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	spaces, hasMore, err := querySpaces(a, offset, limit, allowedSpaces(r.Context())...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Data: spaces, Pagination: pagination{Offset: offset, Limit: limit, HasMore: hasMore}})
+}
+
+// querySpaces returns spaces of the account, only the given spaces are returned when they are passed
+func querySpaces(a *app.App, offset, limit int, spaceIds ...string) ([]space, bool, error) {
+	filters := []*model.BlockContentDataviewFilter{
+		filterEqual(bundle.RelationKeyLayout, pbtypes.Int64(int64(model.ObjectType_spaceView))),
+		filterEqual(bundle.RelationKeySpaceLocalStatus, pbtypes.Int64(int64(model.SpaceStatus_Ok))),
+		{
+			RelationKey: bundle.RelationKeySpaceAccountStatus.String(),
+			Condition:   model.BlockContentDataviewFilter_NotIn,
+			Value:       pbtypes.IntList(int(model.SpaceStatus_SpaceDeleted), int(model.SpaceStatus_SpaceRemoving)),
+		},
+	}
+	if len(spaceIds) > 0 {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeyTargetSpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(spaceIds),
+		})
+	}
+	records, hasMore, err := queryPage(a, filters, offset, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	spaces := make([]space, 0, len(records))
+	for _, rec := range records {
+		spaces = append(spaces, space{
+			Id:   pbtypes.GetString(rec.Details, bundle.RelationKeyTargetSpaceId.String()),
+			Name: pbtypes.GetString(rec.Details, bundle.RelationKeyName.String()),
+		})
+	}
+	return spaces, hasMore, nil
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	records, hasMore, err := queryPage(a, spaceFilters(chi.URLParam(r, "spaceId")), offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Data: recordsToObjects(records), Pagination: pagination{Offset: offset, Limit: limit, HasMore: hasMore}})
+}
+
+// search runs the full-text search, it's limited to the space when called via the space route
+// and to the spaces of the app key otherwise
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	var req searchRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	spaceIds := allowedSpaces(r.Context())
+	if spaceId := chi.URLParam(r, "spaceId"); spaceId != "" {
+		spaceIds = []string{spaceId}
+	}
+	objects, page, err := searchObjects(a, req, spaceIds...)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Data: objects, Pagination: page})
+}
+
+// searchObjects runs the full-text search in the given spaces, all spaces are searched when no space is passed
+func searchObjects(a *app.App, req searchRequest, spaceIds ...string) ([]object, pagination, error) {
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, pagination{}, fmt.Errorf("%w: invalid paging", errInvalidRequest)
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	req.Limit = min(req.Limit, maxLimit)
+
+	filters := spaceFilters(spaceIds...)
+	if len(req.Types) > 0 {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: database.NestedRelationKey(bundle.RelationKeyType, bundle.RelationKeyUniqueKey),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(typeUniqueKeys(req.Types)),
+		})
+	}
+	records, err := app.MustComponent[objectstore.ObjectStore](a).Query(database.Query{
+		FullText: req.Query,
+		Filters:  filters,
+		Offset:   req.Offset,
+		Limit:    req.Limit + 1,
+	})
+	if err != nil {
+		return nil, pagination{}, err
+	}
+	hasMore := len(records) > req.Limit
+	if hasMore {
+		records = records[:req.Limit]
+	}
+	return recordsToObjects(records), pagination{Offset: req.Offset, Limit: req.Limit, HasMore: hasMore}, nil
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request) {
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	id := domain.FullID{SpaceID: chi.URLParam(r, "spaceId"), ObjectID: chi.URLParam(r, "objectId")}
+	obj, err := readObject(r.Context(), a, id)
+	if errors.Is(err, errObjectNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func (s *Server) createObject(w http.ResponseWriter, r *http.Request) {
+	var req createObjectRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	obj, err := createObject(r.Context(), a, chi.URLParam(r, "spaceId"), req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, obj)
+}
+
+func createObject(ctx context.Context, a *app.App, spaceId string, req createObjectRequest) (*object, error) {
+	if req.Type == "" {
+		req.Type = bundle.TypeKeyPage.String()
+	}
+	details := pbtypes.ToStruct(req.Details)
+	if req.Name != "" {
+		details.Fields[bundle.RelationKeyName.String()] = pbtypes.String(req.Name)
+	}
+	if req.IconEmoji != "" {
+		details.Fields[bundle.RelationKeyIconEmoji.String()] = pbtypes.String(req.IconEmoji)
+	}
+	typeKey := domain.MustUniqueKey(coresb.SmartBlockTypeObjectType, req.Type).Marshal()
+	id, _, err := app.MustComponent[objectcreator.Service](a).CreateObjectUsingObjectUniqueTypeKey(ctx, spaceId, typeKey, objectcreator.CreateObjectRequest{
+		Details: details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: create object: %w", errInvalidRequest, err)
+	}
+	if req.Markdown != "" {
+		if err = pasteMarkdown(app.MustComponent[*block.Service](a), id, req.Markdown, nil); err != nil {
+			return nil, err
+		}
+	}
+	return readObject(ctx, a, domain.FullID{SpaceID: spaceId, ObjectID: id})
+}
+
+func (s *Server) updateObject(w http.ResponseWriter, r *http.Request) {
+	var req updateObjectRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	id := domain.FullID{SpaceID: chi.URLParam(r, "spaceId"), ObjectID: chi.URLParam(r, "objectId")}
+	obj, err := updateObject(r.Context(), a, id, req)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func updateObject(ctx context.Context, a *app.App, id domain.FullID, req updateObjectRequest) (*object, error) {
+	if _, err := objectDetails(a, id); err != nil {
+		return nil, errObjectNotFound
+	}
+	bs := app.MustComponent[*block.Service](a)
+
+	details := make([]*model.Detail, 0, len(req.Details)+2)
+	for key, value := range req.Details {
+		details = append(details, &model.Detail{Key: key, Value: pbtypes.ToValue(value)})
+	}
+	if req.Name != nil {
+		details = append(details, &model.Detail{Key: bundle.RelationKeyName.String(), Value: pbtypes.String(*req.Name)})
+	}
+	if req.IconEmoji != nil {
+		details = append(details, &model.Detail{Key: bundle.RelationKeyIconEmoji.String(), Value: pbtypes.String(*req.IconEmoji)})
+	}
+	if len(details) > 0 {
+		if err := bs.SetDetails(nil, id.ObjectID, details); err != nil {
+			return nil, fmt.Errorf("%w: set details: %w", errInvalidRequest, err)
+		}
+	}
+	if req.Markdown != nil {
+		var bodyIds []string
+		err := cache.Do(bs, id.ObjectID, func(sb smartblock.SmartBlock) error {
+			for _, childId := range sb.Doc.Pick(sb.RootId()).Model().ChildrenIds {
+				if childId != template.HeaderLayoutId {
+					bodyIds = append(bodyIds, childId)
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			err = pasteMarkdown(bs, id.ObjectID, *req.Markdown, bodyIds)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return readObject(ctx, a, id)
+}
+
+func (s *Server) listTypes(w http.ResponseWriter, r *http.Request) {
+	s.listByLayout(w, r, model.ObjectType_objectType, detailsToType)
+}
+
+func (s *Server) listRelations(w http.ResponseWriter, r *http.Request) {
+	s.listByLayout(w, r, model.ObjectType_relation, detailsToRelation)
+}
+
+func detailsToType(details *types.Struct) any {
+	var key string
+	if uk, err := domain.UnmarshalUniqueKey(pbtypes.GetString(details, bundle.RelationKeyUniqueKey.String())); err == nil {
+		key = uk.InternalKey()
+	}
+	return objectType{
+		Id:   pbtypes.GetString(details, bundle.RelationKeyId.String()),
+		Key:  key,
+		Name: pbtypes.GetString(details, bundle.RelationKeyName.String()),
+	}
+}
+
+func detailsToRelation(details *types.Struct) any {
+	return relation{
+		Id:     pbtypes.GetString(details, bundle.RelationKeyId.String()),
+		Key:    pbtypes.GetString(details, bundle.RelationKeyRelationKey.String()),
+		Name:   pbtypes.GetString(details, bundle.RelationKeyName.String()),
+		Format: model.RelationFormat(pbtypes.GetInt64(details, bundle.RelationKeyRelationFormat.String())).String(),
+	}
+}
+
+func (s *Server) listByLayout(w http.ResponseWriter, r *http.Request, layout model.ObjectTypeLayout, convert func(details *types.Struct) any) {
+	offset, limit, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	data, hasMore, err := queryByLayout(a, chi.URLParam(r, "spaceId"), layout, convert, offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Data: data, Pagination: pagination{Offset: offset, Limit: limit, HasMore: hasMore}})
+}
+
+func queryByLayout(a *app.App, spaceId string, layout model.ObjectTypeLayout, convert func(details *types.Struct) any, offset, limit int) ([]any, bool, error) {
+	filters := append(spaceFilters(spaceId), filterEqual(bundle.RelationKeyLayout, pbtypes.Int64(int64(layout))))
+	records, hasMore, err := queryPage(a, filters, offset, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	data := make([]any, 0, len(records))
+	for _, rec := range records {
+		data = append(data, convert(rec.Details))
+	}
+	return data, hasMore, nil
+}
+
+// listViewObjects returns objects of the set or collection using filters and sorts of its view,
+// the first view is used unless view_id is passed
+func (s *Server) listViewObjects(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := parsePaging(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a, err := s.app()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	id := domain.FullID{SpaceID: chi.URLParam(r, "spaceId"), ObjectID: chi.URLParam(r, "listId")}
+	objects, page, err := queryView(r.Context(), a, id, r.URL.Query().Get("view_id"), offset, limit)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Data: objects, Pagination: page})
+}
+
+// queryView returns objects of the set or collection using filters and sorts of the view, the first view is used when viewId is empty
+func queryView(ctx context.Context, a *app.App, id domain.FullID, viewId string, offset, limit int) ([]object, pagination, error) {
+	details, err := objectDetails(a, id)
+	if err != nil {
+		return nil, pagination{}, errObjectNotFound
+	}
+
+	var (
+		view         *model.BlockContentDataviewView
+		isCollection bool
+	)
+	err = cache.DoContextFullID(app.MustComponent[*block.Service](a), ctx, id, func(sb smartblock.SmartBlock) error {
+		b := sb.Doc.Pick(template.DataviewBlockId)
+		if b == nil || b.Model().GetDataview() == nil {
+			return errNotAList
+		}
+		dv := b.Model().GetDataview()
+		isCollection = dv.IsCollection
+		for _, v := range dv.Views {
+			if viewId == "" || v.Id == viewId {
+				view = v
+				break
+			}
+		}
+		if view == nil {
+			return errViewNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, pagination{}, err
+	}
+
+	req := pb.RpcObjectSearchSubscribeRequest{
+		SubId:             "local-api-" + bson.NewObjectId().Hex(),
+		Filters:           append(spaceFilters(id.SpaceID), view.Filters...),
+		Sorts:             view.Sorts,
+		Offset:            int64(offset),
+		Limit:             int64(limit),
+		NoDepSubscription: true,
+	}
+	if isCollection {
+		req.CollectionId = id.ObjectID
+	} else {
+		req.Source = pbtypes.GetStringList(details, bundle.RelationKeySetOf.String())
+	}
+	subs := app.MustComponent[subscription.Service](a)
+	resp, err := subs.Search(req)
+	if err != nil {
+		return nil, pagination{}, err
+	}
+	if err = subs.Unsubscribe(req.SubId); err != nil {
+		log.Warnf("unsubscribe %s: %v", req.SubId, err)
+	}
+
+	objects := make([]object, 0, len(resp.Records))
+	for _, rec := range resp.Records {
+		objects = append(objects, detailsToObject(rec))
+	}
+	page := pagination{Offset: offset, Limit: limit}
+	if resp.Counters != nil {
+		page.Total = int(resp.Counters.Total)
+		page.HasMore = resp.Counters.NextCount > 0
+	}
+	return objects, page, nil
+}
+
+// objectDetails returns details of the visible object from the given space
+func objectDetails(a *app.App, id domain.FullID) (*types.Struct, error) {
+	details, err := app.MustComponent[objectstore.ObjectStore](a).GetDetails(id.ObjectID)
+	if err != nil {
+		return nil, err
+	}
+	d := details.GetDetails()
+	if pbtypes.GetString(d, bundle.RelationKeySpaceId.String()) != id.SpaceID ||
+		pbtypes.GetBool(d, bundle.RelationKeyIsDeleted.String()) ||
+		pbtypes.GetBool(d, bundle.RelationKeyIsHidden.String()) {
+		return nil, errObjectNotFound
+	}
+	return d, nil
+}
+
+func readObject(ctx context.Context, a *app.App, id domain.FullID) (*object, error) {
+	details, err := objectDetails(a, id)
+	if err != nil {
+		return nil, err
+	}
+	obj := detailsToObject(details)
+	err = cache.DoContextFullID(app.MustComponent[*block.Service](a), ctx, id, func(sb smartblock.SmartBlock) error {
+		obj.Markdown = string(md.NewMDConverter(sb.NewState(), fileNamer{}, nil).Convert(sb.Type().ToProto()))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("convert to markdown: %w", err)
+	}
+	return &obj, nil
+}
+
+// pasteMarkdown inserts markdown into the object body, replacing the given blocks
+func pasteMarkdown(bs *block.Service, objectId, markdown string, replaceIds []string) error {
+	_, _, _, _, err := bs.Paste(session.NewContext(), pb.RpcBlockPasteRequest{
+		ContextId:        objectId,
+		SelectedBlockIds: replaceIds,
+		TextSlot:         markdown,
+	}, "")
+	if err != nil {
+		return fmt.Errorf("paste markdown: %w", err)
+	}
+	return nil
+}
+
+// queryPage requests one extra record to find out if there are more records after the page
+func queryPage(a *app.App, filters []*model.BlockContentDataviewFilter, offset, limit int) ([]database.Record, bool, error) {
+	records, err := app.MustComponent[objectstore.ObjectStore](a).Query(database.Query{
+		Filters: filters,
+		Sorts: []*model.BlockContentDataviewSort{{
+			RelationKey: bundle.RelationKeyLastModifiedDate.String(),
+			Type:        model.BlockContentDataviewSort_Desc,
+		}},
+		Offset: offset,
+		Limit:  limit + 1,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("query objects: %w", err)
+	}
+	if len(records) > limit {
+		return records[:limit], true, nil
+	}
+	return records, false, nil
+}
+
+// spaceFilters selects visible objects of the given spaces, archived and deleted objects are filtered out by the object store.
+// Objects of all spaces are selected when no space is passed
+func spaceFilters(spaceIds ...string) []*model.BlockContentDataviewFilter {
+	filters := []*model.BlockContentDataviewFilter{{
+		RelationKey: bundle.RelationKeyIsHidden.String(),
+		Condition:   model.BlockContentDataviewFilter_NotEqual,
+		Value:       pbtypes.Bool(true),
+	}}
+	switch {
+	case len(spaceIds) == 1 && spaceIds[0] != "":
+		filters = append(filters, filterEqual(bundle.RelationKeySpaceId, pbtypes.String(spaceIds[0])))
+	case len(spaceIds) > 1:
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(spaceIds),
+		})
+	}
+	return filters
+}
+
+func filterEqual(key domain.RelationKey, value *types.Value) *model.BlockContentDataviewFilter {
+	return &model.BlockContentDataviewFilter{
+		RelationKey: key.String(),
+		Condition:   model.BlockContentDataviewFilter_Equal,
+		Value:       value,
+	}
+}
+
+func typeUniqueKeys(typeKeys []string) []string {
+	slices.Sort(typeKeys)
+	typeKeys = slices.Compact(typeKeys)
+	keys := make([]string, 0, len(typeKeys))
+	for _, key := range typeKeys {
+		keys = append(keys, domain.MustUniqueKey(coresb.SmartBlockTypeObjectType, key).Marshal())
+	}
+	return keys
+}
+
+func recordsToObjects(records []database.Record) []object {
+	objects := make([]object, 0, len(records))
+	for _, rec := range records {
+		objects = append(objects, detailsToObject(rec.Details))
+	}
+	return objects
+}
+
+func detailsToObject(details *types.Struct) object {
+	return object{
+		Id:      pbtypes.GetString(details, bundle.RelationKeyId.String()),
+		SpaceId: pbtypes.GetString(details, bundle.RelationKeySpaceId.String()),
+		Name:    pbtypes.GetString(details, bundle.RelationKeyName.String()),
+		TypeId:  pbtypes.GetString(details, bundle.RelationKeyType.String()),
+		Details: pbtypes.ToMap(details),
+	}
+}
+
+// fileNamer keeps file links as is, the API returns markdown without attached files
+type fileNamer struct{}
+
+func (fileNamer) Get(_, hash, title, ext string) string {
+	if title == "" {
+		return hash + ext
+	}
+	return title
+}
@@ -0,0 +1,358 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/anyproto/anytype-heart/core"
+	"github.com/anyproto/anytype-heart/util/vcs"
+)
+
+// The server implements the Model Context Protocol, so local AI assistants could search, read and file objects.
+// It's served over stdio by "grpcserver mcp" and over HTTP at /v1/mcp of the local API.
+// Only request-response messages are supported, the server doesn't send requests or notifications to the client
+
+const (
+	mcpProtocolVersion = "2025-03-26"
+	maxMcpMessageSize  = 10 << 20
+)
+
+var mcpSupportedVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+const (
+	rpcParseError       = -32700
+	rpcInvalidRequest   = -32600
+	rpcMethodNotFound   = -32601
+	rpcInvalidParams    = -32602
+	rpcInternalError    = -32603
+	rpcResourceNotFound = -32002
+)
+
+var errSpaceNotAllowed = errors.New("space is not available to the assistant")
+
+// McpConfig restricts what is available to the assistant. Over HTTP it narrows the access of the app key,
+// so assistants should use keys issued for their spaces and with the read_only scope if they must not change objects
+type McpConfig struct {
+	// SpaceIds limits access to the given spaces, all spaces of the account are available when it's empty
+	SpaceIds []string
+	// ReadOnly hides and rejects tools that change objects
+	ReadOnly bool
+}
+
+type rpcRequest struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type mcpInitializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type mcpInitializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      mcpServerInfo  `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+type mcpServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type mcpToolCallParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+type mcpResourceParams struct {
+	Uri string `json:"uri"`
+}
+
+type mcpResource struct {
+	Uri      string `json:"uri"`
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+}
+
+type mcpResourceTemplate struct {
+	UriTemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+type mcpResourceContents struct {
+	Uri      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// mcpSession handles messages of one client, the HTTP transport is stateless, so it creates the session per request
+type mcpSession struct {
+	server *Server
+	config McpConfig
+}
+
+// EnableMcp serves MCP at /v1/mcp of the handler, the access is limited by the config and by the scope of the app key
+func (s *Server) EnableMcp(config McpConfig) {
+	s.mcpConfig = &config
+}
+
+// ServeMcpStdio handles newline-delimited messages from the input until it's closed
+func (s *Server) ServeMcpStdio(ctx context.Context, config McpConfig, in io.Reader, out io.Writer) error {
+	session := &mcpSession{server: s, config: config}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxMcpMessageSize)
+	enc := json.NewEncoder(out)
+	for scanner.Scan() {
+		msg := bytes.TrimSpace(scanner.Bytes())
+		if len(msg) == 0 {
+			continue
+		}
+		if resp := session.handle(ctx, msg); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return fmt.Errorf("write response: %w", err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// serveMcp handles the message over HTTP. The config of the server only narrows the access of the app key,
+// the key itself is limited to its spaces and scope for all routes, so the assistant can't bypass MCP restrictions
+func (s *Server) serveMcp(w http.ResponseWriter, r *http.Request) {
+	config := *s.mcpConfig
+	if !hasFullScope(r.Context()) {
+		config.ReadOnly = true
+	}
+	var ok bool
+	if config.SpaceIds, ok = restrictSpaces(r.Context(), config.SpaceIds); !ok {
+		writeError(w, http.StatusForbidden, errSpaceDenied)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMcpMessageSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	session := &mcpSession{server: s, config: config}
+	resp := session.handle(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handle processes the json-rpc message and returns the response, nil is returned for notifications
+func (m *mcpSession) handle(ctx context.Context, msg []byte) (resp *rpcResponse) {
+	var req rpcRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return &rpcResponse{Jsonrpc: "2.0", Error: &rpcError{Code: rpcParseError, Message: "parse error"}}
+	}
+	if req.Jsonrpc != "2.0" || req.Method == "" {
+		return &rpcResponse{Jsonrpc: "2.0", Id: req.Id, Error: &rpcError{Code: rpcInvalidRequest, Message: "invalid request"}}
+	}
+	isNotification := len(req.Id) == 0
+	defer func() {
+		if rec := recover(); rec != nil {
+			var rpcErr *rpcError
+			if err, ok := rec.(error); ok && errors.Is(err, core.ErrNotLoggedIn) {
+				rpcErr = &rpcError{Code: rpcInternalError, Message: errAccountNotRunning.Error()}
+			} else {
+				m.server.mw.OnPanic(rec)
+				rpcErr = &rpcError{Code: rpcInternalError, Message: "panic recovered"}
+			}
+			if !isNotification {
+				resp = &rpcResponse{Jsonrpc: "2.0", Id: req.Id, Error: rpcErr}
+			}
+		}
+	}()
+
+	result, err := m.call(ctx, req.Method, req.Params)
+	if isNotification {
+		return nil
+	}
+	resp = &rpcResponse{Jsonrpc: "2.0", Id: req.Id, Result: result}
+	if err != nil {
+		resp.Result = nil
+		resp.Error = toRpcError(err)
+	}
+	return resp
+}
+
+func (m *mcpSession) call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		var p mcpInitializeParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		version := mcpProtocolVersion
+		if slices.Contains(mcpSupportedVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return mcpInitializeResult{
+			ProtocolVersion: version,
+			Capabilities: map[string]any{
+				"tools":     map[string]any{},
+				"resources": map[string]any{},
+			},
+			ServerInfo:   mcpServerInfo{Name: "anytype", Version: vcs.GetVCSInfo().Version()},
+			Instructions: m.instructions(),
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]any{"tools": m.tools()}, nil
+	case "tools/call":
+		var p mcpToolCallParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return m.callTool(ctx, p)
+	case "resources/list":
+		resources, err := m.listResources()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"resources": resources}, nil
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": []mcpResourceTemplate{{
+			UriTemplate: objectUriPrefix + "{space_id}/{object_id}",
+			Name:        "Object",
+			Description: "Object of the space as Markdown",
+			MimeType:    markdownMimeType,
+		}}}, nil
+	case "resources/read":
+		var p mcpResourceParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		contents, err := m.readResource(ctx, p.Uri)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"contents": []mcpResourceContents{contents}}, nil
+	}
+	if strings.HasPrefix(method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + method}
+}
+
+func (m *mcpSession) instructions() string {
+	text := "Objects of the Anytype spaces of the user. Objects are identified by space and object ids, " +
+		"use list_spaces to find the spaces and search to find the objects."
+	if m.config.ReadOnly {
+		text += " The access is read-only."
+	}
+	return text
+}
+
+// tools returns tools available in the session, write tools are hidden in the read-only mode
+func (m *mcpSession) tools() []mcpTool {
+	tools := make([]mcpTool, 0, len(mcpTools))
+	for _, tool := range mcpTools {
+		if tool.write && m.config.ReadOnly {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// callTool returns errors of the tool as the result, so they are shown to the assistant
+func (m *mcpSession) callTool(ctx context.Context, p mcpToolCallParams) (*mcpToolResult, error) {
+	tools := m.tools()
+	idx := slices.IndexFunc(tools, func(t mcpTool) bool {
+		return t.Name == p.Name
+	})
+	if idx < 0 {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	result, err := tools[idx].run(ctx, m, p.Arguments)
+	if err != nil {
+		return &mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	text, ok := result.(string)
+	if !ok {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	return &mcpToolResult{Content: []mcpContent{{Type: "text", Text: text}}}, nil
+}
+
+func (m *mcpSession) checkSpace(spaceId string) error {
+	if spaceId == "" {
+		return fmt.Errorf("%w: space_id is required", errInvalidRequest)
+	}
+	if len(m.config.SpaceIds) > 0 && !slices.Contains(m.config.SpaceIds, spaceId) {
+		return errSpaceNotAllowed
+	}
+	return nil
+}
+
+// decodeParams decodes params or arguments of the message, missing params are treated as empty
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+	return nil
+}
+
+func toRpcError(err error) *rpcError {
+	var rpcErr *rpcError
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, errObjectNotFound), errors.Is(err, errSpaceNotAllowed):
+		return &rpcError{Code: rpcResourceNotFound, Message: err.Error()}
+	case errors.Is(err, errInvalidRequest):
+		return &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	return &rpcError{Code: rpcInternalError, Message: err.Error()}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+)
+
+func handleMcp(t *testing.T, session *mcpSession, msg string) map[string]any {
+	resp := session.handle(context.Background(), []byte(msg))
+	if resp == nil {
+		return nil
+	}
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+func toolNames(resp map[string]any) []string {
+	var names []string
+	for _, tool := range resp["result"].(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	return names
+}
+
+func TestMcpSession_Handle(t *testing.T) {
+	t.Run("initialize", func(t *testing.T) {
+		// given
+		session := &mcpSession{server: &Server{}}
+
+		// when
+		resp := handleMcp(t, session, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`)
+
+		// then
+		assert.Equal(t, float64(1), resp["id"])
+		result := resp["result"].(map[string]any)
+		assert.Equal(t, "2024-11-05", result["protocolVersion"])
+		assert.Contains(t, result["capabilities"], "tools")
+		assert.Contains(t, result["capabilities"], "resources")
+	})
+
+	t.Run("unknown protocol version is answered with the latest one", func(t *testing.T) {
+		// when
+		resp := handleMcp(t, &mcpSession{server: &Server{}}, `{"jsonrpc":"2.0","id":"a","method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+
+		// then
+		assert.Equal(t, "a", resp["id"])
+		assert.Equal(t, mcpProtocolVersion, resp["result"].(map[string]any)["protocolVersion"])
+	})
+
+	t.Run("notifications are not answered", func(t *testing.T) {
+		// when
+		resp := handleMcp(t, &mcpSession{server: &Server{}}, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+		// then
+		assert.Nil(t, resp)
+	})
+
+	t.Run("protocol errors", func(t *testing.T) {
+		for msg, code := range map[string]float64{
+			`{"jsonrpc":`: rpcParseError,
+			`{"jsonrpc":"1.0","id":1,"method":"ping"}`:                                                  rpcInvalidRequest,
+			`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`:                                          rpcMethodNotFound,
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_all"}}`:             rpcInvalidParams,
+			`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"https://example.com"}}`: rpcInvalidParams,
+		} {
+			// when
+			resp := handleMcp(t, &mcpSession{server: &Server{}}, msg)
+
+			// then
+			require.Contains(t, resp, "error", msg)
+			assert.Equal(t, code, resp["error"].(map[string]any)["code"], msg)
+			assert.NotContains(t, resp, "result")
+		}
+	})
+}
+
+func TestMcpSession_Access(t *testing.T) {
+	t.Run("write tools are not available in read-only mode", func(t *testing.T) {
+		// given
+		full := &mcpSession{server: &Server{}}
+		readOnly := &mcpSession{server: &Server{}, config: McpConfig{ReadOnly: true}}
+
+		// when
+		fullTools := toolNames(handleMcp(t, full, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		readOnlyTools := toolNames(handleMcp(t, readOnly, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		resp := handleMcp(t, readOnly, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_object","arguments":{"space_id":"space1"}}}`)
+
+		// then
+		assert.Contains(t, fullTools, "create_object")
+		assert.Contains(t, fullTools, "update_object")
+		assert.NotContains(t, readOnlyTools, "create_object")
+		assert.NotContains(t, readOnlyTools, "update_object")
+		assert.Contains(t, readOnlyTools, "get_object")
+		assert.Equal(t, float64(rpcInvalidParams), resp["error"].(map[string]any)["code"])
+	})
+
+	t.Run("objects of other spaces are not available", func(t *testing.T) {
+		// given
+		session := &mcpSession{server: &Server{}, config: McpConfig{SpaceIds: []string{"space1"}}}
+
+		// when
+		toolResp := handleMcp(t, session, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_object","arguments":{"space_id":"space2","object_id":"obj"}}}`)
+		resourceResp := handleMcp(t, session, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"anytype://space2/obj"}}`)
+
+		// then
+		result := toolResp["result"].(map[string]any)
+		assert.Equal(t, true, result["isError"])
+		assert.Contains(t, result["content"].([]any)[0].(map[string]any)["text"], errSpaceNotAllowed.Error())
+		assert.Equal(t, float64(rpcResourceNotFound), resourceResp["error"].(map[string]any)["code"])
+	})
+}
+
+func TestServer_ServeMcpStdio(t *testing.T) {
+	// given
+	in := strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n"))
+	out := &bytes.Buffer{}
+
+	// when
+	err := (&Server{}).ServeMcpStdio(context.Background(), McpConfig{}, in, out)
+
+	// then
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"protocolVersion":"2025-03-26"`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, lines[1])
+}
+
+func TestParseObjectUri(t *testing.T) {
+	id, err := parseObjectUri(objectUri("space1", "obj1"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.FullID{SpaceID: "space1", ObjectID: "obj1"}, id)
+
+	for _, uri := range []string{"anytype://space1", "anytype:///obj1", "file:///etc/passwd"} {
+		_, err = parseObjectUri(uri)
+		assert.ErrorIs(t, err, errInvalidRequest, uri)
+	}
+}
@@ -0,0 +1,412 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+const (
+	objectUriPrefix  = "anytype://"
+	markdownMimeType = "text/markdown"
+)
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	// write tools change objects, they are not available in the read-only mode
+	write bool
+	// run returns the text or the value that is sent as json
+	run func(ctx context.Context, m *mcpSession, args json.RawMessage) (any, error)
+}
+
+// mcpObject is the short form of the object, the full object is read by get_object
+type mcpObject struct {
+	Id      string `json:"id"`
+	SpaceId string `json:"space_id"`
+	Name    string `json:"name"`
+	TypeId  string `json:"type_id"`
+	Uri     string `json:"uri"`
+}
+
+type mcpList struct {
+	Objects []mcpObject `json:"objects"`
+	HasMore bool        `json:"has_more"`
+}
+
+type mcpSearchArgs struct {
+	SpaceId string   `json:"space_id"`
+	Query   string   `json:"query"`
+	Types   []string `json:"types"`
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+}
+
+type mcpObjectArgs struct {
+	SpaceId  string `json:"space_id"`
+	ObjectId string `json:"object_id"`
+}
+
+type mcpSpaceArgs struct {
+	SpaceId string `json:"space_id"`
+}
+
+type mcpQueryListArgs struct {
+	SpaceId string `json:"space_id"`
+	ListId  string `json:"list_id"`
+	ViewId  string `json:"view_id"`
+	Offset  int    `json:"offset"`
+	Limit   int    `json:"limit"`
+}
+
+type mcpCreateObjectArgs struct {
+	SpaceId string `json:"space_id"`
+	createObjectRequest
+}
+
+type mcpUpdateObjectArgs struct {
+	SpaceId  string `json:"space_id"`
+	ObjectId string `json:"object_id"`
+	updateObjectRequest
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_spaces",
+		Description: "Lists spaces available to the assistant.",
+		InputSchema: inputSchema(nil),
+		run:         mcpListSpaces,
+	},
+	{
+		Name:        "search",
+		Description: "Searches objects by text in their names and contents. Searches all available spaces unless space_id is passed.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id": stringProperty("Id of the space to search in"),
+			"query":    stringProperty("Text to search for, objects are listed by modification date when empty"),
+			"types":    arrayProperty("Keys of object types to filter by, e.g. page, note, task"),
+			"offset":   integerProperty("Number of objects to skip"),
+			"limit":    integerProperty("Max number of objects, 50 by default"),
+		}),
+		run: mcpSearch,
+	},
+	{
+		Name:        "get_object",
+		Description: "Reads the object as Markdown.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id":  stringProperty("Id of the space of the object"),
+			"object_id": stringProperty("Id of the object"),
+		}, "space_id", "object_id"),
+		run: mcpGetObject,
+	},
+	{
+		Name:        "list_types",
+		Description: "Lists object types of the space with their keys.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id": stringProperty("Id of the space"),
+		}, "space_id"),
+		run: mcpListByLayout(model.ObjectType_objectType, detailsToType),
+	},
+	{
+		Name:        "list_relations",
+		Description: "Lists relations of the space with their keys and formats. Relation keys are used as keys of object details.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id": stringProperty("Id of the space"),
+		}, "space_id"),
+		run: mcpListByLayout(model.ObjectType_relation, detailsToRelation),
+	},
+	{
+		Name:        "query_list",
+		Description: "Lists objects of the set or collection using filters and sorts of its view.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id": stringProperty("Id of the space of the set or collection"),
+			"list_id":  stringProperty("Id of the set or collection"),
+			"view_id":  stringProperty("Id of the view, the first view is used when empty"),
+			"offset":   integerProperty("Number of objects to skip"),
+			"limit":    integerProperty("Max number of objects, 50 by default"),
+		}, "space_id", "list_id"),
+		run: mcpQueryList,
+	},
+	{
+		Name:        "create_object",
+		Description: "Creates the object with the Markdown body.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id":   stringProperty("Id of the space"),
+			"type":       stringProperty("Key of the object type, page by default"),
+			"name":       stringProperty("Name of the object"),
+			"icon_emoji": stringProperty("Emoji icon of the object"),
+			"markdown":   stringProperty("Body of the object"),
+			"details":    objectProperty("Values of relations by their keys"),
+		}, "space_id"),
+		write: true,
+		run:   mcpCreateObject,
+	},
+	{
+		Name:        "update_object",
+		Description: "Updates the name, the icon and the relations of the object. The body is replaced when markdown is passed.",
+		InputSchema: inputSchema(map[string]any{
+			"space_id":   stringProperty("Id of the space of the object"),
+			"object_id":  stringProperty("Id of the object"),
+			"name":       stringProperty("New name of the object"),
+			"icon_emoji": stringProperty("New emoji icon of the object"),
+			"markdown":   stringProperty("New body of the object"),
+			"details":    objectProperty("Values of relations by their keys"),
+		}, "space_id", "object_id"),
+		write: true,
+		run:   mcpUpdateObject,
+	},
+}
+
+func mcpListSpaces(_ context.Context, m *mcpSession, _ json.RawMessage) (any, error) {
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	spaces, _, err := querySpaces(a, 0, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.config.SpaceIds) > 0 {
+		spaces = slices.DeleteFunc(spaces, func(s space) bool {
+			return !slices.Contains(m.config.SpaceIds, s.Id)
+		})
+	}
+	return spaces, nil
+}
+
+func mcpSearch(_ context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+	var req mcpSearchArgs
+	if err := decodeParams(args, &req); err != nil {
+		return nil, err
+	}
+	spaceIds := m.config.SpaceIds
+	if req.SpaceId != "" {
+		if err := m.checkSpace(req.SpaceId); err != nil {
+			return nil, err
+		}
+		spaceIds = []string{req.SpaceId}
+	}
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	objects, page, err := searchObjects(a, searchRequest{
+		Query:  req.Query,
+		Types:  req.Types,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}, spaceIds...)
+	if err != nil {
+		return nil, err
+	}
+	return toMcpList(objects, page), nil
+}
+
+func mcpGetObject(ctx context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+	var req mcpObjectArgs
+	if err := decodeParams(args, &req); err != nil {
+		return nil, err
+	}
+	obj, err := m.readObject(ctx, domain.FullID{SpaceID: req.SpaceId, ObjectID: req.ObjectId})
+	if err != nil {
+		return nil, err
+	}
+	return obj.Markdown, nil
+}
+
+func mcpListByLayout(layout model.ObjectTypeLayout, convert func(details *types.Struct) any) func(context.Context, *mcpSession, json.RawMessage) (any, error) {
+	return func(_ context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+		var req mcpSpaceArgs
+		if err := decodeParams(args, &req); err != nil {
+			return nil, err
+		}
+		if err := m.checkSpace(req.SpaceId); err != nil {
+			return nil, err
+		}
+		a, err := m.server.app()
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := queryByLayout(a, req.SpaceId, layout, convert, 0, maxLimit)
+		return data, err
+	}
+}
+
+func mcpQueryList(ctx context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+	var req mcpQueryListArgs
+	if err := decodeParams(args, &req); err != nil {
+		return nil, err
+	}
+	if err := m.checkSpace(req.SpaceId); err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, fmt.Errorf("%w: invalid paging", errInvalidRequest)
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	objects, page, err := queryView(ctx, a, domain.FullID{SpaceID: req.SpaceId, ObjectID: req.ListId}, req.ViewId, req.Offset, min(req.Limit, maxLimit))
+	if err != nil {
+		return nil, err
+	}
+	return toMcpList(objects, page), nil
+}
+
+func mcpCreateObject(ctx context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+	var req mcpCreateObjectArgs
+	if err := decodeParams(args, &req); err != nil {
+		return nil, err
+	}
+	if err := m.checkSpace(req.SpaceId); err != nil {
+		return nil, err
+	}
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := createObject(ctx, a, req.SpaceId, req.createObjectRequest)
+	if err != nil {
+		return nil, err
+	}
+	return toMcpObject(*obj), nil
+}
+
+func mcpUpdateObject(ctx context.Context, m *mcpSession, args json.RawMessage) (any, error) {
+	var req mcpUpdateObjectArgs
+	if err := decodeParams(args, &req); err != nil {
+		return nil, err
+	}
+	if err := m.checkSpace(req.SpaceId); err != nil {
+		return nil, err
+	}
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := updateObject(ctx, a, domain.FullID{SpaceID: req.SpaceId, ObjectID: req.ObjectId}, req.updateObjectRequest)
+	if err != nil {
+		return nil, err
+	}
+	return toMcpObject(*obj), nil
+}
+
+// listResources lists recently modified objects, other objects are read by the uri template
+func (m *mcpSession) listResources() ([]mcpResource, error) {
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	objects, _, err := searchObjects(a, searchRequest{Limit: defaultLimit}, m.config.SpaceIds...)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]mcpResource, 0, len(objects))
+	for _, obj := range objects {
+		resources = append(resources, mcpResource{
+			Uri:      objectUri(obj.SpaceId, obj.Id),
+			Name:     obj.Name,
+			MimeType: markdownMimeType,
+		})
+	}
+	return resources, nil
+}
+
+func (m *mcpSession) readResource(ctx context.Context, uri string) (mcpResourceContents, error) {
+	id, err := parseObjectUri(uri)
+	if err != nil {
+		return mcpResourceContents{}, err
+	}
+	obj, err := m.readObject(ctx, id)
+	if err != nil {
+		return mcpResourceContents{}, err
+	}
+	return mcpResourceContents{Uri: uri, MimeType: markdownMimeType, Text: obj.Markdown}, nil
+}
+
+func (m *mcpSession) readObject(ctx context.Context, id domain.FullID) (*object, error) {
+	if err := m.checkSpace(id.SpaceID); err != nil {
+		return nil, err
+	}
+	if id.ObjectID == "" {
+		return nil, fmt.Errorf("%w: object_id is required", errInvalidRequest)
+	}
+	a, err := m.server.app()
+	if err != nil {
+		return nil, err
+	}
+	return readObject(ctx, a, id)
+}
+
+func objectUri(spaceId, objectId string) string {
+	return objectUriPrefix + spaceId + "/" + objectId
+}
+
+func parseObjectUri(uri string) (domain.FullID, error) {
+	path, ok := strings.CutPrefix(uri, objectUriPrefix)
+	spaceId, objectId, found := strings.Cut(path, "/")
+	if !ok || !found || spaceId == "" || objectId == "" {
+		return domain.FullID{}, fmt.Errorf("%w: invalid object uri: %s", errInvalidRequest, uri)
+	}
+	return domain.FullID{SpaceID: spaceId, ObjectID: objectId}, nil
+}
+
+func toMcpObject(obj object) mcpObject {
+	return mcpObject{
+		Id:      obj.Id,
+		SpaceId: obj.SpaceId,
+		Name:    obj.Name,
+		TypeId:  obj.TypeId,
+		Uri:     objectUri(obj.SpaceId, obj.Id),
+	}
+}
+
+func toMcpList(objects []object, page pagination) mcpList {
+	list := mcpList{Objects: make([]mcpObject, 0, len(objects)), HasMore: page.HasMore}
+	for _, obj := range objects {
+		list.Objects = append(list.Objects, toMcpObject(obj))
+	}
+	return list
+}
+
+func inputSchema(properties map[string]any, required ...string) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+func integerProperty(description string) map[string]any {
+	return map[string]any{"type": "integer", "description": description}
+}
+
+func arrayProperty(description string) map[string]any {
+	return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": description}
+}
+
+func objectProperty(description string) map[string]any {
+	return map[string]any{"type": "object", "description": description}
+}
@@ -0,0 +1,190 @@
+// Package api provides the local HTTP JSON API. It runs alongside the gRPC server, so scripts and launchers
+// could work with the account data without grpc-web and protobuf. Apps are authorized by the keys
+// issued via the same local link challenge that is used by the client apps
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/anyproto/anytype-heart/core"
+	"github.com/anyproto/anytype-heart/core/event/eventlog"
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+)
+
+var log = logging.Logger("anytype-local-api")
+
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+var errAccountNotRunning = errors.New("account is not running")
+
+//go:embed openapi.yaml
+var openapiSpec []byte
+
+type Server struct {
+	mw *core.Middleware
+
+	// mcpConfig is set when MCP is served by the handler
+	mcpConfig *McpConfig
+	// events is set when events are served by the handler
+	events *eventlog.Stream
+
+	// readLink reads the app link by its key, the account directory is the source of truth
+	readLink func(appKey string) (*wallet.AppLinkPayload, error)
+	now      func() time.Time
+}
+
+func NewServer(mw *core.Middleware) *Server {
+	s := &Server{
+		mw:  mw,
+		now: time.Now,
+	}
+	s.readLink = s.readAppLink
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(s.recoverer)
+
+	r.Get("/v1/openapi.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(openapiSpec)
+	})
+	r.Post("/v1/auth/challenges", s.createChallenge)
+	r.Post("/v1/auth/tokens", s.solveChallenge)
+
+	r.Group(func(r chi.Router) {
+		r.Use(s.authenticate)
+		r.Get("/v1/spaces", s.listSpaces)
+		r.Post("/v1/search", s.search)
+		if s.mcpConfig != nil {
+			r.Post("/v1/mcp", s.serveMcp)
+		}
+		if s.events != nil {
+			r.With(s.requireFullScope).Get("/v1/events", s.streamEvents)
+		}
+		r.Route("/v1/spaces/{spaceId}", func(r chi.Router) {
+			r.Use(s.requireSpace)
+			r.Get("/objects", s.listObjects)
+			r.With(s.requireFullScope).Post("/objects", s.createObject)
+			r.Get("/objects/{objectId}", s.getObject)
+			r.With(s.requireFullScope).Patch("/objects/{objectId}", s.updateObject)
+			r.Post("/search", s.search)
+			r.Get("/types", s.listTypes)
+			r.Get("/relations", s.listRelations)
+			r.Get("/lists/{listId}/objects", s.listViewObjects)
+		})
+	})
+	return r
+}
+
+func (s *Server) recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if err, ok := rec.(error); ok && errors.Is(err, core.ErrNotLoggedIn) {
+					writeError(w, http.StatusServiceUnavailable, errAccountNotRunning)
+					return
+				}
+				s.mw.OnPanic(rec)
+				writeError(w, http.StatusInternalServerError, fmt.Errorf("panic recovered"))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) app() (*app.App, error) {
+	a := s.mw.GetApp()
+	if a == nil {
+		return nil, errAccountNotRunning
+	}
+	return a, nil
+}
+
+type pagination struct {
+	Offset  int  `json:"offset"`
+	Limit   int  `json:"limit"`
+	Total   int  `json:"total,omitempty"`
+	HasMore bool `json:"has_more"`
+}
+
+type listResponse struct {
+	Data       any        `json:"data"`
+	Pagination pagination `json:"pagination"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// parsePaging reads offset and limit query parameters, limit is capped by maxLimit
+func parsePaging(r *http.Request) (offset, limit int, err error) {
+	limit = defaultLimit
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %s", v)
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit: %s", v)
+		}
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return offset, limit, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: errorBody{Code: status, Message: err.Error()}})
+}
+
+// errorStatus maps errors of the shared object functions to http statuses
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errObjectNotFound), errors.Is(err, errNotAList), errors.Is(err, errViewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, errAccountNotRunning):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func readJSON(r *http.Request, v any) error {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func TestParsePaging(t *testing.T) {
+	for _, tc := range []struct {
+		query          string
+		offset, limit  int
+		expectingError bool
+	}{
+		{query: "", offset: 0, limit: defaultLimit},
+		{query: "offset=10&limit=5", offset: 10, limit: 5},
+		{query: "limit=5000", offset: 0, limit: maxLimit},
+		{query: "offset=-1", expectingError: true},
+		{query: "limit=0", expectingError: true},
+		{query: "limit=ten", expectingError: true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			// given
+			r := httptest.NewRequest(http.MethodGet, "/v1/spaces?"+tc.query, nil)
+
+			// when
+			offset, limit, err := parsePaging(r)
+
+			// then
+			if tc.expectingError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.offset, offset)
+			assert.Equal(t, tc.limit, limit)
+		})
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	scope, err := parseScope("")
+	require.NoError(t, err)
+	assert.Equal(t, model.AccountAuth_Full, scope)
+
+	scope, err = parseScope(scopeReadOnly)
+	require.NoError(t, err)
+	assert.Equal(t, model.AccountAuth_ReadOnly, scope)
+
+	_, err = parseScope("admin")
+	assert.Error(t, err)
+}
+
+func TestServer_RequireFullScope(t *testing.T) {
+	s := &Server{}
+	handler := s.requireFullScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(link *wallet.AppLinkPayload) int {
+		r := httptest.NewRequest(http.MethodPost, "/v1/spaces/space/objects", nil)
+		r = r.WithContext(contextWithAppLink(r.Context(), link))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(&wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full)}))
+	assert.Equal(t, http.StatusForbidden, serve(&wallet.AppLinkPayload{Scope: int32(model.AccountAuth_ReadOnly)}))
+}
+
+func TestServer_RequireSpace(t *testing.T) {
+	s := &Server{}
+	router := chi.NewRouter()
+	router.Route("/v1/spaces/{spaceId}", func(r chi.Router) {
+		r.Use(s.requireSpace)
+		r.With(s.requireFullScope).Patch("/objects/{objectId}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+	serve := func(link *wallet.AppLinkPayload, spaceId string) int {
+		r := httptest.NewRequest(http.MethodPatch, "/v1/spaces/"+spaceId+"/objects/object1", nil)
+		r = r.WithContext(contextWithAppLink(r.Context(), link))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	limited := &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full), SpaceIds: []string{"space1"}}
+	assert.Equal(t, http.StatusNoContent, serve(limited, "space1"))
+	assert.Equal(t, http.StatusForbidden, serve(limited, "space2"))
+	assert.Equal(t, http.StatusNoContent, serve(&wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full)}, "space2"))
+}
+
+func TestRestrictSpaces(t *testing.T) {
+	ctx := contextWithAppLink(context.Background(), &wallet.AppLinkPayload{SpaceIds: []string{"space1", "space2"}})
+
+	spaceIds, ok := restrictSpaces(ctx, nil)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"space1", "space2"}, spaceIds)
+
+	spaceIds, ok = restrictSpaces(ctx, []string{"space2", "space3"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"space2"}, spaceIds)
+
+	_, ok = restrictSpaces(ctx, []string{"space3"})
+	assert.False(t, ok)
+
+	spaceIds, ok = restrictSpaces(context.Background(), []string{"space3"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"space3"}, spaceIds)
+}
+
+func TestServer_ServeMcp(t *testing.T) {
+	t.Run("spaces of the config are narrowed by spaces of the app key", func(t *testing.T) {
+		// given
+		s := &Server{mcpConfig: &McpConfig{SpaceIds: []string{"space1"}}}
+		r := httptest.NewRequest(http.MethodPost, "/v1/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		link := &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full), SpaceIds: []string{"space2"}}
+		r = r.WithContext(contextWithAppLink(r.Context(), link))
+		w := httptest.NewRecorder()
+
+		// when
+		s.serveMcp(w, r)
+
+		// then
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	links := map[string]*wallet.AppLinkPayload{
+		"full":     {AppName: "script", Scope: int32(model.AccountAuth_Full)},
+		"readOnly": {AppName: "launcher", Scope: int32(model.AccountAuth_ReadOnly)},
+		"expired":  {AppName: "old", Scope: int32(model.AccountAuth_Full), ExpireAt: now.Add(-time.Minute).Unix()},
+	}
+	s := &Server{
+		readLink: func(appKey string) (*wallet.AppLinkPayload, error) {
+			link, ok := links[appKey]
+			if !ok {
+				return nil, wallet.ErrAppLinkNotFound
+			}
+			return link, nil
+		},
+		now: func() time.Time { return now },
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	read := s.authenticate(ok)
+	write := s.authenticate(s.requireFullScope(ok))
+	serve := func(handler http.Handler, appKey string) int {
+		r := httptest.NewRequest(http.MethodGet, "/v1/spaces", nil)
+		if appKey != "" {
+			r.Header.Set("Authorization", "Bearer "+appKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("scopes", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(read, "full"))
+		assert.Equal(t, http.StatusNoContent, serve(write, "full"))
+		assert.Equal(t, http.StatusNoContent, serve(read, "readOnly"))
+		assert.Equal(t, http.StatusForbidden, serve(write, "readOnly"))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(read, ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(read, "unknown"))
+		assert.Equal(t, http.StatusUnauthorized, serve(read, "expired"))
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		// given
+		require.Equal(t, http.StatusNoContent, serve(read, "full"))
+
+		// when
+		delete(links, "full")
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, serve(read, "full"))
+	})
+
+	t.Run("account is not running", func(t *testing.T) {
+		s.readLink = func(string) (*wallet.AppLinkPayload, error) {
+			return nil, errAccountNotRunning
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, serve(read, "readOnly"))
+	})
+}
@@ -10,4 +10,5 @@ var (
 	ErrSetDetails               = errors.New("failed to set details")
 	ErrBadInput                 = errors.New("bad input")
 	ErrApplicationIsNotRunning  = errors.New("application is not running")
+	ErrAppKeyScope              = errors.New("app key doesn't grant full access")
 )
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/anyproto/any-sync/app"
+
 	"github.com/anyproto/anytype-heart/core/session"
 	walletComp "github.com/anyproto/anytype-heart/core/wallet"
 	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 )
 
 func (s *Service) CreateSession(req *pb.RpcWalletCreateSessionRequest) (token string, accountId string, err error) {
@@ -29,6 +32,10 @@ func (s *Service) CreateSession(req *pb.RpcWalletCreateSessionRequest) (token st
 		if err != nil {
 			return "", "", err
 		}
+		// gRPC sessions are not limited by scope, so only keys with full access could be exchanged for them
+		if model.AccountAuthLocalApiScope(appLink.Scope) != model.AccountAuth_Full {
+			return "", "", ErrAppKeyScope
+		}
 		log.Infof("appLink auth %s", appLink.AppName)
 		token, err := s.sessions.StartSession(s.sessionSigningKey)
 		if err != nil {
@@ -54,7 +61,14 @@ func (s *Service) CreateSession(req *pb.RpcWalletCreateSessionRequest) (token st
 }
 
 func (s *Service) CloseSession(req *pb.RpcWalletCloseSessionRequest) error {
-	if sender, ok := s.eventSender.(session.Closer); ok {
+	if a := s.GetApp(); a != nil {
+		// components keeping the state of sessions release it, the event sender is one of them
+		a.IterateComponents(func(c app.Component) {
+			if closer, ok := c.(session.Closer); ok {
+				closer.CloseSession(req.Token)
+			}
+		})
+	} else if sender, ok := s.eventSender.(session.Closer); ok {
 		sender.CloseSession(req.Token)
 	}
 	return s.sessions.CloseSession(req.Token)
@@ -96,11 +110,21 @@ func (s *Service) LinkLocalSolveChallenge(req *pb.RpcAccountLocalLinkSolveChalle
 	if err != nil {
 		return "", "", err
 	}
+	if clientInfo.Scope != model.AccountAuth_Full {
+		// the app is limited to the local API, which authorizes requests by the app key
+		if err = s.sessions.CloseSession(token); err != nil {
+			return "", "", err
+		}
+		token = ""
+	}
 	wallet := s.app.Component(walletComp.CName).(walletComp.Wallet)
 	appKey, err = wallet.PersistAppLink(&walletComp.AppLinkPayload{
-		AppName:   clientInfo.ProcessName,
-		AppPath:   clientInfo.ProcessPath,
-		CreatedAt: time.Now().Unix(),
+		AppName:          clientInfo.ProcessName,
+		AppPath:          clientInfo.ProcessPath,
+		CreatedAt:        time.Now().Unix(),
+		Scope:            int32(clientInfo.Scope),
+		SpaceIds:         clientInfo.SpaceIds,
+		UnredactedEvents: clientInfo.UnredactedEvents,
 	})
 
 	return
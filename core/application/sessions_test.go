@@ -3,9 +3,15 @@ package application
 import (
 	"testing"
 
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/commonspace/object/accountdata"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/core/wallet/mock_wallet"
 	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 )
 
 func TestCreateSession(t *testing.T) {
@@ -21,5 +27,42 @@ func TestCreateSession(t *testing.T) {
 
 			require.Error(t, err)
 		})
+
+		newService := func(t *testing.T, link *wallet.AppLinkPayload) *Service {
+			keys, err := accountdata.NewRandom()
+			require.NoError(t, err)
+			w := mock_wallet.NewMockWallet(t)
+			w.EXPECT().Name().Return(wallet.CName).Maybe()
+			w.EXPECT().ReadAppLink("appKey").Return(link, nil)
+			w.EXPECT().Account().Return(keys).Maybe()
+			a := new(app.App)
+			a.Register(w)
+			s := New()
+			s.app = a
+			s.sessionSigningKey = []byte("signing key")
+			return s
+		}
+
+		t.Run("with full scope", func(t *testing.T) {
+			s := newService(t, &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_Full)})
+
+			token, _, err := s.CreateSession(&pb.RpcWalletCreateSessionRequest{
+				Auth: &pb.RpcWalletCreateSessionRequestAuthOfAppKey{AppKey: "appKey"},
+			})
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, token)
+		})
+
+		t.Run("with read-only scope expect error", func(t *testing.T) {
+			s := newService(t, &wallet.AppLinkPayload{Scope: int32(model.AccountAuth_ReadOnly)})
+
+			token, _, err := s.CreateSession(&pb.RpcWalletCreateSessionRequest{
+				Auth: &pb.RpcWalletCreateSessionRequestAuthOfAppKey{AppKey: "appKey"},
+			})
+
+			require.ErrorIs(t, err, ErrAppKeyScope)
+			assert.Empty(t, token)
+		})
 	})
 }
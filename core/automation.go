@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+
+	"github.com/anyproto/anytype-heart/core/automation"
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+func (mw *Middleware) AutomationCreate(cctx context.Context, req *pb.RpcAutomationCreateRequest) *pb.RpcAutomationCreateResponse {
+	rule, err := getService[automation.Service](mw).Create(cctx, req.SpaceId, req.Automation)
+	code := mapErrorCode(err,
+		errToCode(automation.ErrInvalidRule, pb.RpcAutomationCreateResponseError_BAD_INPUT),
+	)
+	return &pb.RpcAutomationCreateResponse{
+		Error: &pb.RpcAutomationCreateResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+		Automation: rule,
+	}
+}
+
+func (mw *Middleware) AutomationUpdate(_ context.Context, req *pb.RpcAutomationUpdateRequest) *pb.RpcAutomationUpdateResponse {
+	err := getService[automation.Service](mw).Update(req.AutomationId, req.Automation)
+	code := mapErrorCode(err,
+		errToCode(automation.ErrInvalidRule, pb.RpcAutomationUpdateResponseError_BAD_INPUT),
+		errToCode(automation.ErrNotFound, pb.RpcAutomationUpdateResponseError_NOT_FOUND),
+	)
+	return &pb.RpcAutomationUpdateResponse{
+		Error: &pb.RpcAutomationUpdateResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+}
+
+func (mw *Middleware) AutomationSetDisabled(_ context.Context, req *pb.RpcAutomationSetDisabledRequest) *pb.RpcAutomationSetDisabledResponse {
+	err := getService[automation.Service](mw).SetDisabled(req.AutomationId, req.Disabled)
+	code := mapErrorCode(err,
+		errToCode(automation.ErrNotFound, pb.RpcAutomationSetDisabledResponseError_NOT_FOUND),
+	)
+	return &pb.RpcAutomationSetDisabledResponse{
+		Error: &pb.RpcAutomationSetDisabledResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+}
+
+func (mw *Middleware) AutomationList(_ context.Context, req *pb.RpcAutomationListRequest) *pb.RpcAutomationListResponse {
+	rules, err := getService[automation.Service](mw).List(req.SpaceId)
+	return &pb.RpcAutomationListResponse{
+		Error: &pb.RpcAutomationListResponseError{
+			Code:        mapErrorCode[pb.RpcAutomationListResponseErrorCode](err),
+			Description: getErrorDescription(err),
+		},
+		Automations: rules,
+	}
+}
+
+func (mw *Middleware) AutomationLogList(_ context.Context, req *pb.RpcAutomationLogListRequest) *pb.RpcAutomationLogListResponse {
+	entries, err := getService[automation.Service](mw).Log(req.AutomationId, int(req.Limit))
+	return &pb.RpcAutomationLogListResponse{
+		Error: &pb.RpcAutomationLogListResponseError{
+			Code:        mapErrorCode[pb.RpcAutomationLogListResponseErrorCode](err),
+			Description: getErrorDescription(err),
+		},
+		Entries: entries,
+	}
+}
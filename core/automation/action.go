@@ -0,0 +1,167 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/script"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/slice"
+)
+
+type detailsSetter interface {
+	SetDetails(ctx session.Context, objectId string, details []*model.Detail) error
+}
+
+type templateApplier interface {
+	ObjectApplyTemplate(contextId string, templateId string) error
+}
+
+type collectionEditor interface {
+	Add(ctx session.Context, req *pb.RpcObjectCollectionAddRequest) error
+	Remove(ctx session.Context, req *pb.RpcObjectCollectionRemoveRequest) error
+}
+
+type objectCreator interface {
+	CreateObject(ctx context.Context, spaceID string, req objectcreator.CreateObjectRequest) (id string, details *types.Struct, err error)
+}
+
+type notificationSender interface {
+	CreateAndSend(notification *model.Notification) error
+}
+
+type scriptRunner interface {
+	Run(ctx context.Context, scriptId string, args map[string]any) (*script.Result, error)
+}
+
+// executor runs actions of the rule for the object, all actions are run even if some of them fail
+type executor struct {
+	details     detailsSetter
+	templates   templateApplier
+	collections collectionEditor
+	creator     objectCreator
+	notifier    notificationSender
+	scripts     scriptRunner
+}
+
+func (e *executor) run(ctx context.Context, rule *model.Automation, object *types.Struct, now int64) error {
+	var errs []error
+	for _, action := range rule.Actions {
+		if err := e.runAction(ctx, rule, action, object, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *executor) runAction(ctx context.Context, rule *model.Automation, action *model.AutomationAction, object *types.Struct, now int64) error {
+	objectId := pbtypes.GetString(object, bundle.RelationKeyId.String())
+	switch v := action.GetValue().(type) {
+	case *model.AutomationActionValueOfSetRelation:
+		value := v.SetRelation.Value
+		if v.SetRelation.CurrentDate {
+			value = pbtypes.Int64(now)
+		}
+		if err := e.details.SetDetails(nil, objectId, []*model.Detail{{Key: v.SetRelation.RelationKey, Value: value}}); err != nil {
+			return fmt.Errorf("set relation %s: %w", v.SetRelation.RelationKey, err)
+		}
+	case *model.AutomationActionValueOfApplyTemplate:
+		if err := e.templates.ObjectApplyTemplate(objectId, v.ApplyTemplate.TemplateId); err != nil {
+			return fmt.Errorf("apply template: %w", err)
+		}
+	case *model.AutomationActionValueOfAddToCollection:
+		if err := e.addToCollection(v.AddToCollection.CollectionId, objectId); err != nil {
+			return err
+		}
+	case *model.AutomationActionValueOfMoveToCollection:
+		from := v.MoveToCollection.FromCollectionId
+		if from == "" {
+			from = rule.GetTrigger().GetCollectionId()
+		}
+		// object is added first, so it is not lost if the removal fails
+		if err := e.addToCollection(v.MoveToCollection.ToCollectionId, objectId); err != nil {
+			return err
+		}
+		if err := e.collections.Remove(nil, &pb.RpcObjectCollectionRemoveRequest{ContextId: from, ObjectIds: []string{objectId}}); err != nil {
+			return fmt.Errorf("remove from collection: %w", err)
+		}
+	case *model.AutomationActionValueOfCreateLinkedObject:
+		if err := e.createLinkedObject(ctx, rule, v.CreateLinkedObject, object); err != nil {
+			return err
+		}
+	case *model.AutomationActionValueOfSendNotification:
+		err := e.notifier.CreateAndSend(&model.Notification{
+			Id:      bson.NewObjectId().Hex(),
+			IsLocal: true,
+			Space:   rule.SpaceId,
+			Payload: &model.NotificationPayloadOfAutomation{
+				Automation: &model.NotificationAutomation{
+					SpaceId:      rule.SpaceId,
+					AutomationId: rule.Id,
+					ObjectId:     objectId,
+					ObjectName:   pbtypes.GetString(object, bundle.RelationKeyName.String()),
+					Title:        v.SendNotification.Title,
+					Text:         v.SendNotification.Text,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("send notification: %w", err)
+		}
+	case *model.AutomationActionValueOfRunScript:
+		_, err := e.scripts.Run(ctx, v.RunScript.ScriptId, map[string]any{
+			"objectId":     objectId,
+			"automationId": rule.Id,
+		})
+		if err != nil {
+			return fmt.Errorf("run script: %w", err)
+		}
+	default:
+		return errors.New("unknown action")
+	}
+	return nil
+}
+
+func (e *executor) addToCollection(collectionId, objectId string) error {
+	if err := e.collections.Add(nil, &pb.RpcObjectCollectionAddRequest{ContextId: collectionId, ObjectIds: []string{objectId}}); err != nil {
+		return fmt.Errorf("add to collection: %w", err)
+	}
+	return nil
+}
+
+func (e *executor) createLinkedObject(ctx context.Context, rule *model.Automation, action *model.AutomationActionCreateLinkedObject, object *types.Struct) error {
+	details := &types.Struct{Fields: map[string]*types.Value{}}
+	if action.Name != "" {
+		details.Fields[bundle.RelationKeyName.String()] = pbtypes.String(action.Name)
+	}
+	id, _, err := e.creator.CreateObject(ctx, rule.SpaceId, objectcreator.CreateObjectRequest{
+		Details:       details,
+		ObjectTypeKey: domain.TypeKey(action.ObjectTypeKey),
+		TemplateId:    action.TemplateId,
+	})
+	if err != nil {
+		return fmt.Errorf("create linked object: %w", err)
+	}
+	if action.RelationKey == "" {
+		return nil
+	}
+	links := slice.Union(pbtypes.GetStringList(object, action.RelationKey), []string{id})
+	err = e.details.SetDetails(nil, pbtypes.GetString(object, bundle.RelationKeyId.String()), []*model.Detail{{
+		Key:   action.RelationKey,
+		Value: pbtypes.StringList(links),
+	}})
+	if err != nil {
+		return fmt.Errorf("link created object: %w", err)
+	}
+	return nil
+}
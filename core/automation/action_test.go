@@ -0,0 +1,157 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/script"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type fakeBackend struct {
+	details       map[string][]*model.Detail
+	collections   map[string][]string
+	created       []objectcreator.CreateObjectRequest
+	notifications []*model.Notification
+	scriptRuns    []string
+	removeErr     error
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		details:     map[string][]*model.Detail{},
+		collections: map[string][]string{},
+	}
+}
+
+func (f *fakeBackend) SetDetails(_ session.Context, objectId string, details []*model.Detail) error {
+	f.details[objectId] = append(f.details[objectId], details...)
+	return nil
+}
+
+func (f *fakeBackend) ObjectApplyTemplate(string, string) error {
+	return nil
+}
+
+func (f *fakeBackend) Add(_ session.Context, req *pb.RpcObjectCollectionAddRequest) error {
+	f.collections[req.ContextId] = append(f.collections[req.ContextId], req.ObjectIds...)
+	return nil
+}
+
+func (f *fakeBackend) Remove(_ session.Context, req *pb.RpcObjectCollectionRemoveRequest) error {
+	if f.removeErr != nil {
+		return f.removeErr
+	}
+	delete(f.collections, req.ContextId)
+	return nil
+}
+
+func (f *fakeBackend) CreateObject(_ context.Context, _ string, req objectcreator.CreateObjectRequest) (string, *types.Struct, error) {
+	f.created = append(f.created, req)
+	return "created", nil, nil
+}
+
+func (f *fakeBackend) CreateAndSend(notification *model.Notification) error {
+	f.notifications = append(f.notifications, notification)
+	return nil
+}
+
+func (f *fakeBackend) Run(_ context.Context, scriptId string, args map[string]any) (*script.Result, error) {
+	f.scriptRuns = append(f.scriptRuns, scriptId+":"+args["objectId"].(string))
+	return &script.Result{}, nil
+}
+
+func newTestExecutor(f *fakeBackend) *executor {
+	return &executor{details: f, templates: f, collections: f, creator: f, notifier: f, scripts: f}
+}
+
+func TestExecutor_Run(t *testing.T) {
+	object := &types.Struct{Fields: map[string]*types.Value{
+		bundle.RelationKeyId.String():   pbtypes.String("task"),
+		bundle.RelationKeyName.String(): pbtypes.String("Write docs"),
+		"subtasks":                      pbtypes.StringList([]string{"existing"}),
+	}}
+
+	t.Run("all actions are run", func(t *testing.T) {
+		// given
+		f := newFakeBackend()
+		f.collections["inbox"] = []string{"task"}
+		rule := &model.Automation{
+			Id:      "rule",
+			SpaceId: "space",
+			Trigger: &model.AutomationTrigger{Type: model.AutomationTrigger_AddedToCollection, CollectionId: "inbox"},
+			Actions: []*model.AutomationAction{
+				{Value: &model.AutomationActionValueOfSetRelation{SetRelation: &model.AutomationActionSetRelation{
+					RelationKey: "completionDate",
+					CurrentDate: true,
+				}}},
+				{Value: &model.AutomationActionValueOfMoveToCollection{MoveToCollection: &model.AutomationActionMoveToCollection{
+					ToCollectionId: "done",
+				}}},
+				{Value: &model.AutomationActionValueOfCreateLinkedObject{CreateLinkedObject: &model.AutomationActionCreateLinkedObject{
+					ObjectTypeKey: "task",
+					Name:          "Review",
+					RelationKey:   "subtasks",
+				}}},
+				{Value: &model.AutomationActionValueOfSendNotification{SendNotification: &model.AutomationActionSendNotification{
+					Title: "Task is done",
+				}}},
+				{Value: &model.AutomationActionValueOfRunScript{RunScript: &model.AutomationActionRunScript{
+					ScriptId: "report",
+				}}},
+			},
+		}
+
+		// when
+		err := newTestExecutor(f).run(context.Background(), rule, object, 1000)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Detail{
+			{Key: "completionDate", Value: pbtypes.Int64(1000)},
+			{Key: "subtasks", Value: pbtypes.StringList([]string{"existing", "created"})},
+		}, f.details["task"])
+		assert.Equal(t, map[string][]string{"done": {"task"}}, f.collections)
+		require.Len(t, f.created, 1)
+		assert.Equal(t, "Review", pbtypes.GetString(f.created[0].Details, bundle.RelationKeyName.String()))
+		require.Len(t, f.notifications, 1)
+		assert.True(t, f.notifications[0].IsLocal)
+		assert.Equal(t, "Write docs", f.notifications[0].GetAutomation().ObjectName)
+		assert.Equal(t, []string{"report:task"}, f.scriptRuns)
+	})
+
+	t.Run("failed action does not stop others", func(t *testing.T) {
+		// given
+		f := newFakeBackend()
+		f.removeErr = errors.New("collection is locked")
+		rule := &model.Automation{
+			Actions: []*model.AutomationAction{
+				{Value: &model.AutomationActionValueOfMoveToCollection{MoveToCollection: &model.AutomationActionMoveToCollection{
+					FromCollectionId: "inbox",
+					ToCollectionId:   "done",
+				}}},
+				{Value: &model.AutomationActionValueOfSetRelation{SetRelation: &model.AutomationActionSetRelation{
+					RelationKey: "status",
+					Value:       pbtypes.String("closed"),
+				}}},
+			},
+		}
+
+		// when
+		err := newTestExecutor(f).run(context.Background(), rule, object, 1000)
+
+		// then
+		assert.ErrorIs(t, err, f.removeErr)
+		assert.Equal(t, []*model.Detail{{Key: "status", Value: pbtypes.String("closed")}}, f.details["task"])
+	})
+}
@@ -0,0 +1,657 @@
+// Package automation runs rules of the form "when the trigger fires for an object matching the conditions, run the actions".
+// Rules are stored in the details of objects of automation type, so they are synced with the space, but each rule
+// is executed only on one device of its creator. Only the time up to which date triggers have been fired is stored
+// in the rule object, it prevents repeated runs when the rule is moved between devices. Triggers are tracked
+// by internal subscriptions, date triggers are checked periodically. Execution log and latest runs are kept locally
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/cheggaaa/mb/v3"
+	"github.com/gogo/protobuf/types"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/block"
+	"github.com/anyproto/anytype-heart/core/block/collection"
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/block/template"
+	"github.com/anyproto/anytype-heart/core/notifications"
+	"github.com/anyproto/anytype-heart/core/script"
+	"github.com/anyproto/anytype-heart/core/subscription"
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/space"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const CName = "core.automation"
+
+var log = logging.Logger(CName).Desugar()
+
+const (
+	rulesSubId  = "automation-rules"
+	subIdPrefix = "automation-"
+
+	// cooldown protects from loops, when actions of the rule fire the trigger of the same or another rule
+	cooldown        = 10 * time.Second
+	dateCheckPeriod = time.Minute
+	maxLogEntries   = 100
+)
+
+var ErrNotFound = errors.New("automation not found")
+
+type Service interface {
+	// Create validates the rule and creates the automation object in the space
+	Create(ctx context.Context, spaceId string, rule *model.Automation) (*model.Automation, error)
+	// Update replaces trigger, conditions and actions of the rule
+	Update(id string, rule *model.Automation) error
+	SetDisabled(id string, disabled bool) error
+	// List returns rules of the space, rules of all spaces are returned for empty space id
+	List(spaceId string) ([]*model.Automation, error)
+	// Log returns the latest entries of the execution log of the rule
+	Log(id string, limit int) ([]*model.AutomationLogEntry, error)
+	app.ComponentRunnable
+}
+
+func New() Service {
+	return &service{}
+}
+
+type subscriptionMessages struct {
+	subId string
+	msgs  []*pb.EventMessage
+}
+
+type activeRule struct {
+	rule *model.Automation
+	// encoded rule, it is compared to skip resubscribing when only the name has changed
+	raw         string
+	activatedAt time.Time
+}
+
+type service struct {
+	objectStore  objectstore.ObjectStore
+	subscription subscription.Service
+	spaceService space.Service
+	creator      objectcreator.Service
+	details      detailsSetter
+	executor     *executor
+	wallet       wallet.Wallet
+
+	// identity of the account and id of the device, only rules created by the account and assigned to the device are run
+	identity string
+	deviceId string
+
+	logs       keyvaluestore.Store[[]*model.AutomationLogEntry]
+	dateChecks keyvaluestore.Store[int64]
+	messages   *mb.MB[subscriptionMessages]
+
+	// runs are changed by both the subscription and the date check goroutines
+	runsMu sync.Mutex
+	runs   keyvaluestore.Store[ruleRuns]
+
+	mu    sync.Mutex
+	rules map[string]*activeRule
+	now   func() time.Time
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+}
+
+func (s *service) Init(a *app.App) error {
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	s.subscription = app.MustComponent[subscription.Service](a)
+	s.spaceService = app.MustComponent[space.Service](a)
+	s.creator = app.MustComponent[objectcreator.Service](a)
+	s.wallet = app.MustComponent[wallet.Wallet](a)
+	blockService := app.MustComponent[*block.Service](a)
+	s.details = blockService
+	s.executor = &executor{
+		details:     blockService,
+		templates:   app.MustComponent[template.Service](a),
+		collections: app.MustComponent[*collection.Service](a),
+		creator:     s.creator,
+		notifier:    app.MustComponent[notifications.Notifications](a),
+		scripts:     app.MustComponent[script.Service](a),
+	}
+	db, err := app.MustComponent[datastore.Datastore](a).LocalStorage()
+	if err != nil {
+		return fmt.Errorf("get badger: %w", err)
+	}
+	s.logs = keyvaluestore.NewJson[[]*model.AutomationLogEntry](db, []byte("automation/log/"))
+	s.dateChecks = keyvaluestore.NewJson[int64](db, []byte("automation/dateChecks/"))
+	s.runs = keyvaluestore.NewJson[ruleRuns](db, []byte("automation/runs/"))
+	s.messages = mb.New[subscriptionMessages](0)
+	s.rules = map[string]*activeRule{}
+	s.now = time.Now
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	return nil
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(_ context.Context) error {
+	s.identity = s.wallet.Account().SignKey.GetPublic().Account()
+	s.deviceId = s.wallet.GetDevicePrivkey().GetPublic().PeerId()
+	resp, err := s.subscription.SearchInternal(pb.RpcObjectSearchSubscribeRequest{
+		SubId:   rulesSubId,
+		Filters: ruleFilters(""),
+		Keys: []string{
+			bundle.RelationKeyId.String(),
+			bundle.RelationKeyName.String(),
+			bundle.RelationKeyAutomationRule.String(),
+			bundle.RelationKeyAutomationDisabled.String(),
+			bundle.RelationKeyAutomationDevice.String(),
+			bundle.RelationKeyCreator.String(),
+			bundle.RelationKeyLastModifiedBy.String(),
+		},
+		NoDepSubscription: true,
+	}, s.addMessages(rulesSubId))
+	if err != nil {
+		return fmt.Errorf("subscribe for rules: %w", err)
+	}
+	ids := make([]string, 0, len(resp.Records))
+	for _, details := range resp.Records {
+		ids = append(ids, pbtypes.GetString(details, bundle.RelationKeyId.String()))
+	}
+	// rules are activated in the background, because activation could claim the rule for the device
+	go s.handleMessages(ids)
+	go s.checkDates()
+	return nil
+}
+
+func (s *service) Close(_ context.Context) error {
+	if s.ctxCancel != nil {
+		s.ctxCancel()
+	}
+	s.mu.Lock()
+	subIds := []string{rulesSubId}
+	for id := range s.rules {
+		subIds = append(subIds, subIdPrefix+id)
+	}
+	s.mu.Unlock()
+	if err := s.subscription.Unsubscribe(subIds...); err != nil {
+		log.Warn("unsubscribe", zap.Error(err))
+	}
+	return s.messages.Close()
+}
+
+func (s *service) Create(ctx context.Context, spaceId string, rule *model.Automation) (*model.Automation, error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+	raw, err := marshalRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("marshal rule: %w", err)
+	}
+	spc, err := s.spaceService.Get(ctx, spaceId)
+	if err != nil {
+		return nil, fmt.Errorf("get space: %w", err)
+	}
+	_, _, err = s.creator.InstallBundledObjects(ctx, spc, []string{bundle.TypeKeyAutomation.BundledURL()}, false)
+	if err != nil {
+		return nil, fmt.Errorf("install automation type: %w", err)
+	}
+	id, _, err := s.creator.CreateObject(ctx, spaceId, objectcreator.CreateObjectRequest{
+		ObjectTypeKey: bundle.TypeKeyAutomation,
+		Details: &types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeyName.String():               pbtypes.String(rule.Name),
+			bundle.RelationKeyAutomationRule.String():     pbtypes.String(raw),
+			bundle.RelationKeyAutomationDisabled.String(): pbtypes.Bool(rule.Disabled),
+			bundle.RelationKeyAutomationDevice.String():   pbtypes.String(s.deviceId),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create automation object: %w", err)
+	}
+	return &model.Automation{
+		Id:         id,
+		SpaceId:    spaceId,
+		Name:       rule.Name,
+		Disabled:   rule.Disabled,
+		Trigger:    rule.Trigger,
+		Conditions: rule.Conditions,
+		Actions:    rule.Actions,
+	}, nil
+}
+
+func (s *service) Update(id string, rule *model.Automation) error {
+	if _, err := s.getRuleDetails(id); err != nil {
+		return err
+	}
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	raw, err := marshalRule(rule)
+	if err != nil {
+		return fmt.Errorf("marshal rule: %w", err)
+	}
+	// the rule is reactivated when the change is indexed
+	return s.details.SetDetails(nil, id, []*model.Detail{{
+		Key:   bundle.RelationKeyAutomationRule.String(),
+		Value: pbtypes.String(raw),
+	}})
+}
+
+func (s *service) SetDisabled(id string, disabled bool) error {
+	if _, err := s.getRuleDetails(id); err != nil {
+		return err
+	}
+	return s.details.SetDetails(nil, id, []*model.Detail{{
+		Key:   bundle.RelationKeyAutomationDisabled.String(),
+		Value: pbtypes.Bool(disabled),
+	}})
+}
+
+func (s *service) List(spaceId string) ([]*model.Automation, error) {
+	records, err := s.objectStore.Query(database.Query{
+		Filters: ruleFilters(spaceId),
+		Sorts: []*model.BlockContentDataviewSort{{
+			RelationKey: bundle.RelationKeyCreatedDate.String(),
+			Type:        model.BlockContentDataviewSort_Asc,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query rules: %w", err)
+	}
+	rules := make([]*model.Automation, 0, len(records))
+	for _, rec := range records {
+		rule, err := ruleFromDetails(rec.Details)
+		if err != nil {
+			log.Warn("decode rule", zap.String("id", pbtypes.GetString(rec.Details, bundle.RelationKeyId.String())), zap.Error(err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *service) Log(id string, limit int) ([]*model.AutomationLogEntry, error) {
+	entries, err := s.logs.Get(id)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get log: %w", err)
+	}
+	if limit <= 0 || limit > len(entries) {
+		limit = len(entries)
+	}
+	result := make([]*model.AutomationLogEntry, 0, limit)
+	for i := len(entries) - 1; i >= len(entries)-limit; i-- {
+		result = append(result, entries[i])
+	}
+	return result, nil
+}
+
+func (s *service) getRuleDetails(id string) (*types.Struct, error) {
+	details, err := s.objectStore.GetDetails(id)
+	if err != nil {
+		return nil, fmt.Errorf("get details: %w", err)
+	}
+	if !isRuleObject(details.GetDetails()) {
+		return nil, ErrNotFound
+	}
+	return details.GetDetails(), nil
+}
+
+// addMessages returns the handler of the internal subscription. The handler is called under the subscription service lock,
+// so messages are handled in the separate goroutine
+func (s *service) addMessages(subId string) subscription.InternalHandler {
+	return func(msgs []*pb.EventMessage) {
+		if err := s.messages.Add(s.ctx, subscriptionMessages{subId: subId, msgs: msgs}); err != nil {
+			log.Warn("add subscription messages", zap.Error(err))
+		}
+	}
+}
+
+func (s *service) handleMessages(ruleIds []string) {
+	for _, id := range ruleIds {
+		s.reloadRule(id)
+	}
+	for {
+		batch, err := s.messages.Wait(s.ctx)
+		if err != nil {
+			return
+		}
+		for _, m := range batch {
+			if m.subId == rulesSubId {
+				for _, id := range messageObjectIds(m.msgs) {
+					s.reloadRule(id)
+				}
+				continue
+			}
+			s.mu.Lock()
+			active := s.rules[strings.TrimPrefix(m.subId, subIdPrefix)]
+			s.mu.Unlock()
+			if active == nil {
+				continue
+			}
+			for _, id := range triggeredObjects(active.rule.Trigger, m.msgs) {
+				s.execute(active, id)
+			}
+		}
+	}
+}
+
+// reloadRule activates the rule with the current details of the object, or deactivates it
+// if the object is not an enabled rule anymore or the rule is not executed on this device
+func (s *service) reloadRule(id string) {
+	details, err := s.objectStore.GetDetails(id)
+	if err != nil || !isRuleObject(details.GetDetails()) || !isExecutor(details.GetDetails(), s.identity, s.deviceId) {
+		s.deactivate(id)
+		return
+	}
+	rule, err := ruleFromDetails(details.GetDetails())
+	if err == nil {
+		err = validateRule(rule)
+	}
+	if err != nil {
+		log.Warn("invalid rule", zap.String("id", id), zap.Error(err))
+		s.deactivate(id)
+		return
+	}
+	if rule.Disabled {
+		s.deactivate(id)
+		return
+	}
+	if pbtypes.GetString(details.GetDetails(), bundle.RelationKeyAutomationDevice.String()) == "" {
+		// if several devices claim the rule, the last change wins and other devices deactivate the rule after sync
+		err = s.details.SetDetails(nil, id, []*model.Detail{{
+			Key:   bundle.RelationKeyAutomationDevice.String(),
+			Value: pbtypes.String(s.deviceId),
+		}})
+		if err != nil {
+			log.Error("claim rule", zap.String("id", id), zap.Error(err))
+			return
+		}
+	}
+	if err = s.activate(rule); err != nil {
+		log.Error("activate rule", zap.String("id", id), zap.Error(err))
+	}
+}
+
+func (s *service) activate(rule *model.Automation) error {
+	raw, err := marshalRule(rule)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.rules[rule.Id]; ok {
+		if existing.raw == raw {
+			existing.rule = rule
+			return nil
+		}
+		s.deactivateLocked(rule.Id)
+	}
+	active := &activeRule{rule: rule, raw: raw, activatedAt: s.now()}
+	if req, ok := triggerSubscription(rule); ok {
+		if _, err = s.subscription.SearchInternal(req, s.addMessages(req.SubId)); err != nil {
+			return fmt.Errorf("subscribe: %w", err)
+		}
+	}
+	s.rules[rule.Id] = active
+	return nil
+}
+
+func (s *service) deactivate(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deactivateLocked(id)
+}
+
+func (s *service) deactivateLocked(id string) {
+	active, ok := s.rules[id]
+	if !ok {
+		return
+	}
+	delete(s.rules, id)
+	if _, hasSubscription := triggerSubscription(active.rule); hasSubscription {
+		if err := s.subscription.Unsubscribe(subIdPrefix + id); err != nil {
+			log.Warn("unsubscribe rule", zap.String("id", id), zap.Error(err))
+		}
+	}
+}
+
+// checkDates periodically fires date triggers for objects which dates have been reached since the previous check
+func (s *service) checkDates() {
+	ticker := time.NewTicker(dateCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		var dateRules []*activeRule
+		for _, active := range s.rules {
+			if active.rule.GetTrigger().GetType() == model.AutomationTrigger_DateReached {
+				dateRules = append(dateRules, active)
+			}
+		}
+		s.mu.Unlock()
+		for _, active := range dateRules {
+			if err := s.checkDate(active); err != nil {
+				log.Error("check date trigger", zap.String("id", active.rule.Id), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *service) checkDate(active *activeRule) error {
+	from, err := s.dateChecks.Get(active.rule.Id)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		from = active.activatedAt.Unix()
+	} else if err != nil {
+		return fmt.Errorf("get last check: %w", err)
+	}
+	details, err := s.getRuleDetails(active.rule.Id)
+	if err != nil {
+		return err
+	}
+	st, err := stateFromDetails(details)
+	if err != nil {
+		log.Warn("invalid rule state", zap.String("id", active.rule.Id), zap.Error(err))
+	}
+	// another device could have fired the triggers before the rule has been moved to this device
+	from = max(from, st.CheckedDate)
+	to := s.now().Unix()
+	records, err := s.objectStore.Query(database.Query{Filters: dateFilters(active.rule, from, to)})
+	if err != nil {
+		return fmt.Errorf("query objects: %w", err)
+	}
+	for _, rec := range records {
+		s.execute(active, pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()))
+	}
+	if len(records) > 0 {
+		// the state is stored only when triggers have fired, to not change the rule object every minute
+		err = s.updateState(active.rule.Id, func(st *ruleState) bool {
+			st.CheckedDate = to
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("update state: %w", err)
+		}
+	}
+	return s.dateChecks.Set(active.rule.Id, to)
+}
+
+// execute runs actions of the rule if the object matches the conditions
+func (s *service) execute(active *activeRule, objectId string) {
+	rule := active.rule
+	details, err := s.objectStore.GetDetails(objectId)
+	if err != nil || pbtypes.IsStructEmpty(details.GetDetails()) {
+		return
+	}
+	object := details.GetDetails()
+	if rule.Trigger.Type == model.AutomationTrigger_ObjectCreated &&
+		pbtypes.GetInt64(object, bundle.RelationKeyCreatedDate.String()) < active.activatedAt.Unix() {
+		return
+	}
+	if !s.matches(rule, object) {
+		return
+	}
+	now := s.now()
+	ranRecently, err := s.addRun(rule.Id, objectId, now)
+	if err != nil {
+		log.Error("add automation run", zap.String("id", rule.Id), zap.Error(err))
+		return
+	}
+	if ranRecently {
+		return
+	}
+
+	entry := &model.AutomationLogEntry{
+		Date:     now.Unix(),
+		ObjectId: objectId,
+		Trigger:  rule.Trigger.Type,
+	}
+	if err = s.executor.run(s.ctx, rule, object, now.Unix()); err != nil {
+		log.Warn("run automation", zap.String("id", rule.Id), zap.String("objectId", objectId), zap.Error(err))
+		entry.Error = err.Error()
+	}
+	if err = s.appendLog(rule.Id, entry); err != nil {
+		log.Error("append automation log", zap.String("id", rule.Id), zap.Error(err))
+	}
+}
+
+// updateState changes the state stored in the rule object, the state is saved only if update returns true
+func (s *service) updateState(ruleId string, update func(st *ruleState) bool) error {
+	details, err := s.getRuleDetails(ruleId)
+	if err != nil {
+		return err
+	}
+	st, err := stateFromDetails(details)
+	if err != nil {
+		log.Warn("invalid rule state", zap.String("id", ruleId), zap.Error(err))
+	}
+	if !update(st) {
+		return nil
+	}
+	raw, err := st.marshal()
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+	return s.details.SetDetails(nil, ruleId, []*model.Detail{{
+		Key:   bundle.RelationKeyAutomationState.String(),
+		Value: pbtypes.String(raw),
+	}})
+}
+
+// addRun records the run of the rule for the object, unless the rule has been run for the object within the cooldown.
+// Runs are kept locally, so they are not synced on every trigger
+func (s *service) addRun(ruleId, objectId string, now time.Time) (ranRecently bool, err error) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	runs, err := s.runs.Get(ruleId)
+	if err != nil && !errors.Is(err, keyvaluestore.ErrNotFound) {
+		return false, err
+	}
+	if runs.ranRecently(objectId, now) {
+		return true, nil
+	}
+	return false, s.runs.Set(ruleId, runs.add(objectId, now))
+}
+
+func (s *service) matches(rule *model.Automation, object *types.Struct) bool {
+	if len(rule.Conditions) == 0 {
+		return true
+	}
+	filters, err := database.MakeFiltersAnd(rule.Conditions, s.objectStore)
+	if err != nil {
+		log.Warn("make conditions", zap.String("id", rule.Id), zap.Error(err))
+		return false
+	}
+	return filters.FilterObject(object)
+}
+
+func (s *service) appendLog(id string, entry *model.AutomationLogEntry) error {
+	entries, err := s.logs.Get(id)
+	if err != nil && !errors.Is(err, keyvaluestore.ErrNotFound) {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxLogEntries {
+		entries = entries[len(entries)-maxLogEntries:]
+	}
+	return s.logs.Set(id, entries)
+}
+
+func isRuleObject(details *types.Struct) bool {
+	return pbtypes.GetString(details, bundle.RelationKeyAutomationRule.String()) != "" &&
+		!pbtypes.GetBool(details, bundle.RelationKeyIsArchived.String()) &&
+		!pbtypes.GetBool(details, bundle.RelationKeyIsDeleted.String())
+}
+
+func ruleFilters(spaceId string) []*model.BlockContentDataviewFilter {
+	filters := []*model.BlockContentDataviewFilter{
+		{
+			RelationKey: bundle.RelationKeyAutomationRule.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEmpty,
+		},
+		{
+			RelationKey: bundle.RelationKeyIsArchived.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEqual,
+			Value:       pbtypes.Bool(true),
+		},
+		{
+			RelationKey: bundle.RelationKeyIsDeleted.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEqual,
+			Value:       pbtypes.Bool(true),
+		},
+	}
+	if spaceId != "" {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(spaceId),
+		})
+	}
+	return filters
+}
+
+// messageObjectIds returns ids of objects mentioned in the subscription messages
+func messageObjectIds(msgs []*pb.EventMessage) []string {
+	var (
+		ids  []string
+		seen = map[string]bool{}
+	)
+	for _, msg := range msgs {
+		var id string
+		switch {
+		case msg.GetSubscriptionAdd() != nil:
+			id = msg.GetSubscriptionAdd().Id
+		case msg.GetSubscriptionRemove() != nil:
+			id = msg.GetSubscriptionRemove().Id
+		case msg.GetObjectDetailsSet() != nil:
+			id = msg.GetObjectDetailsSet().Id
+		case msg.GetObjectDetailsAmend() != nil:
+			id = msg.GetObjectDetailsAmend().Id
+		case msg.GetObjectDetailsUnset() != nil:
+			id = msg.GetObjectDetailsUnset().Id
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,59 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+)
+
+func TestService_AddRun(t *testing.T) {
+	newService := func(t *testing.T) *service {
+		ds, err := datastore.NewInMemory()
+		require.NoError(t, err)
+		db, err := ds.LocalStorage()
+		require.NoError(t, err)
+		return &service{runs: keyvaluestore.NewJson[ruleRuns](db, []byte("automation/runs/"))}
+	}
+
+	t.Run("run is skipped within the cooldown", func(t *testing.T) {
+		// given
+		s := newService(t)
+		now := time.Unix(1700000000, 0)
+		ranRecently, err := s.addRun("rule1", "object1", now)
+		require.NoError(t, err)
+		require.False(t, ranRecently)
+
+		// when
+		ranRecently, err = s.addRun("rule1", "object1", now.Add(cooldown/2))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, ranRecently)
+	})
+
+	t.Run("runs are tracked per rule and object", func(t *testing.T) {
+		// given
+		s := newService(t)
+		now := time.Unix(1700000000, 0)
+		_, err := s.addRun("rule1", "object1", now)
+		require.NoError(t, err)
+
+		// when
+		otherObject, err := s.addRun("rule1", "object2", now)
+		require.NoError(t, err)
+		otherRule, err := s.addRun("rule2", "object1", now)
+		require.NoError(t, err)
+		afterCooldown, err := s.addRun("rule1", "object1", now.Add(cooldown))
+		require.NoError(t, err)
+
+		// then
+		assert.False(t, otherObject)
+		assert.False(t, otherRule)
+		assert.False(t, afterCooldown)
+	})
+}
@@ -0,0 +1,183 @@
+package automation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+var ErrInvalidRule = errors.New("invalid automation rule")
+
+// marshalRule encodes trigger, conditions and actions of the rule, other fields are kept in the details of the object
+func marshalRule(rule *model.Automation) (string, error) {
+	m := jsonpb.Marshaler{}
+	return m.MarshalToString(&model.Automation{
+		Trigger:    rule.Trigger,
+		Conditions: rule.Conditions,
+		Actions:    rule.Actions,
+	})
+}
+
+// ruleFromDetails decodes the rule of the automation object
+func ruleFromDetails(details *types.Struct) (*model.Automation, error) {
+	raw := pbtypes.GetString(details, bundle.RelationKeyAutomationRule.String())
+	if raw == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+	rule := &model.Automation{}
+	if err := jsonpb.UnmarshalString(raw, rule); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	rule.Id = pbtypes.GetString(details, bundle.RelationKeyId.String())
+	rule.SpaceId = pbtypes.GetString(details, bundle.RelationKeySpaceId.String())
+	rule.Name = pbtypes.GetString(details, bundle.RelationKeyName.String())
+	rule.Disabled = pbtypes.GetBool(details, bundle.RelationKeyAutomationDisabled.String())
+	return rule, nil
+}
+
+// ruleState is kept in the rule object, so date triggers are not fired again when the rule is moved to another device.
+// The state is changed only when date triggers fire
+type ruleState struct {
+	// CheckedDate is the time up to which date triggers have been fired
+	CheckedDate int64 `json:"checkedDate,omitempty"`
+}
+
+func stateFromDetails(details *types.Struct) (*ruleState, error) {
+	st := &ruleState{}
+	raw := pbtypes.GetString(details, bundle.RelationKeyAutomationState.String())
+	if raw == "" {
+		return st, nil
+	}
+	if err := json.Unmarshal([]byte(raw), st); err != nil {
+		return &ruleState{}, fmt.Errorf("decode state: %w", err)
+	}
+	return st, nil
+}
+
+func (st *ruleState) marshal() (string, error) {
+	raw, err := json.Marshal(st)
+	return string(raw), err
+}
+
+// ruleRuns contains the time of the latest run of the rule per object. Runs are kept locally
+// and dropped after the cooldown
+type ruleRuns map[string]int64
+
+// ranRecently reports whether the rule has been run for the object within the cooldown
+func (r ruleRuns) ranRecently(objectId string, now time.Time) bool {
+	last, ok := r[objectId]
+	return ok && now.Sub(time.Unix(last, 0)) < cooldown
+}
+
+func (r ruleRuns) add(objectId string, now time.Time) ruleRuns {
+	if r == nil {
+		r = ruleRuns{}
+	}
+	for id, last := range r {
+		if now.Sub(time.Unix(last, 0)) >= cooldown {
+			delete(r, id)
+		}
+	}
+	r[objectId] = now.Unix()
+	return r
+}
+
+// isExecutor reports whether the rule is executed on this device. Rules are synced with the space, but they are
+// executed only on the device of the member who created them, and only while no other member has changed them,
+// so the rule planted or edited by someone else never runs actions on behalf of the account.
+// Rules without a device are claimed by the first device of the creator that activates them
+func isExecutor(details *types.Struct, identity, deviceId string) bool {
+	participantId := domain.NewParticipantId(pbtypes.GetString(details, bundle.RelationKeySpaceId.String()), identity)
+	if pbtypes.GetString(details, bundle.RelationKeyCreator.String()) != participantId {
+		return false
+	}
+	if lastModifiedBy := pbtypes.GetString(details, bundle.RelationKeyLastModifiedBy.String()); lastModifiedBy != "" && lastModifiedBy != participantId {
+		return false
+	}
+	device := pbtypes.GetString(details, bundle.RelationKeyAutomationDevice.String())
+	return device == "" || device == deviceId
+}
+
+func validateRule(rule *model.Automation) error {
+	trigger := rule.GetTrigger()
+	if trigger == nil {
+		return fmt.Errorf("%w: trigger is required", ErrInvalidRule)
+	}
+	switch trigger.Type {
+	case model.AutomationTrigger_ObjectCreated:
+		if trigger.ObjectTypeKey == "" {
+			return fmt.Errorf("%w: object type is required", ErrInvalidRule)
+		}
+	case model.AutomationTrigger_RelationChanged, model.AutomationTrigger_DateReached:
+		if trigger.RelationKey == "" {
+			return fmt.Errorf("%w: relation is required", ErrInvalidRule)
+		}
+	case model.AutomationTrigger_AddedToCollection:
+		if trigger.CollectionId == "" {
+			return fmt.Errorf("%w: collection is required", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown trigger %d", ErrInvalidRule, trigger.Type)
+	}
+
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidRule)
+	}
+	for i, action := range rule.Actions {
+		if err := validateAction(trigger, action); err != nil {
+			return fmt.Errorf("%w: action %d: %w", ErrInvalidRule, i, err)
+		}
+	}
+	return nil
+}
+
+func validateAction(trigger *model.AutomationTrigger, action *model.AutomationAction) error {
+	switch v := action.GetValue().(type) {
+	case *model.AutomationActionValueOfSetRelation:
+		if v.SetRelation.RelationKey == "" {
+			return errors.New("relation is required")
+		}
+		if v.SetRelation.Value == nil && !v.SetRelation.CurrentDate {
+			return errors.New("value is required")
+		}
+	case *model.AutomationActionValueOfApplyTemplate:
+		if v.ApplyTemplate.TemplateId == "" {
+			return errors.New("template is required")
+		}
+	case *model.AutomationActionValueOfAddToCollection:
+		if v.AddToCollection.CollectionId == "" {
+			return errors.New("collection is required")
+		}
+	case *model.AutomationActionValueOfMoveToCollection:
+		if v.MoveToCollection.ToCollectionId == "" {
+			return errors.New("target collection is required")
+		}
+		if v.MoveToCollection.FromCollectionId == "" && trigger.Type != model.AutomationTrigger_AddedToCollection {
+			return errors.New("source collection is required")
+		}
+	case *model.AutomationActionValueOfCreateLinkedObject:
+		if v.CreateLinkedObject.ObjectTypeKey == "" {
+			return errors.New("object type is required")
+		}
+	case *model.AutomationActionValueOfSendNotification:
+		if v.SendNotification.Title == "" && v.SendNotification.Text == "" {
+			return errors.New("notification text is required")
+		}
+	case *model.AutomationActionValueOfRunScript:
+		if v.RunScript.ScriptId == "" {
+			return errors.New("script is required")
+		}
+	default:
+		return errors.New("unknown action")
+	}
+	return nil
+}
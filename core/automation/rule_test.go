@@ -0,0 +1,185 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func doneRule() *model.Automation {
+	return &model.Automation{
+		Trigger: &model.AutomationTrigger{
+			Type:        model.AutomationTrigger_RelationChanged,
+			RelationKey: bundle.RelationKeyDone.String(),
+		},
+		Conditions: []*model.BlockContentDataviewFilter{{
+			RelationKey: bundle.RelationKeyDone.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.Bool(true),
+		}},
+		Actions: []*model.AutomationAction{{
+			Value: &model.AutomationActionValueOfSetRelation{SetRelation: &model.AutomationActionSetRelation{
+				RelationKey: "completionDate",
+				CurrentDate: true,
+			}},
+		}},
+	}
+}
+
+func TestRuleFromDetails(t *testing.T) {
+	// given
+	rule := doneRule()
+	raw, err := marshalRule(rule)
+	require.NoError(t, err)
+	details := &types.Struct{Fields: map[string]*types.Value{
+		bundle.RelationKeyId.String():                 pbtypes.String("rule1"),
+		bundle.RelationKeySpaceId.String():            pbtypes.String("space1"),
+		bundle.RelationKeyName.String():               pbtypes.String("Complete tasks"),
+		bundle.RelationKeyAutomationRule.String():     pbtypes.String(raw),
+		bundle.RelationKeyAutomationDisabled.String(): pbtypes.Bool(true),
+	}}
+
+	// when
+	decoded, err := ruleFromDetails(details)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "rule1", decoded.Id)
+	assert.Equal(t, "space1", decoded.SpaceId)
+	assert.Equal(t, "Complete tasks", decoded.Name)
+	assert.True(t, decoded.Disabled)
+	assert.Equal(t, rule.Trigger, decoded.Trigger)
+	assert.Equal(t, rule.Conditions, decoded.Conditions)
+	assert.Equal(t, rule.Actions, decoded.Actions)
+}
+
+func TestValidateRule(t *testing.T) {
+	t.Run("valid rule", func(t *testing.T) {
+		assert.NoError(t, validateRule(doneRule()))
+	})
+
+	for name, modify := range map[string]func(rule *model.Automation){
+		"no trigger": func(rule *model.Automation) {
+			rule.Trigger = nil
+		},
+		"no relation of relation trigger": func(rule *model.Automation) {
+			rule.Trigger.RelationKey = ""
+		},
+		"no actions": func(rule *model.Automation) {
+			rule.Actions = nil
+		},
+		"no value to set": func(rule *model.Automation) {
+			rule.Actions[0].GetSetRelation().CurrentDate = false
+		},
+		"move without source collection": func(rule *model.Automation) {
+			rule.Actions = append(rule.Actions, &model.AutomationAction{
+				Value: &model.AutomationActionValueOfMoveToCollection{MoveToCollection: &model.AutomationActionMoveToCollection{
+					ToCollectionId: "archive",
+				}},
+			})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			rule := doneRule()
+			modify(rule)
+
+			// when
+			err := validateRule(rule)
+
+			// then
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func TestIsExecutor(t *testing.T) {
+	owner := domain.NewParticipantId("space1", "identity1")
+	writer := domain.NewParticipantId("space1", "identity2")
+	ruleDetails := func(creator, lastModifiedBy, device string) *types.Struct {
+		return &types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeySpaceId.String():          pbtypes.String("space1"),
+			bundle.RelationKeyCreator.String():          pbtypes.String(creator),
+			bundle.RelationKeyLastModifiedBy.String():   pbtypes.String(lastModifiedBy),
+			bundle.RelationKeyAutomationDevice.String(): pbtypes.String(device),
+		}}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		details  *types.Struct
+		executor bool
+	}{
+		{"rule of the device", ruleDetails(owner, owner, "device1"), true},
+		{"rule without device", ruleDetails(owner, owner, ""), true},
+		{"rule of another device", ruleDetails(owner, owner, "device2"), false},
+		{"rule of another member", ruleDetails(writer, writer, ""), false},
+		{"rule changed by another member", ruleDetails(owner, writer, "device1"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.executor, isExecutor(tc.details, "identity1", "device1"))
+		})
+	}
+}
+
+func TestRuleState(t *testing.T) {
+	t.Run("checked date is decoded", func(t *testing.T) {
+		// given
+		raw, err := (&ruleState{CheckedDate: 1700000000}).marshal()
+		require.NoError(t, err)
+
+		// when
+		st, err := stateFromDetails(&types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeyAutomationState.String(): pbtypes.String(raw),
+		}})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(1700000000), st.CheckedDate)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		st, err := stateFromDetails(&types.Struct{Fields: map[string]*types.Value{
+			bundle.RelationKeyAutomationState.String(): pbtypes.String("{"),
+		}})
+
+		assert.Error(t, err)
+		assert.NotNil(t, st)
+	})
+}
+
+func TestRuleRuns(t *testing.T) {
+	t.Run("runs within cooldown are repeated only after the cooldown", func(t *testing.T) {
+		// given
+		now := time.Unix(1700000000, 0)
+		var runs ruleRuns
+
+		// when
+		runs = runs.add("object1", now)
+
+		// then
+		assert.True(t, runs.ranRecently("object1", now.Add(cooldown/2)))
+		assert.False(t, runs.ranRecently("object1", now.Add(cooldown)))
+		assert.False(t, runs.ranRecently("object2", now))
+	})
+
+	t.Run("old runs are dropped", func(t *testing.T) {
+		// given
+		now := time.Unix(1700000000, 0)
+		runs := ruleRuns{}.add("object1", now)
+
+		// when
+		runs = runs.add("object2", now.Add(cooldown))
+
+		// then
+		assert.Equal(t, ruleRuns{"object2": now.Add(cooldown).Unix()}, runs)
+	})
+}
@@ -0,0 +1,111 @@
+package automation
+
+import (
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// triggerSubscription returns the subscription that tracks the objects of the trigger.
+// Date triggers are checked periodically, so they have no subscription
+func triggerSubscription(rule *model.Automation) (req pb.RpcObjectSearchSubscribeRequest, ok bool) {
+	trigger := rule.GetTrigger()
+	req = pb.RpcObjectSearchSubscribeRequest{
+		SubId: subIdPrefix + rule.Id,
+		Filters: []*model.BlockContentDataviewFilter{{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(rule.SpaceId),
+		}},
+		Keys:              []string{bundle.RelationKeyId.String()},
+		NoDepSubscription: true,
+	}
+	switch trigger.GetType() {
+	case model.AutomationTrigger_ObjectCreated:
+		req.Filters = append(req.Filters, &model.BlockContentDataviewFilter{
+			RelationKey: database.NestedRelationKey(bundle.RelationKeyType, bundle.RelationKeyUniqueKey),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(domain.TypeKey(trigger.ObjectTypeKey).URL()),
+		})
+		req.Keys = append(req.Keys, bundle.RelationKeyCreatedDate.String())
+	case model.AutomationTrigger_RelationChanged:
+		req.Keys = append(req.Keys, trigger.RelationKey)
+	case model.AutomationTrigger_AddedToCollection:
+		req.CollectionId = trigger.CollectionId
+	default:
+		return req, false
+	}
+	return req, true
+}
+
+// triggeredObjects returns ids of objects for which the subscription messages fire the trigger.
+// Objects matched by the subscription at the moment of subscribing are not reported by messages, so they are not triggered
+func triggeredObjects(trigger *model.AutomationTrigger, msgs []*pb.EventMessage) []string {
+	var (
+		ids     []string
+		seen    = map[string]bool{}
+		isAdded = map[string]bool{}
+	)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, msg := range msgs {
+		if v := msg.GetSubscriptionAdd(); v != nil {
+			isAdded[v.Id] = true
+		}
+	}
+
+	for _, msg := range msgs {
+		switch trigger.GetType() {
+		case model.AutomationTrigger_ObjectCreated, model.AutomationTrigger_AddedToCollection:
+			if v := msg.GetSubscriptionAdd(); v != nil {
+				add(v.Id)
+			}
+		case model.AutomationTrigger_RelationChanged:
+			// details of added objects are sent by set messages, so only amends and unsets are changes
+			if v := msg.GetObjectDetailsAmend(); v != nil && !isAdded[v.Id] {
+				for _, kv := range v.Details {
+					if kv.Key == trigger.RelationKey {
+						add(v.Id)
+					}
+				}
+			}
+			if v := msg.GetObjectDetailsUnset(); v != nil && !isAdded[v.Id] {
+				for _, key := range v.Keys {
+					if key == trigger.RelationKey {
+						add(v.Id)
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// dateFilters selects objects which date in the relation has been reached in the (from, to] interval
+func dateFilters(rule *model.Automation, from, to int64) []*model.BlockContentDataviewFilter {
+	relationKey := rule.GetTrigger().GetRelationKey()
+	return []*model.BlockContentDataviewFilter{
+		{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(rule.SpaceId),
+		},
+		{
+			RelationKey: relationKey,
+			Condition:   model.BlockContentDataviewFilter_Greater,
+			Value:       pbtypes.Int64(from),
+		},
+		{
+			RelationKey: relationKey,
+			Condition:   model.BlockContentDataviewFilter_LessOrEqual,
+			Value:       pbtypes.Int64(to),
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package automation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestTriggeredObjects(t *testing.T) {
+	msgs := []*pb.EventMessage{
+		{Value: &pb.EventMessageValueOfObjectDetailsSet{ObjectDetailsSet: &pb.EventObjectDetailsSet{
+			Id:      "added",
+			Details: pbtypes.ToStruct(map[string]any{"status": "done"}),
+		}}},
+		{Value: &pb.EventMessageValueOfSubscriptionAdd{SubscriptionAdd: &pb.EventObjectSubscriptionAdd{Id: "added"}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &pb.EventObjectDetailsAmend{
+			Id:      "added",
+			Details: []*pb.EventObjectDetailsAmendKeyValue{{Key: "status", Value: pbtypes.String("todo")}},
+		}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &pb.EventObjectDetailsAmend{
+			Id:      "changed",
+			Details: []*pb.EventObjectDetailsAmendKeyValue{{Key: "status", Value: pbtypes.String("done")}},
+		}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &pb.EventObjectDetailsAmend{
+			Id:      "other",
+			Details: []*pb.EventObjectDetailsAmendKeyValue{{Key: "name", Value: pbtypes.String("task")}},
+		}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsUnset{ObjectDetailsUnset: &pb.EventObjectDetailsUnset{
+			Id:   "unset",
+			Keys: []string{"status"},
+		}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &pb.EventObjectDetailsAmend{
+			Id:      "changed",
+			Details: []*pb.EventObjectDetailsAmendKeyValue{{Key: "status", Value: pbtypes.String("todo")}},
+		}}},
+		{Value: &pb.EventMessageValueOfSubscriptionRemove{SubscriptionRemove: &pb.EventObjectSubscriptionRemove{Id: "removed"}}},
+	}
+
+	t.Run("relation changed", func(t *testing.T) {
+		trigger := &model.AutomationTrigger{Type: model.AutomationTrigger_RelationChanged, RelationKey: "status"}
+		assert.Equal(t, []string{"changed", "unset"}, triggeredObjects(trigger, msgs))
+	})
+
+	t.Run("added to collection", func(t *testing.T) {
+		trigger := &model.AutomationTrigger{Type: model.AutomationTrigger_AddedToCollection, CollectionId: "collection"}
+		assert.Equal(t, []string{"added"}, triggeredObjects(trigger, msgs))
+	})
+}
+
+func TestTriggerSubscription(t *testing.T) {
+	rule := &model.Automation{Id: "rule", SpaceId: "space"}
+
+	t.Run("object created", func(t *testing.T) {
+		rule.Trigger = &model.AutomationTrigger{Type: model.AutomationTrigger_ObjectCreated, ObjectTypeKey: "task"}
+		req, ok := triggerSubscription(rule)
+		assert.True(t, ok)
+		assert.Equal(t, "automation-rule", req.SubId)
+		assert.Len(t, req.Filters, 2)
+		assert.Equal(t, "ot-task", req.Filters[1].Value.GetStringValue())
+	})
+
+	t.Run("added to collection", func(t *testing.T) {
+		rule.Trigger = &model.AutomationTrigger{Type: model.AutomationTrigger_AddedToCollection, CollectionId: "collection"}
+		req, ok := triggerSubscription(rule)
+		assert.True(t, ok)
+		assert.Equal(t, "collection", req.CollectionId)
+	})
+
+	t.Run("date reached is not subscribed", func(t *testing.T) {
+		rule.Trigger = &model.AutomationTrigger{Type: model.AutomationTrigger_DateReached, RelationKey: "dueDate"}
+		_, ok := triggerSubscription(rule)
+		assert.False(t, ok)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/editor/bookmark"
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
 	"github.com/anyproto/anytype-heart/core/block/import/markdown/anymark"
+	"github.com/anyproto/anytype-heart/core/block/restriction"
 	"github.com/anyproto/anytype-heart/core/block/source"
 	"github.com/anyproto/anytype-heart/core/block/undo"
 	"github.com/anyproto/anytype-heart/core/domain"
@@ -94,6 +95,7 @@ func (mw *Middleware) ObjectOpen(cctx context.Context, req *pb.RpcObjectOpenRequ
 		errToCode(spacestorage.ErrTreeStorageAlreadyDeleted, pb.RpcObjectOpenResponseError_OBJECT_DELETED),
 		errToCode(source.ErrUnknownDataFormat, pb.RpcObjectOpenResponseError_ANYTYPE_NEEDS_UPGRADE),
 		errToCode(source.ErrObjectNotFound, pb.RpcObjectOpenResponseError_NOT_FOUND),
+		errToCode(restriction.ErrRestricted, pb.RpcObjectOpenResponseError_ACCESS_RESTRICTED),
 	)
 	return response(code, err)
 }
@@ -122,6 +124,7 @@ func (mw *Middleware) ObjectShow(cctx context.Context, req *pb.RpcObjectShowRequ
 		errToCode(spacestorage.ErrTreeStorageAlreadyDeleted, pb.RpcObjectShowResponseError_OBJECT_DELETED),
 		errToCode(source.ErrUnknownDataFormat, pb.RpcObjectShowResponseError_ANYTYPE_NEEDS_UPGRADE),
 		errToCode(source.ErrObjectNotFound, pb.RpcObjectShowResponseError_NOT_FOUND),
+		errToCode(restriction.ErrRestricted, pb.RpcObjectShowResponseError_ACCESS_RESTRICTED),
 	)
 	return response(code, err)
 }
@@ -736,6 +739,7 @@ func getSessionToken(cctx context.Context) (string, bool) {
 }
 
 func (mw *Middleware) newContext(cctx context.Context, opts ...session.ContextOption) session.Context {
+	opts = append(opts, session.WithParent(cctx))
 	tok, ok := getSessionToken(cctx)
 	if ok {
 		return session.NewContext(append(opts, session.WithSession(tok))...)
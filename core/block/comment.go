@@ -0,0 +1,48 @@
+package block
+
+import (
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/comment"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func (s *Service) AddComment(ctx session.Context, req *pb.RpcCommentAddRequest) (commentId string, err error) {
+	err = cache.Do(s, req.ContextId, func(c comment.Comments) error {
+		commentId, err = c.AddComment(ctx, comment.AddRequest{
+			ThreadId: req.ThreadId,
+			BlockId:  req.BlockId,
+			Range:    req.Range,
+			Text:     req.Text,
+		})
+		return err
+	})
+	return
+}
+
+func (s *Service) EditComment(ctx session.Context, req *pb.RpcCommentEditRequest) error {
+	return cache.Do(s, req.ContextId, func(c comment.Comments) error {
+		return c.EditComment(ctx, req.CommentId, req.Text)
+	})
+}
+
+func (s *Service) DeleteComment(ctx session.Context, req *pb.RpcCommentDeleteRequest) error {
+	return cache.Do(s, req.ContextId, func(c comment.Comments) error {
+		return c.DeleteComment(ctx, req.CommentId)
+	})
+}
+
+func (s *Service) SetCommentThreadResolved(ctx session.Context, req *pb.RpcCommentSetResolvedRequest) error {
+	return cache.Do(s, req.ContextId, func(c comment.Comments) error {
+		return c.SetThreadResolved(ctx, req.ThreadId, req.Resolved)
+	})
+}
+
+func (s *Service) ListComments(req *pb.RpcCommentListRequest) (comments []*model.Comment, err error) {
+	err = cache.Do(s, req.ContextId, func(c comment.Comments) error {
+		comments = c.ListComments(req.IncludeResolved)
+		return nil
+	})
+	return
+}
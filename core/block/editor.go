@@ -25,6 +25,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/simple/text"
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/domain/objectorigin"
+	"github.com/anyproto/anytype-heart/core/kanban"
 	"github.com/anyproto/anytype-heart/core/session"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
@@ -62,7 +63,7 @@ func (s *Service) MarkArchived(ctx session.Context, id string, archived bool) (e
 }
 
 func (s *Service) CreateBlock(ctx session.Context, req pb.RpcBlockCreateRequest) (id string, err error) {
-	err = cache.DoStateCtx(s, ctx, req.ContextId, func(st *state.State, b basic.Creatable) error {
+	err = doSyncedStateCtx(s, ctx, req.ContextId, []string{req.TargetId}, func(st *state.State, b basic.Creatable) error {
 		id, err = b.CreateBlock(st, req)
 		return err
 	})
@@ -92,7 +93,7 @@ func (s *Service) DuplicateBlocks(
 }
 
 func (s *Service) UnlinkBlock(ctx session.Context, req pb.RpcBlockListDeleteRequest) (err error) {
-	return cache.Do(s, req.ContextId, func(b basic.Unlinkable) error {
+	return doSynced(s, req.ContextId, req.BlockIds, func(b basic.Unlinkable) error {
 		return b.Unlink(ctx, req.BlockIds...)
 	})
 }
@@ -100,13 +101,13 @@ func (s *Service) UnlinkBlock(ctx session.Context, req pb.RpcBlockListDeleteRequ
 func (s *Service) SetDivStyle(
 	ctx session.Context, contextId string, style model.BlockContentDivStyle, ids ...string,
 ) (err error) {
-	return cache.Do(s, contextId, func(b basic.CommonOperations) error {
+	return doSynced(s, contextId, ids, func(b basic.CommonOperations) error {
 		return b.SetDivStyle(ctx, style, ids...)
 	})
 }
 
 func (s *Service) SplitBlock(ctx session.Context, req pb.RpcBlockSplitRequest) (blockId string, err error) {
-	err = cache.Do(s, req.ContextId, func(b stext.Text) error {
+	err = doSynced(s, req.ContextId, []string{req.BlockId}, func(b stext.Text) error {
 		blockId, err = b.Split(ctx, req)
 		return err
 	})
@@ -114,7 +115,7 @@ func (s *Service) SplitBlock(ctx session.Context, req pb.RpcBlockSplitRequest) (
 }
 
 func (s *Service) MergeBlock(ctx session.Context, req pb.RpcBlockMergeRequest) (err error) {
-	return cache.Do(s, req.ContextId, func(b stext.Text) error {
+	return doSynced(s, req.ContextId, []string{req.FirstBlockId, req.SecondBlockId}, func(b stext.Text) error {
 		return b.Merge(ctx, req.FirstBlockId, req.SecondBlockId)
 	})
 }
@@ -122,7 +123,7 @@ func (s *Service) MergeBlock(ctx session.Context, req pb.RpcBlockMergeRequest) (
 func (s *Service) TurnInto(
 	ctx session.Context, contextId string, style model.BlockContentTextStyle, ids ...string,
 ) error {
-	return cache.Do(s, contextId, func(b stext.Text) error {
+	return doSynced(s, contextId, ids, func(b stext.Text) error {
 		return b.TurnInto(ctx, style, ids...)
 	})
 }
@@ -140,7 +141,7 @@ func (s *Service) SimplePaste(contextId string, anySlot []*model.Block) (err err
 }
 
 func (s *Service) ReplaceBlock(ctx session.Context, req pb.RpcBlockReplaceRequest) (newId string, err error) {
-	err = cache.Do(s, req.ContextId, func(b basic.Replaceable) error {
+	err = doSynced(s, req.ContextId, []string{req.BlockId}, func(b basic.Replaceable) error {
 		newId, err = b.Replace(ctx, req.BlockId, req.Block)
 		return err
 	})
@@ -148,7 +149,7 @@ func (s *Service) ReplaceBlock(ctx session.Context, req pb.RpcBlockReplaceReques
 }
 
 func (s *Service) SetFields(ctx session.Context, req pb.RpcBlockSetFieldsRequest) (err error) {
-	return cache.Do(s, req.ContextId, func(b basic.CommonOperations) error {
+	return doSynced(s, req.ContextId, []string{req.BlockId}, func(b basic.CommonOperations) error {
 		return b.SetFields(ctx, &pb.RpcBlockListSetFieldsRequestBlockField{
 			BlockId: req.BlockId,
 			Fields:  req.Fields,
@@ -226,9 +227,80 @@ func (s *Service) UpdateDataviewObjectOrder(
 func (s *Service) DataviewMoveObjectsInView(
 	ctx session.Context, req *pb.RpcBlockDataviewObjectOrderMoveRequest,
 ) error {
-	return cache.Do(s, req.ContextId, func(b dataview.Dataview) error {
-		return b.DataviewMoveObjectsInView(ctx, req)
+	var groupRelationKey, subGroupRelationKey string
+	err := cache.Do(s, req.ContextId, func(b dataview.Dataview) error {
+		if err := b.DataviewMoveObjectsInView(ctx, req); err != nil {
+			return err
+		}
+		if req.Group == nil && req.SubGroup == nil {
+			return nil
+		}
+		dv, err := b.GetDataview(req.BlockId)
+		if err != nil {
+			return err
+		}
+		for _, view := range dv.Views {
+			if view.Id == req.ViewId {
+				groupRelationKey = view.GroupRelationKey
+				subGroupRelationKey = view.SubGroupRelationKey
+				break
+			}
+		}
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	type movedGroup struct {
+		relationKey string
+		from, to    *model.BlockContentDataviewGroup
+	}
+	var groups []movedGroup
+	for _, g := range []movedGroup{
+		{relationKey: groupRelationKey, from: req.FromGroup, to: req.Group},
+		{relationKey: subGroupRelationKey, from: req.FromSubGroup, to: req.SubGroup},
+	} {
+		if g.to == nil {
+			continue
+		}
+		if g.relationKey == "" {
+			return fmt.Errorf("view %s is not grouped by this level", req.ViewId)
+		}
+		groups = append(groups, g)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	var resultErr error
+	for _, objectId := range req.ObjectIds {
+		// every object gets its own context, so its events don't replace events of the view in the response
+		objectCtx := session.NewChildContext(ctx)
+		err = cache.Do(s, objectId, func(sb smartblock.SmartBlock) error {
+			ds, ok := sb.(basic.DetailsSettable)
+			if !ok {
+				return fmt.Errorf("details of %s can't be set", objectId)
+			}
+			details := make([]*model.Detail, 0, len(groups))
+			for _, g := range groups {
+				value, err := kanban.MovedValue(pbtypes.Get(sb.Details(), g.relationKey), g.from, g.to)
+				if err != nil {
+					return err
+				}
+				details = append(details, &model.Detail{Key: g.relationKey, Value: value})
+			}
+			return ds.SetDetails(objectCtx, details, true)
+		})
+		if err != nil {
+			resultErr = errors.Join(resultErr, err)
+			continue
+		}
+		if msgs := objectCtx.GetMessages(); len(msgs) > 0 {
+			s.eventSender.SendToSession(ctx.ID(), &pb.Event{ContextId: objectId, Messages: msgs})
+		}
+	}
+	return resultErr
 }
 
 func (s *Service) DeleteDataviewView(ctx session.Context, req pb.RpcBlockDataviewViewDeleteRequest) error {
@@ -328,7 +400,7 @@ func (s *Service) Export(req pb.RpcBlockExportRequest) (path string, err error)
 }
 
 func (s *Service) SetTextText(ctx session.Context, req pb.RpcBlockTextSetTextRequest) error {
-	return cache.Do(s, req.ContextId, func(b stext.Text) error {
+	return doSynced(s, req.ContextId, []string{req.BlockId}, func(b stext.Text) error {
 		return b.SetText(ctx, req)
 	})
 }
@@ -348,7 +420,7 @@ func (s *Service) SetLatexProcessor(ctx session.Context, req pb.RpcBlockLatexSet
 func (s *Service) SetTextStyle(
 	ctx session.Context, contextId string, style model.BlockContentTextStyle, blockIds ...string,
 ) error {
-	return cache.Do(s, contextId, func(b stext.Text) error {
+	return doSynced(s, contextId, blockIds, func(b stext.Text) error {
 		return b.UpdateTextBlocks(ctx, blockIds, true, func(t text.Block) error {
 			t.SetStyle(style)
 			return nil
@@ -357,7 +429,7 @@ func (s *Service) SetTextStyle(
 }
 
 func (s *Service) SetTextChecked(ctx session.Context, req pb.RpcBlockTextSetCheckedRequest) error {
-	return cache.Do(s, req.ContextId, func(b stext.Text) error {
+	return doSynced(s, req.ContextId, []string{req.BlockId}, func(b stext.Text) error {
 		return b.UpdateTextBlocks(ctx, []string{req.BlockId}, true, func(t text.Block) error {
 			t.SetChecked(req.Checked)
 			return nil
@@ -366,7 +438,7 @@ func (s *Service) SetTextChecked(ctx session.Context, req pb.RpcBlockTextSetChec
 }
 
 func (s *Service) SetTextColor(ctx session.Context, contextId string, color string, blockIds ...string) error {
-	return cache.Do(s, contextId, func(b stext.Text) error {
+	return doSynced(s, contextId, blockIds, func(b stext.Text) error {
 		return b.UpdateTextBlocks(ctx, blockIds, true, func(t text.Block) error {
 			t.SetTextColor(color)
 			return nil
@@ -416,13 +488,13 @@ func (s *Service) ClearTextContent(ctx session.Context, contextId string, blockI
 func (s *Service) SetTextMark(
 	ctx session.Context, contextId string, mark *model.BlockContentTextMark, blockIds ...string,
 ) error {
-	return cache.Do(s, contextId, func(b stext.Text) error {
+	return doSynced(s, contextId, blockIds, func(b stext.Text) error {
 		return b.SetMark(ctx, mark, blockIds...)
 	})
 }
 
 func (s *Service) SetTextIcon(ctx session.Context, contextId, image, emoji string, blockIds ...string) error {
-	return cache.Do(s, contextId, func(b stext.Text) error {
+	return doSynced(s, contextId, blockIds, func(b stext.Text) error {
 		return b.SetIcon(ctx, image, emoji, blockIds...)
 	})
 }
@@ -430,7 +502,7 @@ func (s *Service) SetTextIcon(ctx session.Context, contextId, image, emoji strin
 func (s *Service) SetBackgroundColor(
 	ctx session.Context, contextId string, color string, blockIds ...string,
 ) (err error) {
-	return cache.Do(s, contextId, func(b basic.Updatable) error {
+	return doSynced(s, contextId, blockIds, func(b basic.Updatable) error {
 		return b.Update(ctx, func(b simple.Block) error {
 			b.Model().BackgroundColor = color
 			return nil
@@ -457,7 +529,7 @@ func (s *Service) SetLinkAppearance(ctx session.Context, req pb.RpcBlockLinkList
 func (s *Service) SetAlign(
 	ctx session.Context, contextId string, align model.BlockAlign, blockIds ...string,
 ) (err error) {
-	return cache.DoStateCtx(s, ctx, contextId, func(st *state.State, sb smartblock.SmartBlock) error {
+	return doSyncedStateCtx(s, ctx, contextId, blockIds, func(st *state.State, sb smartblock.SmartBlock) error {
 		return st.SetAlign(align, blockIds...)
 	})
 }
@@ -595,6 +667,11 @@ func (s *Service) Undo(
 		info, err = b.Undo(ctx)
 		return err
 	})
+	if err == nil && info.SyncedObjectId != "" {
+		info, err = s.undoRedoSynced(info, func(h basic.IHistory) (basic.HistoryInfo, error) {
+			return h.Undo(ctx)
+		})
+	}
 	return
 }
 
@@ -605,6 +682,11 @@ func (s *Service) Redo(
 		info, err = b.Redo(ctx)
 		return err
 	})
+	if err == nil && info.SyncedObjectId != "" {
+		info, err = s.undoRedoSynced(info, func(h basic.IHistory) (basic.HistoryInfo, error) {
+			return h.Redo(ctx)
+		})
+	}
 	return
 }
 
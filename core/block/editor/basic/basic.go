@@ -148,7 +148,7 @@ func (bs *basic) CreateBlock(s *state.State, req pb.RpcBlockCreateRequest) (id s
 func (bs *basic) Duplicate(srcState, destState *state.State, targetBlockId string, position model.BlockPosition, blockIds []string) (newIds []string, err error) {
 	blockIds = srcState.SelectRoots(blockIds)
 	for _, id := range blockIds {
-		copyId, e := copyBlocks(srcState, destState, id)
+		copyId, e := CopyBlocks(srcState, destState, id)
 		if e != nil {
 			return nil, e
 		}
@@ -168,7 +168,8 @@ type duplicatable interface {
 	Duplicate(s *state.State) (newId string, visitedIds []string, blocks []simple.Block, err error)
 }
 
-func copyBlocks(srcState, destState *state.State, sourceId string) (id string, err error) {
+// CopyBlocks copies block with all its descendants from srcState to destState, copies get new ids
+func CopyBlocks(srcState, destState *state.State, sourceId string) (id string, err error) {
 	b := srcState.Pick(sourceId)
 	if b == nil {
 		return "", smartblock.ErrSimpleBlockNotFound
@@ -189,7 +190,7 @@ func copyBlocks(srcState, destState *state.State, sourceId string) (id string, e
 	result := simple.New(m)
 	destState.Add(result)
 	for i, childrenId := range result.Model().ChildrenIds {
-		if result.Model().ChildrenIds[i], err = copyBlocks(srcState, destState, childrenId); err != nil {
+		if result.Model().ChildrenIds[i], err = CopyBlocks(srcState, destState, childrenId); err != nil {
 			return
 		}
 	}
@@ -17,6 +17,8 @@ type IHistory interface {
 type HistoryInfo struct {
 	Counters      pb.RpcObjectUndoRedoCounter
 	CarriageState undo.CarriageState
+	// SyncedObjectId is set when the change should be undone or redone in the source object of synced blocks
+	SyncedObjectId string
 }
 
 func NewHistory(sb smartblock.SmartBlock) IHistory {
@@ -33,6 +35,11 @@ func (h *history) Undo(ctx session.Context) (info HistoryInfo, err error) {
 	if err != nil {
 		return
 	}
+	if action.SyncedObjectId != "" {
+		info.Counters.Undo, info.Counters.Redo = h.History().Counters()
+		info.SyncedObjectId = action.SyncedObjectId
+		return
+	}
 
 	for _, b := range action.Add {
 		s.Unlink(b.Model().Id)
@@ -66,6 +73,11 @@ func (h *history) Redo(ctx session.Context) (info HistoryInfo, err error) {
 	if err != nil {
 		return
 	}
+	if action.SyncedObjectId != "" {
+		info.Counters.Undo, info.Counters.Redo = h.History().Counters()
+		info.SyncedObjectId = action.SyncedObjectId
+		return
+	}
 
 	for _, b := range action.Add {
 		s.Set(b.Copy())
@@ -11,6 +11,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
 	"github.com/anyproto/anytype-heart/core/block/editor/state"
 	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/undo"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/util/pbtypes"
 )
@@ -70,6 +71,27 @@ func TestHistory_Undo(t *testing.T) {
 		require.Len(t, sb.Doc.Pick("test").Model().ChildrenIds, 1)
 		assert.True(t, strings.HasPrefix(sb.Doc.Pick("test").Model().ChildrenIds[0], "r-"))
 	})
+	t.Run("change of synced blocks is undone in the source object", func(t *testing.T) {
+		// given
+		sb := smarttest.New("test")
+		sb.AddBlock(simple.New(&model.Block{Id: "test"}))
+		sb.History().Add(undo.Action{SyncedObjectId: "source"})
+		h := NewHistory(sb)
+
+		// when
+		info, err := h.Undo(nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "source", info.SyncedObjectId)
+		assert.Equal(t, int32(0), info.Counters.Undo)
+		assert.Equal(t, int32(1), info.Counters.Redo)
+
+		info, err = h.Redo(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "source", info.SyncedObjectId)
+		assert.Equal(t, int32(1), info.Counters.Undo)
+	})
 }
 
 func TestHistory_Redo(t *testing.T) {
@@ -0,0 +1,64 @@
+package editor
+
+import (
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/template"
+	"github.com/anyproto/anytype-heart/core/block/restriction"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// collectionAccessHook copies access settings of the collection to its members, so locking or hiding
+// the collection covers objects in it. When an object is in several collections, the latest change wins
+func (p *Page) collectionAccessHook(info smartblock.ApplyInfo) error {
+	if p.Space().MyPermissions() != model.ParticipantPermissions_Owner {
+		return nil
+	}
+	var (
+		accessChanged bool
+		added         []string
+	)
+	for _, ch := range info.Changes {
+		switch {
+		case isAccessKey(ch.GetDetailsSet().GetKey()), isAccessKey(ch.GetDetailsUnset().GetKey()):
+			accessChanged = true
+		case ch.GetStoreSliceUpdate().GetKey() == template.CollectionStoreKey:
+			added = append(added, ch.GetStoreSliceUpdate().GetAdd().GetIds()...)
+		}
+	}
+	access := restriction.ObjectAccessFromDetails(info.State.Details())
+	members := added
+	if accessChanged {
+		members = info.State.GetStoreSlice(template.CollectionStoreKey)
+	} else if access == (restriction.ObjectAccess{}) {
+		return nil
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	// locks of members must not be taken under the lock of the collection
+	go p.setMembersAccess(members, access)
+	return nil
+}
+
+func (p *Page) setMembersAccess(ids []string, access restriction.ObjectAccess) {
+	for _, id := range ids {
+		err := p.Space().Do(id, func(sb smartblock.SmartBlock) error {
+			if restriction.ObjectAccessFromDetails(sb.Details()) == access {
+				return nil
+			}
+			st := sb.NewState()
+			st.SetDetailAndBundledRelation(bundle.RelationKeyIsLockedForWriters, pbtypes.Bool(access.LockedForWriters))
+			st.SetDetailAndBundledRelation(bundle.RelationKeyIsHiddenFromReaders, pbtypes.Bool(access.HiddenFromReaders))
+			return sb.Apply(st)
+		})
+		if err != nil {
+			log.With("objectId", id, "err", err).Warn("failed to set access of collection member")
+		}
+	}
+}
+
+func isAccessKey(key string) bool {
+	return key == bundle.RelationKeyIsLockedForWriters.String() || key == bundle.RelationKeyIsHiddenFromReaders.String()
+}
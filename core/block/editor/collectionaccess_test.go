@@ -0,0 +1,128 @@
+package editor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/block/editor/template"
+	"github.com/anyproto/anytype-heart/core/block/restriction"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type membersSpace struct {
+	smartblock.Space
+	permissions model.ParticipantPermissions
+	members     map[string]*smarttest.SmartTest
+	done        chan string
+}
+
+func (s *membersSpace) MyPermissions() model.ParticipantPermissions {
+	return s.permissions
+}
+
+func (s *membersSpace) Do(objectId string, apply func(sb smartblock.SmartBlock) error) error {
+	defer func() {
+		s.done <- objectId
+	}()
+	return apply(s.members[objectId])
+}
+
+func newCollectionAccessFixture(permissions model.ParticipantPermissions) (*Page, *membersSpace) {
+	spc := &membersSpace{
+		permissions: permissions,
+		members: map[string]*smarttest.SmartTest{
+			"obj1": smarttest.New("obj1"),
+			"obj2": smarttest.New("obj2"),
+		},
+		done: make(chan string, 2),
+	}
+	sb := smarttest.New("collection")
+	sb.SetSpace(spc)
+	return &Page{SmartBlock: sb}, spc
+}
+
+func (s *membersSpace) waitMembers(t *testing.T, count int) {
+	for i := 0; i < count; i++ {
+		select {
+		case <-s.done:
+		case <-time.After(time.Second):
+			t.Fatal("members are not updated")
+		}
+	}
+}
+
+func TestPage_CollectionAccessHook(t *testing.T) {
+	t.Run("access of the collection is copied to members", func(t *testing.T) {
+		// given
+		p, spc := newCollectionAccessFixture(model.ParticipantPermissions_Owner)
+		st := p.NewState()
+		st.SetDetailAndBundledRelation(bundle.RelationKeyIsHiddenFromReaders, pbtypes.Bool(true))
+		st.UpdateStoreSlice(template.CollectionStoreKey, []string{"obj1", "obj2"})
+
+		// when
+		err := p.collectionAccessHook(smartblock.ApplyInfo{State: st, Changes: []*pb.ChangeContent{{
+			Value: &pb.ChangeContentValueOfDetailsSet{DetailsSet: &pb.ChangeDetailsSet{
+				Key:   bundle.RelationKeyIsHiddenFromReaders.String(),
+				Value: pbtypes.Bool(true),
+			}},
+		}}})
+
+		// then
+		require.NoError(t, err)
+		spc.waitMembers(t, 2)
+		for _, member := range spc.members {
+			assert.Equal(t, restriction.ObjectAccess{HiddenFromReaders: true}, restriction.ObjectAccessFromDetails(member.Details()))
+		}
+	})
+
+	t.Run("added objects get access of the collection", func(t *testing.T) {
+		// given
+		p, spc := newCollectionAccessFixture(model.ParticipantPermissions_Owner)
+		st := p.NewState()
+		st.SetDetailAndBundledRelation(bundle.RelationKeyIsLockedForWriters, pbtypes.Bool(true))
+		st.UpdateStoreSlice(template.CollectionStoreKey, []string{"obj1", "obj2"})
+
+		// when
+		err := p.collectionAccessHook(smartblock.ApplyInfo{State: st, Changes: []*pb.ChangeContent{{
+			Value: &pb.ChangeContentValueOfStoreSliceUpdate{StoreSliceUpdate: &pb.ChangeStoreSliceUpdate{
+				Key: template.CollectionStoreKey,
+				Operation: &pb.ChangeStoreSliceUpdateOperationOfAdd{Add: &pb.ChangeStoreSliceUpdateAdd{
+					Ids: []string{"obj2"},
+				}},
+			}},
+		}}})
+
+		// then
+		require.NoError(t, err)
+		spc.waitMembers(t, 1)
+		assert.Equal(t, restriction.ObjectAccess{}, restriction.ObjectAccessFromDetails(spc.members["obj1"].Details()))
+		assert.Equal(t, restriction.ObjectAccess{LockedForWriters: true}, restriction.ObjectAccessFromDetails(spc.members["obj2"].Details()))
+	})
+
+	t.Run("only owners change access of members", func(t *testing.T) {
+		// given
+		p, spc := newCollectionAccessFixture(model.ParticipantPermissions_Writer)
+		st := p.NewState()
+		st.UpdateStoreSlice(template.CollectionStoreKey, []string{"obj1", "obj2"})
+
+		// when
+		err := p.collectionAccessHook(smartblock.ApplyInfo{State: st, Changes: []*pb.ChangeContent{{
+			Value: &pb.ChangeContentValueOfDetailsSet{DetailsSet: &pb.ChangeDetailsSet{
+				Key:   bundle.RelationKeyIsLockedForWriters.String(),
+				Value: pbtypes.Bool(true),
+			}},
+		}}})
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, spc.done)
+	})
+}
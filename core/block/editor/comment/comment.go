@@ -0,0 +1,268 @@
+package comment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple/text"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	textutil "github.com/anyproto/anytype-heart/util/text"
+)
+
+// StoreKey is the key of the object store the comments are kept under
+const StoreKey = state.CommentsStoreKey
+
+var (
+	ErrNotFound      = errors.New("comment not found")
+	ErrBlockNotFound = errors.New("commented block not found")
+	ErrNotAuthor     = errors.New("comment could be changed only by its author")
+	ErrEmptyText     = errors.New("comment text is empty")
+	ErrInvalidRange  = errors.New("invalid range of the commented text")
+)
+
+type AddRequest struct {
+	// ThreadId is the id of the thread to reply to, a new thread is started when empty
+	ThreadId string
+	BlockId  string
+	Range    *model.Range
+	Text     string
+}
+
+type Comments interface {
+	AddComment(ctx session.Context, req AddRequest) (commentId string, err error)
+	EditComment(ctx session.Context, commentId string, text string) error
+	// DeleteComment deletes the comment, deleting the first comment of the thread deletes the whole thread
+	DeleteComment(ctx session.Context, commentId string) error
+	SetThreadResolved(ctx session.Context, threadId string, resolved bool) error
+	ListComments(includeResolved bool) []*model.Comment
+}
+
+func NewComments(sb smartblock.SmartBlock, participantId string) Comments {
+	c := &comments{
+		SmartBlock:    sb,
+		participantId: participantId,
+		now:           time.Now,
+	}
+	sb.AddHook(c.onApply, smartblock.HookAfterApply)
+	return c
+}
+
+type comments struct {
+	smartblock.SmartBlock
+	participantId string
+	now           func() time.Time
+}
+
+func (c *comments) AddComment(ctx session.Context, req AddRequest) (commentId string, err error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return "", ErrEmptyText
+	}
+	s := c.NewStateCtx(ctx)
+	comment := &model.Comment{
+		Id:          bson.NewObjectId().Hex(),
+		Text:        req.Text,
+		AuthorId:    c.participantId,
+		CreatedDate: c.now().Unix(),
+	}
+	if req.ThreadId != "" {
+		thread := s.GetComment(req.ThreadId)
+		if thread != nil && thread.ThreadId != "" {
+			// reply to a reply goes to the same thread
+			thread = s.GetComment(thread.ThreadId)
+		}
+		if thread == nil {
+			return "", ErrNotFound
+		}
+		comment.ThreadId = thread.Id
+		comment.BlockId = thread.BlockId
+	} else {
+		b := s.Pick(req.BlockId)
+		if b == nil {
+			return "", ErrBlockNotFound
+		}
+		comment.BlockId = req.BlockId
+		if req.Range != nil && (req.Range.From != 0 || req.Range.To != 0) {
+			tb, ok := b.(text.Block)
+			if !ok {
+				return "", fmt.Errorf("%w: block is not a text", ErrInvalidRange)
+			}
+			runes := textutil.StrToUTF16(tb.GetText())
+			if req.Range.From < 0 || req.Range.From >= req.Range.To || int(req.Range.To) > len(runes) {
+				return "", ErrInvalidRange
+			}
+			comment.Range = &model.Range{From: req.Range.From, To: req.Range.To}
+			comment.Quote = textutil.UTF16ToStr(runes[req.Range.From:req.Range.To])
+		}
+	}
+	s.SetComment(comment)
+	if err = c.Apply(s); err != nil {
+		return "", err
+	}
+	return comment.Id, nil
+}
+
+func (c *comments) EditComment(ctx session.Context, commentId string, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return ErrEmptyText
+	}
+	s := c.NewStateCtx(ctx)
+	comment := s.GetComment(commentId)
+	if comment == nil {
+		return ErrNotFound
+	}
+	if comment.AuthorId != c.participantId {
+		return ErrNotAuthor
+	}
+	comment.Text = text
+	comment.ModifiedDate = c.now().Unix()
+	s.SetComment(comment)
+	return c.Apply(s)
+}
+
+func (c *comments) DeleteComment(ctx session.Context, commentId string) error {
+	s := c.NewStateCtx(ctx)
+	comment := s.GetComment(commentId)
+	if comment == nil {
+		return ErrNotFound
+	}
+	if comment.AuthorId != c.participantId && c.Space().MyPermissions() != model.ParticipantPermissions_Owner {
+		return ErrNotAuthor
+	}
+	s.RemoveComment(commentId)
+	if comment.ThreadId == "" {
+		for _, reply := range s.ListComments() {
+			if reply.ThreadId == commentId {
+				s.RemoveComment(reply.Id)
+			}
+		}
+	}
+	return c.Apply(s)
+}
+
+func (c *comments) SetThreadResolved(ctx session.Context, threadId string, resolved bool) error {
+	s := c.NewStateCtx(ctx)
+	thread := s.GetComment(threadId)
+	if thread == nil || thread.ThreadId != "" {
+		return ErrNotFound
+	}
+	if thread.Resolved == resolved {
+		return nil
+	}
+	thread.Resolved = resolved
+	if resolved {
+		thread.ResolvedBy = c.participantId
+		thread.ResolvedDate = c.now().Unix()
+	} else {
+		thread.ResolvedBy = ""
+		thread.ResolvedDate = 0
+	}
+	s.SetComment(thread)
+	return c.Apply(s)
+}
+
+func (c *comments) ListComments(includeResolved bool) []*model.Comment {
+	all := c.NewState().ListComments()
+	if includeResolved {
+		return all
+	}
+	resolved := map[string]struct{}{}
+	for _, comment := range all {
+		if comment.ThreadId == "" && comment.Resolved {
+			resolved[comment.Id] = struct{}{}
+		}
+	}
+	res := make([]*model.Comment, 0, len(all))
+	for _, comment := range all {
+		if _, ok := resolved[threadIdOf(comment)]; !ok {
+			res = append(res, comment)
+		}
+	}
+	return res
+}
+
+// onApply sends the updated list of comments to clients.
+// Reply notifications are sent by the indexer, so replies received while the object is not opened are notified too
+func (c *comments) onApply(info smartblock.ApplyInfo) error {
+	var changed bool
+	for _, ch := range info.Changes {
+		if set := ch.GetStoreKeySet(); set != nil && isCommentPath(set.Path) {
+			changed = true
+		}
+		if unset := ch.GetStoreKeyUnset(); unset != nil && isCommentPath(unset.Path) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	c.SendEvent([]*pb.EventMessage{{
+		Value: &pb.EventMessageValueOfObjectCommentsUpdate{
+			ObjectCommentsUpdate: &pb.EventObjectCommentsUpdate{
+				Id:       c.Id(),
+				Comments: info.State.ListComments(),
+			},
+		},
+	}})
+	return nil
+}
+
+// ReplyNotifications returns notifications about replies of others created since the given date
+// in the threads the participant took part in. Notification ids are derived from the comments,
+// so the same reply is not notified twice
+func ReplyNotifications(spaceId, objectId, objectName, participantId string, all []*model.Comment, createdSince int64) []*model.Notification {
+	var res []*model.Notification
+	for _, comment := range all {
+		// edited comments have the modified date, only new replies are notified
+		if comment.ThreadId == "" || comment.AuthorId == participantId || comment.ModifiedDate != 0 || comment.CreatedDate < createdSince {
+			continue
+		}
+		if !tookPartInThread(comment.ThreadId, participantId, all) {
+			continue
+		}
+		res = append(res, &model.Notification{
+			Id:      comment.Id,
+			IsLocal: false,
+			Space:   spaceId,
+			Payload: &model.NotificationPayloadOfCommentReply{
+				CommentReply: &model.NotificationCommentReply{
+					SpaceId:    spaceId,
+					ObjectId:   objectId,
+					ObjectName: objectName,
+					ThreadId:   comment.ThreadId,
+					CommentId:  comment.Id,
+					AuthorId:   comment.AuthorId,
+					Text:       comment.Text,
+				},
+			},
+		})
+	}
+	return res
+}
+
+func tookPartInThread(threadId, participantId string, all []*model.Comment) bool {
+	for _, other := range all {
+		if threadIdOf(other) == threadId && other.AuthorId == participantId {
+			return true
+		}
+	}
+	return false
+}
+
+func isCommentPath(path []string) bool {
+	return len(path) > 0 && path[0] == StoreKey
+}
+
+func threadIdOf(comment *model.Comment) string {
+	if comment.ThreadId != "" {
+		return comment.ThreadId
+	}
+	return comment.Id
+}
@@ -0,0 +1,236 @@
+package comment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/text"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func newFixture(t *testing.T) *smarttest.SmartTest {
+	sb := smarttest.New("object")
+	sb.AddBlock(simple.New(&model.Block{Id: "object", ChildrenIds: []string{"text", "div"}})).
+		AddBlock(text.NewText(&model.Block{
+			Id:      "text",
+			Content: &model.BlockContentOfText{Text: &model.BlockContentText{Text: "hello world"}},
+		})).
+		AddBlock(simple.New(&model.Block{Id: "div", Content: &model.BlockContentOfDiv{Div: &model.BlockContentDiv{}}}))
+	return sb
+}
+
+func newComments(sb *smarttest.SmartTest, participantId string) *comments {
+	c := NewComments(sb, participantId).(*comments)
+	c.now = func() time.Time {
+		return time.Unix(100, 0)
+	}
+	return c
+}
+
+func TestComments_AddComment(t *testing.T) {
+	t.Run("comment to text range", func(t *testing.T) {
+		// given
+		c := newComments(newFixture(t), "p1")
+
+		// when
+		id, err := c.AddComment(nil, AddRequest{BlockId: "text", Range: &model.Range{From: 6, To: 11}, Text: "which one?"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Comment{{
+			Id:          id,
+			BlockId:     "text",
+			Range:       &model.Range{From: 6, To: 11},
+			Quote:       "world",
+			Text:        "which one?",
+			AuthorId:    "p1",
+			CreatedDate: 100,
+		}}, c.ListComments(true))
+	})
+
+	t.Run("reply goes to the thread of the first comment", func(t *testing.T) {
+		// given
+		c := newComments(newFixture(t), "p1")
+		threadId, err := c.AddComment(nil, AddRequest{BlockId: "div", Text: "remove it"})
+		require.NoError(t, err)
+		replyId, err := c.AddComment(nil, AddRequest{ThreadId: threadId, Text: "why?"})
+		require.NoError(t, err)
+
+		// when
+		_, err = c.AddComment(nil, AddRequest{ThreadId: replyId, Text: "it's unused"})
+
+		// then
+		require.NoError(t, err)
+		comments := c.ListComments(true)
+		require.Len(t, comments, 3)
+		for _, reply := range comments[1:] {
+			assert.Equal(t, threadId, reply.ThreadId)
+			assert.Equal(t, "div", reply.BlockId)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		// given
+		c := newComments(newFixture(t), "p1")
+
+		// when
+		_, emptyErr := c.AddComment(nil, AddRequest{BlockId: "text", Text: " "})
+		_, blockErr := c.AddComment(nil, AddRequest{BlockId: "unknown", Text: "text"})
+		_, threadErr := c.AddComment(nil, AddRequest{ThreadId: "unknown", Text: "text"})
+		_, rangeErr := c.AddComment(nil, AddRequest{BlockId: "text", Range: &model.Range{From: 6, To: 20}, Text: "text"})
+		_, divRangeErr := c.AddComment(nil, AddRequest{BlockId: "div", Range: &model.Range{From: 0, To: 1}, Text: "text"})
+
+		// then
+		assert.ErrorIs(t, emptyErr, ErrEmptyText)
+		assert.ErrorIs(t, blockErr, ErrBlockNotFound)
+		assert.ErrorIs(t, threadErr, ErrNotFound)
+		assert.ErrorIs(t, rangeErr, ErrInvalidRange)
+		assert.ErrorIs(t, divRangeErr, ErrInvalidRange)
+		assert.Empty(t, c.ListComments(true))
+	})
+}
+
+func TestComments_EditAndDelete(t *testing.T) {
+	t.Run("only author edits comment", func(t *testing.T) {
+		// given
+		sb := newFixture(t)
+		author := newComments(sb, "p1")
+		other := newComments(sb, "p2")
+		id, err := author.AddComment(nil, AddRequest{BlockId: "text", Text: "first"})
+		require.NoError(t, err)
+
+		// when
+		otherErr := other.EditComment(nil, id, "changed")
+		err = author.EditComment(nil, id, "second")
+
+		// then
+		assert.ErrorIs(t, otherErr, ErrNotAuthor)
+		require.NoError(t, err)
+		comments := author.ListComments(true)
+		require.Len(t, comments, 1)
+		assert.Equal(t, "second", comments[0].Text)
+		assert.Equal(t, int64(100), comments[0].ModifiedDate)
+	})
+
+	t.Run("deleting first comment deletes thread", func(t *testing.T) {
+		// given
+		c := newComments(newFixture(t), "p1")
+		threadId, err := c.AddComment(nil, AddRequest{BlockId: "text", Text: "first"})
+		require.NoError(t, err)
+		_, err = c.AddComment(nil, AddRequest{ThreadId: threadId, Text: "reply"})
+		require.NoError(t, err)
+		otherThreadId, err := c.AddComment(nil, AddRequest{BlockId: "div", Text: "other"})
+		require.NoError(t, err)
+
+		// when
+		err = c.DeleteComment(nil, threadId)
+
+		// then
+		require.NoError(t, err)
+		comments := c.ListComments(true)
+		require.Len(t, comments, 1)
+		assert.Equal(t, otherThreadId, comments[0].Id)
+	})
+}
+
+func TestComments_SetThreadResolved(t *testing.T) {
+	// given
+	c := newComments(newFixture(t), "p1")
+	threadId, err := c.AddComment(nil, AddRequest{BlockId: "text", Text: "first"})
+	require.NoError(t, err)
+	replyId, err := c.AddComment(nil, AddRequest{ThreadId: threadId, Text: "reply"})
+	require.NoError(t, err)
+	_, err = c.AddComment(nil, AddRequest{BlockId: "div", Text: "other"})
+	require.NoError(t, err)
+
+	// when
+	replyErr := c.SetThreadResolved(nil, replyId, true)
+	err = c.SetThreadResolved(nil, threadId, true)
+
+	// then
+	assert.ErrorIs(t, replyErr, ErrNotFound)
+	require.NoError(t, err)
+	assert.Len(t, c.ListComments(true), 3)
+	unresolved := c.ListComments(false)
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "other", unresolved[0].Text)
+
+	// when
+	err = c.SetThreadResolved(nil, threadId, false)
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, c.ListComments(false), 3)
+}
+
+func TestReplyNotifications(t *testing.T) {
+	t.Run("participant of thread is notified about reply", func(t *testing.T) {
+		// given
+		sb := newFixture(t)
+		me := newComments(sb, "p1")
+		other := newComments(sb, "p2")
+		threadId, err := me.AddComment(nil, AddRequest{BlockId: "text", Text: "first"})
+		require.NoError(t, err)
+		replyId, err := other.AddComment(nil, AddRequest{ThreadId: threadId, Text: "reply"})
+		require.NoError(t, err)
+
+		// when
+		notifications := ReplyNotifications("space1", "object", "name", "p1", me.ListComments(true), 0)
+
+		// then
+		require.Len(t, notifications, 1)
+		assert.Equal(t, replyId, notifications[0].Id)
+		assert.Equal(t, &model.NotificationCommentReply{
+			SpaceId:    "space1",
+			ObjectId:   "object",
+			ObjectName: "name",
+			ThreadId:   threadId,
+			CommentId:  replyId,
+			AuthorId:   "p2",
+			Text:       "reply",
+		}, notifications[0].GetCommentReply())
+	})
+
+	t.Run("own comments and other threads are not notified", func(t *testing.T) {
+		// given
+		sb := newFixture(t)
+		me := newComments(sb, "p1")
+		other := newComments(sb, "p2")
+		myThreadId, err := me.AddComment(nil, AddRequest{BlockId: "text", Text: "mine"})
+		require.NoError(t, err)
+		otherThreadId, err := other.AddComment(nil, AddRequest{BlockId: "div", Text: "not mine"})
+		require.NoError(t, err)
+		_, err = me.AddComment(nil, AddRequest{ThreadId: myThreadId, Text: "my reply"})
+		require.NoError(t, err)
+		_, err = other.AddComment(nil, AddRequest{ThreadId: otherThreadId, Text: "other reply"})
+		require.NoError(t, err)
+
+		// when
+		notifications := ReplyNotifications("space1", "object", "name", "p1", me.ListComments(true), 0)
+
+		// then
+		assert.Empty(t, notifications)
+	})
+
+	t.Run("old replies are not notified", func(t *testing.T) {
+		// given
+		sb := newFixture(t)
+		me := newComments(sb, "p1")
+		other := newComments(sb, "p2")
+		threadId, err := me.AddComment(nil, AddRequest{BlockId: "text", Text: "first"})
+		require.NoError(t, err)
+		_, err = other.AddComment(nil, AddRequest{ThreadId: threadId, Text: "reply"})
+		require.NoError(t, err)
+
+		// when
+		notifications := ReplyNotifications("space1", "object", "name", "p1", me.ListComments(true), 101)
+
+		// then
+		assert.Empty(t, notifications)
+	})
+}
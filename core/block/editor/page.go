@@ -4,6 +4,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/editor/basic"
 	"github.com/anyproto/anytype-heart/core/block/editor/bookmark"
 	"github.com/anyproto/anytype-heart/core/block/editor/clipboard"
+	"github.com/anyproto/anytype-heart/core/block/editor/comment"
 	"github.com/anyproto/anytype-heart/core/block/editor/dataview"
 	"github.com/anyproto/anytype-heart/core/block/editor/file"
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
@@ -32,6 +33,7 @@ type Page struct {
 	clipboard.Clipboard
 	bookmark.Bookmark
 	source.ChangeReceiver
+	comment.Comments
 
 	dataview.Dataview
 	table.TableEditor
@@ -65,6 +67,7 @@ func (f *ObjectFactory) newPage(sb smartblock.SmartBlock) *Page {
 		Bookmark:          bookmark.NewBookmark(sb, f.bookmarkService),
 		Dataview:          dataview.NewDataview(sb, f.objectStore),
 		TableEditor:       table.NewEditor(sb),
+		Comments:          comment.NewComments(sb, f.accountService.MyParticipantId(sb.SpaceID())),
 		objectStore:       f.objectStore,
 		fileObjectService: f.fileObjectService,
 		objectDeleter:     f.objectDeleter,
@@ -79,6 +82,7 @@ func (p *Page) Init(ctx *smartblock.InitContext) (err error) {
 	if err = p.SmartBlock.Init(ctx); err != nil {
 		return
 	}
+	p.AddHook(p.collectionAccessHook, smartblock.HookAfterApply)
 
 	if !ctx.IsNewObject {
 		migrateFilesToObjects(p, p.fileObjectService)(ctx.State)
@@ -0,0 +1,34 @@
+package smartblock
+
+import (
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/restriction"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+// ObjectAccess returns per-object access settings, that owners of shared spaces set on top of space-wide permissions
+func (sb *smartBlock) ObjectAccess() restriction.ObjectAccess {
+	return restriction.ObjectAccessFromDetails(sb.Doc.Details())
+}
+
+// ParticipantPermissions returns space-wide permissions of the current participant
+func (sb *smartBlock) ParticipantPermissions() model.ParticipantPermissions {
+	if sb.space == nil {
+		return model.ParticipantPermissions_Owner
+	}
+	return sb.space.MyPermissions()
+}
+
+func (sb *smartBlock) checkAccessChange(s *state.State) error {
+	if sb.ParticipantPermissions() == model.ParticipantPermissions_Owner {
+		return nil
+	}
+	return sb.restrictionService.CheckAccessChange(sb, restriction.ObjectAccessFromDetails(s.Details()), s.IsModified())
+}
+
+func (sb *smartBlock) checkAccessOpen() error {
+	if sb.ParticipantPermissions() == model.ParticipantPermissions_Owner {
+		return nil
+	}
+	return sb.restrictionService.CheckAccessOpen(sb)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -23,6 +24,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/object/objectlink"
 	"github.com/anyproto/anytype-heart/core/block/restriction"
 	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/synced"
 	"github.com/anyproto/anytype-heart/core/block/source"
 	"github.com/anyproto/anytype-heart/core/block/undo"
 	"github.com/anyproto/anytype-heart/core/domain"
@@ -31,6 +33,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/session"
 	"github.com/anyproto/anytype-heart/core/syncstatus/filesyncstatus"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
@@ -124,6 +127,7 @@ type Space interface {
 	DeriveObjectID(ctx context.Context, uniqueKey domain.UniqueKey) (id string, err error)
 
 	IsPersonal() bool
+	MyPermissions() model.ParticipantPermissions
 
 	Do(objectId string, apply func(sb SmartBlock) error) error
 	DoLockedIfNotExists(objectID string, proc func() error) error // TODO Temporarily before rewriting favorites/archive mechanism
@@ -138,6 +142,8 @@ type SmartBlock interface {
 	UniqueKey() domain.UniqueKey
 	Show() (obj *model.ObjectView, err error)
 	RegisterSession(session.Context)
+	// UnregisterSession stops sending events of the object to the session, unlike ObjectClose close hooks are not called
+	UnregisterSession(sessionId string)
 	Apply(s *state.State, flags ...ApplyFlag) error
 	History() undo.History
 	Relations(s *state.State) relationutils.Relations
@@ -180,7 +186,10 @@ type DocInfo struct {
 	Type    domain.TypeKey
 	Details *types.Struct
 
-	SmartblockType smartblock.SmartBlockType
+	// SyncedBlockTargets are ids of objects whose blocks are shown by synced blocks of the object
+	SyncedBlockTargets []string
+	Comments           []*model.Comment
+	SmartblockType     smartblock.SmartBlockType
 }
 
 // TODO Maybe create constructor? Don't want to forget required fields
@@ -413,6 +422,9 @@ func (sb *smartBlock) Restrictions() restriction.Restrictions {
 }
 
 func (sb *smartBlock) Show() (*model.ObjectView, error) {
+	if err := sb.checkAccessOpen(); err != nil {
+		return nil, err
+	}
 	sb.updateRestrictions()
 
 	details, err := sb.fetchMeta()
@@ -544,6 +556,10 @@ func (sb *smartBlock) RegisterSession(ctx session.Context) {
 	sb.sessions[ctx.ID()] = ctx
 }
 
+func (sb *smartBlock) UnregisterSession(sessionId string) {
+	delete(sb.sessions, sessionId)
+}
+
 func (sb *smartBlock) IsLocked() bool {
 	var activeCount int
 	for _, s := range sb.sessions {
@@ -564,6 +580,10 @@ func (sb *smartBlock) EnabledRelationAsDependentObjects() {
 
 func (sb *smartBlock) Apply(s *state.State, flags ...ApplyFlag) (err error) {
 	startTime := time.Now()
+	ctx, span := tracing.Start(session.ParentContext(s.Context()), "smartblock.Apply", tracing.ObjectId(sb.Id()), tracing.SpaceId(sb.SpaceID()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	if sb.IsDeleted() {
 		return domain.ErrObjectIsDeleted
 	}
@@ -611,6 +631,9 @@ func (sb *smartBlock) Apply(s *state.State, flags ...ApplyFlag) (err error) {
 		if err = s.ParentState().CheckRestrictions(); err != nil {
 			return
 		}
+		if err = sb.checkAccessChange(s); err != nil {
+			return
+		}
 	}
 
 	var lastModified = time.Now()
@@ -658,10 +681,10 @@ func (sb *smartBlock) Apply(s *state.State, flags ...ApplyFlag) (err error) {
 	if skipIfNoChanges && len(changes) == 0 && !migrationVersionUpdated {
 		if hasDetailsMsgs(msgs) {
 			// means we have only local details changed, so lets index but skip full text
-			sb.runIndexer(st, SkipFullTextIfHeadsNotChanged)
+			sb.runIndexer(ctx, st, SkipFullTextIfHeadsNotChanged)
 		} else {
 			// we may skip indexing in case we are sure that we have previously indexed the same version of object
-			sb.runIndexer(st, SkipIfHeadsNotChanged)
+			sb.runIndexer(ctx, st, SkipIfHeadsNotChanged)
 		}
 		return nil
 	}
@@ -727,9 +750,9 @@ func (sb *smartBlock) Apply(s *state.State, flags ...ApplyFlag) (err error) {
 	if changeId == "" && len(msgs) == 0 {
 		// means we probably don't have any actual change being made
 		// in case the heads are not changed, we may skip indexing
-		sb.runIndexer(st, SkipIfHeadsNotChanged)
+		sb.runIndexer(ctx, st, SkipIfHeadsNotChanged)
 	} else {
-		sb.runIndexer(st)
+		sb.runIndexer(ctx, st)
 	}
 
 	afterPushChangeTime := time.Now()
@@ -981,7 +1004,12 @@ func (sb *smartBlock) RemoveExtraRelations(ctx session.Context, relationIds []st
 	return sb.Apply(st)
 }
 
-func (sb *smartBlock) StateAppend(f func(d state.Doc) (s *state.State, changes []*pb.ChangeContent, err error)) error {
+func (sb *smartBlock) StateAppend(f func(d state.Doc) (s *state.State, changes []*pb.ChangeContent, err error)) (err error) {
+	// changes of other devices are appended on sync
+	ctx, span := tracing.Start(context.Background(), "smartblock.StateAppend", tracing.ObjectId(sb.Id()), tracing.SpaceId(sb.SpaceID()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	if sb.IsDeleted() {
 		return domain.ErrObjectIsDeleted
 	}
@@ -1007,7 +1035,7 @@ func (sb *smartBlock) StateAppend(f func(d state.Doc) (s *state.State, changes [
 	if hasDepIds(sb.GetRelationLinks(), &act) || isBacklinksChanged(msgs) {
 		sb.CheckSubscriptions()
 	}
-	sb.runIndexer(s)
+	sb.runIndexer(ctx, s)
 	sb.execHooks(HookAfterApply, ApplyInfo{State: s, Events: msgs, Changes: changes})
 
 	return nil
@@ -1015,6 +1043,10 @@ func (sb *smartBlock) StateAppend(f func(d state.Doc) (s *state.State, changes [
 
 // TODO: need to test StateRebuild
 func (sb *smartBlock) StateRebuild(d state.Doc) (err error) {
+	ctx, span := tracing.Start(context.Background(), "smartblock.StateRebuild", tracing.ObjectId(sb.Id()), tracing.SpaceId(sb.SpaceID()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	if sb.IsDeleted() {
 		return domain.ErrObjectIsDeleted
 	}
@@ -1042,7 +1074,7 @@ func (sb *smartBlock) StateRebuild(d state.Doc) (err error) {
 	}
 	sb.storeFileKeys(d)
 	sb.CheckSubscriptions()
-	sb.runIndexer(sb.Doc.(*state.State))
+	sb.runIndexer(ctx, sb.Doc.(*state.State))
 	applyInfo := ApplyInfo{State: sb.Doc.(*state.State), Events: msgs, Changes: d.(*state.State).GetChanges()}
 	sb.execHooks(HookAfterApply, applyInfo)
 	err = sb.execHooks(HookOnStateRebuild, applyInfo)
@@ -1264,20 +1296,34 @@ func (sb *smartBlock) getDocInfo(st *state.State) DocInfo {
 		}
 	}
 	return DocInfo{
-		Id:             sb.Id(),
-		Space:          sb.Space(),
-		Links:          links,
-		Heads:          heads,
-		Creator:        creator,
-		Details:        sb.CombinedDetails(),
-		Type:           sb.ObjectTypeKey(),
-		SmartblockType: sb.Type(),
-	}
+		Id:                 sb.Id(),
+		Space:              sb.Space(),
+		Links:              links,
+		SyncedBlockTargets: syncedBlockTargets(st),
+		Comments:           st.ListComments(),
+		Heads:              heads,
+		Creator:            creator,
+		Details:            sb.CombinedDetails(),
+		Type:               sb.ObjectTypeKey(),
+		SmartblockType:     sb.Type(),
+	}
+}
+
+func syncedBlockTargets(st *state.State) (targets []string) {
+	_ = st.Iterate(func(b simple.Block) (isContinue bool) {
+		if sync, ok := b.(synced.Block); ok {
+			if targetObjectId, _ := sync.Target(); targetObjectId != "" && !slices.Contains(targets, targetObjectId) {
+				targets = append(targets, targetObjectId)
+			}
+		}
+		return true
+	})
+	return targets
 }
 
-func (sb *smartBlock) runIndexer(s *state.State, opts ...IndexOption) {
+func (sb *smartBlock) runIndexer(ctx context.Context, s *state.State, opts ...IndexOption) {
 	docInfo := sb.getDocInfo(s)
-	if err := sb.indexer.Index(context.Background(), docInfo, opts...); err != nil {
+	if err := sb.indexer.Index(ctx, docInfo, opts...); err != nil {
 		log.Errorf("index object %s error: %s", sb.Id(), err)
 	}
 }
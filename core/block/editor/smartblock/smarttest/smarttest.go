@@ -69,6 +69,7 @@ type SmartTest struct {
 	hooksOnce map[string]struct{}
 	sbType    coresb.SmartBlockType
 	spaceId   string
+	sessions  map[string]struct{}
 }
 
 func (st *SmartTest) SpaceID() string { return st.spaceId }
@@ -118,6 +119,10 @@ func (s *stubSpace) IsPersonal() bool {
 	return false
 }
 
+func (s *stubSpace) MyPermissions() model.ParticipantPermissions {
+	return model.ParticipantPermissions_Owner
+}
+
 func (st *SmartTest) Space() smartblock.Space {
 	if st.space != nil {
 		return st.space
@@ -364,6 +369,7 @@ func (st *SmartTest) FileRelationKeys(s *state.State) []string {
 
 func (st *SmartTest) ObjectClose(ctx session.Context) {
 	st.SetEventFunc(nil)
+	delete(st.sessions, ctx.ID())
 }
 
 func (st *SmartTest) Close() (err error) {
@@ -385,8 +391,21 @@ func (st *SmartTest) Inner() smartblock.SmartBlock {
 func (st *SmartTest) ObjectCloseAllSessions() {
 }
 
-func (st *SmartTest) RegisterSession(session.Context) {
+func (st *SmartTest) RegisterSession(ctx session.Context) {
+	if st.sessions == nil {
+		st.sessions = map[string]struct{}{}
+	}
+	st.sessions[ctx.ID()] = struct{}{}
+}
+
+func (st *SmartTest) UnregisterSession(sessionId string) {
+	delete(st.sessions, sessionId)
+}
 
+// HasSession returns true if the session is registered in the object
+func (st *SmartTest) HasSession(sessionId string) bool {
+	_, ok := st.sessions[sessionId]
+	return ok
 }
 
 func (st *SmartTest) UniqueKey() domain.UniqueKey {
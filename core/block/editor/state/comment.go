@@ -0,0 +1,100 @@
+package state
+
+import (
+	"sort"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// CommentsStoreKey is the key of the object store, comments are kept under it by their ids,
+// so they sync with the object and concurrent comments don't conflict
+const CommentsStoreKey = "comments"
+
+func (s *State) GetComment(id string) *model.Comment {
+	coll := s.GetSubObjectCollection(CommentsStoreKey)
+	if coll == nil {
+		return nil
+	}
+	fields := coll.Fields[id].GetStructValue()
+	if fields == nil {
+		return nil
+	}
+	return commentFromStruct(id, fields)
+}
+
+// ListComments returns comments of the object in order of creation
+func (s *State) ListComments() []*model.Comment {
+	coll := s.GetSubObjectCollection(CommentsStoreKey)
+	if coll == nil {
+		return nil
+	}
+	res := make([]*model.Comment, 0, len(coll.Fields))
+	for id, value := range coll.Fields {
+		fields := value.GetStructValue()
+		if fields == nil {
+			continue
+		}
+		res = append(res, commentFromStruct(id, fields))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].CreatedDate != res[j].CreatedDate {
+			return res[i].CreatedDate < res[j].CreatedDate
+		}
+		return res[i].Id < res[j].Id
+	})
+	return res
+}
+
+func (s *State) SetComment(comment *model.Comment) {
+	s.SetInStore([]string{CommentsStoreKey, comment.Id}, commentToValue(comment))
+}
+
+func (s *State) RemoveComment(id string) {
+	s.RemoveFromStore([]string{CommentsStoreKey, id})
+}
+
+func commentToValue(comment *model.Comment) *types.Value {
+	fields := map[string]*types.Value{
+		"threadId":     pbtypes.String(comment.ThreadId),
+		"blockId":      pbtypes.String(comment.BlockId),
+		"quote":        pbtypes.String(comment.Quote),
+		"text":         pbtypes.String(comment.Text),
+		"authorId":     pbtypes.String(comment.AuthorId),
+		"createdDate":  pbtypes.Int64(comment.CreatedDate),
+		"modifiedDate": pbtypes.Int64(comment.ModifiedDate),
+		"resolved":     pbtypes.Bool(comment.Resolved),
+		"resolvedBy":   pbtypes.String(comment.ResolvedBy),
+		"resolvedDate": pbtypes.Int64(comment.ResolvedDate),
+	}
+	if comment.Range != nil {
+		fields["rangeFrom"] = pbtypes.Int64(int64(comment.Range.From))
+		fields["rangeTo"] = pbtypes.Int64(int64(comment.Range.To))
+	}
+	return pbtypes.Struct(&types.Struct{Fields: fields})
+}
+
+func commentFromStruct(id string, fields *types.Struct) *model.Comment {
+	comment := &model.Comment{
+		Id:           id,
+		ThreadId:     pbtypes.GetString(fields, "threadId"),
+		BlockId:      pbtypes.GetString(fields, "blockId"),
+		Quote:        pbtypes.GetString(fields, "quote"),
+		Text:         pbtypes.GetString(fields, "text"),
+		AuthorId:     pbtypes.GetString(fields, "authorId"),
+		CreatedDate:  pbtypes.GetInt64(fields, "createdDate"),
+		ModifiedDate: pbtypes.GetInt64(fields, "modifiedDate"),
+		Resolved:     pbtypes.GetBool(fields, "resolved"),
+		ResolvedBy:   pbtypes.GetString(fields, "resolvedBy"),
+		ResolvedDate: pbtypes.GetInt64(fields, "resolvedDate"),
+	}
+	if _, ok := fields.Fields["rangeFrom"]; ok {
+		comment.Range = &model.Range{
+			From: int32(pbtypes.GetInt64(fields, "rangeFrom")),
+			To:   int32(pbtypes.GetInt64(fields, "rangeTo")),
+		}
+	}
+	return comment
+}
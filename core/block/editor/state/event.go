@@ -13,6 +13,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/simple/file"
 	"github.com/anyproto/anytype-heart/core/block/simple/link"
 	"github.com/anyproto/anytype-heart/core/block/simple/relation"
+	"github.com/anyproto/anytype-heart/core/block/simple/synced"
 	"github.com/anyproto/anytype-heart/core/block/simple/table"
 	"github.com/anyproto/anytype-heart/core/block/simple/text"
 	"github.com/anyproto/anytype-heart/core/block/simple/widget"
@@ -277,6 +278,15 @@ func (s *State) applyEvent(ev *pb.EventMessage) (err error) {
 		}); err != nil {
 			return
 		}
+	case *pb.EventMessageValueOfBlockSetSynced:
+		if err = apply(o.BlockSetSynced.Id, func(b simple.Block) error {
+			if sb, ok := b.(synced.Block); ok {
+				return sb.ApplyEvent(o.BlockSetSynced)
+			}
+			return fmt.Errorf("not a synced block")
+		}); err != nil {
+			return
+		}
 	case *pb.EventMessageValueOfBlockDataviewTargetObjectIdSet:
 		if err = apply(o.BlockDataviewTargetObjectIdSet.Id, func(b simple.Block) error {
 			if dvBlock, ok := b.(dataview.Block); ok {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -1304,6 +1305,41 @@ func (s *State) CheckRestrictions() (err error) {
 	return
 }
 
+// IsModified reports whether the state has changes of blocks, details, relations, object types or store
+// comparing to its parent. Local details are not taken into account
+func (s *State) IsModified() bool {
+	if s.parent == nil {
+		return true
+	}
+	if len(s.changes) > 0 || len(s.storeKeyRemoved) > 0 {
+		return true
+	}
+	if s.details != nil && !s.details.Equal(s.parent.Details()) {
+		return true
+	}
+	if s.store != nil && !s.store.Equal(s.parent.Store()) {
+		return true
+	}
+	if s.objectTypeKeys != nil && !slices.Equal(s.objectTypeKeys, s.parent.ObjectTypeKeys()) {
+		return true
+	}
+	if s.relationLinks != nil {
+		if added, removed := s.relationLinks.Diff(s.parent.PickRelationLinks()); len(added) > 0 || len(removed) > 0 {
+			return true
+		}
+	}
+	for id, b := range s.blocks {
+		ob := s.parent.Pick(id)
+		if ob == nil {
+			return true
+		}
+		if msgs, _ := ob.Diff(b); len(msgs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *State) SetParent(parent *State) {
 	s.rootId = parent.rootId
 	s.parent = parent
@@ -149,6 +149,36 @@ func TestState_Unlink(t *testing.T) {
 	assert.False(t, s.Unlink("2"))
 }
 
+func TestState_IsModified(t *testing.T) {
+	newState := func() *State {
+		return NewDoc("1", map[string]simple.Block{
+			"1": base.NewBase(&model.Block{Id: "1", ChildrenIds: []string{"2"}}),
+			"2": base.NewBase(&model.Block{Id: "2"}),
+		}).NewState()
+	}
+	t.Run("no changes", func(t *testing.T) {
+		s := newState()
+		s.Get("2")
+		s.SetLocalDetail("lastOpenedDate", pbtypes.Int64(1))
+		assert.False(t, s.IsModified())
+	})
+	t.Run("blocks", func(t *testing.T) {
+		s := newState()
+		s.Unlink("2")
+		assert.True(t, s.IsModified())
+	})
+	t.Run("details", func(t *testing.T) {
+		s := newState()
+		s.SetDetail("name", pbtypes.String("name"))
+		assert.True(t, s.IsModified())
+	})
+	t.Run("store", func(t *testing.T) {
+		s := newState()
+		s.UpdateStoreSlice("objects", []string{"id"})
+		assert.True(t, s.IsModified())
+	})
+}
+
 func TestState_GetParentOf(t *testing.T) {
 	t.Run("generic", func(t *testing.T) {
 		s := NewDoc("1", map[string]simple.Block{
@@ -1,6 +1,10 @@
 package editor
 
 import (
+	"sort"
+
+	"github.com/gogo/protobuf/types"
+
 	"github.com/anyproto/anytype-heart/core/anytype/config"
 	"github.com/anyproto/anytype-heart/core/block/editor/basic"
 	"github.com/anyproto/anytype-heart/core/block/editor/dataview"
@@ -112,6 +116,59 @@ func (w *Workspaces) RemoveExistingInviteInfo() (fileCid string, err error) {
 	return fileCid, w.Apply(newState)
 }
 
+const invitesStoreKey = "invites"
+
+func (w *Workspaces) SetInvite(invite domain.Invite) error {
+	st := w.NewState()
+	st.SetInStore([]string{invitesStoreKey, invite.RecordId}, pbtypes.Struct(&types.Struct{Fields: map[string]*types.Value{
+		"fileCid":     pbtypes.String(invite.InviteFileCid),
+		"fileKey":     pbtypes.String(invite.InviteFileKey),
+		"label":       pbtypes.String(invite.Label),
+		"permissions": pbtypes.Int64(int64(invite.Permissions)),
+		"expireDate":  pbtypes.Int64(invite.ExpireDate),
+		"maxUses":     pbtypes.Int64(int64(invite.MaxUses)),
+		"createdDate": pbtypes.Int64(invite.CreatedDate),
+		"revokedDate": pbtypes.Int64(invite.RevokedDate),
+	}}))
+	return w.Apply(st)
+}
+
+func (w *Workspaces) ListInvites() []domain.Invite {
+	invites := w.NewState().GetSubObjectCollection(invitesStoreKey)
+	if invites == nil {
+		return nil
+	}
+	res := make([]domain.Invite, 0, len(invites.Fields))
+	for recordId, value := range invites.Fields {
+		fields := value.GetStructValue()
+		if fields == nil {
+			continue
+		}
+		res = append(res, domain.Invite{
+			InviteInfo: domain.InviteInfo{
+				InviteFileCid: pbtypes.GetString(fields, "fileCid"),
+				InviteFileKey: pbtypes.GetString(fields, "fileKey"),
+			},
+			InviteParams: domain.InviteParams{
+				Label:       pbtypes.GetString(fields, "label"),
+				Permissions: model.ParticipantPermissions(pbtypes.GetInt64(fields, "permissions")),
+				ExpireDate:  pbtypes.GetInt64(fields, "expireDate"),
+				MaxUses:     int(pbtypes.GetInt64(fields, "maxUses")),
+			},
+			RecordId:    recordId,
+			CreatedDate: pbtypes.GetInt64(fields, "createdDate"),
+			RevokedDate: pbtypes.GetInt64(fields, "revokedDate"),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].CreatedDate != res[j].CreatedDate {
+			return res[i].CreatedDate < res[j].CreatedDate
+		}
+		return res[i].RecordId < res[j].RecordId
+	})
+	return res
+}
+
 func (w *Workspaces) StateMigrations() migration.Migrations {
 	return migration.MakeMigrations(nil)
 }
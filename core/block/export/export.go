@@ -22,6 +22,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/anytype/account"
 	"github.com/anyproto/anytype-heart/core/block"
 	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/comment"
 	sb "github.com/anyproto/anytype-heart/core/block/editor/smartblock"
 	"github.com/anyproto/anytype-heart/core/block/editor/state"
 	"github.com/anyproto/anytype-heart/core/block/process"
@@ -70,6 +71,7 @@ type export struct {
 	blockService        *block.Service
 	picker              cache.ObjectGetter
 	objectStore         objectstore.ObjectStore
+	accessStore         objectstore.AccessStore
 	sbtProvider         typeprovider.SmartBlockTypeProvider
 	fileService         files.Service
 	spaceService        space.Service
@@ -84,6 +86,7 @@ func New() Export {
 func (e *export) Init(a *app.App) (err error) {
 	e.blockService = a.MustComponent(block.CName).(*block.Service)
 	e.objectStore = a.MustComponent(objectstore.CName).(objectstore.ObjectStore)
+	e.accessStore = app.MustComponent[objectstore.AccessStore](a)
 	e.fileService = app.MustComponent[files.Service](a)
 	e.picker = app.MustComponent[cache.ObjectGetter](a)
 	e.sbtProvider = app.MustComponent[typeprovider.SmartBlockTypeProvider](a)
@@ -245,13 +248,20 @@ func isAnyblockExport(format model.ExportFormat) bool {
 func (e *export) docsForExport(spaceID string, req pb.RpcObjectListExportRequest) (docs map[string]*types.Struct, err error) {
 	isProtobuf := isAnyblockExport(req.Format)
 	if len(req.ObjectIds) == 0 {
-		return e.getExistedObjects(spaceID, req.IncludeArchived, isProtobuf)
+		docs, err = e.getExistedObjects(spaceID, req.IncludeArchived, isProtobuf)
+	} else {
+		docs, err = e.getObjectsByIDs(spaceID, req.ObjectIds, req.IncludeNested, req.IncludeFiles, isProtobuf)
 	}
-
-	if len(req.ObjectIds) > 0 {
-		return e.getObjectsByIDs(spaceID, req.ObjectIds, req.IncludeNested, req.IncludeFiles, isProtobuf)
+	if err != nil {
+		return nil, err
 	}
-	return
+	// objects hidden from readers are not exported even if they are linked from the exported ones
+	for id, details := range docs {
+		if e.accessStore.IsHidden(details) {
+			delete(docs, id)
+		}
+	}
+	return docs, nil
 }
 
 func (e *export) getObjectsByIDs(spaceId string, reqIds []string, includeNested bool, includeFiles bool, isProtobuf bool) (map[string]*types.Struct, error) {
@@ -467,6 +477,10 @@ func (e *export) writeDoc(ctx context.Context, req *pb.RpcObjectListExportReques
 		if pbtypes.GetBool(st.CombinedDetails(), bundle.RelationKeyIsDeleted.String()) {
 			return nil
 		}
+		if !req.IncludeComments && st.HasInStore([]string{comment.StoreKey}) {
+			st = st.Copy()
+			st.RemoveFromStore([]string{comment.StoreKey})
+		}
 
 		if req.IncludeFiles && b.Type() == smartblock.SmartBlockTypeFileObject {
 			fileName, err := e.saveFile(ctx, wr, b, req.SpaceId == "")
@@ -483,7 +497,7 @@ func (e *export) writeDoc(ctx context.Context, req *pb.RpcObjectListExportReques
 		var conv converter.Converter
 		switch req.Format {
 		case model.Export_Markdown:
-			conv = md.NewMDConverter(st, wr.Namer())
+			conv = md.NewMDConverter(st, wr.Namer(), e.sourceState)
 		case model.Export_Protobuf:
 			conv = pbc.NewConverter(st, req.IsJson)
 		case model.Export_JSON:
@@ -506,6 +520,15 @@ func (e *export) writeDoc(ctx context.Context, req *pb.RpcObjectListExportReques
 	})
 }
 
+// sourceState provides state of the object referenced by synced blocks of the exported one
+func (e *export) sourceState(objectId string) (st *state.State, err error) {
+	err = cache.Do(e.picker, objectId, func(b sb.SmartBlock) error {
+		st = b.NewState().Copy()
+		return nil
+	})
+	return
+}
+
 func (e *export) provideMarkdownName(s *state.State, wr writer, docID string, conv converter.Converter, spaceId string) string {
 	name := pbtypes.GetString(s.Details(), bundle.RelationKeyName.String())
 	if name == "" {
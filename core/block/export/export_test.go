@@ -64,6 +64,7 @@ func Test_docsForExport(t *testing.T) {
 		provider.EXPECT().Type("spaceId", "id1").Return(smartblock.SmartBlockTypePage, nil)
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			sbtProvider: provider,
 		}
 
@@ -97,6 +98,7 @@ func Test_docsForExport(t *testing.T) {
 		provider.EXPECT().Type("spaceId", "id1").Return(smartblock.SmartBlockTypePage, nil)
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			sbtProvider: provider,
 		}
 
@@ -145,6 +147,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 		}
 
@@ -204,6 +207,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 		}
 
@@ -262,6 +266,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 		}
 
@@ -329,6 +334,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 		}
 
@@ -431,6 +437,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 			sbtProvider: provider,
 		}
@@ -477,6 +484,7 @@ func Test_docsForExport(t *testing.T) {
 
 		e := &export{
 			objectStore: storeFixture,
+			accessStore: storeFixture,
 			picker:      objectGetter,
 		}
 
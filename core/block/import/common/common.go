@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/simple/dataview"
 	"github.com/anyproto/anytype-heart/core/block/simple/file"
 	"github.com/anyproto/anytype-heart/core/block/simple/link"
+	"github.com/anyproto/anytype-heart/core/block/simple/synced"
 	"github.com/anyproto/anytype-heart/core/block/simple/text"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/localstore/addr"
@@ -68,6 +70,8 @@ func UpdateLinksToObjects(st *state.State, oldIDtoNew map[string]string, filesID
 			handleDataviewBlock(block, oldIDtoNew, st)
 		case file.Block:
 			handleFileBlock(oldIDtoNew, block, st)
+		case synced.Block:
+			handleSyncedBlock(oldIDtoNew, block, st)
 		}
 		return true
 	})
@@ -95,7 +99,7 @@ func handleDataviewBlock(block simple.Block, oldIDtoNew map[string]string, st *s
 		}
 	}
 	for _, group := range dataView.GetGroupOrders() {
-		for _, vg := range group.ViewGroups {
+		for _, vg := range slices.Concat(group.ViewGroups, group.SubGroups) {
 			groups := replaceChunks(vg.GroupId, oldIDtoNew)
 			sort.Strings(groups)
 			vg.GroupId = strings.Join(groups, "")
@@ -161,6 +165,16 @@ func handleLinkBlock(oldIDtoNew map[string]string, block simple.Block, st *state
 	st.Set(simple.New(block.Model()))
 }
 
+func handleSyncedBlock(oldIDtoNew map[string]string, block simple.Block, st *state.State) {
+	content := block.Model().GetSynced()
+	newTarget := oldIDtoNew[content.TargetObjectId]
+	if newTarget == "" {
+		newTarget = addr.MissingObject
+	}
+	content.TargetObjectId = newTarget
+	st.Set(simple.New(block.Model()))
+}
+
 func handleFileBlock(oldIdToNew map[string]string, block simple.Block, st *state.State) {
 	if targetObjectId := block.Model().GetFile().TargetObjectId; targetObjectId != "" {
 		newId := oldIdToNew[targetObjectId]
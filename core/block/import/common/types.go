@@ -43,4 +43,6 @@ type Snapshot struct {
 type Response struct {
 	Snapshots        []*Snapshot
 	RootCollectionID string
+	// ExistingObjectIDs are ids of objects of the space the snapshots link to, links to them are kept as is
+	ExistingObjectIDs []string
 }
@@ -0,0 +1,373 @@
+package email
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/gogo/protobuf/types"
+	"github.com/google/uuid"
+
+	"github.com/anyproto/anytype-heart/core/block/collection"
+	"github.com/anyproto/anytype-heart/core/block/import/common"
+	"github.com/anyproto/anytype-heart/core/block/import/markdown/anymark"
+	"github.com/anyproto/anytype-heart/core/block/process"
+	"github.com/anyproto/anytype-heart/core/block/simple/file"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/core"
+	"github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const numberOfStages = 2 // 1 cycle to get snapshots and 1 cycle to create objects
+const (
+	Name               = "Email"
+	rootCollectionName = "Email Import"
+)
+
+var errStopReading = errors.New("stop reading mailbox")
+
+var messageRelations = []domain.RelationKey{
+	bundle.RelationKeyMessageFrom,
+	bundle.RelationKeyMessageTo,
+	bundle.RelationKeyMessageCc,
+	bundle.RelationKeyMessageDate,
+	bundle.RelationKeyMessageId,
+	bundle.RelationKeyMessageThreadId,
+	bundle.RelationKeyMessageReplyTo,
+}
+
+// Email imports messages of mbox files and Maildir directories as objects of Message type. Message-ID is stored
+// as the old anytype id, so messages that are already in the space are updated instead of being created again
+type Email struct {
+	collectionService *collection.Service
+	tempDirProvider   core.TempDirProvider
+	objectStore       objectstore.ObjectStore
+}
+
+func New(collectionService *collection.Service, tempDirProvider core.TempDirProvider, objectStore objectstore.ObjectStore) common.Converter {
+	return &Email{
+		collectionService: collectionService,
+		tempDirProvider:   tempDirProvider,
+		objectStore:       objectStore,
+	}
+}
+
+func (e *Email) Name() string {
+	return Name
+}
+
+func (e *Email) GetParams(req *pb.RpcObjectImportRequest) []string {
+	if p := req.GetEmailParams(); p != nil {
+		return p.Path
+	}
+	return nil
+}
+
+func (e *Email) GetSnapshots(ctx context.Context, req *pb.RpcObjectImportRequest, progress process.Progress) (*common.Response, *common.ConvertError) {
+	paths := e.GetParams(req)
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	progress.SetProgressMessage("Start creating snapshots from messages")
+	allErrors := common.NewError(req.Mode)
+	messages := e.readMessages(paths, progress, allErrors)
+	if allErrors.ShouldAbortImport(len(paths), model.Import_Email) {
+		return nil, allErrors
+	}
+	snapshots, targetObjects, existingObjects := e.getSnapshots(req.SpaceId, messages, len(paths), allErrors)
+	if allErrors.ShouldAbortImport(len(paths), model.Import_Email) {
+		return nil, allErrors
+	}
+	rootCollection := common.NewRootCollection(e.collectionService)
+	rootCollectionSnapshot, err := rootCollection.MakeRootCollection(rootCollectionName, targetObjects, "", nil, true, true)
+	if err != nil {
+		allErrors.Add(err)
+		if allErrors.ShouldAbortImport(len(paths), model.Import_Email) {
+			return nil, allErrors
+		}
+	}
+	var rootCollectionID string
+	if rootCollectionSnapshot != nil {
+		snapshots = append(snapshots, rootCollectionSnapshot)
+		rootCollectionID = rootCollectionSnapshot.Id
+	}
+	progress.SetTotal(int64(numberOfStages * len(snapshots)))
+	resp := &common.Response{
+		Snapshots:         snapshots,
+		RootCollectionID:  rootCollectionID,
+		ExistingObjectIDs: existingObjects,
+	}
+	if allErrors.IsEmpty() {
+		return resp, nil
+	}
+	return resp, allErrors
+}
+
+// readMessages reads messages of all paths sorted by date, duplicates of the same Message-ID are skipped
+func (e *Email) readMessages(paths []string, progress process.Progress, allErrors *common.ConvertError) []*sourceMessage {
+	var (
+		messages []*sourceMessage
+		seen     = map[string]struct{}{}
+	)
+	for _, path := range paths {
+		if err := progress.TryStep(1); err != nil {
+			allErrors.Add(common.ErrCancel)
+			return nil
+		}
+		var count int
+		err := readMailbox(path, func(raw []byte) error {
+			attachments := &messageAttachments{rootDir: e.tempDirProvider.TempDir(), names: map[string]int{}}
+			m, err := parseMessage(raw, attachments)
+			if err != nil {
+				attachments.remove()
+				allErrors.Add(err)
+				if allErrors.ShouldAbortImport(len(paths), model.Import_Email) {
+					return errStopReading
+				}
+				return nil
+			}
+			count++
+			if _, ok := seen[m.id]; ok {
+				attachments.remove()
+				return nil
+			}
+			seen[m.id] = struct{}{}
+			messages = append(messages, &sourceMessage{message: m, path: path})
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopReading) {
+			allErrors.Add(fmt.Errorf("read %s: %w", filepath.Base(path), err))
+		}
+		if allErrors.ShouldAbortImport(len(paths), model.Import_Email) {
+			return nil
+		}
+		if count == 0 && err == nil {
+			allErrors.Add(common.ErrNoObjectsToImport)
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].date.Before(messages[j].date)
+	})
+	return messages
+}
+
+type sourceMessage struct {
+	*message
+	path string
+}
+
+// getSnapshots returns snapshots of messages, ids of snapshots to add to the root collection and ids of
+// messages of the space the imported messages reply to
+func (e *Email) getSnapshots(spaceId string, messages []*sourceMessage, pathsCount int, allErrors *common.ConvertError) ([]*common.Snapshot, []string, []string) {
+	snapshotIds := make(map[string]string, len(messages))
+	for _, m := range messages {
+		snapshotIds[m.id] = uuid.New().String()
+	}
+	snapshots := make([]*common.Snapshot, 0, len(messages))
+	targetObjects := make([]string, 0, len(messages))
+	var existingObjects []string
+	for _, m := range messages {
+		blocks, err := e.getBlocks(m.message)
+		if err != nil {
+			allErrors.Add(fmt.Errorf("message %s: %w", m.id, err))
+			if allErrors.ShouldAbortImport(pathsCount, model.Import_Email) {
+				return nil, nil, nil
+			}
+		}
+		details := getDetails(m)
+		if parentId, ok := snapshotIds[m.inReplyTo]; ok {
+			details.Fields[bundle.RelationKeyMessageReplyTo.String()] = pbtypes.String(parentId)
+		} else if parentId := e.findMessageObject(spaceId, m.inReplyTo); parentId != "" {
+			details.Fields[bundle.RelationKeyMessageReplyTo.String()] = pbtypes.String(parentId)
+			existingObjects = append(existingObjects, parentId)
+		}
+		relationLinks := make([]*model.RelationLink, 0, len(messageRelations))
+		for _, key := range messageRelations {
+			relationLinks = append(relationLinks, bundle.MustGetRelationLink(key))
+		}
+		snapshot := &common.Snapshot{
+			Id:       snapshotIds[m.id],
+			FileName: m.path,
+			Snapshot: &pb.ChangeSnapshot{Data: &model.SmartBlockSnapshotBase{
+				Blocks:        blocks,
+				Details:       details,
+				ObjectTypes:   []string{bundle.TypeKeyMessage.String()},
+				RelationLinks: relationLinks,
+			}},
+			SbType: smartblock.SmartBlockTypePage,
+		}
+		snapshots = append(snapshots, snapshot)
+		targetObjects = append(targetObjects, snapshot.Id)
+	}
+	return snapshots, targetObjects, existingObjects
+}
+
+// findMessageObject returns the id of the message object of the space with the given Message-ID,
+// so replies are linked to the messages imported before
+func (e *Email) findMessageObject(spaceId, messageId string) string {
+	if messageId == "" || e.objectStore == nil {
+		return ""
+	}
+	ids, _, err := e.objectStore.QueryObjectIDs(database.Query{
+		Filters: []*model.BlockContentDataviewFilter{
+			{
+				Condition:   model.BlockContentDataviewFilter_Equal,
+				RelationKey: bundle.RelationKeyMessageId.String(),
+				Value:       pbtypes.String(messageId),
+			},
+			{
+				Condition:   model.BlockContentDataviewFilter_Equal,
+				RelationKey: bundle.RelationKeySpaceId.String(),
+				Value:       pbtypes.String(spaceId),
+			},
+			{
+				Condition:   model.BlockContentDataviewFilter_NotEqual,
+				RelationKey: bundle.RelationKeyIsDeleted.String(),
+				Value:       pbtypes.Bool(true),
+			},
+		},
+		Limit: 1,
+	})
+	if err != nil || len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+func getDetails(m *sourceMessage) *types.Struct {
+	h := sha256.Sum256([]byte(m.path + "#" + m.id))
+	fields := map[string]*types.Value{
+		bundle.RelationKeyName.String():            pbtypes.String(m.subject),
+		bundle.RelationKeySourceFilePath.String():  pbtypes.String(hex.EncodeToString(h[:])),
+		bundle.RelationKeyLayout.String():          pbtypes.Float64(float64(model.ObjectType_basic)),
+		bundle.RelationKeyOldAnytypeID.String():    pbtypes.String(m.id),
+		bundle.RelationKeyMessageId.String():       pbtypes.String(m.id),
+		bundle.RelationKeyMessageThreadId.String(): pbtypes.String(m.threadId()),
+		bundle.RelationKeyMessageFrom.String():     pbtypes.String(m.from),
+		bundle.RelationKeyMessageTo.String():       pbtypes.String(m.to),
+		bundle.RelationKeyMessageCc.String():       pbtypes.String(m.cc),
+	}
+	if !m.date.IsZero() {
+		fields[bundle.RelationKeyMessageDate.String()] = pbtypes.Int64(m.date.Unix())
+		fields[bundle.RelationKeyCreatedDate.String()] = pbtypes.Int64(m.date.Unix())
+		fields[bundle.RelationKeyLastModifiedDate.String()] = pbtypes.Int64(m.date.Unix())
+	}
+	return &types.Struct{Fields: fields}
+}
+
+// getBlocks converts the html body, or the text body if there is no html, to blocks. Attachments saved
+// to the temp dir are added as file blocks, so they are uploaded as file objects. Images embedded in html
+// by Content-ID are replaced with the attachment files
+func (e *Email) getBlocks(m *message) ([]*model.Block, error) {
+	var (
+		blocks []*model.Block
+		err    error
+	)
+	switch {
+	case len(m.html) > 0:
+		blocks, _, err = anymark.HTMLToBlocks(m.html, "")
+	case len(m.text) > 0:
+		blocks, _, err = anymark.MarkdownToBlocks(m.text, "", []string{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("convert body: %w", err)
+	}
+	if len(m.attachments) == 0 {
+		return blocks, nil
+	}
+	embedded := make(map[int]bool)
+	for _, block := range blocks {
+		f := block.GetFile()
+		if f == nil || !strings.HasPrefix(f.Name, "cid:") {
+			continue
+		}
+		contentId := strings.TrimPrefix(f.Name, "cid:")
+		for i, a := range m.attachments {
+			if a.contentId == contentId {
+				f.Name = a.path
+				embedded[i] = true
+				break
+			}
+		}
+	}
+	for i, a := range m.attachments {
+		if embedded[i] {
+			continue
+		}
+		blocks = append(blocks, &model.Block{
+			Id: bson.NewObjectId().Hex(),
+			Content: &model.BlockContentOfFile{File: &model.BlockContentFile{
+				Name:  a.path,
+				State: model.BlockContentFile_Empty,
+				Type:  file.DetectTypeByMIME(a.contentType),
+			}},
+		})
+	}
+	return blocks, nil
+}
+
+// messageAttachments saves attachments of the message to its own directory, so names of different messages don't collide
+type messageAttachments struct {
+	rootDir string
+	dir     string
+	names   map[string]int
+}
+
+func (a *messageAttachments) write(name string, r io.Reader) (string, int64, error) {
+	if a.dir == "" {
+		dir, err := os.MkdirTemp(a.rootDir, "email-import-")
+		if err != nil {
+			return "", 0, fmt.Errorf("create attachments dir: %w", err)
+		}
+		a.dir = dir
+	}
+	name = sanitizeFileName(name)
+	if n := a.names[name]; n > 0 {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	a.names[name]++
+	path := filepath.Join(a.dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", 0, err
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || size == 0 {
+		_ = os.Remove(path)
+		return "", 0, err
+	}
+	return path, size, nil
+}
+
+// remove removes attachments of the message that is not imported
+func (a *messageAttachments) remove() {
+	if a.dir != "" {
+		_ = os.RemoveAll(a.dir)
+	}
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		return "attachment"
+	}
+	return name
+}
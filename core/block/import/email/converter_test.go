@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/import/common"
+	"github.com/anyproto/anytype-heart/core/block/process"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type mockTempDirProvider struct {
+	dir string
+}
+
+func (p *mockTempDirProvider) TempDir() string {
+	return p.dir
+}
+
+func getSnapshots(t *testing.T, paths ...string) (*common.Response, *common.ConvertError) {
+	return getSnapshotsWithStore(t, objectstore.NewStoreFixture(t), paths...)
+}
+
+func getSnapshotsWithStore(t *testing.T, store objectstore.ObjectStore, paths ...string) (*common.Response, *common.ConvertError) {
+	e := &Email{tempDirProvider: &mockTempDirProvider{dir: t.TempDir()}, objectStore: store}
+	return e.GetSnapshots(context.Background(), &pb.RpcObjectImportRequest{
+		SpaceId: "space1",
+		Params: &pb.RpcObjectImportRequestParamsOfEmailParams{
+			EmailParams: &pb.RpcObjectImportRequestEmailParams{Path: paths},
+		},
+		Type: model.Import_Email,
+		Mode: pb.RpcObjectImportRequest_IGNORE_ERRORS,
+	}, process.NewProgress(pb.ModelProcess_Import))
+}
+
+func snapshotByMessageId(resp *common.Response, id string) *common.Snapshot {
+	for _, sn := range resp.Snapshots {
+		if pbtypes.GetString(sn.Snapshot.Data.Details, bundle.RelationKeyMessageId.String()) == id {
+			return sn
+		}
+	}
+	return nil
+}
+
+func TestEmail_GetSnapshots(t *testing.T) {
+	t.Run("mbox messages are linked into the thread", func(t *testing.T) {
+		// when
+		resp, ce := getSnapshots(t, "testdata/inbox.mbox")
+
+		// then
+		require.Nil(t, ce)
+		require.Len(t, resp.Snapshots, 3)
+		root := snapshotByMessageId(resp, "root@example.com")
+		reply := snapshotByMessageId(resp, "reply@example.com")
+		require.NotNil(t, root)
+		require.NotNil(t, reply)
+
+		details := root.Snapshot.Data.Details
+		assert.Equal(t, []string{bundle.TypeKeyMessage.String()}, root.Snapshot.Data.ObjectTypes)
+		assert.Equal(t, "Quarterly plan", pbtypes.GetString(details, bundle.RelationKeyName.String()))
+		assert.Equal(t, "Alice <alice@example.com>", pbtypes.GetString(details, bundle.RelationKeyMessageFrom.String()))
+		assert.Equal(t, "Bob <bob@example.com>, carol@example.com", pbtypes.GetString(details, bundle.RelationKeyMessageTo.String()))
+		assert.Equal(t, int64(1704708000), pbtypes.GetInt64(details, bundle.RelationKeyMessageDate.String()))
+		assert.Equal(t, "root@example.com", pbtypes.GetString(details, bundle.RelationKeyOldAnytypeID.String()))
+		assert.Equal(t, "root@example.com", pbtypes.GetString(details, bundle.RelationKeyMessageThreadId.String()))
+
+		details = reply.Snapshot.Data.Details
+		assert.Equal(t, "carol@example.com", pbtypes.GetString(details, bundle.RelationKeyMessageCc.String()))
+		assert.Equal(t, "root@example.com", pbtypes.GetString(details, bundle.RelationKeyMessageThreadId.String()))
+		assert.Equal(t, root.Id, pbtypes.GetString(details, bundle.RelationKeyMessageReplyTo.String()))
+		assert.NotNil(t, reply.Snapshot.Data.RelationLinks)
+
+		assert.Equal(t, resp.RootCollectionID, resp.Snapshots[2].Id)
+		assert.Equal(t, []string{bundle.TypeKeyCollection.String()}, resp.Snapshots[2].Snapshot.Data.ObjectTypes)
+	})
+
+	t.Run("html body is preferred and attachments become file blocks", func(t *testing.T) {
+		// when
+		resp, ce := getSnapshots(t, "testdata/inbox.mbox")
+
+		// then
+		require.Nil(t, ce)
+		reply := snapshotByMessageId(resp, "reply@example.com")
+		var (
+			texts []string
+			files []*model.BlockContentFile
+		)
+		for _, block := range reply.Snapshot.Data.Blocks {
+			if text := block.GetText(); text != nil {
+				texts = append(texts, text.Text)
+			}
+			if f := block.GetFile(); f != nil {
+				files = append(files, f)
+			}
+		}
+		assert.Contains(t, strings.Join(texts, "\n"), "see the budget attached")
+		require.Len(t, files, 1)
+		assert.True(t, strings.HasSuffix(files[0].Name, "budget.csv"))
+		data, err := os.ReadFile(files[0].Name)
+		require.NoError(t, err)
+		assert.Equal(t, "item,amount\nroom,100\n", string(data))
+	})
+
+	t.Run("messages of several mailboxes are deduplicated by Message-ID", func(t *testing.T) {
+		// when
+		resp, ce := getSnapshots(t, "testdata/inbox.mbox", "testdata/maildir", "testdata/inbox.mbox")
+
+		// then
+		require.Nil(t, ce)
+		assert.Len(t, resp.Snapshots, 5)
+		secondReply := snapshotByMessageId(resp, "second-reply@example.com")
+		require.NotNil(t, secondReply)
+		details := secondReply.Snapshot.Data.Details
+		assert.Equal(t, "Re: Quarterly plan à venir", pbtypes.GetString(details, bundle.RelationKeyName.String()))
+		assert.Equal(t, "Carol Müller <carol@example.com>", pbtypes.GetString(details, bundle.RelationKeyMessageFrom.String()))
+		assert.Equal(t, "root@example.com", pbtypes.GetString(details, bundle.RelationKeyMessageThreadId.String()))
+		assert.Equal(t, snapshotByMessageId(resp, "reply@example.com").Id, pbtypes.GetString(details, bundle.RelationKeyMessageReplyTo.String()))
+	})
+
+	t.Run("reply is linked to the message imported before", func(t *testing.T) {
+		// given
+		store := objectstore.NewStoreFixture(t)
+		store.AddObjects(t, []objectstore.TestObject{{
+			bundle.RelationKeyId:        pbtypes.String("reply1"),
+			bundle.RelationKeySpaceId:   pbtypes.String("space1"),
+			bundle.RelationKeyMessageId: pbtypes.String("reply@example.com"),
+		}})
+
+		// when
+		resp, ce := getSnapshotsWithStore(t, store, "testdata/maildir")
+
+		// then
+		require.Nil(t, ce)
+		secondReply := snapshotByMessageId(resp, "second-reply@example.com")
+		require.NotNil(t, secondReply)
+		assert.Equal(t, "reply1", pbtypes.GetString(secondReply.Snapshot.Data.Details, bundle.RelationKeyMessageReplyTo.String()))
+		assert.Equal(t, []string{"reply1"}, resp.ExistingObjectIDs)
+	})
+
+	t.Run("mailbox without messages", func(t *testing.T) {
+		// when
+		resp, ce := getSnapshots(t, "testdata/empty")
+
+		// then
+		assert.Nil(t, resp)
+		require.NotNil(t, ce)
+		assert.True(t, errors.Is(ce.GetResultError(model.Import_Email), common.ErrNoObjectsToImport))
+	})
+}
+
+func newTestAttachments(t *testing.T) *messageAttachments {
+	return &messageAttachments{rootDir: t.TempDir(), names: map[string]int{}}
+}
+
+func TestParseMessage(t *testing.T) {
+	t.Run("text in legacy charset", func(t *testing.T) {
+		// given
+		raw, err := os.ReadFile("testdata/maildir/cur/1704880800.M1P1.host")
+		require.NoError(t, err)
+
+		// when
+		m, err := parseMessage(raw, newTestAttachments(t))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "second-reply@example.com", m.id)
+		assert.Equal(t, "reply@example.com", m.inReplyTo)
+		assert.Equal(t, []string{"root@example.com", "reply@example.com"}, m.references)
+		assert.Equal(t, "Très bien.\n", string(m.text))
+	})
+
+	t.Run("message without Message-ID gets the stable id", func(t *testing.T) {
+		// given
+		raw, err := os.ReadFile("testdata/maildir/new/1704967200.M2P2.host")
+		require.NoError(t, err)
+
+		// when
+		m1, err := parseMessage(raw, newTestAttachments(t))
+		require.NoError(t, err)
+		m2, err := parseMessage(raw, newTestAttachments(t))
+		require.NoError(t, err)
+
+		// then
+		assert.NotEmpty(t, m1.id)
+		assert.Equal(t, m1.id, m2.id)
+		assert.Equal(t, m1.id, m1.threadId())
+	})
+
+	t.Run("inline image is kept with its Content-ID", func(t *testing.T) {
+		// given
+		raw := strings.Join([]string{
+			"Message-ID: <img@example.com>",
+			`Content-Type: multipart/related; boundary="b"`,
+			"",
+			"--b",
+			"Content-Type: text/html",
+			"",
+			`<p><img src="cid:logo"></p>`,
+			"--b",
+			"Content-Type: image/png",
+			"Content-ID: <logo>",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"iVBORw0KGgo=",
+			"--b--",
+		}, "\r\n")
+
+		// when
+		m, err := parseMessage([]byte(raw), newTestAttachments(t))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, m.attachments, 1)
+		assert.Equal(t, "logo", m.attachments[0].contentId)
+		assert.Equal(t, "image/png", m.attachments[0].contentType)
+		assert.Equal(t, "attachment-1.png", m.attachments[0].name)
+		data, err := os.ReadFile(m.attachments[0].path)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, data)
+	})
+}
+
+func TestReadMbox(t *testing.T) {
+	// given
+	mbox := "From a@example.com Mon Jan  8 10:00:00 2024\n" +
+		"Subject: first\n\nline\n>From here\n>>From there\nFrom the middle of paragraph\n\n" +
+		"From b@example.com Mon Jan  8 11:00:00 2024\n" +
+		"Subject: second\n\nbody\n"
+
+	// when
+	var messages []string
+	err := readMbox(bytes.NewBufferString(mbox), func(raw []byte) error {
+		messages = append(messages, string(raw))
+		return nil
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Subject: first\n\nline\nFrom here\n>From there\nFrom the middle of paragraph\n",
+		"Subject: second\n\nbody\n",
+	}, messages)
+
+	err = readMbox(bytes.NewBufferString("Subject: not mbox\n"), func([]byte) error { return nil })
+	assert.Error(t, err)
+}
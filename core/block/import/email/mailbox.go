@@ -0,0 +1,120 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var mboxFromLine = []byte("From ")
+
+// readMailbox calls fn with raw messages of the mbox file or of the directory. Directories are read as Maildir,
+// messages are taken from cur and new subdirectories of the folders, mbox files inside the directory are read as well
+func readMailbox(path string, fn func(raw []byte) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return readMboxFile(path, fn)
+	}
+	var messageFiles, mboxFiles []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		switch dir := filepath.Base(filepath.Dir(p)); {
+		case dir == "cur" || dir == "new":
+			messageFiles = append(messageFiles, p)
+		case d.Name() == "mbox" || strings.EqualFold(filepath.Ext(p), ".mbox"):
+			mboxFiles = append(mboxFiles, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(messageFiles)
+	for _, p := range messageFiles {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err = fn(raw); err != nil {
+			return err
+		}
+	}
+	for _, p := range mboxFiles {
+		if err = readMboxFile(p, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readMboxFile(path string, fn func(raw []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readMbox(f, fn)
+}
+
+// readMbox splits mbox into messages. A message starts with "From " line at the beginning of the file
+// or after an empty line, ">From " lines of the body are unescaped as in mboxrd
+func readMbox(r io.Reader, fn func(raw []byte) error) error {
+	reader := bufio.NewReader(r)
+	var (
+		current   bytes.Buffer
+		started   bool
+		prevEmpty = true
+	)
+	flush := func() error {
+		if !started {
+			return nil
+		}
+		raw := append(bytes.TrimRight(current.Bytes(), "\r\n"), '\n')
+		current.Reset()
+		return fn(raw)
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case prevEmpty && bytes.HasPrefix(line, mboxFromLine):
+				if flushErr := flush(); flushErr != nil {
+					return flushErr
+				}
+				started = true
+			case !started:
+				if len(bytes.TrimSpace(line)) > 0 {
+					return fmt.Errorf("not an mbox file")
+				}
+			default:
+				if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, mboxFromLine) {
+					line = line[1:]
+				}
+				current.Write(line)
+			}
+			prevEmpty = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const maxNestingLevel = 10
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+type message struct {
+	id         string
+	inReplyTo  string
+	references []string
+	from       string
+	to         string
+	cc         string
+	subject    string
+	date       time.Time
+
+	text        []byte
+	html        []byte
+	attachments []attachment
+}
+
+type attachment struct {
+	name        string
+	contentType string
+	contentId   string
+	path        string
+}
+
+// attachmentWriter saves the content of the attachment and returns the path of the saved file.
+// Attachments are written while the message is parsed, so their content is not kept in memory
+type attachmentWriter interface {
+	write(name string, r io.Reader) (path string, size int64, err error)
+}
+
+// threadId returns Message-ID of the first message of the conversation. The first entry of References is
+// the root of the thread, In-Reply-To is used by clients that don't fill References
+func (m *message) threadId() string {
+	if len(m.references) > 0 {
+		return m.references[0]
+	}
+	if m.inReplyTo != "" {
+		return m.inReplyTo
+	}
+	return m.id
+}
+
+// parseMessage parses RFC 5322 message. Messages without Message-ID get the id derived from the content,
+// so they are deduplicated on re-import as well
+func parseMessage(raw []byte, attachments attachmentWriter) (*message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	header := msg.Header
+	m := &message{
+		references: parseMessageIds(header.Get("References")),
+		from:       parseAddresses(header.Get("From")),
+		to:         parseAddresses(header.Get("To")),
+		cc:         parseAddresses(header.Get("Cc")),
+		subject:    decodeHeader(header.Get("Subject")),
+	}
+	if ids := parseMessageIds(header.Get("Message-Id")); len(ids) > 0 {
+		m.id = ids[0]
+	} else {
+		h := sha256.Sum256(raw)
+		m.id = hex.EncodeToString(h[:])
+	}
+	if ids := parseMessageIds(header.Get("In-Reply-To")); len(ids) > 0 {
+		m.inReplyTo = ids[0]
+	}
+	if date, err := header.Date(); err == nil {
+		m.date = date
+	}
+	if err = m.readPart(textproto.MIMEHeader(header), msg.Body, attachments, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readPart collects bodies and attachments of the part. The first text and html parts are treated as the body,
+// so alternatives and the forwarded messages don't override it
+func (m *message) readPart(header textproto.MIMEHeader, body io.Reader, attachments attachmentWriter, level int) error {
+	if level > maxNestingLevel {
+		return fmt.Errorf("too deep nesting of message parts")
+	}
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	body = decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read multipart: %w", err)
+			}
+			if err = m.readPart(part.Header, part, attachments, level+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	fileName := dispositionParams["filename"]
+	if fileName == "" {
+		fileName = params["name"]
+	}
+	fileName = decodeHeader(fileName)
+	isInline := disposition != "attachment" && fileName == ""
+
+	switch {
+	case isInline && mediaType == "text/plain" && m.text == nil:
+		m.text, err = readText(body, params["charset"])
+		return err
+	case isInline && mediaType == "text/html" && m.html == nil:
+		m.html, err = readText(body, params["charset"])
+		return err
+	}
+	name := attachmentName(fileName, mediaType, len(m.attachments))
+	path, size, err := attachments.write(name, body)
+	if err != nil {
+		return fmt.Errorf("save attachment: %w", err)
+	}
+	if size == 0 {
+		return nil
+	}
+	a := attachment{
+		name:        name,
+		contentType: mediaType,
+		path:        path,
+	}
+	if ids := parseMessageIds(header.Get("Content-Id")); len(ids) > 0 {
+		a.contentId = ids[0]
+	}
+	m.attachments = append(m.attachments, a)
+	return nil
+}
+
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// readText converts the text to UTF-8, the text is returned as is if the charset is unknown
+func readText(body io.Reader, charsetLabel string) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read text: %w", err)
+	}
+	label := strings.ToLower(charsetLabel)
+	if label == "" || label == "utf-8" || label == "us-ascii" {
+		return data, nil
+	}
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		return data, nil
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return data, nil
+	}
+	return decoded, nil
+}
+
+func attachmentName(fileName, mediaType string, idx int) string {
+	if fileName != "" {
+		return fileName
+	}
+	name := fmt.Sprintf("attachment-%d", idx+1)
+	if mediaType == "message/rfc822" {
+		return name + ".eml"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return name + exts[0]
+	}
+	return name
+}
+
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// parseAddresses formats the address list as "Name <address>" joined by comma, malformed lists are kept decoded
+func parseAddresses(value string) string {
+	if value == "" {
+		return ""
+	}
+	parser := &mail.AddressParser{WordDecoder: wordDecoder}
+	addresses, err := parser.ParseList(value)
+	if err != nil {
+		return decodeHeader(value)
+	}
+	formatted := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		if addr.Name == "" {
+			formatted = append(formatted, addr.Address)
+		} else {
+			formatted = append(formatted, fmt.Sprintf("%s <%s>", addr.Name, addr.Address))
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// parseMessageIds returns ids of Message-ID, In-Reply-To or References header without angle brackets
+func parseMessageIds(value string) []string {
+	var ids []string
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			break
+		}
+		if id := strings.TrimSpace(value[start+1 : start+end]); id != "" {
+			ids = append(ids, id)
+		}
+		value = value[start+end+1:]
+	}
+	if len(ids) == 0 {
+		ids = strings.Fields(value)
+	}
+	return ids
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -24,10 +26,12 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/import/common/syncer"
 	"github.com/anyproto/anytype-heart/core/block/import/common/workerpool"
 	"github.com/anyproto/anytype-heart/core/block/import/csv"
+	"github.com/anyproto/anytype-heart/core/block/import/email"
 	"github.com/anyproto/anytype-heart/core/block/import/html"
 	"github.com/anyproto/anytype-heart/core/block/import/markdown"
 	"github.com/anyproto/anytype-heart/core/block/import/notion"
 	pbc "github.com/anyproto/anytype-heart/core/block/import/pb"
+	"github.com/anyproto/anytype-heart/core/block/import/plugin"
 	"github.com/anyproto/anytype-heart/core/block/import/txt"
 	"github.com/anyproto/anytype-heart/core/block/import/web"
 	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
@@ -36,6 +40,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/domain/objectorigin"
 	"github.com/anyproto/anytype-heart/core/files/fileobject"
 	"github.com/anyproto/anytype-heart/core/filestorage/filesync"
+	"github.com/anyproto/anytype-heart/core/wallet"
 	"github.com/anyproto/anytype-heart/metrics"
 	"github.com/anyproto/anytype-heart/metrics/anymetry"
 	"github.com/anyproto/anytype-heart/pb"
@@ -56,8 +61,11 @@ const CName = "importer"
 
 const workerPoolSize = 10
 
+const pluginsDirName = "import-plugins"
+
 type Import struct {
 	converters      map[string]common.Converter
+	plugins         map[string]*plugin.Converter
 	s               *block.Service
 	oc              creator.Service
 	idProvider      objectid.IDProvider
@@ -70,6 +78,7 @@ type Import struct {
 func New() Importer {
 	return &Import{
 		converters: make(map[string]common.Converter, 0),
+		plugins:    make(map[string]*plugin.Converter, 0),
 	}
 }
 
@@ -79,6 +88,7 @@ func (i *Import) Init(a *app.App) (err error) {
 	spaceService := app.MustComponent[space.Service](a)
 	col := app.MustComponent[*collection.Service](a)
 	i.tempDirProvider = app.MustComponent[core.TempDirProvider](a)
+	store := app.MustComponent[objectstore.ObjectStore](a)
 	converters := []common.Converter{
 		markdown.New(i.tempDirProvider, col),
 		notion.New(col),
@@ -87,11 +97,12 @@ func (i *Import) Init(a *app.App) (err error) {
 		html.New(col, i.tempDirProvider),
 		txt.New(col),
 		csv.New(col),
+		email.New(col, i.tempDirProvider, store),
 	}
 	for _, c := range converters {
 		i.converters[c.Name()] = c
 	}
-	store := app.MustComponent[objectstore.ObjectStore](a)
+	i.loadPlugins(app.MustComponent[wallet.Wallet](a).RootPath(), col)
 	i.fileStore = app.MustComponent[filestore.FileStore](a)
 	fileObjectService := app.MustComponent[fileobject.Service](a)
 	i.idProvider = objectid.NewIDProvider(store, spaceService, i.s, i.fileStore, fileObjectService)
@@ -103,6 +114,23 @@ func (i *Import) Init(a *app.App) (err error) {
 	return nil
 }
 
+// loadPlugins registers converter executables of the plugins directory. The directory is shared by the accounts,
+// it could be overridden by ANYTYPE_IMPORT_PLUGINS_DIR
+func (i *Import) loadPlugins(rootPath string, col *collection.Service) {
+	dir := os.Getenv("ANYTYPE_IMPORT_PLUGINS_DIR")
+	if dir == "" {
+		dir = filepath.Join(rootPath, pluginsDirName)
+	}
+	plugins, err := plugin.LoadConverters(dir, col, i.tempDirProvider)
+	if err != nil {
+		log.Errorf("failed to load import plugins: %v", err)
+		return
+	}
+	for _, p := range plugins {
+		i.plugins[p.Name()] = p
+	}
+}
+
 // Import get snapshots from converter or external api and create smartblocks from them
 func (i *Import) Import(ctx context.Context,
 	req *pb.RpcObjectImportRequest,
@@ -148,6 +176,13 @@ func (i *Import) Import(ctx context.Context,
 	if req.Type == model.Import_External {
 		objectsCount, returnedErr = i.importFromExternalSource(ctx, req, progress)
 	}
+	if req.Type == model.Import_Plugin {
+		if c, ok := i.plugins[req.GetPluginParams().GetName()]; ok {
+			rootCollectionId, objectsCount, returnedErr = i.importFromBuiltinConverter(ctx, req, c, progress, origin)
+		} else {
+			returnedErr = fmt.Errorf("%w: %s", plugin.ErrNotFound, req.GetPluginParams().GetName())
+		}
+	}
 	return &ImportResponse{
 		RootCollectionId: rootCollectionId,
 		ProcessId:        progress.Id(),
@@ -260,12 +295,19 @@ func (i *Import) Name() string {
 
 // ListImports return all registered import types
 func (i *Import) ListImports(_ *pb.RpcObjectImportListRequest) ([]*pb.RpcObjectImportListImportResponse, error) {
-	res := make([]*pb.RpcObjectImportListImportResponse, len(i.converters))
+	res := make([]*pb.RpcObjectImportListImportResponse, len(i.converters), len(i.converters)+len(i.plugins))
 	var idx int
 	for _, c := range i.converters {
 		res[idx] = &pb.RpcObjectImportListImportResponse{Type: convertType(c.Name())}
 		idx++
 	}
+	for _, p := range i.plugins {
+		res = append(res, &pb.RpcObjectImportListImportResponse{
+			Type:        pb.RpcObjectImportListImportResponse_Plugin,
+			PluginName:  p.Name(),
+			PluginTitle: p.Title(),
+		})
+	}
 	return res, nil
 }
 
@@ -312,6 +354,11 @@ func (i *Import) createObjects(ctx context.Context,
 	if err != nil {
 		return nil, ""
 	}
+	for _, id := range res.ExistingObjectIDs {
+		if _, ok := oldIDToNew[id]; !ok {
+			oldIDToNew[id] = id
+		}
+	}
 	filesIDs := i.getFilesIDs(res)
 	numWorkers := workerPoolSize
 	if len(res.Snapshots) < workerPoolSize {
@@ -0,0 +1,225 @@
+// Package plugin runs import converters implemented as external executables, so formats could be imported
+// without changes of the middleware. Converters produce snapshots, objects are created by the importer
+// the same way as for the builtin converters
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/google/uuid"
+
+	"github.com/anyproto/anytype-heart/core/block/collection"
+	"github.com/anyproto/anytype-heart/core/block/import/common"
+	"github.com/anyproto/anytype-heart/core/block/process"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/core"
+	"github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+const numberOfStages = 2 // 1 cycle to get snapshots and 1 cycle to create objects
+
+var log = logging.Logger("import-plugin")
+
+var ErrNotFound = errors.New("import plugin is not found")
+
+// allowedSmartBlockTypes are types of objects converters could create
+var allowedSmartBlockTypes = map[model.SmartBlockType]smartblock.SmartBlockType{
+	model.SmartBlockType_Page:             smartblock.SmartBlockTypePage,
+	model.SmartBlockType_Template:         smartblock.SmartBlockTypeTemplate,
+	model.SmartBlockType_STType:           smartblock.SmartBlockTypeObjectType,
+	model.SmartBlockType_STRelation:       smartblock.SmartBlockTypeRelation,
+	model.SmartBlockType_STRelationOption: smartblock.SmartBlockTypeRelationOption,
+}
+
+type Converter struct {
+	manifest          *Manifest
+	collectionService *collection.Service
+	tempDirProvider   core.TempDirProvider
+}
+
+// LoadConverters returns converters of the manifests found in the directory
+func LoadConverters(dir string, collectionService *collection.Service, tempDirProvider core.TempDirProvider) ([]*Converter, error) {
+	manifests, err := readManifests(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read import plugins: %w", err)
+	}
+	converters := make([]*Converter, 0, len(manifests))
+	for _, m := range manifests {
+		converters = append(converters, &Converter{
+			manifest:          m,
+			collectionService: collectionService,
+			tempDirProvider:   tempDirProvider,
+		})
+	}
+	return converters, nil
+}
+
+func (c *Converter) Name() string {
+	return c.manifest.Name
+}
+
+func (c *Converter) Title() string {
+	return c.manifest.title()
+}
+
+func (c *Converter) GetSnapshots(ctx context.Context, req *pb.RpcObjectImportRequest, progress process.Progress) (*common.Response, *common.ConvertError) {
+	params := req.GetPluginParams()
+	if params == nil {
+		return nil, nil
+	}
+	pluginReq := &request{
+		Protocol: protocolVersion,
+		Paths:    params.Path,
+		Mode:     req.Mode.String(),
+		TempDir:  c.tempDirProvider.TempDir(),
+	}
+	if params.ParamsJson != "" {
+		if !json.Valid([]byte(params.ParamsJson)) {
+			return nil, common.NewFromError(fmt.Errorf("params of %s are not valid json", c.Name()), req.Mode)
+		}
+		pluginReq.Params = json.RawMessage(params.ParamsJson)
+	}
+	progress.SetProgressMessage("Start creating snapshots with " + c.Title())
+
+	allErrors := common.NewError(req.Mode)
+	snapshots, targetObjects := c.getSnapshots(ctx, pluginReq, progress, allErrors)
+	if allErrors.ShouldAbortImport(0, model.Import_Plugin) {
+		return nil, allErrors
+	}
+	if len(snapshots) == 0 {
+		allErrors.Add(common.ErrNoObjectsToImport)
+		return nil, allErrors
+	}
+	rootCollection := common.NewRootCollection(c.collectionService)
+	rootCollectionSnapshot, err := rootCollection.MakeRootCollection(c.Title()+" Import", targetObjects, "", nil, true, true)
+	if err != nil {
+		allErrors.Add(err)
+		if allErrors.ShouldAbortImport(0, model.Import_Plugin) {
+			return nil, allErrors
+		}
+	}
+	var rootCollectionID string
+	if rootCollectionSnapshot != nil {
+		snapshots = append(snapshots, rootCollectionSnapshot)
+		rootCollectionID = rootCollectionSnapshot.Id
+	}
+	progress.SetTotal(int64(numberOfStages * len(snapshots)))
+	if allErrors.IsEmpty() {
+		return &common.Response{Snapshots: snapshots, RootCollectionID: rootCollectionID}, nil
+	}
+	return &common.Response{
+		Snapshots:        snapshots,
+		RootCollectionID: rootCollectionID,
+	}, allErrors
+}
+
+// getSnapshots runs the converter. Pages are added to the root collection, ids of snapshots are kept,
+// so links and object relations between them are resolved by the importer
+func (c *Converter) getSnapshots(ctx context.Context,
+	req *request,
+	progress process.Progress,
+	allErrors *common.ConvertError,
+) ([]*common.Snapshot, []string) {
+	var (
+		snapshots     []*common.Snapshot
+		targetObjects []string
+		ids           = map[string]struct{}{}
+	)
+	// the converter is killed on cancel even if it doesn't write messages
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-progress.Canceled():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err := c.manifest.run(ctx, req, func(msg *message) error {
+		switch msg.Type {
+		case messageSnapshot:
+			if err := progress.TryStep(1); err != nil {
+				return common.ErrCancel
+			}
+			sn, err := decodeSnapshot(msg)
+			if err == nil {
+				if _, ok := ids[sn.Id]; ok {
+					err = fmt.Errorf("%w: duplicate snapshot id %s", errProtocol, sn.Id)
+				}
+			}
+			if err != nil {
+				allErrors.Add(err)
+				if allErrors.ShouldAbortImport(0, model.Import_Plugin) {
+					return err
+				}
+				return nil
+			}
+			ids[sn.Id] = struct{}{}
+			snapshots = append(snapshots, sn)
+			if sn.SbType == smartblock.SmartBlockTypePage {
+				targetObjects = append(targetObjects, sn.Id)
+			}
+		case messageProgress:
+			if msg.Message != "" {
+				progress.SetProgressMessage(msg.Message)
+			}
+			if err := progress.TryStep(0); err != nil {
+				return common.ErrCancel
+			}
+		case messageError:
+			if msg.Path != "" {
+				allErrors.Add(fmt.Errorf("%s: %s: %s", c.Name(), msg.Path, msg.Message))
+			} else {
+				allErrors.Add(fmt.Errorf("%s: %s", c.Name(), msg.Message))
+			}
+			if allErrors.ShouldAbortImport(0, model.Import_Plugin) {
+				return errors.New("converter reported an error")
+			}
+		default:
+			log.Warnf("%s: unknown message type %q", c.Name(), msg.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, common.ErrCancel), errors.Is(err, context.Canceled):
+			allErrors.Add(common.ErrCancel)
+		case !allErrors.ShouldAbortImport(0, model.Import_Plugin):
+			// errors of the messages are already added
+			allErrors.Add(err)
+		}
+	}
+	return snapshots, targetObjects
+}
+
+// decodeSnapshot reads the snapshot in json format of SnapshotWithType, the format used by the export
+func decodeSnapshot(msg *message) (*common.Snapshot, error) {
+	snapshot := &pb.SnapshotWithType{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(msg.Snapshot), snapshot); err != nil {
+		return nil, fmt.Errorf("%w: decode snapshot %s: %w", errProtocol, msg.Id, err)
+	}
+	if snapshot.Snapshot == nil || snapshot.Snapshot.Data == nil {
+		return nil, fmt.Errorf("%w: snapshot %s has no data", errProtocol, msg.Id)
+	}
+	sbType, ok := allowedSmartBlockTypes[snapshot.SbType]
+	if !ok {
+		return nil, fmt.Errorf("%w: objects of %s type are not supported", errProtocol, snapshot.SbType)
+	}
+	id := msg.Id
+	if id == "" {
+		id = uuid.New().String()
+	}
+	return &common.Snapshot{
+		Id:       id,
+		SbType:   sbType,
+		FileName: msg.FileName,
+		Snapshot: snapshot.Snapshot,
+	}, nil
+}
@@ -0,0 +1,247 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/import/common"
+	"github.com/anyproto/anytype-heart/core/block/process"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const testModeEnv = "IMPORT_PLUGIN_TEST_MODE"
+
+// TestMain runs the test binary as the converter when the mode is set
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(testModeEnv); mode != "" {
+		os.Exit(runTestConverter(mode))
+	}
+	os.Exit(m.Run())
+}
+
+func runTestConverter(mode string) int {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "read request:", err)
+		return 1
+	}
+	switch mode {
+	case "ok":
+		fmt.Println(`{"type":"progress","message":"Reading pages"}`)
+		fmt.Printf(`{"type":"snapshot","id":"page-1","fileName":"first.wiki","snapshot":{"sbType":"Page","snapshot":{"data":{`+
+			`"blocks":[{"id":"link","link":{"targetBlockId":"page-2"}}],"details":{"name":%q},"objectTypes":["page"]}}}}`+"\n",
+			strings.Join(req.Paths, ",")+" "+string(req.Params))
+		fmt.Println(`{"type":"snapshot","id":"page-2","snapshot":{"sbType":"Page","snapshot":{"data":{"details":{"name":"Second"},"objectTypes":["page"]}}}}`)
+		fmt.Println(`{"type":"snapshot","id":"author","snapshot":{"sbType":"STRelation","snapshot":{"data":{` +
+			`"details":{"relationKey":"wikiAuthor","relationFormat":1,"name":"Author"},"objectTypes":["relation"]}}}}`)
+		fmt.Println(`{"type":"snapshot","id":"workspace","snapshot":{"sbType":"Workspace","snapshot":{"data":{}}}}`)
+		fmt.Println(`{"type":"error","path":"broken.wiki","message":"unexpected end of page"}`)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "wiki dump is corrupted")
+		return 2
+	case "garbage":
+		fmt.Println("not a message")
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+	return 0
+}
+
+func newTestConverter(t *testing.T, mode string) *Converter {
+	t.Setenv(testModeEnv, mode)
+	return &Converter{
+		manifest:        &Manifest{Name: "wiki", Title: "Wiki", Command: os.Args[0], dir: t.TempDir()},
+		tempDirProvider: &mockTempDirProvider{dir: t.TempDir()},
+	}
+}
+
+type mockTempDirProvider struct {
+	dir string
+}
+
+func (p *mockTempDirProvider) TempDir() string {
+	return p.dir
+}
+
+func pluginRequest(mode pb.RpcObjectImportRequestMode) *pb.RpcObjectImportRequest {
+	return &pb.RpcObjectImportRequest{
+		Params: &pb.RpcObjectImportRequestParamsOfPluginParams{PluginParams: &pb.RpcObjectImportRequestPluginParams{
+			Name:       "wiki",
+			Path:       []string{"/data/wiki"},
+			ParamsJson: `{"space":"docs"}`,
+		}},
+		Type: model.Import_Plugin,
+		Mode: mode,
+	}
+}
+
+func TestConverter_GetSnapshots(t *testing.T) {
+	t.Run("snapshots of the converter", func(t *testing.T) {
+		// given
+		c := newTestConverter(t, "ok")
+
+		// when
+		resp, ce := c.GetSnapshots(context.Background(), pluginRequest(pb.RpcObjectImportRequest_IGNORE_ERRORS), process.NewNoOp())
+
+		// then
+		require.NotNil(t, resp)
+		require.Len(t, resp.Snapshots, 4)
+		first, second, relation, rootCollection := resp.Snapshots[0], resp.Snapshots[1], resp.Snapshots[2], resp.Snapshots[3]
+		assert.Equal(t, "page-1", first.Id)
+		assert.Equal(t, "first.wiki", first.FileName)
+		assert.Equal(t, smartblock.SmartBlockTypePage, first.SbType)
+		assert.Equal(t, `/data/wiki {"space":"docs"}`, pbtypes.GetString(first.Snapshot.Data.Details, bundle.RelationKeyName.String()))
+		assert.Equal(t, "page-2", first.Snapshot.Data.Blocks[0].GetLink().TargetBlockId)
+		assert.Equal(t, "page-2", second.Id)
+		assert.Equal(t, smartblock.SmartBlockTypeRelation, relation.SbType)
+
+		assert.Equal(t, resp.RootCollectionID, rootCollection.Id)
+		assert.True(t, strings.HasPrefix(rootCollection.FileName, "Wiki Import"))
+		assert.Equal(t, []string{"page-1", "page-2"}, pbtypes.GetStringList(rootCollection.Snapshot.Data.Collections, "objects"))
+
+		require.NotNil(t, ce)
+		assert.Contains(t, ce.Error().Error(), "broken.wiki: unexpected end of page")
+		assert.Contains(t, ce.Error().Error(), "objects of Workspace type are not supported")
+	})
+
+	t.Run("errors abort import in all or nothing mode", func(t *testing.T) {
+		// given
+		c := newTestConverter(t, "ok")
+
+		// when
+		resp, ce := c.GetSnapshots(context.Background(), pluginRequest(pb.RpcObjectImportRequest_ALL_OR_NOTHING), process.NewNoOp())
+
+		// then
+		assert.Nil(t, resp)
+		require.NotNil(t, ce)
+		assert.Contains(t, ce.Error().Error(), "objects of Workspace type are not supported")
+		assert.NotContains(t, ce.Error().Error(), "broken.wiki")
+	})
+
+	t.Run("stderr is reported when converter fails", func(t *testing.T) {
+		// given
+		c := newTestConverter(t, "fail")
+
+		// when
+		resp, ce := c.GetSnapshots(context.Background(), pluginRequest(pb.RpcObjectImportRequest_IGNORE_ERRORS), process.NewNoOp())
+
+		// then
+		assert.Nil(t, resp)
+		require.NotNil(t, ce)
+		assert.Contains(t, ce.Error().Error(), "wiki dump is corrupted")
+	})
+
+	t.Run("malformed output", func(t *testing.T) {
+		// given
+		c := newTestConverter(t, "garbage")
+
+		// when
+		resp, ce := c.GetSnapshots(context.Background(), pluginRequest(pb.RpcObjectImportRequest_IGNORE_ERRORS), process.NewNoOp())
+
+		// then
+		assert.Nil(t, resp)
+		require.NotNil(t, ce)
+		assert.Contains(t, ce.Error().Error(), errProtocol.Error())
+	})
+
+	t.Run("converter is killed on cancel", func(t *testing.T) {
+		// given
+		c := newTestConverter(t, "hang")
+		progress := process.NewProgress(pb.ModelProcess_Import)
+		time.AfterFunc(100*time.Millisecond, func() {
+			_ = progress.Cancel()
+		})
+
+		// when
+		start := time.Now()
+		resp, ce := c.GetSnapshots(context.Background(), pluginRequest(pb.RpcObjectImportRequest_IGNORE_ERRORS), progress)
+
+		// then
+		assert.Less(t, time.Since(start), 30*time.Second)
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(ce.GetResultError(model.Import_Plugin), common.ErrCancel))
+	})
+}
+
+func TestReadMessages(t *testing.T) {
+	output := `{"type":"progress","message":"first"}` + "\n\n" + `{"type":"progress","message":"second"}` + "\n"
+	read := func(messageLimit, outputLimit int) ([]string, error) {
+		var messages []string
+		err := readMessages(strings.NewReader(output), messageLimit, outputLimit, func(msg *message) error {
+			messages = append(messages, msg.Message)
+			return nil
+		})
+		return messages, err
+	}
+
+	t.Run("messages are read line by line", func(t *testing.T) {
+		// when
+		messages, err := read(1024, 1024)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, messages)
+	})
+
+	t.Run("too large message", func(t *testing.T) {
+		// when
+		messages, err := read(16, 1024)
+
+		// then
+		assert.ErrorIs(t, err, errProtocol)
+		assert.Contains(t, err.Error(), "message exceeds 16 bytes")
+		assert.Empty(t, messages)
+	})
+
+	t.Run("too large output", func(t *testing.T) {
+		// when
+		messages, err := read(1024, 64)
+
+		// then
+		assert.ErrorIs(t, err, errProtocol)
+		assert.Contains(t, err.Error(), "output exceeds 64 bytes")
+		assert.Equal(t, []string{"first"}, messages)
+	})
+}
+
+func TestReadManifests(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	write("a.json", `{"name":"wiki","title":"Wiki","command":"bin/wiki-import","args":["--v2"]}`)
+	write("b.json", `{"name":"wiki","command":"other"}`)
+	write("c.json", `{"name":"Bad Name","command":"x"}`)
+	write("d.json", `{"name":"nocommand"}`)
+	write("e.json", `not json`)
+	write("readme.txt", `{"name":"txt","command":"x"}`)
+
+	// when
+	manifests, err := readManifests(dir)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "wiki", manifests[0].Name)
+	assert.Equal(t, []string{"--v2"}, manifests[0].Args)
+	assert.Equal(t, filepath.Join(dir, "bin", "wiki-import"), manifests[0].command())
+
+	manifests, err = readManifests(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, manifests)
+}
@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const manifestExt = ".json"
+
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Manifest describes the converter executable. Manifests are json files of the plugins directory,
+// relative command paths are resolved against the directory of the manifest
+type Manifest struct {
+	// Name identifies the converter in import requests
+	Name string `json:"name"`
+	// Title is shown in the list of imports and used as the name of the root collection
+	Title   string   `json:"title"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	dir string
+}
+
+func (m *Manifest) validate() error {
+	if !nameRe.MatchString(m.Name) {
+		return fmt.Errorf("invalid name %q: lowercase letters, digits, dashes and underscores are allowed", m.Name)
+	}
+	if m.Command == "" {
+		return errors.New("command is required")
+	}
+	return nil
+}
+
+func (m *Manifest) title() string {
+	if m.Title != "" {
+		return m.Title
+	}
+	return m.Name
+}
+
+func (m *Manifest) command() string {
+	if filepath.IsAbs(m.Command) || !strings.ContainsAny(m.Command, `/\`) {
+		return m.Command
+	}
+	return filepath.Join(m.dir, m.Command)
+}
+
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if err = m.validate(); err != nil {
+		return nil, err
+	}
+	m.dir = filepath.Dir(path)
+	return m, nil
+}
+
+// readManifests reads manifests of the directory, invalid manifests are skipped. Missing directory means there are no plugins
+func readManifests(dir string) ([]*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		manifests []*Manifest
+		names     = map[string]struct{}{}
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), manifestExt) {
+			continue
+		}
+		m, err := readManifest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Warnf("skip import plugin %s: %v", entry.Name(), err)
+			continue
+		}
+		if _, ok := names[m.Name]; ok {
+			log.Warnf("skip import plugin %s: name %s is already used", entry.Name(), m.Name)
+			continue
+		}
+		names[m.Name] = struct{}{}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// The converter is started per import. It receives the request as a json document on stdin and writes
+// json messages to stdout, one message per line. The import fails if the converter exits with non-zero code.
+// Protocol is described in docs/ImportPlugins.md
+
+const (
+	protocolVersion = 1
+	maxStderrSize   = 4 << 10
+	waitDelay       = 5 * time.Second
+	// maxMessageSize limits the size of one message, snapshots with large files should refer to the files
+	// extracted to tempDir instead of embedding them
+	maxMessageSize = 64 << 20
+	// maxOutputSize limits the size of all messages of the converter, snapshots are kept in memory
+	// until objects are created
+	maxOutputSize = 1 << 30
+)
+
+const (
+	messageSnapshot = "snapshot"
+	messageProgress = "progress"
+	messageError    = "error"
+)
+
+var errProtocol = errors.New("import plugin protocol error")
+
+type request struct {
+	Protocol int             `json:"protocol"`
+	Paths    []string        `json:"paths"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	// Mode is ALL_OR_NOTHING or IGNORE_ERRORS, converters may stop on the first error in the first mode
+	Mode string `json:"mode"`
+	// TempDir could be used for files extracted by the converter, files of file blocks are uploaded from there
+	TempDir string `json:"tempDir"`
+}
+
+type message struct {
+	Type string `json:"type"`
+
+	// snapshot
+	Id       string          `json:"id,omitempty"`
+	FileName string          `json:"fileName,omitempty"`
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+
+	// progress and error
+	Message string `json:"message,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// run starts the converter, writes the request to its stdin and passes messages of stdout to the handler.
+// The converter is killed when ctx is done or the handler returns an error
+func (m *Manifest) run(ctx context.Context, req *request, handle func(msg *message) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, m.command(), m.Args...)
+	cmd.Dir = m.dir
+	cmd.WaitDelay = waitDelay
+	stderr := &tailBuffer{limit: maxStderrSize}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", m.Name, err)
+	}
+	go func() {
+		// the converter may exit without reading the request, so the error is not interesting
+		_ = json.NewEncoder(stdin).Encode(req)
+		_ = stdin.Close()
+	}()
+
+	handleErr := readMessages(stdout, maxMessageSize, maxOutputSize, handle)
+	if handleErr != nil {
+		cancel()
+		_ = cmd.Wait()
+		return handleErr
+	}
+	if err = cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return fmt.Errorf("%s failed: %w: %s", m.Name, err, output)
+		}
+		return fmt.Errorf("%s failed: %w", m.Name, err)
+	}
+	return nil
+}
+
+// readMessages reads messages line by line. The converter is stopped by the protocol error if a message
+// or the whole output is larger than the limit
+func readMessages(r io.Reader, messageLimit, outputLimit int, handle func(msg *message) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(64<<10, messageLimit)), messageLimit)
+	var total int
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		total += len(line)
+		if total > outputLimit {
+			return fmt.Errorf("%w: output exceeds %d bytes", errProtocol, outputLimit)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		msg := &message{}
+		if err := json.Unmarshal(line, msg); err != nil {
+			return fmt.Errorf("%w: %w", errProtocol, err)
+		}
+		if err := handle(msg); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: message exceeds %d bytes", errProtocol, messageLimit)
+		}
+		return fmt.Errorf("%w: %w", errProtocol, err)
+	}
+	return nil
+}
+
+// tailBuffer keeps the last bytes written to it, so the end of stderr is reported if the converter fails
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
 	"github.com/anyproto/anytype-heart/core/block/object/payloadcreator"
 	"github.com/anyproto/anytype-heart/core/block/source"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pkg/lib/logging"
 )
 
@@ -108,6 +109,10 @@ func ContextWithBuildOptions(ctx context.Context, buildOpts source.BuildOptions)
 
 func (c *objectCache) cacheLoad(ctx context.Context, id string) (value ocache.Object, err error) {
 	opts := ctx.Value(optsKey).(cacheOpts)
+	ctx, span := tracing.Start(ctx, "objectcache.Load", tracing.ObjectId(id), tracing.SpaceId(c.space.Id()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	buildObject := func(id string) (sb smartblock.SmartBlock, err error) {
 		initCtx := &smartblock.InitContext{
 			Ctx:       ctx,
@@ -0,0 +1,94 @@
+package restriction
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// ObjectAccess holds per-object access settings. Owners of a shared space use them to narrow
+// space-wide permissions of participants for particular objects
+type ObjectAccess struct {
+	// LockedForWriters makes the object read-only for everyone except owners
+	LockedForWriters bool
+	// HiddenFromReaders forbids readers to open the object
+	HiddenFromReaders bool
+}
+
+func ObjectAccessFromDetails(details *types.Struct) ObjectAccess {
+	return ObjectAccess{
+		LockedForWriters:  pbtypes.GetBool(details, bundle.RelationKeyIsLockedForWriters.String()),
+		HiddenFromReaders: pbtypes.GetBool(details, bundle.RelationKeyIsHiddenFromReaders.String()),
+	}
+}
+
+// AccessHolder is implemented by objects which access could be restricted for participants of the space
+type AccessHolder interface {
+	ObjectAccess() ObjectAccess
+	ParticipantPermissions() model.ParticipantPermissions
+}
+
+var (
+	objLockedRestrictions = ObjectRestrictions{
+		model.Restrictions_Blocks,
+		model.Restrictions_Relations,
+		model.Restrictions_Details,
+		model.Restrictions_Delete,
+		model.Restrictions_LayoutChange,
+		model.Restrictions_TypeChange,
+		model.Restrictions_Template,
+	}
+	objHiddenRestrictions = objRestrictAll
+)
+
+func getAccessRestrictions(rh RestrictionHolder) ObjectRestrictions {
+	ah, ok := rh.(AccessHolder)
+	if !ok {
+		return nil
+	}
+	access := ah.ObjectAccess()
+	switch ah.ParticipantPermissions() {
+	case model.ParticipantPermissions_Owner:
+		return nil
+	case model.ParticipantPermissions_Writer:
+		if access.LockedForWriters {
+			return objLockedRestrictions
+		}
+	default:
+		if access.HiddenFromReaders {
+			return objHiddenRestrictions
+		}
+	}
+	return nil
+}
+
+// checkAccessChange checks that the participant is allowed to apply the change of the object.
+// Only owners could change access settings, and nobody except owners could modify locked objects
+func checkAccessChange(ah AccessHolder, newAccess ObjectAccess, modified bool) error {
+	if ah.ParticipantPermissions() == model.ParticipantPermissions_Owner {
+		return nil
+	}
+	access := ah.ObjectAccess()
+	if newAccess != access {
+		return fmt.Errorf("%w: only owners can change object access", ErrRestricted)
+	}
+	if modified && access.LockedForWriters {
+		return fmt.Errorf("%w: object is locked", ErrRestricted)
+	}
+	return nil
+}
+
+func checkAccessOpen(ah AccessHolder) error {
+	switch ah.ParticipantPermissions() {
+	case model.ParticipantPermissions_Owner, model.ParticipantPermissions_Writer:
+		return nil
+	}
+	if ah.ObjectAccess().HiddenFromReaders {
+		return fmt.Errorf("%w: object is hidden from readers", ErrRestricted)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package restriction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	coresb "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+type accessHolder struct {
+	RestrictionHolder
+	access      ObjectAccess
+	permissions model.ParticipantPermissions
+}
+
+func (ah *accessHolder) ObjectAccess() ObjectAccess {
+	return ah.access
+}
+
+func (ah *accessHolder) ParticipantPermissions() model.ParticipantPermissions {
+	return ah.permissions
+}
+
+func givenAccessHolder(typeKey domain.TypeKey, access ObjectAccess, permissions model.ParticipantPermissions) *accessHolder {
+	return &accessHolder{
+		RestrictionHolder: givenRestrictionHolder(coresb.SmartBlockTypePage, typeKey),
+		access:            access,
+		permissions:       permissions,
+	}
+}
+
+func TestService_AccessRestrictions(t *testing.T) {
+	s := service{}
+	locked := ObjectAccess{LockedForWriters: true}
+	hidden := ObjectAccess{HiddenFromReaders: true}
+
+	t.Run("locked object is read-only for writers", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, locked, model.ParticipantPermissions_Writer)
+
+		// when
+		r := s.GetRestrictions(ah)
+
+		// then
+		assert.ErrorIs(t, r.Object.Check(model.Restrictions_Blocks), ErrRestricted)
+		assert.ErrorIs(t, r.Object.Check(model.Restrictions_Details), ErrRestricted)
+		assert.ErrorIs(t, r.Object.Check(model.Restrictions_Delete), ErrRestricted)
+		assert.NoError(t, r.Object.Check(model.Restrictions_Duplicate))
+		assert.ErrorIs(t, s.CheckRestrictions(ah, model.Restrictions_Blocks), ErrRestricted)
+	})
+
+	t.Run("locked collection restricts dataview", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyCollection, locked, model.ParticipantPermissions_Writer)
+
+		// when
+		r := s.GetRestrictions(ah)
+
+		// then
+		assert.ErrorIs(t, r.Dataview.Check(DataviewBlockId, model.Restrictions_DVViews), ErrRestricted)
+	})
+
+	t.Run("locked object is editable for owners", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, locked, model.ParticipantPermissions_Owner)
+
+		// when
+		r := s.GetRestrictions(ah)
+
+		// then
+		assert.NoError(t, r.Object.Check(objLockedRestrictions...))
+	})
+
+	t.Run("hidden object is not restricted for writers", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, hidden, model.ParticipantPermissions_Writer)
+
+		// when
+		r := s.GetRestrictions(ah)
+
+		// then
+		assert.NoError(t, r.Object.Check(objRestrictAll...))
+		assert.NoError(t, s.CheckAccessOpen(ah))
+	})
+
+	t.Run("hidden object could not be opened by readers", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, hidden, model.ParticipantPermissions_Reader)
+
+		// when
+		err := s.CheckAccessOpen(ah)
+
+		// then
+		assert.ErrorIs(t, err, ErrRestricted)
+		assert.ErrorIs(t, s.GetRestrictions(ah).Object.Check(model.Restrictions_Duplicate), ErrRestricted)
+	})
+
+	t.Run("restrictions of layouts are not modified", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeySet, locked, model.ParticipantPermissions_Writer)
+
+		// when
+		s.GetRestrictions(ah)
+
+		// then
+		assert.Len(t, objectRestrictionsByLayout[model.ObjectType_set], len(objRestrictEdit))
+	})
+}
+
+func TestService_CheckAccessChange(t *testing.T) {
+	s := service{}
+
+	t.Run("owner changes access", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, ObjectAccess{}, model.ParticipantPermissions_Owner)
+
+		// when
+		err := s.CheckAccessChange(ah, ObjectAccess{LockedForWriters: true}, true)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("writer changes access", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, ObjectAccess{}, model.ParticipantPermissions_Writer)
+
+		// when
+		err := s.CheckAccessChange(ah, ObjectAccess{HiddenFromReaders: true}, true)
+
+		// then
+		assert.ErrorIs(t, err, ErrRestricted)
+	})
+
+	t.Run("writer modifies locked object", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, ObjectAccess{LockedForWriters: true}, model.ParticipantPermissions_Writer)
+
+		// when
+		err := s.CheckAccessChange(ah, ah.access, true)
+
+		// then
+		assert.ErrorIs(t, err, ErrRestricted)
+		assert.NoError(t, s.CheckAccessChange(ah, ah.access, false))
+	})
+
+	t.Run("writer modifies unlocked object", func(t *testing.T) {
+		// given
+		ah := givenAccessHolder(bundle.TypeKeyPage, ObjectAccess{HiddenFromReaders: true}, model.ParticipantPermissions_Writer)
+
+		// when
+		err := s.CheckAccessChange(ah, ah.access, true)
+
+		// then
+		assert.NoError(t, err)
+	})
+}
@@ -24,6 +24,100 @@ func (_m *MockService) EXPECT() *MockService_Expecter {
 	return &MockService_Expecter{mock: &_m.Mock}
 }
 
+// CheckAccessChange provides a mock function with given fields: ah, newAccess, modified
+func (_m *MockService) CheckAccessChange(ah restriction.AccessHolder, newAccess restriction.ObjectAccess, modified bool) error {
+	ret := _m.Called(ah, newAccess, modified)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckAccessChange")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(restriction.AccessHolder, restriction.ObjectAccess, bool) error); ok {
+		r0 = rf(ah, newAccess, modified)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_CheckAccessChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckAccessChange'
+type MockService_CheckAccessChange_Call struct {
+	*mock.Call
+}
+
+// CheckAccessChange is a helper method to define mock.On call
+//   - ah restriction.AccessHolder
+//   - newAccess restriction.ObjectAccess
+//   - modified bool
+func (_e *MockService_Expecter) CheckAccessChange(ah interface{}, newAccess interface{}, modified interface{}) *MockService_CheckAccessChange_Call {
+	return &MockService_CheckAccessChange_Call{Call: _e.mock.On("CheckAccessChange", ah, newAccess, modified)}
+}
+
+func (_c *MockService_CheckAccessChange_Call) Run(run func(ah restriction.AccessHolder, newAccess restriction.ObjectAccess, modified bool)) *MockService_CheckAccessChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(restriction.AccessHolder), args[1].(restriction.ObjectAccess), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockService_CheckAccessChange_Call) Return(_a0 error) *MockService_CheckAccessChange_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_CheckAccessChange_Call) RunAndReturn(run func(restriction.AccessHolder, restriction.ObjectAccess, bool) error) *MockService_CheckAccessChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CheckAccessOpen provides a mock function with given fields: ah
+func (_m *MockService) CheckAccessOpen(ah restriction.AccessHolder) error {
+	ret := _m.Called(ah)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckAccessOpen")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(restriction.AccessHolder) error); ok {
+		r0 = rf(ah)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_CheckAccessOpen_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckAccessOpen'
+type MockService_CheckAccessOpen_Call struct {
+	*mock.Call
+}
+
+// CheckAccessOpen is a helper method to define mock.On call
+//   - ah restriction.AccessHolder
+func (_e *MockService_Expecter) CheckAccessOpen(ah interface{}) *MockService_CheckAccessOpen_Call {
+	return &MockService_CheckAccessOpen_Call{Call: _e.mock.On("CheckAccessOpen", ah)}
+}
+
+func (_c *MockService_CheckAccessOpen_Call) Run(run func(ah restriction.AccessHolder)) *MockService_CheckAccessOpen_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(restriction.AccessHolder))
+	})
+	return _c
+}
+
+func (_c *MockService_CheckAccessOpen_Call) Return(_a0 error) *MockService_CheckAccessOpen_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_CheckAccessOpen_Call) RunAndReturn(run func(restriction.AccessHolder) error) *MockService_CheckAccessOpen_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CheckRestrictions provides a mock function with given fields: rh, cr
 func (_m *MockService) CheckRestrictions(rh restriction.RestrictionHolder, cr ...model.RestrictionsObjectRestriction) error {
 	_va := make([]interface{}, len(cr))
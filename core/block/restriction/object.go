@@ -175,6 +175,20 @@ func (or ObjectRestrictions) Copy() ObjectRestrictions {
 	return obj
 }
 
+// merge returns the new list with restrictions of both lists, the original lists are not modified
+func (or ObjectRestrictions) merge(or2 ObjectRestrictions) ObjectRestrictions {
+	if len(or2) == 0 {
+		return or
+	}
+	res := or.Copy()
+	for _, r := range or2 {
+		if res.Check(r) == nil {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
 func (or ObjectRestrictions) ToPB() *types.Value {
 	var ints = make([]int, len(or))
 	for i, v := range or {
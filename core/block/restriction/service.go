@@ -23,6 +23,10 @@ type RestrictionHolder interface {
 type Service interface {
 	GetRestrictions(RestrictionHolder) Restrictions
 	CheckRestrictions(rh RestrictionHolder, cr ...model.RestrictionsObjectRestriction) error
+	// CheckAccessChange checks per-object access settings before applying the change of the object
+	CheckAccessChange(ah AccessHolder, newAccess ObjectAccess, modified bool) error
+	// CheckAccessOpen checks that the current participant is allowed to open the object
+	CheckAccessOpen(ah AccessHolder) error
 	app.Component
 }
 
@@ -41,16 +45,31 @@ func (s *service) Name() (name string) {
 }
 
 func (s *service) GetRestrictions(rh RestrictionHolder) (r Restrictions) {
-	return Restrictions{
+	r = Restrictions{
 		Object:   getObjectRestrictions(rh),
 		Dataview: getDataviewRestrictions(rh),
 	}
+	if accessRestrictions := getAccessRestrictions(rh); len(accessRestrictions) > 0 {
+		r.Object = r.Object.merge(accessRestrictions)
+		if len(r.Dataview) > 0 {
+			r.Dataview = dvRestrictAll.Copy()
+		}
+	}
+	return r
 }
 
 func (s *service) CheckRestrictions(rh RestrictionHolder, cr ...model.RestrictionsObjectRestriction) error {
-	r := getObjectRestrictions(rh)
+	r := getObjectRestrictions(rh).merge(getAccessRestrictions(rh))
 	if err := r.Check(cr...); err != nil {
 		return err
 	}
 	return nil
 }
+
+func (s *service) CheckAccessChange(ah AccessHolder, newAccess ObjectAccess, modified bool) error {
+	return checkAccessChange(ah, newAccess, modified)
+}
+
+func (s *service) CheckAccessOpen(ah AccessHolder) error {
+	return checkAccessOpen(ah)
+}
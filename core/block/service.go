@@ -39,6 +39,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/session"
 	"github.com/anyproto/anytype-heart/core/syncstatus"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/core"
@@ -56,6 +57,7 @@ import (
 	_ "github.com/anyproto/anytype-heart/core/block/editor/table"
 	_ "github.com/anyproto/anytype-heart/core/block/simple/file"
 	_ "github.com/anyproto/anytype-heart/core/block/simple/link"
+	_ "github.com/anyproto/anytype-heart/core/block/simple/synced"
 	_ "github.com/anyproto/anytype-heart/core/block/simple/widget"
 )
 
@@ -91,6 +93,7 @@ func New() *Service {
 			objects: make(map[string]bool),
 			lock:    &sync.Mutex{},
 		},
+		syncedSources: newSyncedSources(),
 	}
 	return s
 }
@@ -116,6 +119,10 @@ type Service struct {
 
 	predefinedObjectWasMissing bool
 	openedObjs                 *openedObjects
+	syncedSources              *syncedSources
+
+	componentCtx       context.Context
+	componentCtxCancel context.CancelFunc
 }
 
 type builtinObjects interface {
@@ -154,10 +161,12 @@ func (s *Service) Init(a *app.App) (err error) {
 
 	s.builtinObjectService = app.MustComponent[builtinObjects](a)
 	s.app = a
+	s.componentCtx, s.componentCtxCancel = context.WithCancel(context.Background())
 	return
 }
 
 func (s *Service) Run(ctx context.Context) (err error) {
+	go s.syncedSources.run(s.componentCtx, s)
 	return
 }
 
@@ -180,13 +189,25 @@ func (s *Service) GetObjectByFullID(ctx context.Context, id domain.FullID) (sb s
 func (s *Service) OpenBlock(sctx session.Context, id domain.FullID, includeRelationsAsDependentObjects bool) (obj *model.ObjectView, err error) {
 	id = s.resolveFullId(id)
 	startTime := time.Now()
-	err = s.DoFullId(id, func(ob smartblock.SmartBlock) error {
+	ctx, span := tracing.Start(session.ParentContext(sctx), "block.OpenBlock", tracing.ObjectId(id.ObjectID), tracing.SpaceId(id.SpaceID))
+	defer func() {
+		tracing.End(span, err)
+	}()
+	// the span is only passed down, opening must not be interrupted when the rpc is canceled
+	ctx = context.WithoutCancel(ctx)
+	spc, err := s.spaceService.Get(ctx, id.SpaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get space: %w", err)
+	}
+	err = spc.DoCtx(ctx, id.ObjectID, func(ob smartblock.SmartBlock) error {
 		if includeRelationsAsDependentObjects {
 			ob.EnabledRelationAsDependentObjects()
 		}
 		afterSmartBlockTime := time.Now()
+		span.AddEvent("object loaded")
 
 		ob.RegisterSession(sctx)
+		s.syncedSources.open(sctx.ID(), id.ObjectID)
 
 		afterDataviewTime := time.Now()
 		st := ob.NewState()
@@ -196,10 +217,12 @@ func (s *Service) OpenBlock(sctx session.Context, id domain.FullID, includeRelat
 			log.Errorf("failed to update lastOpenedDate: %s", err)
 		}
 		afterApplyTime := time.Now()
+		span.AddEvent("last opened date applied")
 		if obj, err = ob.Show(); err != nil {
 			return fmt.Errorf("show: %w", err)
 		}
 		afterShowTime := time.Now()
+		span.AddEvent("object shown")
 
 		_, err = s.syncStatus.Watch(id.SpaceID, id.ObjectID, nil)
 
@@ -228,6 +251,8 @@ func (s *Service) OpenBlock(sctx session.Context, id domain.FullID, includeRelat
 	if err != nil {
 		return nil, err
 	}
+	// sources are opened after the lock of the object is released, they could show blocks of this object too
+	s.showSyncedSources(sctx, id.ObjectID, obj)
 	mutex.WithLock(s.openedObjs.lock, func() any { s.openedObjs.objects[id.ObjectID] = true; return nil })
 	return obj, nil
 }
@@ -268,6 +293,10 @@ func (s *Service) CloseBlock(ctx session.Context, id domain.FullID) error {
 	var isDraft bool
 	err := s.DoFullId(id, func(b smartblock.SmartBlock) error {
 		b.ObjectClose(ctx)
+		if s.syncedSources.close(ctx.ID(), id.ObjectID) {
+			// blocks of the object are still shown to the session by synced blocks of other objects
+			b.RegisterSession(ctx)
+		}
 		s := b.NewState()
 		isDraft = internalflag.NewFromState(s).Has(model.InternalFlag_editorDeleteEmpty)
 		return nil
@@ -275,6 +304,7 @@ func (s *Service) CloseBlock(ctx session.Context, id domain.FullID) error {
 	if err != nil {
 		return err
 	}
+	s.hideSyncedSources(ctx, id.ObjectID)
 
 	if isDraft {
 		if err = s.DeleteObjectByFullID(id); err != nil {
@@ -685,7 +715,10 @@ func (s *Service) ProcessCancel(id string) (err error) {
 }
 
 func (s *Service) Close(ctx context.Context) (err error) {
-	return nil
+	if s.componentCtxCancel != nil {
+		s.componentCtxCancel()
+	}
+	return s.syncedSources.updates.Close()
 }
 
 func (s *Service) DoFileNonLock(id string, apply func(b file.File) error) error {
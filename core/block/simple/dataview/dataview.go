@@ -180,6 +180,11 @@ func (s *Dataview) SetView(viewID string, view model.BlockContentDataviewView) e
 	v.PageLimit = view.PageLimit
 	v.DefaultTemplateId = view.DefaultTemplateId
 	v.DefaultObjectTypeId = view.DefaultObjectTypeId
+	v.GroupDateBucket = view.GroupDateBucket
+	v.GroupNumberBoundaries = view.GroupNumberBoundaries
+	v.SubGroupRelationKey = view.SubGroupRelationKey
+	v.SubGroupDateBucket = view.SubGroupDateBucket
+	v.SubGroupNumberBoundaries = view.SubGroupNumberBoundaries
 
 	return nil
 }
@@ -202,6 +207,11 @@ func (d *Dataview) SetViewFields(viewID string, view *model.BlockContentDataview
 	v.PageLimit = view.PageLimit
 	v.DefaultTemplateId = view.DefaultTemplateId
 	v.DefaultObjectTypeId = view.DefaultObjectTypeId
+	v.GroupDateBucket = view.GroupDateBucket
+	v.GroupNumberBoundaries = view.GroupNumberBoundaries
+	v.SubGroupRelationKey = view.SubGroupRelationKey
+	v.SubGroupDateBucket = view.SubGroupDateBucket
+	v.SubGroupNumberBoundaries = view.SubGroupNumberBoundaries
 
 	return nil
 }
@@ -434,6 +444,7 @@ func (d *Dataview) SetViewGroupOrder(order *model.BlockContentDataviewGroupOrder
 		if groupOrder.ViewId == order.ViewId {
 			isExist = true
 			groupOrder.ViewGroups = order.ViewGroups
+			groupOrder.SubGroups = order.SubGroups
 			break
 		}
 	}
@@ -446,7 +457,7 @@ func (d *Dataview) SetViewObjectOrder(orders []*model.BlockContentDataviewObject
 	for _, reqOrder := range orders {
 		isExist := false
 		for _, existOrder := range d.Model().GetDataview().ObjectOrders {
-			if reqOrder.ViewId == existOrder.ViewId && reqOrder.GroupId == existOrder.GroupId {
+			if reqOrder.ViewId == existOrder.ViewId && reqOrder.GroupId == existOrder.GroupId && reqOrder.SubGroupId == existOrder.SubGroupId {
 				isExist = true
 				existOrder.ObjectIds = reqOrder.ObjectIds
 				break
@@ -461,7 +472,7 @@ func (d *Dataview) SetViewObjectOrder(orders []*model.BlockContentDataviewObject
 func (d *Dataview) MoveObjectsInView(req *pb.RpcBlockDataviewObjectOrderMoveRequest) error {
 	var found bool
 	for _, order := range d.content.ObjectOrders {
-		if order.ViewId == req.ViewId && order.GroupId == req.GroupId {
+		if order.ViewId == req.ViewId && order.GroupId == req.GroupId && order.SubGroupId == req.SubGroupId {
 			order.ObjectIds = slice.Difference(order.ObjectIds, req.ObjectIds)
 
 			pos := slice.FindPos(order.ObjectIds, req.AfterId)
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/util/pbtypes"
 )
@@ -130,3 +131,29 @@ func TestDataview_MigrateFile(t *testing.T) {
 
 	assert.Equal(t, want, dv)
 }
+
+func TestDataview_MoveObjectsInView(t *testing.T) {
+	t.Run("object order is tracked per cell of sub grouped view", func(t *testing.T) {
+		// given
+		d := Dataview{content: &model.BlockContentDataview{
+			ObjectOrders: []*model.BlockContentDataviewObjectOrder{
+				{ViewId: "view", GroupId: "todo", SubGroupId: "alice", ObjectIds: []string{"obj1", "obj2"}},
+				{ViewId: "view", GroupId: "todo", SubGroupId: "bob", ObjectIds: []string{"obj3", "obj4"}},
+			},
+		}}
+
+		// when
+		err := d.MoveObjectsInView(&pb.RpcBlockDataviewObjectOrderMoveRequest{
+			ViewId:     "view",
+			GroupId:    "todo",
+			SubGroupId: "bob",
+			AfterId:    "obj4",
+			ObjectIds:  []string{"obj3"},
+		})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"obj1", "obj2"}, d.content.ObjectOrders[0].ObjectIds)
+		assert.Equal(t, []string{"obj4", "obj3"}, d.content.ObjectOrders[1].ObjectIds)
+	})
+}
@@ -191,7 +191,7 @@ func (d *Dataview) diffObjectOrders(other *Dataview, msgs []simple.EventMessage)
 		var found bool
 		var changes []*pb.EventBlockDataviewSliceChange
 		for _, order1 := range d.content.ObjectOrders {
-			if order1.ViewId == order2.ViewId && order1.GroupId == order2.GroupId {
+			if order1.ViewId == order2.ViewId && order1.GroupId == order2.GroupId && order1.SubGroupId == order2.SubGroupId {
 				found = true
 				changes = diffViewObjectOrder(order1, order2)
 				break
@@ -206,6 +206,7 @@ func (d *Dataview) diffObjectOrders(other *Dataview, msgs []simple.EventMessage)
 							Id:           other.Id,
 							ViewId:       order2.ViewId,
 							GroupId:      order2.GroupId,
+							SubGroupId:   order2.SubGroupId,
 							SliceChanges: []*pb.EventBlockDataviewSliceChange{{Op: pb.EventBlockDataview_SliceOperationAdd, Ids: order2.ObjectIds}},
 						}}}})
 		}
@@ -218,6 +219,7 @@ func (d *Dataview) diffObjectOrders(other *Dataview, msgs []simple.EventMessage)
 							Id:           other.Id,
 							ViewId:       order2.ViewId,
 							GroupId:      order2.GroupId,
+							SubGroupId:   order2.SubGroupId,
 							SliceChanges: changes,
 						}}}})
 		}
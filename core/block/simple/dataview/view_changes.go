@@ -1,6 +1,8 @@
 package dataview
 
 import (
+	"slices"
+
 	"github.com/gogo/protobuf/proto"
 
 	"github.com/anyproto/anytype-heart/pb"
@@ -19,23 +21,33 @@ func diffViewFields(a, b *model.BlockContentDataviewView) *pb.EventBlockDataview
 		a.GroupBackgroundColors == b.GroupBackgroundColors &&
 		a.PageLimit == b.PageLimit &&
 		a.DefaultTemplateId == b.DefaultTemplateId &&
-		a.DefaultObjectTypeId == b.DefaultObjectTypeId
+		a.DefaultObjectTypeId == b.DefaultObjectTypeId &&
+		a.GroupDateBucket == b.GroupDateBucket &&
+		slices.Equal(a.GroupNumberBoundaries, b.GroupNumberBoundaries) &&
+		a.SubGroupRelationKey == b.SubGroupRelationKey &&
+		a.SubGroupDateBucket == b.SubGroupDateBucket &&
+		slices.Equal(a.SubGroupNumberBoundaries, b.SubGroupNumberBoundaries)
 
 	if isEqual {
 		return nil
 	}
 	return &pb.EventBlockDataviewViewUpdateFields{
-		Type:                  b.Type,
-		Name:                  b.Name,
-		CoverRelationKey:      b.CoverRelationKey,
-		HideIcon:              b.HideIcon,
-		CardSize:              b.CardSize,
-		CoverFit:              b.CoverFit,
-		GroupRelationKey:      b.GroupRelationKey,
-		GroupBackgroundColors: b.GroupBackgroundColors,
-		PageLimit:             b.PageLimit,
-		DefaultTemplateId:     b.DefaultTemplateId,
-		DefaultObjectTypeId:   b.DefaultObjectTypeId,
+		Type:                     b.Type,
+		Name:                     b.Name,
+		CoverRelationKey:         b.CoverRelationKey,
+		HideIcon:                 b.HideIcon,
+		CardSize:                 b.CardSize,
+		CoverFit:                 b.CoverFit,
+		GroupRelationKey:         b.GroupRelationKey,
+		GroupBackgroundColors:    b.GroupBackgroundColors,
+		PageLimit:                b.PageLimit,
+		DefaultTemplateId:        b.DefaultTemplateId,
+		DefaultObjectTypeId:      b.DefaultObjectTypeId,
+		GroupDateBucket:          b.GroupDateBucket,
+		GroupNumberBoundaries:    b.GroupNumberBoundaries,
+		SubGroupRelationKey:      b.SubGroupRelationKey,
+		SubGroupDateBucket:       b.SubGroupDateBucket,
+		SubGroupNumberBoundaries: b.SubGroupNumberBoundaries,
 	}
 }
 
@@ -237,6 +249,11 @@ func (l *Dataview) ApplyViewUpdate(upd *pb.EventBlockDataviewViewUpdate) {
 		view.PageLimit = f.PageLimit
 		view.DefaultTemplateId = f.DefaultTemplateId
 		view.DefaultObjectTypeId = f.DefaultObjectTypeId
+		view.GroupDateBucket = f.GroupDateBucket
+		view.GroupNumberBoundaries = f.GroupNumberBoundaries
+		view.SubGroupRelationKey = f.SubGroupRelationKey
+		view.SubGroupDateBucket = f.SubGroupDateBucket
+		view.SubGroupNumberBoundaries = f.SubGroupNumberBoundaries
 	}
 
 	{
@@ -324,7 +341,7 @@ func diffViewObjectOrder(a, b *model.BlockContentDataviewObjectOrder) []*pb.Even
 func (l *Dataview) ApplyObjectOrderUpdate(upd *pb.EventBlockDataviewObjectOrderUpdate) {
 	var existOrder []string
 	for _, order := range l.Model().GetDataview().ObjectOrders {
-		if order.ViewId == upd.ViewId && order.GroupId == upd.GroupId {
+		if order.ViewId == upd.ViewId && order.GroupId == upd.GroupId && order.SubGroupId == upd.SubGroupId {
 			existOrder = order.ObjectIds
 		}
 	}
@@ -350,6 +367,6 @@ func (l *Dataview) ApplyObjectOrderUpdate(upd *pb.EventBlockDataviewObjectOrderU
 	changedIds := slice.ApplyChanges(existOrder, changes, slice.StringIdentity[string])
 
 	l.SetViewObjectOrder([]*model.BlockContentDataviewObjectOrder{
-		{ViewId: upd.ViewId, GroupId: upd.GroupId, ObjectIds: changedIds},
+		{ViewId: upd.ViewId, GroupId: upd.GroupId, SubGroupId: upd.SubGroupId, ObjectIds: changedIds},
 	})
 }
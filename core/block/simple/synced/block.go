@@ -0,0 +1,108 @@
+package synced
+
+import (
+	"fmt"
+
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/base"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func init() {
+	simple.RegisterCreator(NewBlock)
+}
+
+func NewBlock(b *model.Block) simple.Block {
+	if s := b.GetSynced(); s != nil {
+		return &block{
+			Base:    base.NewBase(b).(*base.Base),
+			content: s,
+		}
+	}
+	return nil
+}
+
+type Block interface {
+	simple.Block
+	FillSmartIds(ids []string) []string
+	HasSmartIds() bool
+	ApplyEvent(e *pb.EventBlockSetSynced) error
+	// Target returns ids of the object and the root block of the referenced subtree
+	Target() (objectId, blockId string)
+}
+
+type block struct {
+	*base.Base
+	content *model.BlockContentSynced
+}
+
+func (b *block) Copy() simple.Block {
+	return NewBlock(pbtypes.CopyBlock(b.Model()))
+}
+
+func (b *block) Validate() error {
+	if b.content.TargetObjectId == "" || b.content.TargetBlockId == "" {
+		return fmt.Errorf("synced block target is empty")
+	}
+	return nil
+}
+
+func (b *block) Target() (objectId, blockId string) {
+	return b.content.TargetObjectId, b.content.TargetBlockId
+}
+
+func (b *block) Diff(ob simple.Block) (msgs []simple.EventMessage, err error) {
+	other, ok := ob.(*block)
+	if !ok {
+		return nil, fmt.Errorf("can't make diff with incompatible block")
+	}
+	if msgs, err = b.Base.Diff(other); err != nil {
+		return
+	}
+
+	var hasChanges bool
+	changes := &pb.EventBlockSetSynced{
+		Id: other.Id,
+	}
+	if b.content.TargetObjectId != other.content.TargetObjectId {
+		hasChanges = true
+		changes.TargetObjectId = &pb.EventBlockSetSyncedTargetObjectId{Value: other.content.TargetObjectId}
+	}
+	if b.content.TargetBlockId != other.content.TargetBlockId {
+		hasChanges = true
+		changes.TargetBlockId = &pb.EventBlockSetSyncedTargetBlockId{Value: other.content.TargetBlockId}
+	}
+	if hasChanges {
+		msgs = append(msgs, simple.EventMessage{Msg: &pb.EventMessage{Value: &pb.EventMessageValueOfBlockSetSynced{BlockSetSynced: changes}}})
+	}
+	return
+}
+
+func (b *block) ApplyEvent(e *pb.EventBlockSetSynced) error {
+	if e.TargetObjectId != nil {
+		b.content.TargetObjectId = e.TargetObjectId.GetValue()
+	}
+	if e.TargetBlockId != nil {
+		b.content.TargetBlockId = e.TargetBlockId.GetValue()
+	}
+	return nil
+}
+
+func (b *block) FillSmartIds(ids []string) []string {
+	if b.content.TargetObjectId != "" {
+		ids = append(ids, b.content.TargetObjectId)
+	}
+	return ids
+}
+
+func (b *block) HasSmartIds() bool {
+	return b.content.TargetObjectId != ""
+}
+
+func (b *block) ReplaceLinkIds(replacer func(oldId string) (newId string)) {
+	if b.content.TargetObjectId != "" {
+		b.content.TargetObjectId = replacer(b.content.TargetObjectId)
+	}
+}
@@ -0,0 +1,67 @@
+package synced
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/simple/test"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func TestDiff(t *testing.T) {
+	testBlock := func() *block {
+		return NewBlock(&model.Block{
+			Restrictions: &model.BlockRestrictions{},
+			Content: &model.BlockContentOfSynced{Synced: &model.BlockContentSynced{
+				TargetObjectId: "object1",
+				TargetBlockId:  "block1",
+			}},
+		}).(*block)
+	}
+	t.Run("no changes", func(t *testing.T) {
+		diff, err := testBlock().Diff(testBlock())
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+	t.Run("target changed", func(t *testing.T) {
+		// given
+		b1 := testBlock()
+		b2 := testBlock()
+
+		// when
+		b2.content.TargetBlockId = "block2"
+		diff, err := b1.Diff(b2)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, test.MakeEvent(&pb.EventMessageValueOfBlockSetSynced{
+			BlockSetSynced: &pb.EventBlockSetSynced{
+				Id:            b1.Id,
+				TargetBlockId: &pb.EventBlockSetSyncedTargetBlockId{Value: "block2"},
+			},
+		}), diff)
+	})
+}
+
+func TestApplyEvent(t *testing.T) {
+	// given
+	b := NewBlock(&model.Block{
+		Content: &model.BlockContentOfSynced{Synced: &model.BlockContentSynced{}},
+	}).(*block)
+
+	// when
+	err := b.ApplyEvent(&pb.EventBlockSetSynced{
+		TargetObjectId: &pb.EventBlockSetSyncedTargetObjectId{Value: "object"},
+		TargetBlockId:  &pb.EventBlockSetSyncedTargetBlockId{Value: "block"},
+	})
+
+	// then
+	require.NoError(t, err)
+	objectId, blockId := b.Target()
+	assert.Equal(t, "object", objectId)
+	assert.Equal(t, "block", blockId)
+	assert.Equal(t, []string{"object"}, b.FillSmartIds(nil))
+}
@@ -0,0 +1,525 @@
+package block
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cheggaaa/mb/v3"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/basic"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/restriction"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/synced"
+	"github.com/anyproto/anytype-heart/core/block/undo"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// ListSyncedBlockReferences returns synced blocks referencing blocks of the object.
+// Only objects from the index of synced block references are checked
+func (s *Service) ListSyncedBlockReferences(
+	req *pb.RpcBlockSyncedListReferencesRequest,
+) (refs []*pb.RpcBlockSyncedListReferencesResponseReference, err error) {
+	if req.ObjectId == "" {
+		return nil, fmt.Errorf("objectId is empty")
+	}
+	candidates, err := s.objectStore.GetSyncedBlockReferences(req.ObjectId)
+	if err != nil {
+		return nil, fmt.Errorf("get synced block references: %w", err)
+	}
+
+	for _, id := range candidates {
+		err = cache.Do(s, id, func(sb smartblock.SmartBlock) error {
+			return sb.NewState().Iterate(func(b simple.Block) (isContinue bool) {
+				sync, ok := b.(synced.Block)
+				if !ok {
+					return true
+				}
+				targetObjectId, targetBlockId := sync.Target()
+				if targetObjectId == req.ObjectId && (req.BlockId == "" || req.BlockId == targetBlockId) {
+					refs = append(refs, &pb.RpcBlockSyncedListReferencesResponseReference{
+						ObjectId:      id,
+						BlockId:       b.Model().Id,
+						TargetBlockId: targetBlockId,
+					})
+				}
+				return true
+			})
+		})
+		if err != nil {
+			log.With("objectID", id).Warnf("failed to check synced blocks: %s", err)
+		}
+	}
+	return refs, nil
+}
+
+// DetachSyncedBlock replaces synced block with the copy of source blocks, so they could be edited independently
+func (s *Service) DetachSyncedBlock(ctx session.Context, req *pb.RpcBlockSyncedDetachRequest) (blockId string, err error) {
+	var targetObjectId, targetBlockId string
+	if err = cache.Do(s, req.ContextId, func(sb smartblock.SmartBlock) error {
+		sync, ok := sb.NewState().Pick(req.BlockId).(synced.Block)
+		if !ok {
+			return fmt.Errorf("block '%s' is not a synced block", req.BlockId)
+		}
+		targetObjectId, targetBlockId = sync.Target()
+		return nil
+	}); err != nil {
+		return
+	}
+
+	var sourceState *state.State
+	if targetObjectId != req.ContextId {
+		if err = cache.Do(s, targetObjectId, func(sb smartblock.SmartBlock) error {
+			sourceState = sb.NewState().Copy()
+			return nil
+		}); err != nil {
+			return "", fmt.Errorf("get source object: %w", err)
+		}
+	}
+
+	err = cache.DoStateCtx(s, ctx, req.ContextId, func(st *state.State, sb smartblock.SmartBlock) error {
+		src := sourceState
+		if src == nil {
+			src = st
+		}
+		if blockId, err = basic.CopyBlocks(src, st, targetBlockId); err != nil {
+			return fmt.Errorf("copy source blocks: %w", err)
+		}
+		return st.InsertTo(req.BlockId, model.Block_Replace, blockId)
+	})
+	return
+}
+
+const syncedSourceHookId = "synced-blocks-source"
+
+// syncedSources keeps source blocks of synced blocks in opened objects up to date. Source blocks are
+// added to the view of the opened object, changes of them are sent to the sessions of this object
+// and edits of them are routed to the source object
+type syncedSources struct {
+	lock sync.Mutex
+	// views by id of the opened object
+	views map[string]*syncedView
+	// registered counts views the session is registered in the source object for
+	registered map[syncedSession]int
+	// opened keeps objects opened by the session directly, the session stays registered in them
+	// when views are hidden
+	opened  map[syncedSession]struct{}
+	updates *mb.MB[syncedUpdate]
+}
+
+type syncedSession struct {
+	sessionId string
+	objectId  string
+}
+
+type syncedView struct {
+	// sessions maps ids of sessions the object is shown to to ids of source objects the session is registered in
+	sessions map[string][]string
+	// roots maps id of the source object to ids of the shown source blocks
+	roots map[string][]string
+	// blocks maps ids of all shown blocks to id of the source object
+	blocks map[string]string
+}
+
+type syncedUpdate struct {
+	objectId string
+	msgs     []*pb.EventMessage
+}
+
+func newSyncedSources() *syncedSources {
+	return &syncedSources{
+		views:      make(map[string]*syncedView),
+		registered: make(map[syncedSession]int),
+		opened:     make(map[syncedSession]struct{}),
+		updates:    mb.New[syncedUpdate](0),
+	}
+}
+
+// sourceId returns the id of the source object when all blocks are shown from the same source
+func (ss *syncedSources) sourceId(objectId string, blockIds ...string) string {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	view := ss.views[objectId]
+	if view == nil || len(blockIds) == 0 {
+		return ""
+	}
+	sourceId := view.blocks[blockIds[0]]
+	for _, id := range blockIds[1:] {
+		if view.blocks[id] != sourceId {
+			return ""
+		}
+	}
+	return sourceId
+}
+
+// add shows sources to the session and returns sources the session is not registered in for any view anymore
+func (ss *syncedSources) add(sessionId, objectId string, roots map[string][]string, blocks map[string]string) (unused []string) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	view := ss.views[objectId]
+	if view == nil {
+		view = &syncedView{sessions: make(map[string][]string)}
+		ss.views[objectId] = view
+	}
+	sourceIds := make([]string, 0, len(roots))
+	for sourceId := range roots {
+		ss.registered[syncedSession{sessionId: sessionId, objectId: sourceId}]++
+		sourceIds = append(sourceIds, sourceId)
+	}
+	prevSourceIds := view.sessions[sessionId]
+	view.sessions[sessionId] = sourceIds
+	view.roots = roots
+	view.blocks = blocks
+	return ss.releaseLocked(sessionId, prevSourceIds)
+}
+
+// remove forgets the session of the object and returns sources the session is not registered in for any view anymore
+func (ss *syncedSources) remove(sessionId, objectId string) (unused []string) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	view := ss.views[objectId]
+	if view == nil {
+		return nil
+	}
+	sourceIds, ok := view.sessions[sessionId]
+	if !ok {
+		return nil
+	}
+	delete(view.sessions, sessionId)
+	if len(view.sessions) == 0 {
+		delete(ss.views, objectId)
+	}
+	return ss.releaseLocked(sessionId, sourceIds)
+}
+
+func (ss *syncedSources) releaseLocked(sessionId string, sourceIds []string) (unused []string) {
+	for _, sourceId := range sourceIds {
+		key := syncedSession{sessionId: sessionId, objectId: sourceId}
+		ss.registered[key]--
+		if ss.registered[key] > 0 {
+			continue
+		}
+		delete(ss.registered, key)
+		if _, ok := ss.opened[key]; !ok {
+			unused = append(unused, sourceId)
+		}
+	}
+	return unused
+}
+
+// open marks the object as opened by the session directly
+func (ss *syncedSources) open(sessionId, objectId string) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.opened[syncedSession{sessionId: sessionId, objectId: objectId}] = struct{}{}
+}
+
+// close forgets the direct opening of the object and returns true if the session should stay registered
+// in the object, because blocks of it are shown by synced blocks
+func (ss *syncedSources) close(sessionId, objectId string) (keepSession bool) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	key := syncedSession{sessionId: sessionId, objectId: objectId}
+	delete(ss.opened, key)
+	return ss.registered[key] > 0
+}
+
+// isRegistered returns true if the session is registered in the object for synced blocks or opened it directly
+func (ss *syncedSources) isRegistered(sessionId, objectId string) bool {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	key := syncedSession{sessionId: sessionId, objectId: objectId}
+	if _, ok := ss.opened[key]; ok {
+		return true
+	}
+	return ss.registered[key] > 0
+}
+
+// closeSession forgets views and opened objects of the closed session
+func (ss *syncedSources) closeSession(sessionId string) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	for objectId, view := range ss.views {
+		delete(view.sessions, sessionId)
+		if len(view.sessions) == 0 {
+			delete(ss.views, objectId)
+		}
+	}
+	for key := range ss.registered {
+		if key.sessionId == sessionId {
+			delete(ss.registered, key)
+		}
+	}
+	for key := range ss.opened {
+		if key.sessionId == sessionId {
+			delete(ss.opened, key)
+		}
+	}
+}
+
+// onSourceApply runs under the lock of the source object, so events are only queued here
+func (ss *syncedSources) onSourceApply(sourceId string, info smartblock.ApplyInfo) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	for objectId, view := range ss.views {
+		roots, ok := view.roots[sourceId]
+		if !ok {
+			continue
+		}
+		shown := syncedSubtreeIds(info.State, roots)
+		var msgs []*pb.EventMessage
+		for _, msg := range info.Events {
+			for _, id := range syncedEventBlockIds(msg.Msg) {
+				_, isShown := shown[id]
+				if isShown || view.blocks[id] == sourceId {
+					msgs = append(msgs, msg.Msg)
+					break
+				}
+			}
+		}
+		for id, blockSourceId := range view.blocks {
+			if _, isShown := shown[id]; blockSourceId == sourceId && !isShown {
+				delete(view.blocks, id)
+			}
+		}
+		for id := range shown {
+			view.blocks[id] = sourceId
+		}
+		if len(msgs) > 0 {
+			if err := ss.updates.Add(context.Background(), syncedUpdate{objectId: objectId, msgs: msgs}); err != nil {
+				log.With("objectID", objectId).Warnf("failed to queue synced blocks update: %s", err)
+			}
+		}
+	}
+}
+
+func (ss *syncedSources) run(ctx context.Context, picker cache.ObjectGetter) {
+	for {
+		update, err := ss.updates.WaitOne(ctx)
+		if err != nil {
+			return
+		}
+		err = cache.Do(picker, update.objectId, func(sb smartblock.SmartBlock) error {
+			sb.SendEvent(update.msgs)
+			return nil
+		})
+		if err != nil {
+			log.With("objectID", update.objectId).Warnf("failed to send synced blocks update: %s", err)
+		}
+	}
+}
+
+// CloseSession forgets synced blocks shown to the closed session
+func (s *Service) CloseSession(token string) {
+	s.syncedSources.closeSession(token)
+}
+
+// showSyncedSources adds blocks of source objects to the view of the opened object and subscribes
+// the session to changes of the sources
+func (s *Service) showSyncedSources(sctx session.Context, objectId string, obj *model.ObjectView) {
+	s.syncedSources.show(s, sctx, objectId, obj, func(sb smartblock.SmartBlock) error {
+		if ah, ok := sb.(restriction.AccessHolder); ok {
+			return s.restriction.CheckAccessOpen(ah)
+		}
+		return nil
+	})
+}
+
+// hideSyncedSources unsubscribes the session from sources that are not shown to it anymore
+func (s *Service) hideSyncedSources(sctx session.Context, objectId string) {
+	s.syncedSources.hide(s, sctx.ID(), objectId)
+}
+
+func (ss *syncedSources) show(
+	picker cache.ObjectGetter,
+	sctx session.Context,
+	objectId string,
+	obj *model.ObjectView,
+	checkAccess func(sb smartblock.SmartBlock) error,
+) {
+	roots := make(map[string][]string)
+	existing := make(map[string]struct{}, len(obj.Blocks))
+	for _, b := range obj.Blocks {
+		existing[b.Id] = struct{}{}
+		if sync := b.GetSynced(); sync != nil && sync.TargetObjectId != "" && sync.TargetObjectId != objectId {
+			roots[sync.TargetObjectId] = append(roots[sync.TargetObjectId], sync.TargetBlockId)
+		}
+	}
+	if len(roots) == 0 {
+		return
+	}
+
+	blocks := make(map[string]string)
+	for sourceId, rootIds := range roots {
+		err := cache.Do(picker, sourceId, func(sb smartblock.SmartBlock) error {
+			if err := checkAccess(sb); err != nil {
+				return err
+			}
+			sb.RegisterSession(sctx)
+			sb.AddHookOnce(syncedSourceHookId, func(info smartblock.ApplyInfo) error {
+				ss.onSourceApply(sourceId, info)
+				return nil
+			}, smartblock.HookAfterApply)
+
+			st := sb.NewState()
+			for id := range syncedSubtreeIds(st, rootIds) {
+				blocks[id] = sourceId
+				if _, ok := existing[id]; ok {
+					continue
+				}
+				existing[id] = struct{}{}
+				obj.Blocks = append(obj.Blocks, pbtypes.CopyBlock(st.Pick(id).Model()))
+			}
+			return nil
+		})
+		if err != nil {
+			log.With("objectID", sourceId).Warnf("failed to show synced blocks: %s", err)
+			delete(roots, sourceId)
+		}
+	}
+	ss.unregister(picker, sctx.ID(), ss.add(sctx.ID(), objectId, roots, blocks))
+}
+
+func (ss *syncedSources) hide(picker cache.ObjectGetter, sessionId, objectId string) {
+	ss.unregister(picker, sessionId, ss.remove(sessionId, objectId))
+}
+
+// unregister removes the session from sources. Sources are not closed for the session, so close hooks are not called
+// for objects opened by the session directly
+func (ss *syncedSources) unregister(picker cache.ObjectGetter, sessionId string, sourceIds []string) {
+	for _, sourceId := range sourceIds {
+		err := cache.Do(picker, sourceId, func(sb smartblock.SmartBlock) error {
+			// the source could be shown again or opened before its lock is taken
+			if !ss.isRegistered(sessionId, sourceId) {
+				sb.UnregisterSession(sessionId)
+			}
+			return nil
+		})
+		if err != nil {
+			log.With("objectID", sourceId).Warnf("failed to unregister session in synced blocks source: %s", err)
+		}
+	}
+}
+
+// doSynced applies the edit to the source object when blocks are shown from it by synced blocks. The edit is marked
+// in the history of the opened object, so it's undone and redone from the opened object in the right order
+func doSynced[t any](s *Service, contextId string, blockIds []string, apply func(b t) error) error {
+	sourceId := s.syncedSources.sourceId(contextId, blockIds...)
+	if sourceId == "" {
+		return cache.Do(s, contextId, apply)
+	}
+	var changed bool
+	err := cache.Do(s, sourceId, func(sb smartblock.SmartBlock) error {
+		b, ok := sb.(t)
+		if !ok {
+			var dummy = new(t)
+			return fmt.Errorf("the interface %T is not implemented in %T", dummy, sb)
+		}
+		undoBefore, redoBefore := sb.History().Counters()
+		if err := apply(b); err != nil {
+			return err
+		}
+		undoAfter, redoAfter := sb.History().Counters()
+		changed = undoAfter != undoBefore || redoAfter != redoBefore
+		return nil
+	})
+	if err != nil || !changed {
+		return err
+	}
+	if err = cache.Do(s, contextId, func(sb smartblock.SmartBlock) error {
+		sb.History().Add(undo.Action{SyncedObjectId: sourceId})
+		return nil
+	}); err != nil {
+		log.With("objectID", contextId).Warnf("failed to add synced blocks change to history: %s", err)
+	}
+	return nil
+}
+
+func doSyncedStateCtx[t any](
+	s *Service, ctx session.Context, contextId string, blockIds []string, apply func(st *state.State, b t) error,
+) error {
+	return doSynced(s, contextId, blockIds, func(sb smartblock.SmartBlock) error {
+		b, ok := sb.(t)
+		if !ok {
+			var dummy = new(t)
+			return fmt.Errorf("the interface %T is not implemented in %T", dummy, sb)
+		}
+		st := sb.NewStateCtx(ctx)
+		if err := apply(st, b); err != nil {
+			return fmt.Errorf("apply func: %w", err)
+		}
+		return sb.Apply(st)
+	})
+}
+
+// undoRedoSynced undoes or redoes the change of synced blocks in the source object,
+// counters of the history of the opened object are returned
+func (s *Service) undoRedoSynced(info basic.HistoryInfo, do func(h basic.IHistory) (basic.HistoryInfo, error)) (basic.HistoryInfo, error) {
+	err := cache.Do(s, info.SyncedObjectId, func(h basic.IHistory) error {
+		sourceInfo, err := do(h)
+		info.CarriageState = sourceInfo.CarriageState
+		return err
+	})
+	return info, err
+}
+
+func syncedSubtreeIds(st *state.State, rootIds []string) map[string]struct{} {
+	ids := make(map[string]struct{})
+	for _, rootId := range rootIds {
+		if st.Pick(rootId) == nil {
+			continue
+		}
+		ids[rootId] = struct{}{}
+		for _, b := range st.Descendants(rootId) {
+			ids[b.Model().Id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// syncedEventBlockIds returns ids of blocks changed by the event
+func syncedEventBlockIds(msg *pb.EventMessage) []string {
+	switch v := msg.Value.(type) {
+	case *pb.EventMessageValueOfBlockAdd:
+		ids := make([]string, 0, len(v.BlockAdd.Blocks))
+		for _, b := range v.BlockAdd.Blocks {
+			ids = append(ids, b.Id)
+		}
+		return ids
+	case *pb.EventMessageValueOfBlockDelete:
+		return v.BlockDelete.BlockIds
+	case *pb.EventMessageValueOfBlockSetChildrenIds:
+		return []string{v.BlockSetChildrenIds.Id}
+	case *pb.EventMessageValueOfBlockSetText:
+		return []string{v.BlockSetText.Id}
+	case *pb.EventMessageValueOfBlockSetFields:
+		return []string{v.BlockSetFields.Id}
+	case *pb.EventMessageValueOfBlockSetBackgroundColor:
+		return []string{v.BlockSetBackgroundColor.Id}
+	case *pb.EventMessageValueOfBlockSetAlign:
+		return []string{v.BlockSetAlign.Id}
+	case *pb.EventMessageValueOfBlockSetVerticalAlign:
+		return []string{v.BlockSetVerticalAlign.Id}
+	case *pb.EventMessageValueOfBlockSetFile:
+		return []string{v.BlockSetFile.Id}
+	case *pb.EventMessageValueOfBlockSetLink:
+		return []string{v.BlockSetLink.Id}
+	case *pb.EventMessageValueOfBlockSetBookmark:
+		return []string{v.BlockSetBookmark.Id}
+	case *pb.EventMessageValueOfBlockSetDiv:
+		return []string{v.BlockSetDiv.Id}
+	case *pb.EventMessageValueOfBlockSetLatex:
+		return []string{v.BlockSetLatex.Id}
+	case *pb.EventMessageValueOfBlockSetRelation:
+		return []string{v.BlockSetRelation.Id}
+	case *pb.EventMessageValueOfBlockSetTableRow:
+		return []string{v.BlockSetTableRow.Id}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/anyproto/anytype-heart/core/block/cache/mock_cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+type syncedFixture struct {
+	*syncedSources
+	picker *mock_cache.MockObjectGetter
+	source *smarttest.SmartTest
+	sctx   session.Context
+}
+
+func newSyncedFixture(t *testing.T) *syncedFixture {
+	source := smarttest.New("source")
+	source.AddBlock(simple.New(&model.Block{Id: "source", ChildrenIds: []string{"list"}})).
+		AddBlock(simple.New(&model.Block{Id: "list", ChildrenIds: []string{"item"}})).
+		AddBlock(simple.New(&model.Block{Id: "item"}))
+	picker := mock_cache.NewMockObjectGetter(t)
+	picker.EXPECT().GetObject(mock.Anything, "source").Return(source, nil)
+	return &syncedFixture{
+		syncedSources: newSyncedSources(),
+		picker:        picker,
+		source:        source,
+		sctx:          session.NewContext(session.WithSession("token1")),
+	}
+}
+
+func (fx *syncedFixture) show(objectId string) *model.ObjectView {
+	obj := &model.ObjectView{Blocks: []*model.Block{
+		{Id: objectId, ChildrenIds: []string{"synced"}},
+		{Id: "synced", Content: &model.BlockContentOfSynced{Synced: &model.BlockContentSynced{
+			TargetObjectId: "source",
+			TargetBlockId:  "list",
+		}}},
+	}}
+	fx.syncedSources.show(fx.picker, fx.sctx, objectId, obj, func(smartblock.SmartBlock) error { return nil })
+	return obj
+}
+
+// openSource registers the session in the source as opening of the object does
+func (fx *syncedFixture) openSource() {
+	fx.source.RegisterSession(fx.sctx)
+	fx.syncedSources.open(fx.sctx.ID(), "source")
+}
+
+func TestSyncedSources_ShowHide(t *testing.T) {
+	t.Run("source blocks are shown and session is registered in the source", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+
+		// when
+		obj := fx.show("object")
+
+		// then
+		assert.Len(t, obj.Blocks, 4)
+		assert.True(t, fx.source.HasSession("token1"))
+		assert.Equal(t, "source", fx.sourceId("object", "list", "item"))
+		assert.Empty(t, fx.sourceId("object", "synced"))
+	})
+
+	t.Run("session is unregistered when the last view showing the source is hidden", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+		fx.show("object1")
+		fx.show("object2")
+
+		// when
+		fx.hide(fx.picker, "token1", "object1")
+
+		// then
+		assert.True(t, fx.source.HasSession("token1"))
+
+		// when
+		fx.hide(fx.picker, "token1", "object2")
+
+		// then
+		assert.False(t, fx.source.HasSession("token1"))
+		assert.Empty(t, fx.registered)
+	})
+
+	t.Run("source opened directly before showing stays registered when view is hidden", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+		fx.openSource()
+		fx.show("object")
+
+		// when
+		fx.hide(fx.picker, "token1", "object")
+
+		// then
+		assert.True(t, fx.source.HasSession("token1"))
+	})
+
+	t.Run("source opened directly after showing stays registered when view is hidden", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+		fx.show("object")
+		fx.openSource()
+
+		// when
+		fx.hide(fx.picker, "token1", "object")
+
+		// then
+		assert.True(t, fx.source.HasSession("token1"))
+	})
+
+	t.Run("closing source opened directly keeps session registered for shown view", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+		fx.openSource()
+		fx.show("object")
+
+		// when
+		keepSession := fx.close("token1", "source")
+
+		// then
+		assert.True(t, keepSession)
+
+		// when
+		fx.hide(fx.picker, "token1", "object")
+
+		// then
+		assert.False(t, fx.source.HasSession("token1"))
+	})
+
+	t.Run("closed session is forgotten", func(t *testing.T) {
+		// given
+		fx := newSyncedFixture(t)
+		fx.openSource()
+		fx.show("object")
+
+		// when
+		fx.closeSession("token1")
+
+		// then
+		assert.Empty(t, fx.views)
+		assert.Empty(t, fx.registered)
+		assert.Empty(t, fx.opened)
+	})
+}
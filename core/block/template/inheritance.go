@@ -0,0 +1,155 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/basic"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/slice"
+)
+
+const (
+	// SectionBlockField marks root-level block of a template, that is replaced by the block
+	// with the same section value of a template extending it
+	SectionBlockField = "templateSection"
+	// SnippetBlockField contains id of the snippet object the block was inserted from
+	SnippetBlockField = "templateSnippetId"
+
+	maxInheritanceDepth = 10
+)
+
+// notInheritedRelationKeys describe template itself and are never taken from the parent template
+var notInheritedRelationKeys = []domain.RelationKey{
+	bundle.RelationKeyId, bundle.RelationKeySpaceId, bundle.RelationKeyType,
+	bundle.RelationKeyTemplateParent, bundle.RelationKeyTargetObjectType,
+	bundle.RelationKeyTemplateIsBundled, bundle.RelationKeySourceObject,
+	bundle.RelationKeyOrigin, bundle.RelationKeyAddedDate,
+}
+
+// applyParentTemplates merges the chain of parent templates into st, starting from the most distant one
+func (s *service) applyParentTemplates(st *state.State, templateId string) error {
+	visited := []string{templateId}
+	parentId := pbtypes.GetString(st.Details(), bundle.RelationKeyTemplateParent.String())
+	if parentId == "" {
+		return nil
+	}
+
+	var parents []*state.State
+	for parentId != "" {
+		if slice.FindPos(visited, parentId) != -1 {
+			return fmt.Errorf("template inheritance cycle: %v -> %s", visited, parentId)
+		}
+		if len(visited) > maxInheritanceDepth {
+			return fmt.Errorf("template inheritance is deeper than %d levels", maxInheritanceDepth)
+		}
+		visited = append(visited, parentId)
+
+		var parentState *state.State
+		err := cache.Do(s.picker, parentId, func(sb smartblock.SmartBlock) error {
+			if !lo.Contains(sb.ObjectTypeKeys(), bundle.TypeKeyTemplate) {
+				return fmt.Errorf("parent object '%s' is not a template", parentId)
+			}
+			parentState = sb.NewState().Copy()
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("get parent template: %w", err)
+		}
+		parents = append(parents, parentState)
+		parentId = pbtypes.GetString(parentState.Details(), bundle.RelationKeyTemplateParent.String())
+	}
+
+	base := parents[len(parents)-1]
+	for i := len(parents) - 2; i >= 0; i-- {
+		if err := inheritTemplateState(base, parents[i]); err != nil {
+			return err
+		}
+		base = parents[i]
+	}
+	return inheritTemplateState(base, st)
+}
+
+// inheritTemplateState merges parent template into the child state. Root blocks of the parent go first,
+// parent blocks marked with a section are replaced by child blocks of the same section.
+// Other child blocks are appended after the parent ones. Header of the child is kept as is.
+// Details missing in the child are taken from the parent
+func inheritTemplateState(parent, child *state.State) error {
+	childRoot := child.Get(child.RootId())
+	parentRoot := parent.Pick(parent.RootId())
+	if childRoot == nil || parentRoot == nil {
+		return fmt.Errorf("root block not found")
+	}
+
+	sections := make(map[string]string)
+	for _, id := range childRoot.Model().ChildrenIds {
+		if section := blockSection(child, id); section != "" {
+			sections[section] = id
+		}
+	}
+
+	var (
+		childrenIds []string
+		usedIds     = make(map[string]struct{})
+	)
+	if child.Exists(state.HeaderLayoutID) {
+		childrenIds = append(childrenIds, state.HeaderLayoutID)
+		usedIds[state.HeaderLayoutID] = struct{}{}
+	}
+	for _, id := range parentRoot.Model().ChildrenIds {
+		if state.IsRequiredBlockId(id) {
+			continue
+		}
+		if childId, ok := sections[blockSection(parent, id)]; ok {
+			childrenIds = append(childrenIds, childId)
+			usedIds[childId] = struct{}{}
+			continue
+		}
+		copyId, err := basic.CopyBlocks(parent, child, id)
+		if err != nil {
+			return fmt.Errorf("copy block of parent template: %w", err)
+		}
+		childrenIds = append(childrenIds, copyId)
+	}
+	for _, id := range childRoot.Model().ChildrenIds {
+		if _, ok := usedIds[id]; !ok {
+			childrenIds = append(childrenIds, id)
+		}
+	}
+	childRoot.Model().ChildrenIds = childrenIds
+
+	inheritDetails(parent, child)
+	return nil
+}
+
+func inheritDetails(parent, child *state.State) {
+	childDetails := child.Details()
+	for key, value := range parent.Details().GetFields() {
+		if lo.Contains(notInheritedRelationKeys, domain.RelationKey(key)) {
+			continue
+		}
+		if !pbtypes.IsEmptyValueOrAbsent(childDetails, key) {
+			continue
+		}
+		child.SetDetail(key, pbtypes.CopyVal(value))
+	}
+	for _, link := range parent.GetRelationLinks() {
+		if !child.HasRelation(link.Key) {
+			child.AddRelationLinks(link)
+		}
+	}
+}
+
+func blockSection(st *state.State, id string) string {
+	b := st.Pick(id)
+	if b == nil {
+		return ""
+	}
+	return pbtypes.GetString(b.Model().GetFields(), SectionBlockField)
+}
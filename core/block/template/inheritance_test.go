@@ -0,0 +1,63 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func textBlock(id, text, section string) simple.Block {
+	b := &model.Block{Id: id, Content: &model.BlockContentOfText{Text: &model.BlockContentText{Text: text}}}
+	if section != "" {
+		b.Fields = &types.Struct{Fields: map[string]*types.Value{SectionBlockField: pbtypes.String(section)}}
+	}
+	return simple.New(b)
+}
+
+func TestInheritTemplateState(t *testing.T) {
+	// given
+	parent := state.NewDoc("parent", map[string]simple.Block{
+		"parent":             simple.New(&model.Block{Id: "parent", ChildrenIds: []string{state.HeaderLayoutID, "intro", "agenda", "footer"}}),
+		state.HeaderLayoutID: simple.New(&model.Block{Id: state.HeaderLayoutID}),
+		"intro":              textBlock("intro", "Intro", ""),
+		"agenda":             textBlock("agenda", "Agenda", "agenda"),
+		"footer":             textBlock("footer", "Footer", ""),
+	}).NewState()
+	parent.SetDetail(bundle.RelationKeyDescription.String(), pbtypes.String("Parent description"))
+	parent.SetDetail(bundle.RelationKeyName.String(), pbtypes.String("Parent"))
+	parent.SetDetail(bundle.RelationKeyTargetObjectType.String(), pbtypes.String("parentType"))
+
+	child := state.NewDoc("child", map[string]simple.Block{
+		"child":              simple.New(&model.Block{Id: "child", ChildrenIds: []string{state.HeaderLayoutID, "childAgenda", "notes"}}),
+		state.HeaderLayoutID: simple.New(&model.Block{Id: state.HeaderLayoutID}),
+		"childAgenda":        textBlock("childAgenda", "Sprint agenda", "agenda"),
+		"notes":              textBlock("notes", "Notes", ""),
+	}).NewState()
+	child.SetDetail(bundle.RelationKeyName.String(), pbtypes.String("Child"))
+
+	// when
+	err := inheritTemplateState(parent, child)
+
+	// then
+	require.NoError(t, err)
+	childrenIds := child.Pick("child").Model().ChildrenIds
+	require.Len(t, childrenIds, 5)
+	assert.Equal(t, state.HeaderLayoutID, childrenIds[0])
+	assert.Equal(t, "Intro", child.Pick(childrenIds[1]).Model().GetText().Text)
+	assert.NotEqual(t, "intro", childrenIds[1])
+	assert.Equal(t, "childAgenda", childrenIds[2])
+	assert.Equal(t, "Footer", child.Pick(childrenIds[3]).Model().GetText().Text)
+	assert.Equal(t, "notes", childrenIds[4])
+
+	assert.Equal(t, "Child", pbtypes.GetString(child.Details(), bundle.RelationKeyName.String()))
+	assert.Equal(t, "Parent description", pbtypes.GetString(child.Details(), bundle.RelationKeyDescription.String()))
+	assert.Empty(t, pbtypes.GetString(child.Details(), bundle.RelationKeyTargetObjectType.String()))
+}
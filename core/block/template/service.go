@@ -10,6 +10,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/samber/lo"
 
+	"github.com/anyproto/anytype-heart/core/anytype/account"
 	"github.com/anyproto/anytype-heart/core/block/cache"
 	"github.com/anyproto/anytype-heart/core/block/editor/converter"
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
@@ -19,6 +20,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/object/idresolver"
 	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
 	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/session"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	coresb "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
@@ -59,17 +61,20 @@ type Service interface {
 
 	TemplateExportAll(ctx context.Context, path string) (string, error)
 
+	SnippetInsert(ctx session.Context, req pb.RpcTemplateSnippetInsertRequest) (blockIds []string, err error)
+
 	app.Component
 }
 
 type service struct {
-	picker       cache.ObjectGetter
-	store        objectstore.ObjectStore
-	spaceService space.Service
-	creator      objectcreator.Service
-	resolver     idresolver.Resolver
-	exporter     export.Export
-	converter    converter.LayoutConverter
+	picker         cache.ObjectGetter
+	store          objectstore.ObjectStore
+	spaceService   space.Service
+	creator        objectcreator.Service
+	resolver       idresolver.Resolver
+	exporter       export.Export
+	converter      converter.LayoutConverter
+	accountService account.Service
 }
 
 func New() Service {
@@ -88,10 +93,13 @@ func (s *service) Init(a *app.App) error {
 	s.resolver = a.MustComponent(idresolver.CName).(idresolver.Resolver)
 	s.exporter = a.MustComponent(export.CName).(export.Export)
 	s.converter = app.MustComponent[converter.LayoutConverter](a)
+	s.accountService = app.MustComponent[account.Service](a)
 	return nil
 }
 
 // CreateTemplateStateWithDetails creates clone of template object state with empty localDetails and updated objectTypes.
+// Template variables like {{today}} in text blocks and details are substituted with actual values,
+// the object is not created if they could not be resolved.
 // Blank template is created in case template object is deleted or blank/empty templateIв is provided
 func (s *service) CreateTemplateStateWithDetails(
 	templateId string,
@@ -108,6 +116,13 @@ func (s *service) CreateTemplateStateWithDetails(
 	}
 	targetDetails := extractTargetDetails(details, targetState.Details())
 	targetState.AddDetails(targetDetails)
+	resolver := s.newVariableResolver(templateId, targetState.Details())
+	if err = resolver.resolveState(targetState); err != nil {
+		return nil, fmt.Errorf("resolve template variables: %w", err)
+	}
+	if resolver.counter != 0 {
+		targetState.SetDetailAndBundledRelation(bundle.RelationKeyTemplateCounter, pbtypes.Int64(resolver.counter))
+	}
 	targetState.BlocksInit(targetState)
 
 	return targetState, nil
@@ -157,6 +172,7 @@ func (s *service) createCustomTemplateState(templateId string) (targetState *sta
 			bundle.RelationKeyTemplateIsBundled.String(),
 			bundle.RelationKeyOrigin.String(),
 			bundle.RelationKeyAddedDate.String(),
+			bundle.RelationKeyTemplateCounter.String(),
 		)
 		targetState.SetDetailAndBundledRelation(bundle.RelationKeySourceObject, pbtypes.String(sb.Id()))
 		// original created timestamp is used to set creationDate for imported objects, not for template-based objects
@@ -167,6 +183,13 @@ func (s *service) createCustomTemplateState(templateId string) (targetState *sta
 	if errors.Is(err, spacestorage.ErrTreeStorageAlreadyDeleted) {
 		return s.createBlankTemplateState(model.ObjectType_basic), nil
 	}
+	if err != nil {
+		return
+	}
+	if err = s.applyParentTemplates(targetState, templateId); err != nil {
+		return nil, fmt.Errorf("apply parent templates: %w", err)
+	}
+	targetState.RemoveDetail(bundle.RelationKeyTemplateParent.String())
 	return
 }
 
@@ -0,0 +1,94 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/basic"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// SnippetInsert copies content blocks of the snippet object (all root blocks except header) into the object.
+// In synced mode synced blocks referencing snippet blocks are inserted instead, so further changes of the snippet
+// are propagated to the object. Inserted root blocks are marked with id of the snippet
+func (s *service) SnippetInsert(ctx session.Context, req pb.RpcTemplateSnippetInsertRequest) (blockIds []string, err error) {
+	if req.SnippetId == "" || req.SnippetId == req.ContextId {
+		return nil, fmt.Errorf("invalid snippet id")
+	}
+
+	var snippetState *state.State
+	if err = cache.Do(s.picker, req.SnippetId, func(sb smartblock.SmartBlock) error {
+		snippetState = sb.NewState().Copy()
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("get snippet: %w", err)
+	}
+
+	contentIds := snippetContentIds(snippetState)
+	if len(contentIds) == 0 {
+		return nil, fmt.Errorf("snippet has no content blocks")
+	}
+
+	err = cache.DoStateCtx(s.picker, ctx, req.ContextId, func(st *state.State, sb basic.Duplicatable) error {
+		targetId, position := req.TargetId, req.Position
+		if targetId == "" {
+			targetId, position = st.RootId(), model.Block_Inner
+		}
+		if req.Synced {
+			blockIds, err = insertSyncedBlocks(st, targetId, position, req.SnippetId, contentIds)
+		} else {
+			blockIds, err = sb.Duplicate(snippetState, st, targetId, position, contentIds)
+		}
+		if err != nil {
+			return err
+		}
+		for _, id := range blockIds {
+			b := st.Get(id)
+			b.Model().Fields = pbtypes.StructMerge(b.Model().GetFields(), &types.Struct{Fields: map[string]*types.Value{
+				SnippetBlockField: pbtypes.String(req.SnippetId),
+			}}, false)
+		}
+		return nil
+	})
+	return
+}
+
+func snippetContentIds(st *state.State) []string {
+	root := st.Pick(st.RootId())
+	if root == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(root.Model().ChildrenIds))
+	for _, id := range root.Model().ChildrenIds {
+		if !state.IsRequiredBlockId(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func insertSyncedBlocks(st *state.State, targetId string, position model.BlockPosition, snippetId string, sourceIds []string) (blockIds []string, err error) {
+	for _, sourceId := range sourceIds {
+		b := simple.New(&model.Block{
+			Content: &model.BlockContentOfSynced{Synced: &model.BlockContentSynced{
+				TargetObjectId: snippetId,
+				TargetBlockId:  sourceId,
+			}},
+		})
+		st.Add(b)
+		if err = st.InsertTo(targetId, position, b.Model().Id); err != nil {
+			return nil, err
+		}
+		targetId, position = b.Model().Id, model.Block_Bottom
+		blockIds = append(blockIds, b.Model().Id)
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func TestInsertSyncedBlocks(t *testing.T) {
+	// given
+	st := state.NewDoc("object", map[string]simple.Block{
+		"object": simple.New(&model.Block{Id: "object", ChildrenIds: []string{"text"}}),
+		"text":   textBlock("text", "Text", ""),
+	}).NewState()
+
+	// when
+	blockIds, err := insertSyncedBlocks(st, "text", model.Block_Bottom, "snippet", []string{"checklist", "notes"})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, blockIds, 2)
+	assert.Equal(t, append([]string{"text"}, blockIds...), st.Pick("object").Model().ChildrenIds)
+	for i, sourceId := range []string{"checklist", "notes"} {
+		sync := st.Pick(blockIds[i]).Model().GetSynced()
+		require.NotNil(t, sync)
+		assert.Equal(t, "snippet", sync.TargetObjectId)
+		assert.Equal(t, sourceId, sync.TargetBlockId)
+	}
+}
@@ -0,0 +1,396 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/basic"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/text"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	textutil "github.com/anyproto/anytype-heart/util/text"
+)
+
+// dateVariableLayouts are layouts of date variables by the name of the format, names follow the date formats of
+// dataview relations. ISO format is used by default, because it doesn't depend on the language, e.g. {{today:short}}
+var dateVariableLayouts = map[string]string{
+	"":                   "2006-01-02",
+	"iso":                "2006-01-02",
+	"short":              "02/01/2006",
+	"shortUS":            "01/02/2006",
+	"monthAbbrBeforeDay": "Jan 2, 2006",
+	"monthAbbrAfterDay":  "2 Jan 2006",
+}
+
+const timeVariableLayout = "15:04"
+
+// variableRegexp matches template variables like {{today}}, {{now+7d}} or {{relation:status}}
+var variableRegexp = regexp.MustCompile(`\{\{\s*([a-zA-Z]+)(?::([a-zA-Z0-9_\-]+))?([+-]\d+[hdwmy])?\s*\}\}`)
+
+// variableResolver substitutes template variables with values of the object being created:
+//   - {{today}}, {{now}} - current date or date with time, optionally formatted and shifted, e.g. {{today:short+7d}} or {{now-2h}}
+//   - {{creator}} - name of the current user
+//   - {{title}} - name of the object
+//   - {{relation:key}} - value of the object relation, object ids are replaced with their names
+//   - {{counter}} - sequential number of objects of the type created from templates
+//
+// Details of date and number relations get the timestamp or the number instead of the text
+type variableResolver struct {
+	now     time.Time
+	details *types.Struct
+
+	creatorName    func() (string, error)
+	nextCounter    func() (int64, error)
+	objectName     func(id string) string
+	relationFormat func(key string) model.RelationFormat
+
+	// counter is the number of the object, it is requested only once and kept in the object,
+	// so applying another template to the object doesn't increment the counter of the type
+	counter int64
+}
+
+func (r *variableResolver) hasVariables(s string) bool {
+	return strings.Contains(s, "{{") && variableRegexp.MatchString(s)
+}
+
+// resolveState substitutes variables in string details and in text blocks of the state, including nested blocks and table cells.
+// Title and description blocks are filled from details, so they are updated via details
+func (r *variableResolver) resolveState(st *state.State) error {
+	details := st.Details()
+	for key, value := range details.GetFields() {
+		raw, ok := value.GetKind().(*types.Value_StringValue)
+		if !ok || !r.hasVariables(raw.StringValue) {
+			continue
+		}
+		resolved, err := r.resolveDetail(key, raw.StringValue)
+		if err != nil {
+			return fmt.Errorf("resolve detail %s: %w", key, err)
+		}
+		st.SetDetail(key, resolved)
+	}
+
+	var blockIds []string
+	err := st.Iterate(func(b simple.Block) (isContinue bool) {
+		if _, isDetailsBlock := b.(text.DetailsBlock); isDetailsBlock {
+			return true
+		}
+		if tb, ok := b.(text.Block); ok && r.hasVariables(tb.GetText()) {
+			blockIds = append(blockIds, b.Model().Id)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range blockIds {
+		tb, ok := st.Get(id).(text.Block)
+		if !ok {
+			continue
+		}
+		resolved, replacements, err := r.resolveText(tb.GetText())
+		if err != nil {
+			return err
+		}
+		marks := tb.Model().GetText().GetMarks()
+		if marks == nil {
+			marks = &model.BlockContentTextMarks{}
+		}
+		for _, repl := range replacements {
+			shiftMarks(marks.Marks, repl)
+		}
+		tb.SetText(resolved, marks)
+	}
+	return nil
+}
+
+// resolveDetail returns the value of the detail. Variables of date and number relations must be the whole value
+func (r *variableResolver) resolveDetail(key, raw string) (*types.Value, error) {
+	format := model.RelationFormat_longtext
+	if r.relationFormat != nil {
+		format = r.relationFormat(key)
+	}
+	switch format {
+	case model.RelationFormat_date:
+		t, err := r.timeValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		return pbtypes.Int64(t.Unix()), nil
+	case model.RelationFormat_number:
+		resolved, _, err := r.resolveText(raw)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(resolved), 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", resolved)
+		}
+		return pbtypes.Float64(n), nil
+	}
+	resolved, _, err := r.resolveText(raw)
+	if err != nil {
+		return nil, err
+	}
+	return pbtypes.String(resolved), nil
+}
+
+// timeValue returns the time of the single date variable, {{today}} is the start of the day
+func (r *variableResolver) timeValue(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	m := variableRegexp.FindStringSubmatch(raw)
+	if m == nil || m[0] != raw {
+		return time.Time{}, fmt.Errorf("'%s' is not a date variable", raw)
+	}
+	name, arg, offset := m[1], m[2], m[3]
+	switch name {
+	case "today":
+		y, mon, d := r.now.Date()
+		return shiftTime(time.Date(y, mon, d, 0, 0, 0, 0, r.now.Location()), offset)
+	case "now":
+		return shiftTime(r.now, offset)
+	case "relation":
+		if v, ok := pbtypes.Get(r.details, arg).GetKind().(*types.Value_NumberValue); ok {
+			return shiftTime(time.Unix(int64(v.NumberValue), 0), offset)
+		}
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a date variable", raw)
+}
+
+// textReplacement describes replaced range of text in UTF-16 code units, as used by marks
+type textReplacement struct {
+	from, to int32
+	newLen   int32
+}
+
+// resolveText returns text with substituted variables and list of replacements ordered from the end of text
+func (r *variableResolver) resolveText(s string) (string, []textReplacement, error) {
+	matches := variableRegexp.FindAllStringSubmatchIndex(s, -1)
+	var replacements []textReplacement
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		name := s[m[2]:m[3]]
+		var arg, offset string
+		if m[4] != -1 {
+			arg = s[m[4]:m[5]]
+		}
+		if m[6] != -1 {
+			offset = s[m[6]:m[7]]
+		}
+
+		value, ok, err := r.variableValue(name, arg, offset)
+		if err != nil {
+			return "", nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		from := int32(textutil.UTF16RuneCountString(s[:m[0]]))
+		to := int32(textutil.UTF16RuneCountString(s[:m[1]]))
+		replacements = append(replacements, textReplacement{
+			from:   from,
+			to:     to,
+			newLen: int32(textutil.UTF16RuneCountString(value)),
+		})
+		s = s[:m[0]] + value + s[m[1]:]
+	}
+	return s, replacements, nil
+}
+
+func (r *variableResolver) variableValue(name, arg, offset string) (value string, ok bool, err error) {
+	switch name {
+	case "today", "now":
+		layout, ok := dateVariableLayouts[arg]
+		if !ok {
+			return "", false, nil
+		}
+		if name == "now" {
+			layout += " " + timeVariableLayout
+		}
+		t, err := shiftTime(r.now, offset)
+		if err != nil {
+			return "", false, err
+		}
+		return t.Format(layout), true, nil
+	case "creator":
+		if r.creatorName == nil {
+			return "", false, nil
+		}
+		name, err := r.creatorName()
+		if err != nil {
+			return "", false, fmt.Errorf("get creator name: %w", err)
+		}
+		return name, true, nil
+	case "title":
+		return pbtypes.GetString(r.details, bundle.RelationKeyName.String()), true, nil
+	case "relation":
+		if arg == "" {
+			return "", false, nil
+		}
+		return r.relationValue(arg), true, nil
+	case "counter":
+		if r.nextCounter == nil {
+			return "", false, nil
+		}
+		// the same counter value is used for all occurrences within the object
+		if r.counter == 0 {
+			if r.counter, err = r.nextCounter(); err != nil {
+				return "", false, fmt.Errorf("get next counter value: %w", err)
+			}
+		}
+		return strconv.FormatInt(r.counter, 10), true, nil
+	}
+	return "", false, nil
+}
+
+func (r *variableResolver) relationValue(key string) string {
+	value := pbtypes.Get(r.details, key)
+	switch v := value.GetKind().(type) {
+	case *types.Value_StringValue:
+		return r.name(v.StringValue)
+	case *types.Value_NumberValue:
+		return strconv.FormatFloat(v.NumberValue, 'f', -1, 64)
+	case *types.Value_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *types.Value_ListValue:
+		ids := pbtypes.GetStringListValue(value)
+		names := make([]string, 0, len(ids))
+		for _, id := range ids {
+			names = append(names, r.name(id))
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func (r *variableResolver) name(id string) string {
+	if r.objectName == nil || id == "" {
+		return id
+	}
+	return r.objectName(id)
+}
+
+// shiftTime applies offset like +7d or -1m to t. Supported units are h(ours), d(ays), w(eeks), m(onths) and y(ears)
+func shiftTime(t time.Time, offset string) (time.Time, error) {
+	if offset == "" {
+		return t, nil
+	}
+	n, err := strconv.Atoi(offset[:len(offset)-1])
+	if err != nil {
+		return t, fmt.Errorf("invalid offset %s: %w", offset, err)
+	}
+	switch offset[len(offset)-1] {
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case 'd':
+		return t.AddDate(0, 0, n), nil
+	case 'w':
+		return t.AddDate(0, 0, n*7), nil
+	case 'm':
+		return t.AddDate(0, n, 0), nil
+	case 'y':
+		return t.AddDate(n, 0, 0), nil
+	}
+	return t, fmt.Errorf("invalid offset unit in %s", offset)
+}
+
+// shiftMarks moves marks placed after replaced range, marks that end inside the range are extended to its new end
+func shiftMarks(marks []*model.BlockContentTextMark, repl textReplacement) {
+	delta := repl.newLen - (repl.to - repl.from)
+	shift := func(pos int32) int32 {
+		if pos >= repl.to {
+			return pos + delta
+		}
+		if pos > repl.from {
+			return repl.from + repl.newLen
+		}
+		return pos
+	}
+	for _, mark := range marks {
+		if mark.Range == nil {
+			continue
+		}
+		mark.Range.From = shift(mark.Range.From)
+		mark.Range.To = shift(mark.Range.To)
+	}
+}
+
+func (s *service) newVariableResolver(templateId string, details *types.Struct) *variableResolver {
+	r := &variableResolver{
+		now:     time.Now(),
+		details: details,
+		counter: pbtypes.GetInt64(details, bundle.RelationKeyTemplateCounter.String()),
+		relationFormat: func(key string) model.RelationFormat {
+			if rel, err := bundle.GetRelation(domain.RelationKey(key)); err == nil {
+				return rel.Format
+			}
+			if s.store == nil {
+				return model.RelationFormat_longtext
+			}
+			rel, err := s.store.GetRelationByKey(key)
+			if err != nil {
+				return model.RelationFormat_longtext
+			}
+			return rel.Format
+		},
+	}
+	if s.accountService != nil {
+		r.creatorName = func() (string, error) {
+			profile, err := s.accountService.ProfileInfo()
+			return profile.Name, err
+		}
+	}
+	if s.store != nil {
+		r.objectName = func(id string) string {
+			records, err := s.store.QueryByID([]string{id})
+			if err != nil || len(records) == 0 {
+				return id
+			}
+			return pbtypes.GetString(records[0].Details, bundle.RelationKeyName.String())
+		}
+	}
+	if s.store != nil && templateId != BlankTemplateId {
+		r.nextCounter = func() (int64, error) {
+			return s.nextTypeCounter(templateId)
+		}
+	}
+	return r
+}
+
+// nextTypeCounter increments counter stored in details of the object type, that template belongs to
+func (s *service) nextTypeCounter(templateId string) (counter int64, err error) {
+	details, err := s.store.GetDetails(templateId)
+	if err != nil {
+		return 0, fmt.Errorf("get template details: %w", err)
+	}
+	typeId := pbtypes.GetString(details.GetDetails(), bundle.RelationKeyTargetObjectType.String())
+	if typeId == "" {
+		return 0, fmt.Errorf("template has no target object type")
+	}
+	err = cache.Do(s.picker, typeId, func(sb basic.DetailsUpdatable) error {
+		return sb.UpdateDetails(func(current *types.Struct) (*types.Struct, error) {
+			counter = pbtypes.GetInt64(current, bundle.RelationKeyTemplateCounter.String()) + 1
+			current = pbtypes.CopyStruct(current, false)
+			if current == nil {
+				current = &types.Struct{}
+			}
+			if current.Fields == nil {
+				current.Fields = map[string]*types.Value{}
+			}
+			current.Fields[bundle.RelationKeyTemplateCounter.String()] = pbtypes.Int64(counter)
+			return current, nil
+		})
+	})
+	return counter, err
+}
@@ -0,0 +1,143 @@
+package template
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestVariableResolver_ResolveText(t *testing.T) {
+	newResolver := func() *variableResolver {
+		return &variableResolver{
+			now: time.Date(2024, 3, 30, 10, 15, 0, 0, time.UTC),
+			details: &types.Struct{Fields: map[string]*types.Value{
+				bundle.RelationKeyName.String(): pbtypes.String("Weekly sync"),
+				"estimate":                      pbtypes.Float64(2.5),
+			}},
+			creatorName: func() (string, error) { return "Alice", nil },
+		}
+	}
+
+	t.Run("dates with offsets", func(t *testing.T) {
+		text, _, err := newResolver().resolveText("{{today}} - {{ today+7d }} - {{now-2h}} - {{today+1m}}")
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-30 - 2024-04-06 - 2024-03-30 08:15 - 2024-04-30", text)
+	})
+
+	t.Run("dates with formats", func(t *testing.T) {
+		text, _, err := newResolver().resolveText("{{today:short}} - {{today:shortUS+1d}} - {{now:monthAbbrBeforeDay}} - {{today:unknown}}")
+		require.NoError(t, err)
+		assert.Equal(t, "30/03/2024 - 03/31/2024 - Mar 30, 2024 10:15 - {{today:unknown}}", text)
+	})
+
+	t.Run("object values", func(t *testing.T) {
+		text, _, err := newResolver().resolveText("{{title}} by {{creator}}, estimate {{relation:estimate}}h")
+		require.NoError(t, err)
+		assert.Equal(t, "Weekly sync by Alice, estimate 2.5h", text)
+	})
+
+	t.Run("counter is requested once per object", func(t *testing.T) {
+		r := newResolver()
+		var calls int
+		r.nextCounter = func() (int64, error) {
+			calls++
+			return 42, nil
+		}
+		text, _, err := r.resolveText("TASK-{{counter}} ({{counter}})")
+		require.NoError(t, err)
+		assert.Equal(t, "TASK-42 (42)", text)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("counter of the object is reused", func(t *testing.T) {
+		r := newResolver()
+		r.counter = 7
+		r.nextCounter = func() (int64, error) {
+			return 0, errors.New("counter must not be incremented")
+		}
+		text, _, err := r.resolveText("TASK-{{counter}}")
+		require.NoError(t, err)
+		assert.Equal(t, "TASK-7", text)
+	})
+
+	t.Run("unknown variables are kept", func(t *testing.T) {
+		text, replacements, err := newResolver().resolveText("{{unknown}} {{counter}}")
+		require.NoError(t, err)
+		assert.Equal(t, "{{unknown}} {{counter}}", text)
+		assert.Empty(t, replacements)
+	})
+}
+
+func TestVariableResolver_ResolveState(t *testing.T) {
+	// given
+	st := state.NewDoc("root", map[string]simple.Block{
+		"root": simple.New(&model.Block{Id: "root", ChildrenIds: []string{"text"}}),
+		"text": simple.New(&model.Block{Id: "text", Content: &model.BlockContentOfText{Text: &model.BlockContentText{
+			Text: "Due {{today}}, see link",
+			Marks: &model.BlockContentTextMarks{Marks: []*model.BlockContentTextMark{{
+				Range: &model.Range{From: 19, To: 23},
+				Type:  model.BlockContentTextMark_Link,
+			}}},
+		}}}),
+	}).NewState()
+	st.SetDetail(bundle.RelationKeyDescription.String(), pbtypes.String("Created {{today}}"))
+	r := &variableResolver{now: time.Date(2024, 3, 30, 10, 15, 0, 0, time.UTC)}
+
+	// when
+	err := r.resolveState(st)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "Created 2024-03-30", pbtypes.GetString(st.Details(), bundle.RelationKeyDescription.String()))
+	textBlock := st.Pick("text").Model().GetText()
+	assert.Equal(t, "Due 2024-03-30, see link", textBlock.Text)
+	assert.Equal(t, &model.Range{From: 20, To: 24}, textBlock.Marks.Marks[0].Range)
+}
+
+func TestVariableResolver_ResolveDetail(t *testing.T) {
+	r := &variableResolver{
+		now:     time.Date(2024, 3, 30, 10, 15, 0, 0, time.UTC),
+		details: &types.Struct{Fields: map[string]*types.Value{"estimate": pbtypes.Float64(2.5)}},
+		relationFormat: func(key string) model.RelationFormat {
+			switch key {
+			case "dueDate":
+				return model.RelationFormat_date
+			case "points":
+				return model.RelationFormat_number
+			}
+			return model.RelationFormat_shorttext
+		},
+		nextCounter: func() (int64, error) { return 3, nil },
+	}
+
+	t.Run("date relation gets timestamp", func(t *testing.T) {
+		value, err := r.resolveDetail("dueDate", "{{today+7d}}")
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.Int64(time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC).Unix()), value)
+	})
+
+	t.Run("number relation gets number", func(t *testing.T) {
+		value, err := r.resolveDetail("points", "{{relation:estimate}}")
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.Float64(2.5), value)
+
+		value, err = r.resolveDetail("points", "{{counter}}")
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.Float64(3), value)
+	})
+
+	t.Run("text in date relation is an error", func(t *testing.T) {
+		_, err := r.resolveDetail("dueDate", "due {{today}}")
+		assert.Error(t, err)
+	})
+}
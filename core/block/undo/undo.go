@@ -56,10 +56,14 @@ type Action struct {
 	Group         string
 	ObjectTypes   *ObjectType
 	CarriageInfo  CarriageInfo
+	// SyncedObjectId is the id of the source object the change of synced blocks was applied to,
+	// the change is undone and redone in the history of the source object
+	SyncedObjectId string
 }
 
 func (a Action) IsEmpty() bool {
-	return len(a.Add)+len(a.Change)+len(a.Remove) == 0 && a.Details == nil && a.ObjectTypes == nil && a.RelationLinks == nil
+	return len(a.Add)+len(a.Change)+len(a.Remove) == 0 && a.Details == nil && a.ObjectTypes == nil && a.RelationLinks == nil &&
+		a.SyncedObjectId == ""
 }
 
 func (a Action) Merge(b Action) (result Action) {
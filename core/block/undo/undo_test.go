@@ -51,6 +51,11 @@ func TestHistory_Add(t *testing.T) {
 		h.Add(Action{})
 		assert.Equal(t, 0, h.Len())
 	})
+	t.Run("add change of synced blocks", func(t *testing.T) {
+		h := NewHistory(100)
+		h.Add(Action{SyncedObjectId: "source"})
+		assert.Equal(t, 1, h.Len())
+	})
 }
 
 func TestHistory_Previous(t *testing.T) {
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+
+	"github.com/anyproto/anytype-heart/core/block"
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+func (mw *Middleware) BlockSyncedListReferences(cctx context.Context, req *pb.RpcBlockSyncedListReferencesRequest) *pb.RpcBlockSyncedListReferencesResponse {
+	response := func(code pb.RpcBlockSyncedListReferencesResponseErrorCode, refs []*pb.RpcBlockSyncedListReferencesResponseReference, err error) *pb.RpcBlockSyncedListReferencesResponse {
+		m := &pb.RpcBlockSyncedListReferencesResponse{Error: &pb.RpcBlockSyncedListReferencesResponseError{Code: code}, References: refs}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+	var refs []*pb.RpcBlockSyncedListReferencesResponseReference
+	err := mw.doBlockService(func(bs *block.Service) (err error) {
+		refs, err = bs.ListSyncedBlockReferences(req)
+		return
+	})
+	if err != nil {
+		return response(pb.RpcBlockSyncedListReferencesResponseError_UNKNOWN_ERROR, nil, err)
+	}
+	return response(pb.RpcBlockSyncedListReferencesResponseError_NULL, refs, nil)
+}
+
+func (mw *Middleware) BlockSyncedDetach(cctx context.Context, req *pb.RpcBlockSyncedDetachRequest) *pb.RpcBlockSyncedDetachResponse {
+	ctx := mw.newContext(cctx)
+	response := func(code pb.RpcBlockSyncedDetachResponseErrorCode, blockId string, err error) *pb.RpcBlockSyncedDetachResponse {
+		m := &pb.RpcBlockSyncedDetachResponse{Error: &pb.RpcBlockSyncedDetachResponseError{Code: code}, BlockId: blockId}
+		if err != nil {
+			m.Error.Description = err.Error()
+		} else {
+			m.Event = mw.getResponseEvent(ctx)
+		}
+		return m
+	}
+	var blockId string
+	err := mw.doBlockService(func(bs *block.Service) (err error) {
+		blockId, err = bs.DetachSyncedBlock(ctx, req)
+		return
+	})
+	if err != nil {
+		return response(pb.RpcBlockSyncedDetachResponseError_UNKNOWN_ERROR, "", err)
+	}
+	return response(pb.RpcBlockSyncedDetachResponseError_NULL, blockId, nil)
+}
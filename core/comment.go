@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+
+	"github.com/anyproto/anytype-heart/core/block"
+	"github.com/anyproto/anytype-heart/core/block/editor/comment"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func (mw *Middleware) CommentAdd(cctx context.Context, req *pb.RpcCommentAddRequest) *pb.RpcCommentAddResponse {
+	ctx := mw.newContext(cctx)
+	var commentId string
+	err := mw.doBlockService(func(bs *block.Service) (err error) {
+		commentId, err = bs.AddComment(ctx, req)
+		return
+	})
+	code := mapErrorCode(err,
+		errToCode(comment.ErrEmptyText, pb.RpcCommentAddResponseError_BAD_INPUT),
+		errToCode(comment.ErrInvalidRange, pb.RpcCommentAddResponseError_BAD_INPUT),
+		errToCode(comment.ErrNotFound, pb.RpcCommentAddResponseError_NOT_FOUND),
+		errToCode(comment.ErrBlockNotFound, pb.RpcCommentAddResponseError_NOT_FOUND),
+	)
+	m := &pb.RpcCommentAddResponse{
+		Error: &pb.RpcCommentAddResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+		CommentId: commentId,
+	}
+	if err == nil {
+		m.Event = mw.getResponseEvent(ctx)
+	}
+	return m
+}
+
+func (mw *Middleware) CommentEdit(cctx context.Context, req *pb.RpcCommentEditRequest) *pb.RpcCommentEditResponse {
+	ctx := mw.newContext(cctx)
+	err := mw.doBlockService(func(bs *block.Service) error {
+		return bs.EditComment(ctx, req)
+	})
+	code := mapErrorCode(err,
+		errToCode(comment.ErrEmptyText, pb.RpcCommentEditResponseError_BAD_INPUT),
+		errToCode(comment.ErrNotFound, pb.RpcCommentEditResponseError_NOT_FOUND),
+		errToCode(comment.ErrNotAuthor, pb.RpcCommentEditResponseError_NOT_AUTHOR),
+	)
+	m := &pb.RpcCommentEditResponse{
+		Error: &pb.RpcCommentEditResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+	if err == nil {
+		m.Event = mw.getResponseEvent(ctx)
+	}
+	return m
+}
+
+func (mw *Middleware) CommentDelete(cctx context.Context, req *pb.RpcCommentDeleteRequest) *pb.RpcCommentDeleteResponse {
+	ctx := mw.newContext(cctx)
+	err := mw.doBlockService(func(bs *block.Service) error {
+		return bs.DeleteComment(ctx, req)
+	})
+	code := mapErrorCode(err,
+		errToCode(comment.ErrNotFound, pb.RpcCommentDeleteResponseError_NOT_FOUND),
+		errToCode(comment.ErrNotAuthor, pb.RpcCommentDeleteResponseError_NOT_AUTHOR),
+	)
+	m := &pb.RpcCommentDeleteResponse{
+		Error: &pb.RpcCommentDeleteResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+	if err == nil {
+		m.Event = mw.getResponseEvent(ctx)
+	}
+	return m
+}
+
+func (mw *Middleware) CommentSetResolved(cctx context.Context, req *pb.RpcCommentSetResolvedRequest) *pb.RpcCommentSetResolvedResponse {
+	ctx := mw.newContext(cctx)
+	err := mw.doBlockService(func(bs *block.Service) error {
+		return bs.SetCommentThreadResolved(ctx, req)
+	})
+	code := mapErrorCode(err,
+		errToCode(comment.ErrNotFound, pb.RpcCommentSetResolvedResponseError_NOT_FOUND),
+	)
+	m := &pb.RpcCommentSetResolvedResponse{
+		Error: &pb.RpcCommentSetResolvedResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+	if err == nil {
+		m.Event = mw.getResponseEvent(ctx)
+	}
+	return m
+}
+
+func (mw *Middleware) CommentList(_ context.Context, req *pb.RpcCommentListRequest) *pb.RpcCommentListResponse {
+	var comments []*model.Comment
+	err := mw.doBlockService(func(bs *block.Service) (err error) {
+		comments, err = bs.ListComments(req)
+		return
+	})
+	return &pb.RpcCommentListResponse{
+		Error: &pb.RpcCommentListResponseError{
+			Code:        mapErrorCode[pb.RpcCommentListResponseErrorCode](err),
+			Description: getErrorDescription(err),
+		},
+		Comments: comments,
+	}
+}
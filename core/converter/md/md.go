@@ -29,8 +29,14 @@ type FileNamer interface {
 	Get(path, hash, title, ext string) (name string)
 }
 
-func NewMDConverter(s *state.State, fn FileNamer) converter.Converter {
-	return &MD{s: s, fn: fn}
+// SourceGetter returns state of the object referenced by synced block
+type SourceGetter func(objectId string) (*state.State, error)
+
+// maxSyncedDepth limits rendering of synced blocks referencing other synced blocks
+const maxSyncedDepth = 5
+
+func NewMDConverter(s *state.State, fn FileNamer, sources SourceGetter) converter.Converter {
+	return &MD{s: s, fn: fn, sources: sources}
 }
 
 type MD struct {
@@ -43,6 +49,10 @@ type MD struct {
 
 	mw *marksWriter
 	fn FileNamer
+
+	sources      SourceGetter
+	sourceStates map[string]*state.State
+	syncedDepth  int
 }
 
 func (h *MD) Convert(sbType model.SmartBlockType) (result []byte) {
@@ -110,6 +120,8 @@ func (h *MD) render(buf writer, in *renderState, b *model.Block) {
 		h.renderLatex(buf, in, b)
 	case *model.BlockContentOfTable:
 		h.renderTable(buf, in, b)
+	case *model.BlockContentOfSynced:
+		h.renderSynced(buf, in, b)
 	default:
 		h.renderLayout(buf, in, b)
 	}
@@ -272,6 +284,48 @@ func (h *MD) renderLink(buf writer, in *renderState, b *model.Block) {
 	}
 }
 
+// renderSynced inlines content of the source block
+func (h *MD) renderSynced(buf writer, in *renderState, b *model.Block) {
+	sync := b.GetSynced()
+	if sync == nil || h.syncedDepth >= maxSyncedDepth {
+		return
+	}
+	src, err := h.sourceState(sync.TargetObjectId)
+	if err != nil {
+		log.Warnf("failed to get source of synced block %s: %s", b.Id, err)
+		return
+	}
+	source := src.Pick(sync.TargetBlockId)
+	if source == nil {
+		return
+	}
+
+	prev := h.s
+	h.s = src
+	h.syncedDepth++
+	h.render(buf, in, source.Model())
+	h.syncedDepth--
+	h.s = prev
+}
+
+func (h *MD) sourceState(objectId string) (*state.State, error) {
+	if h.sourceStates == nil {
+		h.sourceStates = map[string]*state.State{h.s.RootId(): h.s}
+	}
+	if st, ok := h.sourceStates[objectId]; ok {
+		return st, nil
+	}
+	if h.sources == nil {
+		return nil, fmt.Errorf("object %s is not available", objectId)
+	}
+	st, err := h.sources(objectId)
+	if err != nil {
+		return nil, err
+	}
+	h.sourceStates[objectId] = st
+	return st, nil
+}
+
 func (h *MD) renderLatex(buf writer, in *renderState, b *model.Block) {
 	l := b.GetLatex()
 	if l != nil {
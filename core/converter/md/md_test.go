@@ -77,7 +77,7 @@ func TestMD_Convert(t *testing.T) {
 				},
 			},
 		)
-		c := NewMDConverter(s, nil)
+		c := NewMDConverter(s, nil, nil)
 		res := c.Convert(model.SmartBlockType_Page)
 		exp := "# Header 1   \n## Header 2   \n --- \n### Header 3   \nUsual text   \n#### Header 4   \n"
 		assert.Equal(t, exp, string(res))
@@ -126,7 +126,7 @@ func TestMD_Convert(t *testing.T) {
 				},
 			},
 		})
-		c := NewMDConverter(s, nil)
+		c := NewMDConverter(s, nil, nil)
 		res := c.Convert(model.SmartBlockType_Page)
 		exp := "***[some](http://golang.org)*** [t](http://golang.org) [e](http://golang.org)xt **wi~~th m~~**~~ar~~ks @mention   \n"
 		assert.Equal(t, exp, string(res))
@@ -143,7 +143,7 @@ func TestMD_Convert(t *testing.T) {
 				},
 			},
 		})
-		c := NewMDConverter(s, nil)
+		c := NewMDConverter(s, nil, nil)
 		res := c.Convert(model.SmartBlockType_Page)
 		exp := "Test 😝   \n"
 		assert.Equal(t, exp, string(res))
@@ -165,9 +165,29 @@ func TestMD_Convert(t *testing.T) {
 				},
 			},
 		})
-		c := NewMDConverter(s, nil)
+		c := NewMDConverter(s, nil, nil)
 		res := c.Convert(model.SmartBlockType_Page)
 		exp := "Test ⛰️   \n"
 		assert.Equal(t, exp, string(res))
 	})
+
+	t.Run("test render synced block", func(t *testing.T) {
+		source := newState(&model.Block{
+			Id:      "checklist",
+			Content: &model.BlockContentOfText{Text: &model.BlockContentText{Text: "Check", Style: model.BlockContentText_Checkbox}},
+		})
+		s := newState(&model.Block{
+			Content: &model.BlockContentOfSynced{Synced: &model.BlockContentSynced{
+				TargetObjectId: "source",
+				TargetBlockId:  "checklist",
+			}},
+		})
+		c := NewMDConverter(s, nil, func(objectId string) (*state.State, error) {
+			assert.Equal(t, "source", objectId)
+			return source, nil
+		})
+		res := c.Convert(model.SmartBlockType_Page)
+		exp := "- [ ] Check   \n"
+		assert.Equal(t, exp, string(res))
+	})
 }
@@ -1,11 +1,24 @@
 package domain
 
+import (
+	"time"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
 type InviteView struct {
 	SpaceId      string
 	SpaceName    string
 	SpaceIconCid string
 	CreatorName  string
 	InviteKey    []byte
+	Permissions  model.ParticipantPermissions
+	ExpireDate   int64
+}
+
+// IsExpired reports whether the invite can't be used to join anymore
+func (v InviteView) IsExpired(now time.Time) bool {
+	return v.ExpireDate > 0 && now.Unix() >= v.ExpireDate
 }
 
 type InviteInfo struct {
@@ -18,3 +31,51 @@ type InviteObject interface {
 	GetExistingInviteInfo() (fileCid string, fileKey string)
 	RemoveExistingInviteInfo() (fileCid string, err error)
 }
+
+// InviteParams are set by the space owner when creating an invite
+type InviteParams struct {
+	Label string
+	// Permissions are granted to members joined through the invite by default
+	Permissions model.ParticipantPermissions
+	// ExpireDate is a unix time after which the invite can't be used, zero means the invite never expires
+	ExpireDate int64
+	// MaxUses limits the number of members that could join through the invite, zero means unlimited
+	MaxUses int
+}
+
+// Invite is one of several concurrent invites of a space, it is identified by the id of its acl record
+type Invite struct {
+	InviteInfo
+	InviteParams
+	RecordId    string
+	CreatedDate int64
+	RevokedDate int64
+	// Uses is the number of accepted join requests made through the invite, it is filled from the acl
+	Uses int
+}
+
+func (i Invite) IsExpired(now time.Time) bool {
+	return i.ExpireDate > 0 && now.Unix() >= i.ExpireDate
+}
+
+func (i Invite) IsExhausted() bool {
+	return i.MaxUses > 0 && i.Uses >= i.MaxUses
+}
+
+func (i Invite) IsActive(now time.Time) bool {
+	return i.RevokedDate == 0 && !i.IsExpired(now) && !i.IsExhausted()
+}
+
+// InviteJoin is a join request made through one of the space invites
+type InviteJoin struct {
+	Identity       string
+	InviteRecordId string
+	RequestDate    int64
+	Accepted       bool
+}
+
+// InviteListObject keeps metadata of all invites of a space
+type InviteListObject interface {
+	SetInvite(invite Invite) error
+	ListInvites() []Invite
+}
@@ -0,0 +1,68 @@
+package eventlog
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+const messageTypePrefix = "EventMessageValueOf"
+
+// Filter selects messages of events. Empty lists match everything, messages should match all non-empty lists
+type Filter struct {
+	ContextIds []string
+	// Types are names of message values as in the json format, e.g. objectDetailsSet or blockSetText
+	Types    []string
+	SpaceIds []string
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.ContextIds) == 0 && len(f.Types) == 0 && len(f.SpaceIds) == 0
+}
+
+// MessageType returns the name of the message value, the same name is used as the key of the value in json
+func MessageType(msg *pb.EventMessage) string {
+	if msg == nil || msg.Value == nil {
+		return ""
+	}
+	name := strings.TrimPrefix(reflect.TypeOf(msg.Value).Elem().Name(), messageTypePrefix)
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// messageObjectId returns the id of the object the message is about. Details messages of subscriptions
+// are sent without context, so the id of the message is used for them
+func messageObjectId(contextId string, msg *pb.EventMessage) string {
+	switch v := msg.Value.(type) {
+	case *pb.EventMessageValueOfObjectDetailsSet:
+		return v.ObjectDetailsSet.Id
+	case *pb.EventMessageValueOfObjectDetailsAmend:
+		return v.ObjectDetailsAmend.Id
+	case *pb.EventMessageValueOfObjectDetailsUnset:
+		return v.ObjectDetailsUnset.Id
+	case *pb.EventMessageValueOfSubscriptionAdd:
+		return v.SubscriptionAdd.Id
+	}
+	return contextId
+}
+
+// match reports whether the message passes the filter, resolveSpace is called only when spaces are filtered
+func (f Filter) match(event *pb.Event, msg *pb.EventMessage, resolveSpace func(objectId string) string) bool {
+	if len(f.ContextIds) > 0 && !slices.Contains(f.ContextIds, event.ContextId) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, MessageType(msg)) {
+		return false
+	}
+	if len(f.SpaceIds) > 0 {
+		objectId := messageObjectId(event.ContextId, msg)
+		if objectId == "" || !slices.Contains(f.SpaceIds, resolveSpace(objectId)) {
+			return false
+		}
+	}
+	return true
+}
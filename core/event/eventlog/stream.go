@@ -0,0 +1,190 @@
+// Package eventlog writes events of the middleware as json lines, one line per event. It's used by the file sink
+// and the events endpoint of the local API, so consumers could read events without generated protobuf code
+package eventlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/util/anonymize"
+)
+
+var log = logging.Logger("anytype-event-log")
+
+const (
+	// subscriberBuffer is the number of events queued for a subscriber, events are dropped when it's full
+	subscriberBuffer = 1024
+	spaceCacheSize   = 10000
+)
+
+// Options of the written events
+type Options struct {
+	Filter
+	// Anonymize replaces texts and details of messages using util/anonymize and omits the initiator
+	Anonymize bool
+}
+
+// Line is the json document written per event
+type Line struct {
+	Time time.Time `json:"time"`
+	// Dropped is the number of events dropped before this one because the consumer didn't keep up
+	Dropped int64           `json:"dropped,omitempty"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// Stream passes observed events to its consumers. Observe never blocks the sender
+type Stream struct {
+	resolveSpace func(objectId string) (string, error)
+	now          func() time.Time
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	events  chan *pb.Event
+	dropped atomic.Int64
+}
+
+// NewStream creates a stream, resolveSpace is used to filter events by spaces
+func NewStream(resolveSpace func(objectId string) (string, error)) *Stream {
+	return &Stream{
+		resolveSpace: resolveSpace,
+		now:          time.Now,
+		subscribers:  map[*subscriber]struct{}{},
+	}
+}
+
+// Observe queues the event for consumers, it could be passed to event.NewObservedSender
+func (s *Stream) Observe(event *pb.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+func (s *Stream) subscribe() *subscriber {
+	sub := &subscriber{events: make(chan *pb.Event, subscriberBuffer)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	return sub
+}
+
+func (s *Stream) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	delete(s.subscribers, sub)
+	s.mu.Unlock()
+}
+
+// Serve writes events matching the options to w, one json line per event, until ctx is done or writing fails.
+// Only events observed after the call are written. flush is called after every line if it's set
+func (s *Stream) Serve(ctx context.Context, opts Options, w io.Writer, flush func()) error {
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+	enc := &encoder{opts: opts, resolveSpace: s.resolveSpace, spaces: map[string]string{}}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-sub.events:
+			dropped := sub.dropped.Swap(0)
+			line, err := enc.encode(event, s.now(), dropped)
+			if err != nil {
+				log.Warnf("encode event: %v", err)
+			}
+			if line == nil {
+				// keep the number for the next written line
+				sub.dropped.Add(dropped)
+				continue
+			}
+			if _, err = w.Write(line); err != nil {
+				return err
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+	}
+}
+
+type encoder struct {
+	opts         Options
+	resolveSpace func(objectId string) (string, error)
+	// spaces caches resolved space ids of objects
+	spaces    map[string]string
+	marshaler jsonpb.Marshaler
+}
+
+// encode returns the json line of the event with the messages matching the filter,
+// nil is returned when there are no such messages
+func (e *encoder) encode(event *pb.Event, now time.Time, dropped int64) ([]byte, error) {
+	if event == nil {
+		return nil, nil
+	}
+	messages := make([]*pb.EventMessage, 0, len(event.Messages))
+	for _, msg := range event.Messages {
+		if !e.opts.match(event, msg, e.space) {
+			continue
+		}
+		if e.opts.Anonymize {
+			msg = anonymize.Event(msg)
+		}
+		messages = append(messages, msg)
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	out := &pb.Event{
+		Messages:  messages,
+		ContextId: event.ContextId,
+		Initiator: event.Initiator,
+		TraceId:   event.TraceId,
+	}
+	if e.opts.Anonymize {
+		out.Initiator = nil
+	}
+	buf := &bytes.Buffer{}
+	if err := e.marshaler.Marshal(buf, out); err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+	line, err := json.Marshal(Line{Time: now.UTC(), Dropped: dropped, Event: buf.Bytes()})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+func (e *encoder) space(objectId string) string {
+	if spaceId, ok := e.spaces[objectId]; ok {
+		return spaceId
+	}
+	if e.resolveSpace == nil {
+		return ""
+	}
+	spaceId, err := e.resolveSpace(objectId)
+	if err != nil {
+		// objects could be unknown yet, so the failures are not cached
+		return ""
+	}
+	if len(e.spaces) >= spaceCacheSize {
+		clear(e.spaces)
+	}
+	e.spaces[objectId] = spaceId
+	return spaceId
+}
@@ -0,0 +1,203 @@
+package eventlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+var errUnknownObject = errors.New("unknown object")
+
+func testResolveSpace(objectId string) (string, error) {
+	switch objectId {
+	case "page1", "page2":
+		return "space1", nil
+	case "page3":
+		return "space2", nil
+	}
+	return "", errUnknownObject
+}
+
+func textMessage(id, text string) *pb.EventMessage {
+	return &pb.EventMessage{Value: &pb.EventMessageValueOfBlockSetText{
+		BlockSetText: &pb.EventBlockSetText{Id: id, Text: &pb.EventBlockSetTextText{Value: text}},
+	}}
+}
+
+func detailsMessage(id, name string) *pb.EventMessage {
+	return &pb.EventMessage{Value: &pb.EventMessageValueOfObjectDetailsAmend{
+		ObjectDetailsAmend: &pb.EventObjectDetailsAmend{Id: id, Details: []*pb.EventObjectDetailsAmendKeyValue{
+			{Key: "name", Value: pbtypes.String(name)},
+		}},
+	}}
+}
+
+type decodedLine struct {
+	Time    time.Time `json:"time"`
+	Dropped int64     `json:"dropped"`
+	Event   struct {
+		ContextId string           `json:"contextId"`
+		Messages  []map[string]any `json:"messages"`
+		Initiator map[string]any   `json:"initiator"`
+	} `json:"event"`
+}
+
+func decode(t *testing.T, line []byte) decodedLine {
+	var res decodedLine
+	require.NoError(t, json.Unmarshal(line, &res))
+	return res
+}
+
+func TestMessageType(t *testing.T) {
+	assert.Equal(t, "blockSetText", MessageType(textMessage("b", "text")))
+	assert.Equal(t, "objectDetailsAmend", MessageType(detailsMessage("page1", "name")))
+	assert.Equal(t, "", MessageType(&pb.EventMessage{}))
+}
+
+func TestEncoder_encode(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := &pb.Event{
+		ContextId: "page1",
+		Initiator: &model.Account{Id: "account"},
+		Messages: []*pb.EventMessage{
+			textMessage("block1", "secret text"),
+			detailsMessage("page3", "Other space"),
+		},
+	}
+
+	t.Run("whole event", func(t *testing.T) {
+		// given
+		enc := &encoder{resolveSpace: testResolveSpace, spaces: map[string]string{}}
+
+		// when
+		line, err := enc.encode(event, now, 2)
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, byte('\n'), line[len(line)-1])
+		res := decode(t, line)
+		assert.Equal(t, now, res.Time)
+		assert.Equal(t, int64(2), res.Dropped)
+		assert.Equal(t, "page1", res.Event.ContextId)
+		assert.Equal(t, "account", res.Event.Initiator["id"])
+		require.Len(t, res.Event.Messages, 2)
+		assert.Contains(t, res.Event.Messages[0], "blockSetText")
+		assert.Contains(t, string(line), "secret text")
+	})
+
+	t.Run("filter by type", func(t *testing.T) {
+		// given
+		enc := &encoder{opts: Options{Filter: Filter{Types: []string{"objectDetailsAmend"}}}, spaces: map[string]string{}}
+
+		// when
+		line, err := enc.encode(event, now, 0)
+
+		// then
+		require.NoError(t, err)
+		res := decode(t, line)
+		require.Len(t, res.Event.Messages, 1)
+		assert.Contains(t, res.Event.Messages[0], "objectDetailsAmend")
+	})
+
+	t.Run("filter by space uses ids of details messages", func(t *testing.T) {
+		// given
+		enc := &encoder{opts: Options{Filter: Filter{SpaceIds: []string{"space1"}}}, resolveSpace: testResolveSpace, spaces: map[string]string{}}
+
+		// when
+		line, err := enc.encode(event, now, 0)
+
+		// then
+		require.NoError(t, err)
+		res := decode(t, line)
+		require.Len(t, res.Event.Messages, 1)
+		assert.Contains(t, res.Event.Messages[0], "blockSetText")
+		assert.Equal(t, map[string]string{"page1": "space1", "page3": "space2"}, enc.spaces)
+	})
+
+	t.Run("no matching messages", func(t *testing.T) {
+		// given
+		enc := &encoder{opts: Options{Filter: Filter{ContextIds: []string{"page2"}}}, spaces: map[string]string{}}
+
+		// when
+		line, err := enc.encode(event, now, 0)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, line)
+	})
+
+	t.Run("anonymize", func(t *testing.T) {
+		// given
+		enc := &encoder{opts: Options{Anonymize: true}, spaces: map[string]string{}}
+
+		// when
+		line, err := enc.encode(event, now, 0)
+
+		// then
+		require.NoError(t, err)
+		res := decode(t, line)
+		require.Len(t, res.Event.Messages, 2)
+		assert.Nil(t, res.Event.Initiator)
+		assert.NotContains(t, string(line), "secret text")
+		assert.NotContains(t, string(line), "Other space")
+		assert.Equal(t, "secret text", event.Messages[0].GetBlockSetText().Text.Value)
+	})
+}
+
+func TestStream_Serve(t *testing.T) {
+	t.Run("events are written as lines", func(t *testing.T) {
+		// given
+		s := NewStream(testResolveSpace)
+		r, w := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- s.Serve(ctx, Options{Filter: Filter{SpaceIds: []string{"space1"}}}, w, nil)
+		}()
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.subscribers) == 1
+		}, time.Second, time.Millisecond)
+
+		// when
+		s.Observe(&pb.Event{ContextId: "page3", Messages: []*pb.EventMessage{textMessage("b", "skipped")}})
+		s.Observe(&pb.Event{ContextId: "page2", Messages: []*pb.EventMessage{textMessage("b", "written")}})
+
+		// then
+		line, err := bufio.NewReader(r).ReadBytes('\n')
+		require.NoError(t, err)
+		res := decode(t, line)
+		assert.Equal(t, "page2", res.Event.ContextId)
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		assert.Empty(t, s.subscribers)
+	})
+
+	t.Run("events are dropped for slow consumers", func(t *testing.T) {
+		// given
+		s := NewStream(nil)
+		sub := s.subscribe()
+
+		// when
+		for i := 0; i < subscriberBuffer+3; i++ {
+			s.Observe(&pb.Event{ContextId: "page1", Messages: []*pb.EventMessage{textMessage("b", "text")}})
+		}
+
+		// then
+		assert.Len(t, sub.events, subscriberBuffer)
+		assert.Equal(t, int64(3), sub.dropped.Load())
+	})
+}
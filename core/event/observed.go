@@ -0,0 +1,45 @@
+package event
+
+import (
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+// ObservedSender passes every event to the observer before sending it with the wrapped sender.
+// The observer is called synchronously, so it should not block
+type ObservedSender struct {
+	Sender
+	observe func(event *pb.Event)
+}
+
+func NewObservedSender(sender Sender, observe func(event *pb.Event)) *ObservedSender {
+	return &ObservedSender{Sender: sender, observe: observe}
+}
+
+var _ = Sender(&ObservedSender{})
+
+// Unwrap returns the wrapped sender
+func (s *ObservedSender) Unwrap() Sender {
+	return s.Sender
+}
+
+func (s *ObservedSender) Broadcast(event *pb.Event) {
+	s.observe(event)
+	s.Sender.Broadcast(event)
+}
+
+func (s *ObservedSender) SendToSession(token string, event *pb.Event) {
+	s.observe(event)
+	s.Sender.SendToSession(token, event)
+}
+
+func (s *ObservedSender) BroadcastToOtherSessions(token string, e *pb.Event) {
+	s.observe(e)
+	s.Sender.BroadcastToOtherSessions(token, e)
+}
+
+func (s *ObservedSender) CloseSession(token string) {
+	if closer, ok := s.Sender.(session.Closer); ok {
+		closer.CloseSession(token)
+	}
+}
@@ -6,11 +6,14 @@ import (
 
 	"github.com/gogo/protobuf/types"
 
+	"github.com/anyproto/anytype-heart/core/anytype/config"
 	"github.com/anyproto/anytype-heart/core/block"
 	"github.com/anyproto/anytype-heart/core/domain/objectorigin"
 	"github.com/anyproto/anytype-heart/core/files"
+	"github.com/anyproto/anytype-heart/core/files/filededup"
 	"github.com/anyproto/anytype-heart/core/files/fileoffloader"
 	"github.com/anyproto/anytype-heart/core/files/reconciler"
+	"github.com/anyproto/anytype-heart/core/files/storagepolicy"
 	"github.com/anyproto/anytype-heart/pb"
 )
 
@@ -154,9 +157,71 @@ func (mw *Middleware) FileSpaceUsage(cctx context.Context, req *pb.RpcFileSpaceU
 	if err != nil {
 		return response(pb.RpcFileSpaceUsageResponseError_UNKNOWN_ERROR, err, nil)
 	}
+	usage.ReclaimableBytes = getService[filededup.Service](mw).ReclaimableBytes(req.SpaceId)
 	return response(pb.RpcFileSpaceUsageResponseError_NULL, nil, usage)
 }
 
+func (mw *Middleware) FileListDuplicates(_ context.Context, req *pb.RpcFileListDuplicatesRequest) *pb.RpcFileListDuplicatesResponse {
+	response := func(code pb.RpcFileListDuplicatesResponseErrorCode, err error, groups []*pb.RpcFileListDuplicatesResponseGroup) *pb.RpcFileListDuplicatesResponse {
+		m := &pb.RpcFileListDuplicatesResponse{
+			Error:  &pb.RpcFileListDuplicatesResponseError{Code: code},
+			Groups: groups,
+		}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+
+	groups, err := getService[filededup.Service](mw).ListDuplicates(req.SpaceIds)
+	if err != nil {
+		return response(pb.RpcFileListDuplicatesResponseError_UNKNOWN_ERROR, err, nil)
+	}
+	res := make([]*pb.RpcFileListDuplicatesResponseGroup, 0, len(groups))
+	for _, group := range groups {
+		objects := make([]*pb.RpcFileListDuplicatesResponseGroupObject, 0, len(group.Objects))
+		for _, obj := range group.Objects {
+			objects = append(objects, &pb.RpcFileListDuplicatesResponseGroupObject{
+				ObjectId: obj.Id,
+				SpaceId:  obj.SpaceId,
+				FileId:   obj.FileId.String(),
+			})
+		}
+		res = append(res, &pb.RpcFileListDuplicatesResponseGroup{
+			ContentHash:      group.ContentHash,
+			SizeInBytes:      group.SizeInBytes,
+			ReclaimableBytes: group.ReclaimableBytes(),
+			Objects:          objects,
+		})
+	}
+	return response(pb.RpcFileListDuplicatesResponseError_NULL, nil, res)
+}
+
+func (mw *Middleware) FileMergeDuplicates(cctx context.Context, req *pb.RpcFileMergeDuplicatesRequest) *pb.RpcFileMergeDuplicatesResponse {
+	response := func(code pb.RpcFileMergeDuplicatesResponseErrorCode, err error, res *filededup.MergeResult) *pb.RpcFileMergeDuplicatesResponse {
+		m := &pb.RpcFileMergeDuplicatesResponse{
+			Error: &pb.RpcFileMergeDuplicatesResponseError{Code: code},
+		}
+		if res != nil {
+			m.ObjectsMerged = int32(res.ObjectsMerged)
+			m.BytesReclaimed = res.BytesReclaimed
+		}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+
+	if req.SpaceId == "" {
+		return response(pb.RpcFileMergeDuplicatesResponseError_BAD_INPUT, fmt.Errorf("spaceId is empty"), nil)
+	}
+	res, err := getService[filededup.Service](mw).Merge(cctx, req.SpaceId, req.ContentHashes)
+	if err != nil {
+		return response(pb.RpcFileMergeDuplicatesResponseError_UNKNOWN_ERROR, err, res)
+	}
+	return response(pb.RpcFileMergeDuplicatesResponseError_NULL, nil, res)
+}
+
 func (mw *Middleware) FileNodeUsage(ctx context.Context, req *pb.RpcFileNodeUsageRequest) *pb.RpcFileNodeUsageResponse {
 	usage, err := getService[files.Service](mw).GetNodeUsage(ctx)
 	code := mapErrorCode[pb.RpcFileNodeUsageResponseErrorCode](err)
@@ -201,3 +266,85 @@ func (mw *Middleware) FileReconcile(ctx context.Context, req *pb.RpcFileReconcil
 	}
 	return &pb.RpcFileReconcileResponse{}
 }
+
+func (mw *Middleware) FileStoragePolicyGet(_ context.Context, _ *pb.RpcFileStoragePolicyGetRequest) *pb.RpcFileStoragePolicyGetResponse {
+	policy := getService[storagepolicy.Service](mw).GetPolicy()
+	return &pb.RpcFileStoragePolicyGetResponse{
+		Error: &pb.RpcFileStoragePolicyGetResponseError{Code: pb.RpcFileStoragePolicyGetResponseError_NULL},
+		Policy: &pb.RpcFileStoragePolicyPolicy{
+			MaxLocalCacheBytes:     policy.MaxLocalCacheBytes,
+			OffloadNotOpenedDays:   int32(policy.OffloadNotOpenedDays),
+			OffloadLargerThanBytes: policy.OffloadLargerThanBytes,
+		},
+	}
+}
+
+func (mw *Middleware) FileStoragePolicySet(_ context.Context, req *pb.RpcFileStoragePolicySetRequest) *pb.RpcFileStoragePolicySetResponse {
+	response := func(code pb.RpcFileStoragePolicySetResponseErrorCode, err error) *pb.RpcFileStoragePolicySetResponse {
+		m := &pb.RpcFileStoragePolicySetResponse{Error: &pb.RpcFileStoragePolicySetResponseError{Code: code}}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+
+	if req.Policy == nil {
+		return response(pb.RpcFileStoragePolicySetResponseError_BAD_INPUT, fmt.Errorf("policy is empty"))
+	}
+	err := getService[storagepolicy.Service](mw).SetPolicy(config.StoragePolicy{
+		MaxLocalCacheBytes:     req.Policy.MaxLocalCacheBytes,
+		OffloadNotOpenedDays:   int(req.Policy.OffloadNotOpenedDays),
+		OffloadLargerThanBytes: req.Policy.OffloadLargerThanBytes,
+	})
+	if err != nil {
+		return response(pb.RpcFileStoragePolicySetResponseError_UNKNOWN_ERROR, err)
+	}
+	return response(pb.RpcFileStoragePolicySetResponseError_NULL, nil)
+}
+
+func (mw *Middleware) FileSetKeepOffline(_ context.Context, req *pb.RpcFileSetKeepOfflineRequest) *pb.RpcFileSetKeepOfflineResponse {
+	response := func(code pb.RpcFileSetKeepOfflineResponseErrorCode, err error) *pb.RpcFileSetKeepOfflineResponse {
+		m := &pb.RpcFileSetKeepOfflineResponse{Error: &pb.RpcFileSetKeepOfflineResponseError{Code: code}}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+
+	if len(req.ObjectIds) == 0 {
+		return response(pb.RpcFileSetKeepOfflineResponseError_BAD_INPUT, fmt.Errorf("object ids are empty"))
+	}
+	err := getService[storagepolicy.Service](mw).SetKeepOffline(req.ObjectIds, req.KeepOffline)
+	if err != nil {
+		return response(pb.RpcFileSetKeepOfflineResponseError_UNKNOWN_ERROR, err)
+	}
+	return response(pb.RpcFileSetKeepOfflineResponseError_NULL, nil)
+}
+
+func (mw *Middleware) FileStoragePolicyApply(cctx context.Context, req *pb.RpcFileStoragePolicyApplyRequest) *pb.RpcFileStoragePolicyApplyResponse {
+	response := func(code pb.RpcFileStoragePolicyApplyResponseErrorCode, err error, report *storagepolicy.Report) *pb.RpcFileStoragePolicyApplyResponse {
+		m := &pb.RpcFileStoragePolicyApplyResponse{Error: &pb.RpcFileStoragePolicyApplyResponseError{Code: code}}
+		if report != nil {
+			m.BytesToFree = report.BytesToFree
+			m.LocalBytesUsage = report.LocalBytesUsage
+			for _, f := range report.Files {
+				m.Files = append(m.Files, &pb.RpcFileStoragePolicyApplyResponseFile{
+					ObjectId:    f.ObjectId,
+					SpaceId:     f.SpaceId,
+					SizeInBytes: f.SizeInBytes,
+					Reason:      pb.RpcFileStoragePolicyApplyResponseReason(f.Reason),
+				})
+			}
+		}
+		if err != nil {
+			m.Error.Description = err.Error()
+		}
+		return m
+	}
+
+	report, err := getService[storagepolicy.Service](mw).Apply(cctx, req.DryRun)
+	if err != nil {
+		return response(pb.RpcFileStoragePolicyApplyResponseError_UNKNOWN_ERROR, err, report)
+	}
+	return response(pb.RpcFileStoragePolicyApplyResponseError_NULL, nil, report)
+}
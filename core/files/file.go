@@ -2,6 +2,7 @@ package files
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/storage"
 	"github.com/anyproto/anytype-heart/util/pbtypes"
@@ -80,6 +82,67 @@ func (f *file) audioDetails(ctx context.Context) (*types.Struct, error) {
 	return d, nil
 }
 
+// mediaDetails reads duration, codec and dimensions of audio and video files
+func (f *file) mediaDetails(ctx context.Context) (*types.Struct, error) {
+	r, err := f.Reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := mill.ParseMediaInfo(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &types.Struct{
+		Fields: map[string]*types.Value{},
+	}
+	if info.Duration > 0 {
+		d.Fields[bundle.RelationKeyMediaDuration.String()] = pbtypes.Float64(info.Duration)
+	}
+	if info.Codec != "" {
+		d.Fields[bundle.RelationKeyMediaCodec.String()] = pbtypes.String(info.Codec)
+	}
+	if info.Bitrate > 0 {
+		d.Fields[bundle.RelationKeyMediaBitrate.String()] = pbtypes.Int64(int64(info.Bitrate))
+	}
+	if info.Width > 0 && info.Height > 0 {
+		d.Fields[bundle.RelationKeyWidthInPixels.String()] = pbtypes.Int64(int64(info.Width))
+		d.Fields[bundle.RelationKeyHeightInPixels.String()] = pbtypes.Int64(int64(info.Height))
+	}
+	return d, nil
+}
+
+// pdfDetails reads page count and document information of PDF files. Text of pages is stored locally
+// for full-text indexing, so the document is parsed only once
+func (f *file) pdfDetails(ctx context.Context) (*types.Struct, error) {
+	r, err := f.Reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := mill.ParsePdfInfo(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.node.fileStore.SetPdfText(f.fileId, info.Text); err != nil {
+		return nil, fmt.Errorf("store pdf text: %w", err)
+	}
+
+	d := &types.Struct{
+		Fields: map[string]*types.Value{
+			bundle.RelationKeyPdfPageCount.String(): pbtypes.Int64(int64(info.Pages)),
+		},
+	}
+	if info.Title != "" {
+		d.Fields[bundle.RelationKeyPdfTitle.String()] = pbtypes.String(info.Title)
+	}
+	if info.Author != "" {
+		d.Fields[bundle.RelationKeyPdfAuthor.String()] = pbtypes.String(info.Author)
+	}
+	return d, nil
+}
+
 func (f *file) Details(ctx context.Context) (*types.Struct, domain.TypeKey, error) {
 	meta := f.Meta()
 
@@ -99,6 +162,9 @@ func (f *file) Details(ctx context.Context) (*types.Struct, domain.TypeKey, erro
 	if meta.Media == "application/pdf" {
 		typeKey = bundle.TypeKeyFile
 		t.Fields[bundle.RelationKeyLayout.String()] = pbtypes.Float64(float64(model.ObjectType_pdf))
+		if pdfDetails, err := f.pdfDetails(ctx); err == nil {
+			t = pbtypes.StructMerge(t, pdfDetails, false)
+		}
 	}
 	if strings.HasPrefix(meta.Media, "video") {
 		typeKey = bundle.TypeKeyVideo
@@ -112,6 +178,12 @@ func (f *file) Details(ctx context.Context) (*types.Struct, domain.TypeKey, erro
 		typeKey = bundle.TypeKeyAudio
 	}
 
+	if strings.HasPrefix(meta.Media, "audio") || strings.HasPrefix(meta.Media, "video") {
+		if mediaDetails, err := f.mediaDetails(ctx); err == nil {
+			t = pbtypes.StructMerge(t, mediaDetails, false)
+		}
+	}
+
 	return t, typeKey, nil
 }
 
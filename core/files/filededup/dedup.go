@@ -0,0 +1,445 @@
+package filededup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/gogo/protobuf/types"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/file"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/filestore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const CName = "core.files.filededup"
+
+var log = logging.Logger(CName).Desugar()
+
+// reclaimableTTL is how long the calculated reclaimable size of a space is shown before it is recalculated
+const reclaimableTTL = 10 * time.Minute
+
+var ErrObjectsInDifferentSpaces = errors.New("duplicates could be merged only within one space")
+
+// Service finds file objects with the same content. Files are deduplicated by the checksum of the original
+// content, so the same upload in several spaces, or under different names, is reported as one group
+type Service interface {
+	app.ComponentRunnable
+
+	// ListDuplicates returns groups of file objects with equal content in given spaces, or in all spaces if none given
+	ListDuplicates(spaceIds []string) ([]*Group, error)
+	// ReclaimableBytes returns the last calculated size that could be freed in the space by merging its duplicates.
+	// The size is recalculated in background when it is missing or outdated, so zero is returned before the first calculation
+	ReclaimableBytes(spaceId string) uint64
+	// Merge rewrites references to duplicates of given content hashes, or of all groups if none given,
+	// to the oldest file object of each group and deletes the rest
+	Merge(ctx context.Context, spaceId string, contentHashes []string) (*MergeResult, error)
+}
+
+type objectDeleter interface {
+	DeleteObjectByFullID(id domain.FullID) (err error)
+}
+
+type Object struct {
+	Id        string
+	SpaceId   string
+	FileId    domain.FileId
+	AddedDate int64
+}
+
+type Group struct {
+	ContentHash string
+	SizeInBytes uint64
+	Objects     []Object
+}
+
+// ReclaimableBytes counts distinct copies of content inside each space except the first one.
+// Copies in different spaces are reported, but they can't be reclaimed because spaces are stored separately
+func (g *Group) ReclaimableBytes() uint64 {
+	fileIdsBySpace := map[string]map[domain.FileId]struct{}{}
+	for _, obj := range g.Objects {
+		if fileIdsBySpace[obj.SpaceId] == nil {
+			fileIdsBySpace[obj.SpaceId] = map[domain.FileId]struct{}{}
+		}
+		fileIdsBySpace[obj.SpaceId][obj.FileId] = struct{}{}
+	}
+	var copies uint64
+	for _, fileIds := range fileIdsBySpace {
+		copies += uint64(len(fileIds) - 1)
+	}
+	return copies * g.SizeInBytes
+}
+
+type MergeResult struct {
+	ObjectsMerged  int
+	BytesReclaimed uint64
+}
+
+type reclaimableSize struct {
+	bytes        uint64
+	calculatedAt time.Time
+	inProgress   bool
+}
+
+type service struct {
+	objectStore objectstore.ObjectStore
+	fileStore   filestore.FileStore
+	picker      cache.ObjectGetter
+	deleter     objectDeleter
+
+	componentCtx       context.Context
+	componentCtxCancel context.CancelFunc
+
+	// contentByFileId caches checksums of files, variants of a file don't change after upload
+	contentLock     sync.Mutex
+	contentByFileId map[domain.FileId]fileContent
+
+	reclaimableLock    sync.Mutex
+	reclaimableBySpace map[string]*reclaimableSize
+}
+
+func New() Service {
+	componentCtx, componentCtxCancel := context.WithCancel(context.Background())
+	return &service{
+		componentCtx:       componentCtx,
+		componentCtxCancel: componentCtxCancel,
+		contentByFileId:    map[domain.FileId]fileContent{},
+		reclaimableBySpace: map[string]*reclaimableSize{},
+	}
+}
+
+func (s *service) Init(a *app.App) error {
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	s.fileStore = app.MustComponent[filestore.FileStore](a)
+	s.picker = app.MustComponent[cache.ObjectGetter](a)
+	s.deleter = app.MustComponent[objectDeleter](a)
+	return nil
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(_ context.Context) error {
+	return nil
+}
+
+func (s *service) Close(_ context.Context) error {
+	s.componentCtxCancel()
+	return nil
+}
+
+func (s *service) ListDuplicates(spaceIds []string) ([]*Group, error) {
+	filters := []*model.BlockContentDataviewFilter{
+		{
+			RelationKey: bundle.RelationKeyFileId.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEmpty,
+		},
+		{
+			RelationKey: bundle.RelationKeyIsDeleted.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEqual,
+			Value:       pbtypes.Bool(true),
+		},
+	}
+	if len(spaceIds) > 0 {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(spaceIds),
+		})
+	}
+	records, err := s.objectStore.Query(database.Query{Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("query file objects: %w", err)
+	}
+
+	groupsByHash := map[string]*Group{}
+	for _, rec := range records {
+		obj := Object{
+			Id:        pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()),
+			SpaceId:   pbtypes.GetString(rec.Details, bundle.RelationKeySpaceId.String()),
+			FileId:    domain.FileId(pbtypes.GetString(rec.Details, bundle.RelationKeyFileId.String())),
+			AddedDate: pbtypes.GetInt64(rec.Details, bundle.RelationKeyAddedDate.String()),
+		}
+		content, err := s.fileContent(obj.FileId)
+		if err != nil {
+			log.Debug("skip file without local variants", zap.String("fileId", obj.FileId.String()), zap.Error(err))
+			continue
+		}
+		group, ok := groupsByHash[content.hash]
+		if !ok {
+			group = &Group{ContentHash: content.hash, SizeInBytes: content.size}
+			groupsByHash[content.hash] = group
+		}
+		group.Objects = append(group.Objects, obj)
+	}
+
+	var groups []*Group
+	for _, group := range groupsByHash {
+		if len(group.Objects) < 2 {
+			continue
+		}
+		sortObjects(group.Objects)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].SizeInBytes != groups[j].SizeInBytes {
+			return groups[i].SizeInBytes > groups[j].SizeInBytes
+		}
+		return groups[i].ContentHash < groups[j].ContentHash
+	})
+	return groups, nil
+}
+
+// sortObjects puts the oldest object first, it survives the merge
+func sortObjects(objects []Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].AddedDate != objects[j].AddedDate {
+			return objects[i].AddedDate < objects[j].AddedDate
+		}
+		return objects[i].Id < objects[j].Id
+	})
+}
+
+type fileContent struct {
+	hash string
+	size uint64
+}
+
+// fileContent returns checksum of the original content and size of the largest variant
+func (s *service) fileContent(fileId domain.FileId) (fileContent, error) {
+	s.contentLock.Lock()
+	content, ok := s.contentByFileId[fileId]
+	s.contentLock.Unlock()
+	if ok {
+		return content, nil
+	}
+	content, err := s.listFileContent(fileId)
+	if err != nil {
+		return fileContent{}, err
+	}
+	s.contentLock.Lock()
+	s.contentByFileId[fileId] = content
+	s.contentLock.Unlock()
+	return content, nil
+}
+
+func (s *service) listFileContent(fileId domain.FileId) (fileContent, error) {
+	variants, err := s.fileStore.ListFileVariants(fileId)
+	if err != nil {
+		return fileContent{}, err
+	}
+	var content fileContent
+	for _, variant := range variants {
+		if content.hash == "" {
+			content.hash = variant.Source
+		}
+		if uint64(variant.Size_) > content.size {
+			content.size = uint64(variant.Size_)
+		}
+	}
+	if content.hash == "" {
+		return fileContent{}, fmt.Errorf("no variants with source checksum")
+	}
+	return content, nil
+}
+
+func (s *service) ReclaimableBytes(spaceId string) uint64 {
+	s.reclaimableLock.Lock()
+	defer s.reclaimableLock.Unlock()
+	size, ok := s.reclaimableBySpace[spaceId]
+	if !ok {
+		size = &reclaimableSize{}
+		s.reclaimableBySpace[spaceId] = size
+	}
+	if !size.inProgress && time.Since(size.calculatedAt) > reclaimableTTL {
+		size.inProgress = true
+		go s.calculateReclaimableBytes(spaceId)
+	}
+	return size.bytes
+}
+
+func (s *service) calculateReclaimableBytes(spaceId string) {
+	var total uint64
+	groups, err := s.ListDuplicates([]string{spaceId})
+	if err != nil {
+		log.Warn("calculate reclaimable bytes", zap.String("spaceId", spaceId), zap.Error(err))
+	}
+	for _, group := range groups {
+		total += group.ReclaimableBytes()
+	}
+
+	s.reclaimableLock.Lock()
+	defer s.reclaimableLock.Unlock()
+	size := s.reclaimableBySpace[spaceId]
+	size.inProgress = false
+	if err == nil && s.componentCtx.Err() == nil {
+		size.bytes = total
+		size.calculatedAt = time.Now()
+	}
+}
+
+// resetReclaimableBytes makes the next ReclaimableBytes call recalculate the size
+func (s *service) resetReclaimableBytes(spaceId string) {
+	s.reclaimableLock.Lock()
+	defer s.reclaimableLock.Unlock()
+	if size, ok := s.reclaimableBySpace[spaceId]; ok {
+		size.calculatedAt = time.Time{}
+	}
+}
+
+func (s *service) Merge(ctx context.Context, spaceId string, contentHashes []string) (*MergeResult, error) {
+	groups, err := s.ListDuplicates([]string{spaceId})
+	if err != nil {
+		return nil, err
+	}
+	filter := map[string]bool{}
+	for _, hash := range contentHashes {
+		filter[hash] = true
+	}
+
+	res := &MergeResult{}
+	defer s.resetReclaimableBytes(spaceId)
+	for _, group := range groups {
+		if len(filter) > 0 && !filter[group.ContentHash] {
+			continue
+		}
+		reclaimable := group.ReclaimableBytes()
+		merged, err := s.mergeGroup(ctx, group)
+		res.ObjectsMerged += merged
+		if err != nil {
+			return res, fmt.Errorf("merge %s: %w", group.ContentHash, err)
+		}
+		res.BytesReclaimed += reclaimable
+	}
+	return res, nil
+}
+
+func (s *service) mergeGroup(ctx context.Context, group *Group) (merged int, err error) {
+	survivor := group.Objects[0]
+	replace := map[string]string{}
+	for _, obj := range group.Objects[1:] {
+		if obj.SpaceId != survivor.SpaceId {
+			return 0, ErrObjectsInDifferentSpaces
+		}
+		replace[obj.Id] = survivor.Id
+	}
+
+	for _, obj := range group.Objects[1:] {
+		if ctx.Err() != nil {
+			return merged, ctx.Err()
+		}
+		inbound, err := s.objectStore.GetInboundLinksByID(obj.Id)
+		if err != nil {
+			return merged, fmt.Errorf("get inbound links: %w", err)
+		}
+		for _, id := range inbound {
+			if _, isDuplicate := replace[id]; isDuplicate {
+				continue
+			}
+			err = cache.Do(s.picker, id, func(sb smartblock.SmartBlock) error {
+				st := sb.NewState()
+				if !replaceFileReferences(st, replace) {
+					return nil
+				}
+				return sb.Apply(st)
+			})
+			if err != nil {
+				return merged, fmt.Errorf("rewrite references in %s: %w", id, err)
+			}
+		}
+		// file data is kept because DeleteFileData removes only content that is not used by other objects
+		if err = s.deleter.DeleteObjectByFullID(domain.FullID{SpaceID: obj.SpaceId, ObjectID: obj.Id}); err != nil {
+			return merged, fmt.Errorf("delete duplicate: %w", err)
+		}
+		merged++
+	}
+	return merged, nil
+}
+
+// replaceFileReferences points file blocks, links and object relations to the surviving objects
+func replaceFileReferences(st *state.State, replace map[string]string) (changed bool) {
+	replacer := func(oldId string) string {
+		if newId, ok := replace[oldId]; ok {
+			changed = true
+			return newId
+		}
+		return oldId
+	}
+
+	var blockIds []string
+	st.Iterate(func(b simple.Block) (isContinue bool) {
+		blockIds = append(blockIds, b.Model().Id)
+		return true
+	})
+	for _, id := range blockIds {
+		b := st.Get(id)
+		if fb, ok := b.(file.Block); ok {
+			if target := fb.TargetObjectId(); replacer(target) != target {
+				fb.SetTargetObjectId(replace[target])
+			}
+			continue
+		}
+		if lr, ok := b.(simple.ObjectLinkReplacer); ok {
+			lr.ReplaceLinkIds(replacer)
+		}
+	}
+
+	details := st.Details()
+	for _, rel := range st.GetRelationLinks() {
+		if rel.Format != model.RelationFormat_object && rel.Format != model.RelationFormat_file {
+			continue
+		}
+		value := pbtypes.Get(details, rel.Key)
+		if value == nil {
+			continue
+		}
+		if newValue, ok := replaceInValue(value, replacer); ok {
+			st.SetDetail(rel.Key, newValue)
+		}
+	}
+	return changed
+}
+
+func replaceInValue(value *types.Value, replacer func(string) string) (*types.Value, bool) {
+	switch v := value.Kind.(type) {
+	case *types.Value_StringValue:
+		if newId := replacer(v.StringValue); newId != v.StringValue {
+			return pbtypes.String(newId), true
+		}
+	case *types.Value_ListValue:
+		ids := pbtypes.GetStringListValue(value)
+		var (
+			res      = make([]string, 0, len(ids))
+			seen     = map[string]bool{}
+			replaced bool
+		)
+		for _, id := range ids {
+			newId := replacer(id)
+			replaced = replaced || newId != id
+			if !seen[newId] {
+				seen[newId] = true
+				res = append(res, newId)
+			}
+		}
+		if replaced {
+			return pbtypes.StringList(res), true
+		}
+	}
+	return nil, false
+}
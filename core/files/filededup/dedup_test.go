@@ -0,0 +1,248 @@
+package filededup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/block/cache/mock_cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/filestore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/storage"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type fileStoreStub struct {
+	filestore.FileStore
+	variants map[domain.FileId][]*storage.FileInfo
+	calls    int
+}
+
+func (s *fileStoreStub) ListFileVariants(fileId domain.FileId) ([]*storage.FileInfo, error) {
+	s.calls++
+	return s.variants[fileId], nil
+}
+
+type deleterStub struct {
+	deleted []domain.FullID
+}
+
+func (d *deleterStub) DeleteObjectByFullID(id domain.FullID) error {
+	d.deleted = append(d.deleted, id)
+	return nil
+}
+
+type fixture struct {
+	*service
+	objectStore *objectstore.StoreFixture
+	fileStore   *fileStoreStub
+	picker      *mock_cache.MockObjectGetter
+	deleter     *deleterStub
+}
+
+func newFixture(t *testing.T) *fixture {
+	fx := &fixture{
+		service:     New().(*service),
+		objectStore: objectstore.NewStoreFixture(t),
+		fileStore: &fileStoreStub{variants: map[domain.FileId][]*storage.FileInfo{
+			"file1": {{Source: "hash1", Size_: 100}, {Source: "hash1", Size_: 10}},
+			"file2": {{Source: "hash1", Size_: 100}},
+			"file3": {{Source: "hash2", Size_: 50}},
+		}},
+		picker:  mock_cache.NewMockObjectGetter(t),
+		deleter: &deleterStub{},
+	}
+	fx.service.objectStore = fx.objectStore
+	fx.service.fileStore = fx.fileStore
+	fx.service.picker = fx.picker
+	fx.service.deleter = fx.deleter
+	fx.objectStore.AddObjects(t, []objectstore.TestObject{
+		fileObject("obj1", "space1", "file1", 10),
+		fileObject("obj2", "space1", "file2", 20),
+		fileObject("obj3", "space1", "file3", 30),
+		fileObject("obj4", "space2", "file1", 40),
+		fileObject("obj5", "space1", "fileWithoutVariants", 50),
+	})
+	return fx
+}
+
+func fileObject(id, spaceId string, fileId domain.FileId, addedDate int64) objectstore.TestObject {
+	return objectstore.TestObject{
+		bundle.RelationKeyId:        pbtypes.String(id),
+		bundle.RelationKeySpaceId:   pbtypes.String(spaceId),
+		bundle.RelationKeyFileId:    pbtypes.String(fileId.String()),
+		bundle.RelationKeyAddedDate: pbtypes.Int64(addedDate),
+	}
+}
+
+func TestService_ListDuplicates(t *testing.T) {
+	t.Run("all spaces", func(t *testing.T) {
+		// given
+		fx := newFixture(t)
+
+		// when
+		groups, err := fx.ListDuplicates(nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []*Group{{
+			ContentHash: "hash1",
+			SizeInBytes: 100,
+			Objects: []Object{
+				{Id: "obj1", SpaceId: "space1", FileId: "file1", AddedDate: 10},
+				{Id: "obj2", SpaceId: "space1", FileId: "file2", AddedDate: 20},
+				{Id: "obj4", SpaceId: "space2", FileId: "file1", AddedDate: 40},
+			},
+		}}, groups)
+	})
+
+	t.Run("checksums of files are cached", func(t *testing.T) {
+		// given
+		fx := newFixture(t)
+		_, err := fx.ListDuplicates([]string{"space2"})
+		require.NoError(t, err)
+
+		// when
+		groups, err := fx.ListDuplicates([]string{"space2"})
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+		assert.Equal(t, 1, fx.fileStore.calls)
+	})
+}
+
+func TestService_Merge(t *testing.T) {
+	// given
+	fx := newFixture(t)
+	require.NoError(t, fx.objectStore.UpdateObjectLinks("page", []string{"obj2"}))
+	page := smarttest.New("page")
+	page.AddBlock(simple.New(&model.Block{Id: "page", ChildrenIds: []string{"file"}})).
+		AddBlock(simple.New(&model.Block{Id: "file", Content: &model.BlockContentOfFile{File: &model.BlockContentFile{TargetObjectId: "obj2"}}}))
+	fx.picker.EXPECT().GetObject(mock.Anything, "page").Return(page, nil)
+
+	// when
+	res, err := fx.Merge(context.Background(), "space1", nil)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &MergeResult{ObjectsMerged: 1, BytesReclaimed: 100}, res)
+	assert.Equal(t, "obj1", page.Pick("file").Model().GetFile().TargetObjectId)
+	assert.Equal(t, []domain.FullID{{SpaceID: "space1", ObjectID: "obj2"}}, fx.deleter.deleted)
+}
+
+func TestReplaceFileReferences(t *testing.T) {
+	replace := map[string]string{"duplicate": "survivor"}
+
+	t.Run("blocks and relations", func(t *testing.T) {
+		// given
+		st := state.NewDoc("root", map[string]simple.Block{
+			"root": simple.New(&model.Block{Id: "root", ChildrenIds: []string{"file", "link"}}),
+			"file": simple.New(&model.Block{Id: "file", Content: &model.BlockContentOfFile{File: &model.BlockContentFile{TargetObjectId: "duplicate"}}}),
+			"link": simple.New(&model.Block{Id: "link", Content: &model.BlockContentOfLink{Link: &model.BlockContentLink{TargetBlockId: "duplicate"}}}),
+		}).NewState()
+		st.AddRelationLinks(
+			&model.RelationLink{Key: "attachments", Format: model.RelationFormat_file},
+			&model.RelationLink{Key: "title", Format: model.RelationFormat_shorttext},
+		)
+		st.SetDetail("attachments", pbtypes.StringList([]string{"survivor", "duplicate"}))
+		st.SetDetail("title", pbtypes.String("duplicate"))
+
+		// when
+		changed := replaceFileReferences(st, replace)
+
+		// then
+		assert.True(t, changed)
+		assert.Equal(t, "survivor", st.Pick("file").Model().GetFile().TargetObjectId)
+		assert.Equal(t, "survivor", st.Pick("link").Model().GetLink().TargetBlockId)
+		assert.Equal(t, []string{"survivor"}, pbtypes.GetStringList(st.Details(), "attachments"))
+		assert.Equal(t, "duplicate", pbtypes.GetString(st.Details(), "title"))
+	})
+
+	t.Run("no references", func(t *testing.T) {
+		// given
+		st := state.NewDoc("root", map[string]simple.Block{
+			"root": simple.New(&model.Block{Id: "root"}),
+		}).NewState()
+
+		// when
+		changed := replaceFileReferences(st, replace)
+
+		// then
+		assert.False(t, changed)
+	})
+}
+
+func TestGroup_ReclaimableBytes(t *testing.T) {
+	// given
+	group := &Group{
+		ContentHash: "hash",
+		SizeInBytes: 100,
+		Objects: []Object{
+			{Id: "obj1", SpaceId: "space1", FileId: "file1"},
+			{Id: "obj2", SpaceId: "space1", FileId: "file2"},
+			{Id: "obj3", SpaceId: "space1", FileId: "file2"},
+			{Id: "obj4", SpaceId: "space2", FileId: "file1"},
+		},
+	}
+
+	// when
+	reclaimable := group.ReclaimableBytes()
+
+	// then: only the second file in space1 takes additional storage
+	assert.Equal(t, uint64(100), reclaimable)
+}
+
+func TestSortObjects(t *testing.T) {
+	// given
+	objects := []Object{
+		{Id: "b", AddedDate: 20},
+		{Id: "c", AddedDate: 10},
+		{Id: "a", AddedDate: 20},
+	}
+
+	// when
+	sortObjects(objects)
+
+	// then
+	assert.Equal(t, []Object{
+		{Id: "c", AddedDate: 10},
+		{Id: "a", AddedDate: 20},
+		{Id: "b", AddedDate: 20},
+	}, objects)
+}
+
+func TestReplaceInValue(t *testing.T) {
+	replacer := func(id string) string {
+		if id == "duplicate" {
+			return "survivor"
+		}
+		return id
+	}
+
+	t.Run("string", func(t *testing.T) {
+		value, ok := replaceInValue(pbtypes.String("duplicate"), replacer)
+		assert.True(t, ok)
+		assert.Equal(t, pbtypes.String("survivor"), value)
+	})
+
+	t.Run("list without duplicates after replace", func(t *testing.T) {
+		value, ok := replaceInValue(pbtypes.StringList([]string{"survivor", "other", "duplicate"}), replacer)
+		assert.True(t, ok)
+		assert.Equal(t, pbtypes.StringList([]string{"survivor", "other"}), value)
+	})
+
+	t.Run("nothing to replace", func(t *testing.T) {
+		_, ok := replaceInValue(pbtypes.StringList([]string{"other"}), replacer)
+		assert.False(t, ok)
+	})
+}
@@ -37,6 +37,8 @@ type Service interface {
 	FilesOffload(ctx context.Context, objectIds []string, includeNotPinned bool) (err error)
 	FileSpaceOffload(ctx context.Context, spaceId string, includeNotPinned bool) (filesOffloaded int, totalSize uint64, err error)
 	FileOffloadRaw(ctx context.Context, id domain.FullFileId) (totalSize uint64, err error)
+	// FileLocalSize returns size of file blocks that are stored locally
+	FileLocalSize(ctx context.Context, id domain.FullFileId) (totalSize uint64, err error)
 }
 
 type service struct {
@@ -230,6 +232,11 @@ func (s *service) FileOffloadRaw(ctx context.Context, id domain.FullFileId) (tot
 	return totalSize, nil
 }
 
+func (s *service) FileLocalSize(ctx context.Context, id domain.FullFileId) (totalSize uint64, err error) {
+	totalSize, _, err = s.getAllExistingFileBlocksCids(ctx, id)
+	return totalSize, err
+}
+
 func (s *service) getAllExistingFileBlocksCids(ctx context.Context, id domain.FullFileId) (totalSize uint64, cids []cid.Cid, err error) {
 	var getCidsLinksRecursively func(c cid.Cid) (err error)
 	dagService := s.dagServiceForSpace(id.SpaceId)
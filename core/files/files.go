@@ -21,10 +21,12 @@ import (
 	"github.com/miolini/datacounter"
 	"github.com/multiformats/go-base32"
 	mh "github.com/multiformats/go-multihash"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/filestorage"
 	"github.com/anyproto/anytype-heart/core/filestorage/filesync"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/crypto/symmetric"
 	"github.com/anyproto/anytype-heart/pkg/lib/crypto/symmetric/cfb"
@@ -44,6 +46,8 @@ const (
 
 var log = logging.Logger("anytype-files")
 
+const fileIdKey = attribute.Key("anytype.file.id")
+
 var _ Service = (*service)(nil)
 
 type Service interface {
@@ -114,6 +118,16 @@ func (r *AddResult) Commit() {
 }
 
 func (s *service) FileAdd(ctx context.Context, spaceId string, options ...AddOption) (*AddResult, error) {
+	ctx, span := tracing.Start(ctx, "files.FileAdd", tracing.SpaceId(spaceId))
+	res, err := s.fileAdd(ctx, spaceId, options...)
+	if res != nil {
+		span.SetAttributes(fileIdKey.String(res.FileId.String()))
+	}
+	tracing.End(span, err)
+	return res, err
+}
+
+func (s *service) fileAdd(ctx context.Context, spaceId string, options ...AddOption) (*AddResult, error) {
 	opts := AddOptions{}
 	for _, opt := range options {
 		opt(&opts)
@@ -634,6 +648,13 @@ func getEncryptorDecryptor(key symmetric.Key, mode storage.FileInfoEncryptionMod
 }
 
 func (s *service) FileByHash(ctx context.Context, id domain.FullFileId) (File, error) {
+	ctx, span := tracing.Start(ctx, "files.FileByHash", tracing.SpaceId(id.SpaceId), fileIdKey.String(id.FileId.String()))
+	file, err := s.fileByHash(ctx, id)
+	tracing.End(span, err)
+	return file, err
+}
+
+func (s *service) fileByHash(ctx context.Context, id domain.FullFileId) (File, error) {
 	fileList, err := s.fileStore.ListFileVariants(id.FileId)
 	if err != nil {
 		return nil, err
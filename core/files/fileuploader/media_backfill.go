@@ -0,0 +1,158 @@
+package fileuploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/domain/objectorigin"
+	"github.com/anyproto/anytype-heart/core/filestorage"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/badgerhelper"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
+)
+
+// mediaBackfillDoneKey marks that media uploaded before thumbnails and media details existed is queued for backfill
+var mediaBackfillDoneKey = []byte("media_backfill/done")
+
+// mediaBackfillItem is a queue item for audio or video file object without thumbnail. Should be fully serializable
+type mediaBackfillItem struct {
+	SpaceId  string
+	ObjectId string
+}
+
+func (it *mediaBackfillItem) Key() string {
+	return it.SpaceId + "/" + it.ObjectId
+}
+
+func makeMediaBackfillItem() *mediaBackfillItem {
+	return &mediaBackfillItem{}
+}
+
+// addMediaToBackfillQueue queues existing audio and video objects once. Items are stored in the queue,
+// so the backfill continues after restart. Files that are not stored locally are skipped, backfill should not
+// download them from the file node
+func (f *service) addMediaToBackfillQueue(ctx context.Context) error {
+	var done bool
+	err := f.db.View(func(txn *badger.Txn) (err error) {
+		done, err = badgerhelper.Has(txn, mediaBackfillDoneKey)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("check backfill flag: %w", err)
+	}
+	if done {
+		return nil
+	}
+
+	records, err := f.objectStore.Query(database.Query{
+		Filters: []*model.BlockContentDataviewFilter{
+			{
+				RelationKey: bundle.RelationKeyLayout.String(),
+				Condition:   model.BlockContentDataviewFilter_In,
+				Value:       pbtypes.IntList(int(model.ObjectType_audio), int(model.ObjectType_video)),
+			},
+			{
+				RelationKey: bundle.RelationKeyFileId.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEmpty,
+			},
+			{
+				RelationKey: bundle.RelationKeyThumbnailImage.String(),
+				Condition:   model.BlockContentDataviewFilter_Empty,
+			},
+			{
+				RelationKey: bundle.RelationKeyIsDeleted.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEqual,
+				Value:       pbtypes.Bool(true),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("query media objects: %w", err)
+	}
+	for _, rec := range records {
+		fileId := domain.FullFileId{
+			SpaceId: pbtypes.GetString(rec.Details, bundle.RelationKeySpaceId.String()),
+			FileId:  domain.FileId(pbtypes.GetString(rec.Details, bundle.RelationKeyFileId.String())),
+		}
+		size, err := f.fileOffloader.FileLocalSize(ctx, fileId)
+		if err != nil {
+			log.Warnf("get local size of file %s: %v", fileId.FileId, err)
+			continue
+		}
+		if size == 0 {
+			continue
+		}
+		err = f.mediaBackfillQueue.Add(&mediaBackfillItem{
+			SpaceId:  fileId.SpaceId,
+			ObjectId: pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()),
+		})
+		if err != nil {
+			return fmt.Errorf("add to backfill queue: %w", err)
+		}
+	}
+	return badgerhelper.SetValue(f.db, mediaBackfillDoneKey, nil)
+}
+
+// mediaBackfillHandler adds cover art as a thumbnail and makes the file indexer read media details again.
+// Items that fail are not retried, because the file could be deleted or not available.
+// File blocks are read only from the local storage, files offloaded after queueing are skipped
+func (f *service) mediaBackfillHandler(ctx context.Context, it *mediaBackfillItem) (persistentqueue.Action, error) {
+	ctx = context.WithValue(ctx, filestorage.CtxKeyRemoteLoadDisabled, true)
+	err := f.backfillMedia(ctx, it)
+	if errors.Is(err, context.Canceled) {
+		return persistentqueue.ActionRetry, err
+	}
+	if errors.Is(err, filestorage.ErrRemoteLoadDisabled) {
+		return persistentqueue.ActionDone, nil
+	}
+	if err != nil {
+		return persistentqueue.ActionDone, fmt.Errorf("backfill media %s: %w", it.ObjectId, err)
+	}
+	return persistentqueue.ActionDone, nil
+}
+
+func (f *service) backfillMedia(ctx context.Context, it *mediaBackfillItem) error {
+	details, err := f.objectStore.GetDetails(it.ObjectId)
+	if err != nil {
+		return fmt.Errorf("get details: %w", err)
+	}
+	fileId := domain.FullFileId{
+		SpaceId: it.SpaceId,
+		FileId:  domain.FileId(pbtypes.GetString(details.Details, bundle.RelationKeyFileId.String())),
+	}
+	file, err := f.fileService.FileByHash(ctx, fileId)
+	if err != nil {
+		return fmt.Errorf("get file: %w", err)
+	}
+	r, err := file.Reader(ctx)
+	if err != nil {
+		return fmt.Errorf("get reader: %w", err)
+	}
+	u := f.NewUploader(it.SpaceId, objectorigin.None()).(*uploader)
+	u.name = pbtypes.GetString(details.Details, bundle.RelationKeyName.String())
+	thumbnailId, err := u.uploadThumbnail(ctx, r, &mill.MediaThumbnail{Opts: thumbnailOpts})
+	if err != nil {
+		return err
+	}
+
+	return cache.Do(f.picker, it.ObjectId, func(sb smartblock.SmartBlock) error {
+		st := sb.NewState()
+		if thumbnailId != "" {
+			st.SetDetailAndBundledRelation(bundle.RelationKeyThumbnailImage, pbtypes.StringList([]string{thumbnailId}))
+		}
+		if pbtypes.GetFloat64(st.Details(), bundle.RelationKeyMediaDuration.String()) == 0 {
+			st.SetDetailAndBundledRelation(bundle.RelationKeyFileIndexingStatus, pbtypes.Int64(int64(model.FileIndexingStatus_NotIndexed)))
+		}
+		return sb.Apply(st)
+	})
+}
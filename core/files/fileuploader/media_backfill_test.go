@@ -0,0 +1,76 @@
+package fileuploader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/files/fileoffloader"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
+)
+
+type localSizes struct {
+	fileoffloader.Service
+	sizes map[domain.FileId]uint64
+}
+
+func (l *localSizes) FileLocalSize(_ context.Context, id domain.FullFileId) (uint64, error) {
+	return l.sizes[id.FileId], nil
+}
+
+func TestService_AddMediaToBackfillQueue(t *testing.T) {
+	// given
+	store := objectstore.NewStoreFixture(t)
+	mediaObject := func(id string, layout model.ObjectTypeLayout) objectstore.TestObject {
+		return objectstore.TestObject{
+			bundle.RelationKeyId:      pbtypes.String(id),
+			bundle.RelationKeySpaceId: pbtypes.String("space1"),
+			bundle.RelationKeyFileId:  pbtypes.String("file-" + id),
+			bundle.RelationKeyLayout:  pbtypes.Int64(int64(layout)),
+		}
+	}
+	store.AddObjects(t, []objectstore.TestObject{
+		mediaObject("video", model.ObjectType_video),
+		mediaObject("audio", model.ObjectType_audio),
+		mediaObject("offloaded", model.ObjectType_video),
+		mediaObject("image", model.ObjectType_image),
+	})
+	ds, err := datastore.NewInMemory()
+	require.NoError(t, err)
+	db, err := ds.LocalStorage()
+	require.NoError(t, err)
+
+	s := &service{
+		objectStore: store,
+		fileOffloader: &localSizes{sizes: map[domain.FileId]uint64{
+			"file-video": 100,
+			"file-audio": 100,
+			"file-image": 100,
+		}},
+		db: db,
+	}
+	s.mediaBackfillQueue = persistentqueue.New(
+		persistentqueue.NewBadgerStorage(db, []byte("queue/media_backfill/"), makeMediaBackfillItem),
+		zap.NewNop(),
+		s.mediaBackfillHandler,
+	)
+	t.Cleanup(func() {
+		_ = s.mediaBackfillQueue.Close()
+	})
+
+	// when
+	err = s.addMediaToBackfillQueue(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"space1/audio", "space1/video"}, s.mediaBackfillQueue.ListKeys())
+}
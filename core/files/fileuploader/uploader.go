@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/anyproto/any-sync/app"
+	"github.com/dgraph-io/badger/v4"
 	"github.com/gogo/protobuf/types"
 	"github.com/h2non/filetype"
 
@@ -26,18 +27,24 @@ import (
 	"github.com/anyproto/anytype-heart/core/domain/objectorigin"
 	"github.com/anyproto/anytype-heart/core/files"
 	"github.com/anyproto/anytype-heart/core/files/fileobject"
+	"github.com/anyproto/anytype-heart/core/files/fileoffloader"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/core"
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
 	"github.com/anyproto/anytype-heart/pkg/lib/logging"
 	"github.com/anyproto/anytype-heart/pkg/lib/mill"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	oserror "github.com/anyproto/anytype-heart/util/os"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
 	"github.com/anyproto/anytype-heart/util/uri"
 )
 
 var log = logging.Logger("file-uploader")
 
 type Service interface {
-	app.Component
+	app.ComponentRunnable
 
 	NewUploader(spaceId string, origin objectorigin.ObjectOrigin) Uploader
 }
@@ -47,6 +54,11 @@ type service struct {
 	tempDirProvider   core.TempDirProvider
 	picker            cache.ObjectGetter
 	fileObjectService fileobject.Service
+	fileOffloader     fileoffloader.Service
+	objectStore       objectstore.ObjectStore
+
+	db                 *badger.DB
+	mediaBackfillQueue *persistentqueue.Queue[*mediaBackfillItem]
 }
 
 func New() Service {
@@ -75,9 +87,36 @@ func (f *service) Init(a *app.App) error {
 	f.tempDirProvider = app.MustComponent[core.TempDirProvider](a)
 	f.picker = app.MustComponent[cache.ObjectGetter](a)
 	f.fileObjectService = app.MustComponent[fileobject.Service](a)
+	f.fileOffloader = app.MustComponent[fileoffloader.Service](a)
+	f.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+
+	var err error
+	f.db, err = app.MustComponent[datastore.Datastore](a).LocalStorage()
+	if err != nil {
+		return fmt.Errorf("get badger: %w", err)
+	}
+	f.mediaBackfillQueue = persistentqueue.New(
+		persistentqueue.NewBadgerStorage(f.db, []byte("queue/media_backfill/"), makeMediaBackfillItem),
+		log.Desugar(),
+		f.mediaBackfillHandler,
+	)
+	return nil
+}
+
+func (f *service) Run(_ context.Context) error {
+	f.mediaBackfillQueue.Run()
+	go func() {
+		if err := f.addMediaToBackfillQueue(context.Background()); err != nil {
+			log.Errorf("add media to backfill queue: %v", err)
+		}
+	}()
 	return nil
 }
 
+func (f *service) Close(_ context.Context) error {
+	return f.mediaBackfillQueue.Close()
+}
+
 var (
 	// limiting overall file upload goroutines
 	uploadFilesLimiter = make(chan struct{}, 8)
@@ -458,6 +497,15 @@ func (u *uploader) Upload(ctx context.Context) (result UploadResult) {
 	}
 	defer addResult.Commit()
 
+	if !addResult.IsExisting {
+		switch u.fileType {
+		case model.BlockContentFile_Video, model.BlockContentFile_Audio:
+			u.addThumbnail(ctx, buf, &mill.MediaThumbnail{Opts: thumbnailOpts})
+		case model.BlockContentFile_PDF:
+			u.addThumbnail(ctx, buf, &mill.PdfThumbnail{Opts: thumbnailOpts})
+		}
+	}
+
 	result.MIME = addResult.MIME
 	result.Size = addResult.Size
 
@@ -513,6 +561,58 @@ func (u *uploader) getOrCreateFileObject(ctx context.Context, addResult *files.A
 
 }
 
+var thumbnailOpts = mill.ImageResizeOpts{Width: "1280", Quality: "85"}
+
+// addThumbnail uploads preview produced by thumbnailMill (cover art of audio and video, first page of PDF)
+// as an image and links it to the file object being created
+func (u *uploader) addThumbnail(ctx context.Context, r io.ReadSeeker, thumbnailMill mill.Mill) {
+	thumbnailId, err := u.uploadThumbnail(ctx, r, thumbnailMill)
+	if err != nil {
+		log.Warnf("failed to add thumbnail: %v", err)
+		return
+	}
+	if thumbnailId == "" {
+		return
+	}
+	if u.additionalDetails == nil {
+		u.additionalDetails = &types.Struct{Fields: map[string]*types.Value{}}
+	} else {
+		u.additionalDetails = pbtypes.CopyStruct(u.additionalDetails, false)
+	}
+	u.additionalDetails.Fields[bundle.RelationKeyThumbnailImage.String()] = pbtypes.StringList([]string{thumbnailId})
+}
+
+// uploadThumbnail returns id of the uploaded image object, or empty id if the file has no preview
+func (u *uploader) uploadThumbnail(ctx context.Context, r io.ReadSeeker, thumbnailMill mill.Mill) (string, error) {
+	res, err := thumbnailMill.Mill(r, u.name)
+	if errors.Is(err, mill.ErrNoMediaThumbnail) || errors.Is(err, mill.ErrNoPdfThumbnail) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("make thumbnail: %w", err)
+	}
+	defer res.File.Close()
+	data, err := io.ReadAll(res.File)
+	if err != nil {
+		return "", fmt.Errorf("read thumbnail: %w", err)
+	}
+
+	thumbnailUploader := &uploader{
+		spaceId:           u.spaceId,
+		picker:            u.picker,
+		fileService:       u.fileService,
+		tempDirProvider:   u.tempDirProvider,
+		fileObjectService: u.fileObjectService,
+		origin:            u.origin,
+	}
+	name := strings.TrimSuffix(u.name, filepath.Ext(u.name)) + " thumbnail"
+	thumbnail := thumbnailUploader.SetBytes(data).SetName(name).SetType(model.BlockContentFile_Image).Upload(ctx)
+	if thumbnail.Err != nil {
+		return "", fmt.Errorf("upload thumbnail: %w", thumbnail.Err)
+	}
+	return thumbnail.FileObjectId, nil
+}
+
 func (u *uploader) detectType(buf *fileReader) model.BlockContentFileType {
 	b, err := buf.Peek(8192)
 	if err != nil && err != io.EOF {
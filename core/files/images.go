@@ -9,6 +9,7 @@ import (
 	ipld "github.com/ipfs/go-ipld-format"
 
 	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pkg/lib/ipfs/helpers"
 	"github.com/anyproto/anytype-heart/pkg/lib/localstore"
 	"github.com/anyproto/anytype-heart/pkg/lib/mill/schema"
@@ -39,6 +40,16 @@ func (s *service) ImageByHash(ctx context.Context, id domain.FullFileId) (Image,
 }
 
 func (s *service) ImageAdd(ctx context.Context, spaceId string, options ...AddOption) (*AddResult, error) {
+	ctx, span := tracing.Start(ctx, "files.ImageAdd", tracing.SpaceId(spaceId))
+	res, err := s.imageAdd(ctx, spaceId, options...)
+	if res != nil {
+		span.SetAttributes(fileIdKey.String(res.FileId.String()))
+	}
+	tracing.End(span, err)
+	return res, err
+}
+
+func (s *service) imageAdd(ctx context.Context, spaceId string, options ...AddOption) (*AddResult, error) {
 	opts := AddOptions{}
 	for _, opt := range options {
 		opt(&opts)
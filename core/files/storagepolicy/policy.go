@@ -0,0 +1,340 @@
+package storagepolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/util/periodicsync"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/anytype/config"
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/process"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/files/fileoffloader"
+	"github.com/anyproto/anytype-heart/core/filestorage"
+	"github.com/anyproto/anytype-heart/core/syncstatus/filesyncstatus"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const CName = "core.files.storagepolicy"
+
+var log = logging.Logger(CName).Desugar()
+
+const (
+	applyIntervalSecs = 60 * 60
+	applyTimeout      = 30 * time.Minute
+
+	// markOpenedInterval limits how often the last opened date is updated on access by the gateway
+	markOpenedInterval = time.Hour
+)
+
+// Service offloads files from local storage according to the storage policy.
+// Only files synced with the node are offloaded, favorite files and files marked to keep offline are never offloaded
+type Service interface {
+	app.ComponentRunnable
+
+	GetPolicy() config.StoragePolicy
+	SetPolicy(policy config.StoragePolicy) error
+	// Apply offloads files matched by the policy. In dry-run mode matched files are only reported
+	Apply(ctx context.Context, dryRun bool) (*Report, error)
+	// SetKeepOffline marks files to be never offloaded by the policy
+	SetKeepOffline(objectIds []string, keepOffline bool) error
+	// MarkOpened updates the last opened date of the file object accessed without opening it, e.g. via gateway
+	MarkOpened(objectId string)
+}
+
+type Reason int
+
+const (
+	ReasonNotOpened Reason = iota
+	ReasonTooLarge
+	ReasonCacheLimit
+)
+
+type File struct {
+	ObjectId    string
+	SpaceId     string
+	SizeInBytes uint64
+	Reason      Reason
+}
+
+type Report struct {
+	Files           []File
+	BytesToFree     uint64
+	LocalBytesUsage uint64
+}
+
+type service struct {
+	config         *config.Config
+	objectStore    objectstore.ObjectStore
+	fileStorage    filestorage.FileStorage
+	fileOffloader  fileoffloader.Service
+	processService process.Service
+	picker         cache.ObjectGetter
+	periodicApply  periodicsync.PeriodicSync
+
+	markedLock sync.Mutex
+	marked     map[string]time.Time
+
+	now func() time.Time
+}
+
+func New() Service {
+	return &service{now: time.Now, marked: map[string]time.Time{}}
+}
+
+func (s *service) Init(a *app.App) error {
+	s.config = app.MustComponent[*config.Config](a)
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	s.fileStorage = app.MustComponent[filestorage.FileStorage](a)
+	s.fileOffloader = app.MustComponent[fileoffloader.Service](a)
+	s.processService = app.MustComponent[process.Service](a)
+	s.picker = app.MustComponent[cache.ObjectGetter](a)
+	s.periodicApply = periodicsync.NewPeriodicSync(applyIntervalSecs, applyTimeout, s.applyPeriodically, logger.CtxLogger{Logger: log})
+	return nil
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(_ context.Context) error {
+	s.periodicApply.Run()
+	return nil
+}
+
+func (s *service) Close(_ context.Context) error {
+	if s.periodicApply != nil {
+		s.periodicApply.Close()
+	}
+	return nil
+}
+
+func (s *service) GetPolicy() config.StoragePolicy {
+	return s.config.GetStoragePolicy()
+}
+
+func (s *service) SetPolicy(policy config.StoragePolicy) error {
+	if policy.OffloadNotOpenedDays < 0 {
+		return fmt.Errorf("invalid number of days: %d", policy.OffloadNotOpenedDays)
+	}
+	if err := s.config.PersistStoragePolicy(policy); err != nil {
+		return fmt.Errorf("save policy: %w", err)
+	}
+	return nil
+}
+
+func (s *service) SetKeepOffline(objectIds []string, keepOffline bool) error {
+	for _, id := range objectIds {
+		err := cache.DoState(s.picker, id, func(st *state.State, sb smartblock.SmartBlock) error {
+			if pbtypes.GetString(st.Details(), bundle.RelationKeyFileId.String()) == "" {
+				return fmt.Errorf("object is not a file")
+			}
+			st.SetDetailAndBundledRelation(bundle.RelationKeyFileKeepOffline, pbtypes.Bool(keepOffline))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("set keep offline for %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) MarkOpened(objectId string) {
+	now := s.now()
+	s.markedLock.Lock()
+	if last, ok := s.marked[objectId]; ok && now.Sub(last) < markOpenedInterval {
+		s.markedLock.Unlock()
+		return
+	}
+	for id, last := range s.marked {
+		if now.Sub(last) >= markOpenedInterval {
+			delete(s.marked, id)
+		}
+	}
+	s.marked[objectId] = now
+	s.markedLock.Unlock()
+
+	err := cache.Do(s.picker, objectId, func(sb smartblock.SmartBlock) error {
+		st := sb.NewState()
+		st.SetLocalDetail(bundle.RelationKeyLastOpenedDate.String(), pbtypes.Int64(now.Unix()))
+		return sb.Apply(st, smartblock.NoHistory, smartblock.NoEvent, smartblock.SkipIfNoChanges, smartblock.KeepInternalFlags, smartblock.IgnoreNoPermissions)
+	})
+	if err != nil {
+		log.Warn("update last opened date", zap.String("objectId", objectId), zap.Error(err))
+	}
+}
+
+func (s *service) applyPeriodically(ctx context.Context) error {
+	if s.GetPolicy().IsEmpty() {
+		return nil
+	}
+	report, err := s.Apply(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(report.Files) > 0 {
+		log.Info("files offloaded by storage policy", zap.Int("count", len(report.Files)), zap.Uint64("bytes", report.BytesToFree))
+	}
+	return nil
+}
+
+type candidate struct {
+	File
+	lastUsed int64
+}
+
+func (s *service) Apply(ctx context.Context, dryRun bool) (*Report, error) {
+	policy := s.GetPolicy()
+	localUsage, err := s.fileStorage.LocalDiskUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get local disk usage: %w", err)
+	}
+	report := &Report{LocalBytesUsage: localUsage}
+	if policy.IsEmpty() {
+		return report, nil
+	}
+
+	candidates, err := s.listCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notOpenedSince := s.now().AddDate(0, 0, -policy.OffloadNotOpenedDays).Unix()
+	var rest []candidate
+	for _, c := range candidates {
+		switch {
+		case policy.OffloadNotOpenedDays > 0 && c.lastUsed < notOpenedSince:
+			c.Reason = ReasonNotOpened
+		case policy.OffloadLargerThanBytes > 0 && c.SizeInBytes > policy.OffloadLargerThanBytes:
+			c.Reason = ReasonTooLarge
+		default:
+			rest = append(rest, c)
+			continue
+		}
+		report.Files = append(report.Files, c.File)
+		report.BytesToFree += c.SizeInBytes
+	}
+	// candidates are sorted by last usage, so the least recently used files are offloaded first
+	for _, c := range rest {
+		if policy.MaxLocalCacheBytes == 0 || localUsage <= policy.MaxLocalCacheBytes+report.BytesToFree {
+			break
+		}
+		c.Reason = ReasonCacheLimit
+		report.Files = append(report.Files, c.File)
+		report.BytesToFree += c.SizeInBytes
+	}
+
+	if dryRun || len(report.Files) == 0 {
+		return report, nil
+	}
+	return report, s.offload(ctx, report)
+}
+
+// listCandidates returns synced files that are stored locally, sorted by the last usage
+func (s *service) listCandidates(ctx context.Context) ([]candidate, error) {
+	records, err := s.objectStore.Query(database.Query{
+		Filters: []*model.BlockContentDataviewFilter{
+			{
+				RelationKey: bundle.RelationKeyFileId.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEmpty,
+			},
+			{
+				RelationKey: bundle.RelationKeyIsDeleted.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEqual,
+				Value:       pbtypes.Bool(true),
+			},
+			{
+				RelationKey: bundle.RelationKeyFileBackupStatus.String(),
+				Condition:   model.BlockContentDataviewFilter_Equal,
+				Value:       pbtypes.Int64(int64(filesyncstatus.Synced)),
+			},
+			{
+				RelationKey: bundle.RelationKeyIsFavorite.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEqual,
+				Value:       pbtypes.Bool(true),
+			},
+			{
+				RelationKey: bundle.RelationKeyFileKeepOffline.String(),
+				Condition:   model.BlockContentDataviewFilter_NotEqual,
+				Value:       pbtypes.Bool(true),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query synced files: %w", err)
+	}
+
+	candidates := make([]candidate, 0, len(records))
+	for _, rec := range records {
+		fileId := domain.FullFileId{
+			SpaceId: pbtypes.GetString(rec.Details, bundle.RelationKeySpaceId.String()),
+			FileId:  domain.FileId(pbtypes.GetString(rec.Details, bundle.RelationKeyFileId.String())),
+		}
+		size, err := s.fileOffloader.FileLocalSize(ctx, fileId)
+		if err != nil {
+			log.Warn("get local file size", zap.String("fileId", fileId.FileId.String()), zap.Error(err))
+			continue
+		}
+		if size == 0 {
+			continue
+		}
+		lastUsed := pbtypes.GetInt64(rec.Details, bundle.RelationKeyLastOpenedDate.String())
+		if lastUsed == 0 {
+			lastUsed = pbtypes.GetInt64(rec.Details, bundle.RelationKeyAddedDate.String())
+		}
+		candidates = append(candidates, candidate{
+			File: File{
+				ObjectId:    pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()),
+				SpaceId:     fileId.SpaceId,
+				SizeInBytes: size,
+			},
+			lastUsed: lastUsed,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].lastUsed < candidates[j].lastUsed
+	})
+	return candidates, nil
+}
+
+func (s *service) offload(ctx context.Context, report *Report) (err error) {
+	progress := process.NewProgress(pb.ModelProcess_OffloadFiles)
+	defer func() {
+		progress.Finish(err)
+	}()
+	if err = s.processService.Add(progress); err != nil {
+		return fmt.Errorf("add process: %w", err)
+	}
+	progress.SetProgressMessage("offloading files")
+	progress.SetTotal(int64(len(report.Files)))
+
+	for _, f := range report.Files {
+		select {
+		case <-progress.Canceled():
+			return fmt.Errorf("offloading canceled")
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if _, err := s.fileOffloader.FileOffload(ctx, f.ObjectId, false); err != nil {
+			log.Error("offload file by storage policy", zap.String("objectId", f.ObjectId), zap.Error(err))
+		}
+		progress.AddDone(1)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package storagepolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/anytype/config"
+	"github.com/anyproto/anytype-heart/core/block/cache/mock_cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/files/fileoffloader"
+	"github.com/anyproto/anytype-heart/core/filestorage"
+	"github.com/anyproto/anytype-heart/core/syncstatus/filesyncstatus"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type localSizes struct {
+	fileoffloader.Service
+	sizes map[domain.FileId]uint64
+}
+
+func (l *localSizes) FileLocalSize(_ context.Context, id domain.FullFileId) (uint64, error) {
+	return l.sizes[id.FileId], nil
+}
+
+type diskUsage struct {
+	filestorage.FileStorage
+	usage uint64
+}
+
+func (d *diskUsage) LocalDiskUsage(context.Context) (uint64, error) {
+	return d.usage, nil
+}
+
+func TestService_Apply(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	day := int64(24 * 60 * 60)
+
+	newFixture := func(t *testing.T, policy config.StoragePolicy) *service {
+		store := objectstore.NewStoreFixture(t)
+		fileObject := func(id string, lastOpened int64, opts ...func(o objectstore.TestObject)) objectstore.TestObject {
+			obj := objectstore.TestObject{
+				bundle.RelationKeyId:               pbtypes.String(id),
+				bundle.RelationKeySpaceId:          pbtypes.String("space1"),
+				bundle.RelationKeyFileId:           pbtypes.String("file-" + id),
+				bundle.RelationKeyFileBackupStatus: pbtypes.Int64(int64(filesyncstatus.Synced)),
+				bundle.RelationKeyLastOpenedDate:   pbtypes.Int64(lastOpened),
+			}
+			for _, opt := range opts {
+				opt(obj)
+			}
+			return obj
+		}
+		store.AddObjects(t, []objectstore.TestObject{
+			fileObject("old", now.Unix()-40*day),
+			fileObject("large", now.Unix()-day),
+			fileObject("recent", now.Unix()-2*day),
+			fileObject("newest", now.Unix()),
+			fileObject("favorite", now.Unix()-100*day, func(o objectstore.TestObject) {
+				o[bundle.RelationKeyIsFavorite] = pbtypes.Bool(true)
+			}),
+			fileObject("keepOffline", now.Unix()-100*day, func(o objectstore.TestObject) {
+				o[bundle.RelationKeyFileKeepOffline] = pbtypes.Bool(true)
+			}),
+			fileObject("notSynced", now.Unix()-100*day, func(o objectstore.TestObject) {
+				o[bundle.RelationKeyFileBackupStatus] = pbtypes.Int64(int64(filesyncstatus.Queued))
+			}),
+		})
+		return &service{
+			config:      &config.Config{ConfigRequired: config.ConfigRequired{StoragePolicy: &policy}},
+			objectStore: store,
+			fileStorage: &diskUsage{usage: 1000},
+			fileOffloader: &localSizes{sizes: map[domain.FileId]uint64{
+				"file-old":         100,
+				"file-large":       500,
+				"file-recent":      200,
+				"file-newest":      200,
+				"file-favorite":    100,
+				"file-keepOffline": 100,
+				"file-notSynced":   100,
+			}},
+			now: func() time.Time { return now },
+		}
+	}
+
+	t.Run("age and size rules", func(t *testing.T) {
+		// given
+		s := newFixture(t, config.StoragePolicy{OffloadNotOpenedDays: 30, OffloadLargerThanBytes: 300})
+
+		// when
+		report, err := s.Apply(context.Background(), true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []File{
+			{ObjectId: "old", SpaceId: "space1", SizeInBytes: 100, Reason: ReasonNotOpened},
+			{ObjectId: "large", SpaceId: "space1", SizeInBytes: 500, Reason: ReasonTooLarge},
+		}, report.Files)
+		assert.Equal(t, uint64(600), report.BytesToFree)
+	})
+
+	t.Run("cache limit offloads least recently used files", func(t *testing.T) {
+		// given
+		s := newFixture(t, config.StoragePolicy{MaxLocalCacheBytes: 300})
+
+		// when
+		report, err := s.Apply(context.Background(), true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []File{
+			{ObjectId: "old", SpaceId: "space1", SizeInBytes: 100, Reason: ReasonCacheLimit},
+			{ObjectId: "recent", SpaceId: "space1", SizeInBytes: 200, Reason: ReasonCacheLimit},
+			{ObjectId: "large", SpaceId: "space1", SizeInBytes: 500, Reason: ReasonCacheLimit},
+		}, report.Files)
+		assert.Equal(t, uint64(1000), report.LocalBytesUsage)
+	})
+
+	t.Run("empty policy", func(t *testing.T) {
+		// given
+		s := newFixture(t, config.StoragePolicy{})
+
+		// when
+		report, err := s.Apply(context.Background(), true)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, report.Files)
+	})
+}
+
+func TestService_MarkOpened(t *testing.T) {
+	t.Run("last opened date is updated once per interval", func(t *testing.T) {
+		// given
+		now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		sb := smarttest.New("file1")
+		picker := mock_cache.NewMockObjectGetter(t)
+		picker.EXPECT().GetObject(context.Background(), "file1").Return(sb, nil).Once()
+		s := &service{picker: picker, marked: map[string]time.Time{}, now: func() time.Time { return now }}
+
+		// when
+		s.MarkOpened("file1")
+		now = now.Add(time.Minute)
+		s.MarkOpened("file1")
+
+		// then
+		assert.Equal(t, now.Add(-time.Minute).Unix(), pbtypes.GetInt64(sb.LocalDetails(), bundle.RelationKeyLastOpenedDate.String()))
+	})
+}
@@ -242,6 +242,21 @@ func (mr *MockFileStoreMockRecorder) GetFileVariantBySource(arg0, arg1, arg2 any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileVariantBySource", reflect.TypeOf((*MockFileStore)(nil).GetFileVariantBySource), arg0, arg1, arg2)
 }
 
+// GetPdfText mocks base method.
+func (m *MockFileStore) GetPdfText(arg0 domain.FileId) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPdfText", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPdfText indicates an expected call of GetPdfText.
+func (mr *MockFileStoreMockRecorder) GetPdfText(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPdfText", reflect.TypeOf((*MockFileStore)(nil).GetPdfText), arg0)
+}
+
 // Indexes mocks base method.
 func (m *MockFileStore) Indexes() []localstore.Index {
 	m.ctrl.T.Helper()
@@ -427,3 +442,17 @@ func (mr *MockFileStoreMockRecorder) SetIsFileImported(arg0, arg1 any) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIsFileImported", reflect.TypeOf((*MockFileStore)(nil).SetIsFileImported), arg0, arg1)
 }
+
+// SetPdfText mocks base method.
+func (m *MockFileStore) SetPdfText(arg0 domain.FileId, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPdfText", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPdfText indicates an expected call of SetPdfText.
+func (mr *MockFileStoreMockRecorder) SetPdfText(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPdfText", reflect.TypeOf((*MockFileStore)(nil).SetPdfText), arg0, arg1)
+}
@@ -23,7 +23,11 @@ func (mw *Middleware) ListenSessionEvents(req *pb.StreamRequest, server lib.Clie
 	}
 
 	var srv event.SessionServer
-	if sender, ok := mw.applicationService.GetEventSender().(*event.GrpcSender); ok {
+	eventSender := mw.applicationService.GetEventSender()
+	if observed, ok := eventSender.(*event.ObservedSender); ok {
+		eventSender = observed.Unwrap()
+	}
+	if sender, ok := eventSender.(*event.GrpcSender); ok {
 		srv = sender.SetSessionServer(req.Token, server)
 	} else {
 		log.Fatal("failed to ListenEvents: has a wrong Sender")
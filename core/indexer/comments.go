@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"time"
+
+	"github.com/anyproto/anytype-heart/core/block/editor/comment"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// replyNotificationMaxAge limits the age of notified replies, so indexing of the space on a new device
+// doesn't notify about the whole history of comments
+const replyNotificationMaxAge = 7 * 24 * time.Hour
+
+type participantIdProvider interface {
+	MyParticipantId(spaceId string) string
+}
+
+type notificationSender interface {
+	CreateAndSend(notification *model.Notification) error
+}
+
+// notifyCommentReplies notifies about replies to the threads of the current participant. Replies are notified on indexing,
+// so replies received while the object is not opened are notified too, the same reply is notified only once
+func (i *indexer) notifyCommentReplies(info smartblock.DocInfo) {
+	if len(info.Comments) == 0 || i.notifications == nil || i.participantIds == nil {
+		return
+	}
+	spaceId := info.Space.Id()
+	notifications := comment.ReplyNotifications(
+		spaceId,
+		info.Id,
+		pbtypes.GetString(info.Details, bundle.RelationKeyName.String()),
+		i.participantIds.MyParticipantId(spaceId),
+		info.Comments,
+		time.Now().Add(-replyNotificationMaxAge).Unix(),
+	)
+	for _, notification := range notifications {
+		if err := i.notifications.CreateAndSend(notification); err != nil {
+			log.With("objectID", info.Id).Errorf("failed to send comment reply notification: %v", err)
+		}
+	}
+}
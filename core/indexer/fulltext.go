@@ -15,7 +15,9 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/simple/text"
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore"
 	"github.com/anyproto/anytype-heart/pkg/lib/localstore/ftsearch"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/util/pbtypes"
@@ -70,7 +72,11 @@ func (i *indexer) ftLoopRoutine() {
 
 func (i *indexer) runFullTextIndexer(ctx context.Context) {
 	batcher := i.ftsearch.NewAutoBatcher(ftsearch.AutoBatcherRecommendedMaxDocs, ftsearch.AutoBatcherRecommendedMaxSize)
-	err := i.store.BatchProcessFullTextQueue(ctx, ftBatchLimit, func(objectIds []string) error {
+	err := i.store.BatchProcessFullTextQueue(ctx, ftBatchLimit, func(objectIds []string) (err error) {
+		_, span := tracing.Start(ctx, "indexer.FullText", ftObjectsKey.Int(len(objectIds)))
+		defer func() {
+			tracing.End(span, err)
+		}()
 		for _, objectId := range objectIds {
 			objDocs, err := i.prepareSearchDocument(ctx, objectId)
 			if err != nil {
@@ -148,11 +154,24 @@ func (i *indexer) filterOutNotChangedDocuments(id string, newDocs []ftsearch.Sea
 
 func (i *indexer) prepareSearchDocument(ctx context.Context, id string) (docs []ftsearch.SearchDoc, err error) {
 	ctx = context.WithValue(ctx, metrics.CtxKeyEntrypoint, "index_fulltext")
+	var (
+		spaceId   string
+		pdfFileId domain.FileId
+		pdfInfo   map[domain.RelationKey]string
+	)
 	err = cache.DoContext(i.picker, ctx, id, func(sb smartblock2.SmartBlock) error {
 		indexDetails, _ := sb.Type().Indexable()
 		if !indexDetails {
 			return nil
 		}
+		if model.ObjectTypeLayout(pbtypes.GetInt64(sb.Details(), bundle.RelationKeyLayout.String())) == model.ObjectType_pdf {
+			spaceId = sb.SpaceID()
+			pdfFileId = domain.FileId(pbtypes.GetString(sb.Details(), bundle.RelationKeyFileId.String()))
+			pdfInfo = map[domain.RelationKey]string{
+				bundle.RelationKeyPdfTitle:  pbtypes.GetString(sb.Details(), bundle.RelationKeyPdfTitle.String()),
+				bundle.RelationKeyPdfAuthor: pbtypes.GetString(sb.Details(), bundle.RelationKeyPdfAuthor.String()),
+			}
+		}
 
 		for _, rel := range sb.GetRelationLinks() {
 			if rel.Format != model.RelationFormat_shorttext && rel.Format != model.RelationFormat_longtext {
@@ -211,8 +230,57 @@ func (i *indexer) prepareSearchDocument(ctx context.Context, id string) (docs []
 
 		return nil
 	})
+	if err != nil || pdfFileId == "" {
+		return docs, err
+	}
+
+	pdfDocs, pdfErr := i.preparePdfDocuments(id, spaceId, pdfFileId, pdfInfo)
+	if pdfErr != nil {
+		log.With("objectId", id).Warnf("index pdf text: %v", pdfErr)
+	}
+	return append(docs, pdfDocs...), nil
+}
+
+// preparePdfDocuments makes a search document for every page of PDF file and for its title and author.
+// Text is extracted when the file object is indexed, files that are not indexed yet are skipped
+// and never fetched here, the object is indexed again after its details are updated
+func (i *indexer) preparePdfDocuments(id, spaceId string, fileId domain.FileId, info map[domain.RelationKey]string) (docs []ftsearch.SearchDoc, err error) {
+	for key, val := range info {
+		if strings.TrimSpace(val) == "" {
+			continue
+		}
+		docs = append(docs, ftsearch.SearchDoc{
+			Id:      domain.NewObjectPathWithRelation(id, key.String()).String(),
+			SpaceID: spaceId,
+			Text:    val,
+		})
+	}
+	pages, err := i.fileStore.GetPdfText(fileId)
+	if errors.Is(err, localstore.ErrNotFound) {
+		return docs, nil
+	}
+	if err != nil {
+		return docs, fmt.Errorf("get pdf text: %w", err)
+	}
+	for page, text := range pages {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if len(text) > ftBlockMaxSize {
+			text = text[:ftBlockMaxSize]
+		}
+		docs = append(docs, ftsearch.SearchDoc{
+			Id:      domain.NewObjectPathWithBlock(id, pdfPageBlockId(page)).String(),
+			SpaceID: spaceId,
+			Text:    text,
+		})
+	}
+	return docs, nil
+}
 
-	return docs, err
+// pdfPageBlockId is the virtual block id of PDF page in full-text search results, pages are numbered from 1
+func pdfPageBlockId(page int) string {
+	return fmt.Sprintf("page-%d", page+1)
 }
 
 func (i *indexer) ftInit() error {
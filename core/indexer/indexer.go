@@ -10,6 +10,7 @@ import (
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/commonspace/spacestorage"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
 	"github.com/anyproto/anytype-heart/core/block/source"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/database"
 	"github.com/anyproto/anytype-heart/pkg/lib/localstore/filestore"
@@ -36,6 +38,12 @@ const (
 
 var log = logging.Logger("anytype-doc-indexer")
 
+const (
+	// indexSkippedKey marks spans of objects that are not indexed because their heads are not changed
+	indexSkippedKey = attribute.Key("anytype.index.skipped")
+	ftObjectsKey    = attribute.Key("anytype.index.objects")
+)
+
 func New() Indexer {
 	return &indexer{}
 }
@@ -61,6 +69,8 @@ type indexer struct {
 	picker         cache.ObjectGetter
 	ftsearch       ftsearch.FTSearch
 	storageService storage.ClientStorage
+	participantIds participantIdProvider
+	notifications  notificationSender
 
 	quit            chan struct{}
 	ftQueueFinished chan struct{}
@@ -80,6 +90,8 @@ func (i *indexer) Init(a *app.App) (err error) {
 	i.fileStore = app.MustComponent[filestore.FileStore](a)
 	i.ftsearch = app.MustComponent[ftsearch.FTSearch](a)
 	i.picker = app.MustComponent[cache.ObjectGetter](a)
+	i.participantIds = app.MustComponent[participantIdProvider](a)
+	i.notifications = app.MustComponent[notificationSender](a)
 	i.quit = make(chan struct{})
 	i.ftQueueFinished = make(chan struct{})
 	i.forceFt = make(chan struct{})
@@ -131,14 +143,18 @@ func (i *indexer) RemoveAclIndexes(spaceId string) (err error) {
 	return i.store.DeleteDetails(ids...)
 }
 
-func (i *indexer) Index(ctx context.Context, info smartblock.DocInfo, options ...smartblock.IndexOption) error {
+func (i *indexer) Index(ctx context.Context, info smartblock.DocInfo, options ...smartblock.IndexOption) (err error) {
 	// options are stored in smartblock pkg because of cyclic dependency :(
 	startTime := time.Now()
+	_, span := tracing.Start(ctx, "indexer.Index", tracing.ObjectId(info.Id), tracing.SpaceId(info.Space.Id()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	opts := &smartblock.IndexOptions{}
 	for _, o := range options {
 		o(opts)
 	}
-	err := i.storageService.BindSpaceID(info.Space.Id(), info.Id)
+	err = i.storageService.BindSpaceID(info.Space.Id(), info.Id)
 	if err != nil {
 		log.Error("failed to bind space id", zap.Error(err), zap.String("id", info.Id))
 		return err
@@ -170,6 +186,7 @@ func (i *indexer) Index(ctx context.Context, info smartblock.DocInfo, options ..
 			log.With("objectID", info.Id).Errorf("heads hash is empty")
 		} else if lastIndexedHash == headHashToIndex {
 			log.With("objectID", info.Id).Debugf("heads not changed, skipping indexing")
+			span.SetAttributes(indexSkippedKey.Bool(true))
 			return nil
 		}
 	}
@@ -183,6 +200,10 @@ func (i *indexer) Index(ctx context.Context, info smartblock.DocInfo, options ..
 			hasError = true
 			log.With("objectID", info.Id).Errorf("failed to save object links: %v", err)
 		}
+		if err = i.store.UpdateSyncedBlockTargets(info.Id, info.SyncedBlockTargets); err != nil {
+			hasError = true
+			log.With("objectID", info.Id).Errorf("failed to save synced block targets: %v", err)
+		}
 	}
 
 	indexLinksTime := time.Now()
@@ -228,6 +249,7 @@ func (i *indexer) Index(ctx context.Context, info smartblock.DocInfo, options ..
 	if !hasError {
 		saveIndexedHash()
 	}
+	i.notifyCommentReplies(info)
 
 	metrics.Service.Send(&metrics.IndexEvent{
 		ObjectId:                info.Id,
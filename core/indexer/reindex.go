@@ -18,6 +18,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/syncstatus/detailsupdater/helper"
 	"github.com/anyproto/anytype-heart/metrics"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	smartblock2 "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
 	"github.com/anyproto/anytype-heart/pkg/lib/database"
@@ -116,6 +117,10 @@ func (i *indexer) buildFlags(spaceID string) (reindexFlags, error) {
 }
 
 func (i *indexer) ReindexSpace(space clientspace.Space) (err error) {
+	_, span := tracing.Start(context.Background(), "indexer.ReindexSpace", tracing.SpaceId(space.Id()))
+	defer func() {
+		tracing.End(span, err)
+	}()
 	flags, err := i.buildFlags(space.Id())
 	if err != nil {
 		return
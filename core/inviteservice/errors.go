@@ -12,6 +12,9 @@ var (
 	ErrInviteGenerate   = errors.New("generate invite")
 	ErrInviteRemove     = errors.New("remove invite")
 	ErrPersonalSpace    = errors.New("sharing of personal space is forbidden")
+	ErrInviteExpired    = errors.New("invite expired")
+	ErrInviteExhausted  = errors.New("invite reached the maximum number of uses")
+	ErrInviteRevoked    = errors.New("invite revoked")
 )
 
 func removeInviteError(msg string, err error) error {
@@ -3,6 +3,7 @@ package inviteservice
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
@@ -34,6 +35,13 @@ type InviteService interface {
 	RemoveExisting(ctx context.Context, spaceId string) error
 	Generate(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, sendInvite func() error) (domain.InviteInfo, error)
 	GetCurrent(ctx context.Context, spaceId string) (domain.InviteInfo, error)
+
+	// GenerateWithParams creates one more invite of the space. sendInvite adds the invite to the acl and returns its record id
+	GenerateWithParams(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, params domain.InviteParams, sendInvite func() (recordId string, err error)) (domain.Invite, error)
+	// List returns all invites created with GenerateWithParams, including revoked ones
+	List(ctx context.Context, spaceId string) ([]domain.Invite, error)
+	// Revoke marks the invite revoked and removes its file, acl record should be revoked by the caller
+	Revoke(ctx context.Context, spaceId string, recordId string) error
 }
 
 var _ InviteService = (*inviteService)(nil)
@@ -43,10 +51,12 @@ type inviteService struct {
 	fileAcl        fileacl.Service
 	accountService account.Service
 	spaceService   space.Service
+
+	now func() time.Time
 }
 
 func New() InviteService {
-	return &inviteService{}
+	return &inviteService{now: time.Now}
 }
 
 func (i *inviteService) Init(a *app.App) (err error) {
@@ -80,6 +90,8 @@ func (i *inviteService) View(ctx context.Context, inviteCid cid.Cid, inviteFileK
 		SpaceIconCid: invitePayload.SpaceIconCid,
 		CreatorName:  invitePayload.CreatorName,
 		InviteKey:    invitePayload.InviteKey,
+		Permissions:  invitePayload.Permissions,
+		ExpireDate:   invitePayload.ExpireDate,
 	}, nil
 }
 
@@ -162,6 +174,116 @@ func (i *inviteService) doInviteObject(ctx context.Context, spaceId string, f fu
 	})
 }
 
+func (i *inviteService) doInviteList(ctx context.Context, spaceId string, f func(object domain.InviteListObject) error) error {
+	sp, err := i.spaceService.Get(ctx, spaceId)
+	if err != nil {
+		return err
+	}
+	return sp.Do(sp.DerivedIDs().Workspace, func(sb smartblock.SmartBlock) error {
+		invObject, ok := sb.(domain.InviteListObject)
+		if !ok {
+			return fmt.Errorf("space is not invite list object")
+		}
+		return f(invObject)
+	})
+}
+
+func (i *inviteService) GenerateWithParams(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, params domain.InviteParams, sendInvite func() (string, error)) (domain.Invite, error) {
+	if spaceId == i.accountService.PersonalSpaceID() {
+		return domain.Invite{}, ErrPersonalSpace
+	}
+	invitePayload, err := i.buildInvitePayload(ctx, spaceId, inviteKey)
+	if err != nil {
+		return domain.Invite{}, generateInviteError("build invite payload", err)
+	}
+	invitePayload.Permissions = params.Permissions
+	invitePayload.ExpireDate = params.ExpireDate
+	invite, err := i.signInvitePayload(invitePayload)
+	if err != nil {
+		return domain.Invite{}, generateInviteError("build invite", err)
+	}
+	inviteFileCid, inviteFileKey, err := i.inviteStore.StoreInvite(ctx, invite)
+	if err != nil {
+		return domain.Invite{}, generateInviteError("store invite in ipfs", err)
+	}
+	removeInviteFile := func() {
+		err := i.inviteStore.RemoveInvite(ctx, inviteFileCid)
+		if err != nil {
+			log.Error("remove invite file", zap.Error(err))
+		}
+	}
+	inviteFileKeyRaw, err := encode.EncodeKeyToBase58(inviteFileKey)
+	if err != nil {
+		removeInviteFile()
+		return domain.Invite{}, generateInviteError("encode invite file key", err)
+	}
+	recordId, err := sendInvite()
+	if err != nil {
+		removeInviteFile()
+		return domain.Invite{}, generateInviteError("send invite", err)
+	}
+	result := domain.Invite{
+		InviteInfo: domain.InviteInfo{
+			InviteFileCid: inviteFileCid.String(),
+			InviteFileKey: inviteFileKeyRaw,
+		},
+		InviteParams: params,
+		RecordId:     recordId,
+		CreatedDate:  i.now().Unix(),
+	}
+	err = i.doInviteList(ctx, spaceId, func(obj domain.InviteListObject) error {
+		return obj.SetInvite(result)
+	})
+	if err != nil {
+		removeInviteFile()
+		return domain.Invite{}, generateInviteError("save invite", err)
+	}
+	return result, nil
+}
+
+func (i *inviteService) List(ctx context.Context, spaceId string) (invites []domain.Invite, err error) {
+	err = i.doInviteList(ctx, spaceId, func(obj domain.InviteListObject) error {
+		invites = obj.ListInvites()
+		return nil
+	})
+	if err != nil {
+		return nil, getInviteError("list invites", err)
+	}
+	return invites, nil
+}
+
+func (i *inviteService) Revoke(ctx context.Context, spaceId string, recordId string) error {
+	var fileCid string
+	err := i.doInviteList(ctx, spaceId, func(obj domain.InviteListObject) error {
+		for _, invite := range obj.ListInvites() {
+			if invite.RecordId != recordId {
+				continue
+			}
+			if invite.RevokedDate != 0 {
+				return nil
+			}
+			fileCid = invite.InviteFileCid
+			invite.RevokedDate = i.now().Unix()
+			return obj.SetInvite(invite)
+		}
+		return ErrInviteNotExists
+	})
+	if err != nil {
+		return removeInviteError("revoke invite", err)
+	}
+	if fileCid == "" {
+		return nil
+	}
+	invCid, err := cid.Decode(fileCid)
+	if err != nil {
+		return removeInviteError("decode invite cid", err)
+	}
+	if err = i.inviteStore.RemoveInvite(ctx, invCid); err != nil {
+		return removeInviteError("remove invite from store", err)
+	}
+	return nil
+}
+
 func (i *inviteService) Generate(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, sendInvite func() error) (result domain.InviteInfo, err error) {
 	if spaceId == i.accountService.PersonalSpaceID() {
 		return domain.InviteInfo{}, ErrPersonalSpace
@@ -253,6 +375,12 @@ func (i *inviteService) buildInvite(ctx context.Context, spaceId string, inviteK
 	if err != nil {
 		return nil, fmt.Errorf("build invite payload: %w", err)
 	}
+	// current invite has no default permissions, join requests are approved with permissions chosen by the owner
+	invitePayload.Permissions = model.ParticipantPermissions_NoPermissions
+	return i.signInvitePayload(invitePayload)
+}
+
+func (i *inviteService) signInvitePayload(invitePayload *model.InvitePayload) (*model.Invite, error) {
 	invitePayloadRaw, err := proto.Marshal(invitePayload)
 	if err != nil {
 		return nil, fmt.Errorf("marshal invite payload: %w", err)
@@ -3,8 +3,10 @@ package inviteservice
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/anyproto/any-sync/app"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -12,6 +14,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/anytype/account/mock_account"
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
 	"github.com/anyproto/anytype-heart/core/block/editor/smartblock/smarttest"
+	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/domain/mock_domain"
 	"github.com/anyproto/anytype-heart/core/files/fileacl/mock_fileacl"
 	"github.com/anyproto/anytype-heart/core/invitestore/mock_invitestore"
@@ -77,6 +80,91 @@ func TestInviteService_GetCurrent(t *testing.T) {
 	})
 }
 
+func TestInviteService_RemoveExisting(t *testing.T) {
+	t.Run("scoped invites are kept", func(t *testing.T) {
+		// given
+		const inviteFileCid = "bafybeiazx6t2ixn2ii3k7ywhkmy4uvzqvrlh7v4cb7ooonsd2iikjmfn2i"
+		fx := newFixture(t)
+		defer fx.ctrl.Finish()
+		fx.mockSpaceService.EXPECT().Get(ctx, "spaceId").Return(fx.mockSpace, nil).Once()
+		fx.mockSpace.EXPECT().DerivedIDs().Return(threads.DerivedSmartblockIds{
+			Workspace: "workspaceId",
+		})
+		fx.mockSpace.EXPECT().Do("workspaceId", mock.Anything).RunAndReturn(func(s string, f func(smartblock.SmartBlock) error) error {
+			return f(mockInviteObject{SmartBlock: smarttest.New("root"), MockInviteObject: fx.mockInviteObject})
+		}).Once()
+		fx.mockInviteObject.EXPECT().RemoveExistingInviteInfo().Return(inviteFileCid, nil)
+		fx.mockInviteStore.EXPECT().RemoveInvite(ctx, cid.MustParse(inviteFileCid)).Return(nil)
+
+		// when
+		err := fx.RemoveExisting(ctx, "spaceId")
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+type inviteListObject struct {
+	smartblock.SmartBlock
+	invites map[string]domain.Invite
+}
+
+func (o *inviteListObject) SetInvite(invite domain.Invite) error {
+	o.invites[invite.RecordId] = invite
+	return nil
+}
+
+func (o *inviteListObject) ListInvites() []domain.Invite {
+	res := make([]domain.Invite, 0, len(o.invites))
+	for _, invite := range o.invites {
+		res = append(res, invite)
+	}
+	return res
+}
+
+func TestInviteService_Revoke(t *testing.T) {
+	const inviteFileCid = "bafybeiazx6t2ixn2ii3k7ywhkmy4uvzqvrlh7v4cb7ooonsd2iikjmfn2i"
+	prepare := func(t *testing.T) (*fixture, *inviteListObject) {
+		fx := newFixture(t)
+		fx.now = func() time.Time { return time.Unix(100, 0) }
+		obj := &inviteListObject{SmartBlock: smarttest.New("root"), invites: map[string]domain.Invite{
+			"record1": {RecordId: "record1", InviteInfo: domain.InviteInfo{InviteFileCid: inviteFileCid}},
+		}}
+		fx.mockSpaceService.EXPECT().Get(ctx, "spaceId").Return(fx.mockSpace, nil)
+		fx.mockSpace.EXPECT().DerivedIDs().Return(threads.DerivedSmartblockIds{
+			Workspace: "workspaceId",
+		})
+		fx.mockSpace.EXPECT().Do("workspaceId", mock.Anything).RunAndReturn(func(s string, f func(smartblock.SmartBlock) error) error {
+			return f(obj)
+		})
+		return fx, obj
+	}
+
+	t.Run("revoke invite", func(t *testing.T) {
+		// given
+		fx, obj := prepare(t)
+		fx.mockInviteStore.EXPECT().RemoveInvite(ctx, cid.MustParse(inviteFileCid)).Return(nil)
+
+		// when
+		err := fx.Revoke(ctx, "spaceId", "record1")
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, int64(100), obj.invites["record1"].RevokedDate)
+	})
+
+	t.Run("unknown invite", func(t *testing.T) {
+		// given
+		fx, _ := prepare(t)
+
+		// when
+		err := fx.Revoke(ctx, "spaceId", "record2")
+
+		// then
+		require.ErrorIs(t, err, ErrInviteNotExists)
+	})
+}
+
 var ctx = context.Background()
 
 type fixture struct {
@@ -135,6 +135,66 @@ func (_c *MockInviteService_Generate_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// GenerateWithParams provides a mock function with given fields: ctx, spaceId, inviteKey, params, sendInvite
+func (_m *MockInviteService) GenerateWithParams(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, params domain.InviteParams, sendInvite func() (string, error)) (domain.Invite, error) {
+	ret := _m.Called(ctx, spaceId, inviteKey, params, sendInvite)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateWithParams")
+	}
+
+	var r0 domain.Invite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, crypto.PrivKey, domain.InviteParams, func() (string, error)) (domain.Invite, error)); ok {
+		return rf(ctx, spaceId, inviteKey, params, sendInvite)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, crypto.PrivKey, domain.InviteParams, func() (string, error)) domain.Invite); ok {
+		r0 = rf(ctx, spaceId, inviteKey, params, sendInvite)
+	} else {
+		r0 = ret.Get(0).(domain.Invite)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, crypto.PrivKey, domain.InviteParams, func() (string, error)) error); ok {
+		r1 = rf(ctx, spaceId, inviteKey, params, sendInvite)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockInviteService_GenerateWithParams_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateWithParams'
+type MockInviteService_GenerateWithParams_Call struct {
+	*mock.Call
+}
+
+// GenerateWithParams is a helper method to define mock.On call
+//   - ctx context.Context
+//   - spaceId string
+//   - inviteKey crypto.PrivKey
+//   - params domain.InviteParams
+//   - sendInvite func() (string, error)
+func (_e *MockInviteService_Expecter) GenerateWithParams(ctx interface{}, spaceId interface{}, inviteKey interface{}, params interface{}, sendInvite interface{}) *MockInviteService_GenerateWithParams_Call {
+	return &MockInviteService_GenerateWithParams_Call{Call: _e.mock.On("GenerateWithParams", ctx, spaceId, inviteKey, params, sendInvite)}
+}
+
+func (_c *MockInviteService_GenerateWithParams_Call) Run(run func(ctx context.Context, spaceId string, inviteKey crypto.PrivKey, params domain.InviteParams, sendInvite func() (string, error))) *MockInviteService_GenerateWithParams_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(crypto.PrivKey), args[3].(domain.InviteParams), args[4].(func() (string, error)))
+	})
+	return _c
+}
+
+func (_c *MockInviteService_GenerateWithParams_Call) Return(_a0 domain.Invite, _a1 error) *MockInviteService_GenerateWithParams_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockInviteService_GenerateWithParams_Call) RunAndReturn(run func(context.Context, string, crypto.PrivKey, domain.InviteParams, func() (string, error)) (domain.Invite, error)) *MockInviteService_GenerateWithParams_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetCurrent provides a mock function with given fields: ctx, spaceId
 func (_m *MockInviteService) GetCurrent(ctx context.Context, spaceId string) (domain.InviteInfo, error) {
 	ret := _m.Called(ctx, spaceId)
@@ -298,6 +358,65 @@ func (_c *MockInviteService_Init_Call) RunAndReturn(run func(*app.App) error) *M
 	return _c
 }
 
+// List provides a mock function with given fields: ctx, spaceId
+func (_m *MockInviteService) List(ctx context.Context, spaceId string) ([]domain.Invite, error) {
+	ret := _m.Called(ctx, spaceId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Invite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Invite, error)); ok {
+		return rf(ctx, spaceId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Invite); ok {
+		r0 = rf(ctx, spaceId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Invite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, spaceId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockInviteService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockInviteService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - spaceId string
+func (_e *MockInviteService_Expecter) List(ctx interface{}, spaceId interface{}) *MockInviteService_List_Call {
+	return &MockInviteService_List_Call{Call: _e.mock.On("List", ctx, spaceId)}
+}
+
+func (_c *MockInviteService_List_Call) Run(run func(ctx context.Context, spaceId string)) *MockInviteService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockInviteService_List_Call) Return(_a0 []domain.Invite, _a1 error) *MockInviteService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockInviteService_List_Call) RunAndReturn(run func(context.Context, string) ([]domain.Invite, error)) *MockInviteService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Name provides a mock function with given fields:
 func (_m *MockInviteService) Name() string {
 	ret := _m.Called()
@@ -390,6 +509,54 @@ func (_c *MockInviteService_RemoveExisting_Call) RunAndReturn(run func(context.C
 	return _c
 }
 
+// Revoke provides a mock function with given fields: ctx, spaceId, recordId
+func (_m *MockInviteService) Revoke(ctx context.Context, spaceId string, recordId string) error {
+	ret := _m.Called(ctx, spaceId, recordId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, spaceId, recordId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockInviteService_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockInviteService_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx context.Context
+//   - spaceId string
+//   - recordId string
+func (_e *MockInviteService_Expecter) Revoke(ctx interface{}, spaceId interface{}, recordId interface{}) *MockInviteService_Revoke_Call {
+	return &MockInviteService_Revoke_Call{Call: _e.mock.On("Revoke", ctx, spaceId, recordId)}
+}
+
+func (_c *MockInviteService_Revoke_Call) Run(run func(ctx context.Context, spaceId string, recordId string)) *MockInviteService_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockInviteService_Revoke_Call) Return(_a0 error) *MockInviteService_Revoke_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockInviteService_Revoke_Call) RunAndReturn(run func(context.Context, string, string) error) *MockInviteService_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Run provides a mock function with given fields: ctx
 func (_m *MockInviteService) Run(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -0,0 +1,131 @@
+package kanban
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	timeutil "github.com/anyproto/anytype-heart/util/time"
+)
+
+type GroupDate struct {
+	Key     string
+	Bucket  model.BlockContentDataviewViewDateGroupBucket
+	store   objectstore.ObjectStore
+	loc     *time.Location
+	Records []database.Record
+}
+
+func (d *GroupDate) InitGroups(spaceID string, f *database.Filters) error {
+	records, err := queryRecordsWithValue(d.store, d.Key, f)
+	if err != nil {
+		return fmt.Errorf("init kanban by date, objectStore query error: %w", err)
+	}
+
+	d.Records = records
+
+	return nil
+}
+
+func (d *GroupDate) GetRecords() []database.Record {
+	return d.Records
+}
+
+func (d *GroupDate) SetRecords(records []database.Record) {
+	d.Records = records
+}
+
+func (d *GroupDate) MakeGroups() (GroupSlice, error) {
+	var starts []int64
+
+	uniqMap := make(map[int64]bool)
+
+	for _, rec := range d.Records {
+		if _, ok := rec.Details.GetFields()[d.Key]; !ok {
+			continue
+		}
+		from, _ := d.bucketRange(time.Unix(pbtypes.GetInt64(rec.Details, d.Key), 0))
+		if !uniqMap[from] {
+			uniqMap[from] = true
+			starts = append(starts, from)
+		}
+	}
+
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i] < starts[j]
+	})
+
+	groups := make(GroupSlice, 0, len(starts))
+	for _, from := range starts {
+		groups = append(groups, Group{
+			Id:   dateGroupID(d.Bucket, from),
+			Data: GroupData{Ids: []string{strconv.FormatInt(from, 10)}},
+		})
+	}
+
+	return groups, nil
+}
+
+func (d *GroupDate) MakeDataViewGroups() ([]*model.BlockContentDataviewGroup, error) {
+	var result []*model.BlockContentDataviewGroup
+
+	groups, err := d.MakeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range groups {
+		bucketStart, err := strconv.ParseInt(g.Data.Ids[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date group %s: %w", g.Id, err)
+		}
+		from, to := d.bucketRange(time.Unix(bucketStart, 0))
+		result = append(result, &model.BlockContentDataviewGroup{
+			Id: g.Id,
+			Value: &model.BlockContentDataviewGroupValueOfDate{
+				Date: &model.BlockContentDataviewDate{
+					From: from,
+					To:   to,
+				}},
+		})
+	}
+
+	result = append([]*model.BlockContentDataviewGroup{{
+		Id:    "empty",
+		Value: &model.BlockContentDataviewGroupValueOfDate{Date: &model.BlockContentDataviewDate{}},
+	}}, result...)
+
+	return result, nil
+}
+
+// bucketRange returns unix timestamps of the start (inclusive) and the end (exclusive) of the bucket containing t
+func (d *GroupDate) bucketRange(t time.Time) (from, to int64) {
+	loc := d.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	calendar := timeutil.NewCalendar(t.In(loc), loc)
+
+	var start, end time.Time
+	switch d.Bucket {
+	case model.BlockContentDataviewView_Week:
+		start = calendar.WeekNumStart(0)
+		end = start.AddDate(0, 0, 7)
+	case model.BlockContentDataviewView_Month:
+		start = calendar.MonthNumStart(0)
+		end = start.AddDate(0, 1, 0)
+	default:
+		start = calendar.DayNumStart(0)
+		end = start.AddDate(0, 0, 1)
+	}
+	return start.Unix(), end.Unix()
+}
+
+func dateGroupID(bucket model.BlockContentDataviewViewDateGroupBucket, from int64) string {
+	return fmt.Sprintf("%s-%d", bucket.String(), from)
+}
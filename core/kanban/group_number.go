@@ -0,0 +1,81 @@
+package kanban
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+// GroupNumber splits numbers into ranges defined by sorted lower bounds:
+// (-inf, b0), [b0, b1), ..., [bN, +inf). All ranges are shown even if there are no objects in them
+type GroupNumber struct {
+	Boundaries []float64
+}
+
+func (n *GroupNumber) InitGroups(spaceID string, f *database.Filters) error {
+	boundaries := make([]float64, 0, len(n.Boundaries))
+	uniqMap := make(map[float64]bool)
+	for _, b := range n.Boundaries {
+		if !uniqMap[b] {
+			uniqMap[b] = true
+			boundaries = append(boundaries, b)
+		}
+	}
+	sort.Float64s(boundaries)
+	n.Boundaries = boundaries
+	return nil
+}
+
+func (n *GroupNumber) MakeGroups() (GroupSlice, error) {
+	groups := make(GroupSlice, 0, len(n.Boundaries)+1)
+	for i := 0; i <= len(n.Boundaries); i++ {
+		groups = append(groups, Group{Id: n.rangeID(i)})
+	}
+	return groups, nil
+}
+
+func (n *GroupNumber) MakeDataViewGroups() ([]*model.BlockContentDataviewGroup, error) {
+	var result []*model.BlockContentDataviewGroup
+
+	groups, err := n.MakeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, g := range groups {
+		number := &model.BlockContentDataviewNumber{}
+		if i > 0 {
+			number.From = n.Boundaries[i-1]
+			number.HasFrom = true
+		}
+		if i < len(n.Boundaries) {
+			number.To = n.Boundaries[i]
+			number.HasTo = true
+		}
+		result = append(result, &model.BlockContentDataviewGroup{
+			Id:    g.Id,
+			Value: &model.BlockContentDataviewGroupValueOfNumber{Number: number},
+		})
+	}
+
+	result = append([]*model.BlockContentDataviewGroup{{
+		Id:    "empty",
+		Value: &model.BlockContentDataviewGroupValueOfNumber{Number: &model.BlockContentDataviewNumber{}},
+	}}, result...)
+
+	return result, nil
+}
+
+func (n *GroupNumber) rangeID(i int) string {
+	var from, to string
+	if i > 0 {
+		from = strconv.FormatFloat(n.Boundaries[i-1], 'f', -1, 64)
+	}
+	if i < len(n.Boundaries) {
+		to = strconv.FormatFloat(n.Boundaries[i], 'f', -1, 64)
+	}
+	return fmt.Sprintf("range-%s-%s", from, to)
+}
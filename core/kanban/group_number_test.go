@@ -0,0 +1,60 @@
+package kanban
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestGroupNumber(t *testing.T) {
+	grouper := &GroupNumber{Boundaries: []float64{10, 0, 10}}
+	require.NoError(t, grouper.InitGroups("", nil))
+
+	groups, err := grouper.MakeDataViewGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, 4)
+
+	assert.Equal(t, "empty", groups[0].Id)
+	assert.Equal(t, &model.BlockContentDataviewNumber{To: 0, HasTo: true}, groups[1].GetNumber())
+	assert.Equal(t, &model.BlockContentDataviewNumber{From: 0, To: 10, HasFrom: true, HasTo: true}, groups[2].GetNumber())
+	assert.Equal(t, &model.BlockContentDataviewNumber{From: 10, HasFrom: true}, groups[3].GetNumber())
+
+	value, err := GroupValue(groups[2])
+	require.NoError(t, err)
+	assert.Equal(t, pbtypes.Float64(0), value)
+
+	value, err = GroupValue(groups[1])
+	require.NoError(t, err)
+	assert.Equal(t, pbtypes.Float64(-1), value)
+}
+
+func TestGroupDate(t *testing.T) {
+	day := func(d int) int64 {
+		return time.Date(2024, 5, d, 15, 30, 0, 0, time.UTC).Unix()
+	}
+	grouper := &GroupDate{Key: "dueDate", Bucket: model.BlockContentDataviewView_Week, loc: time.UTC}
+	grouper.SetRecords([]database.Record{
+		{Details: pbtypes.ToStruct(map[string]interface{}{"dueDate": day(15)})},
+		{Details: pbtypes.ToStruct(map[string]interface{}{"dueDate": day(1)})},
+		{Details: pbtypes.ToStruct(map[string]interface{}{"dueDate": day(13)})},
+	})
+
+	groups, err := grouper.MakeDataViewGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "empty", groups[0].Id)
+	assert.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC).Unix(), groups[1].GetDate().From)
+	assert.Equal(t, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC).Unix(), groups[1].GetDate().To)
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC).Unix(), groups[2].GetDate().From)
+
+	value, err := GroupValue(groups[2])
+	require.NoError(t, err)
+	assert.Equal(t, pbtypes.Int64(groups[2].GetDate().From), value)
+}
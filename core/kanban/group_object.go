@@ -0,0 +1,119 @@
+package kanban
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type GroupObject struct {
+	Key     string
+	store   objectstore.ObjectStore
+	Records []database.Record
+}
+
+func (o *GroupObject) InitGroups(spaceID string, f *database.Filters) error {
+	records, err := queryRecordsWithValue(o.store, o.Key, f)
+	if err != nil {
+		return fmt.Errorf("init kanban by object, objectStore query error: %w", err)
+	}
+
+	o.Records = records
+
+	return nil
+}
+
+func (o *GroupObject) GetRecords() []database.Record {
+	return o.Records
+}
+
+func (o *GroupObject) SetRecords(records []database.Record) {
+	o.Records = records
+}
+
+func (o *GroupObject) MakeGroups() (GroupSlice, error) {
+	var groups GroupSlice
+
+	uniqMap := make(map[string]bool)
+
+	// single object groups
+	for _, rec := range o.Records {
+		for _, id := range pbtypes.GetStringList(rec.Details, o.Key) {
+			if !uniqMap[id] {
+				uniqMap[id] = true
+				groups = append(groups, Group{
+					Id:   id,
+					Data: GroupData{Ids: []string{id}},
+				})
+			}
+		}
+	}
+
+	// multiple object groups
+	for _, rec := range o.Records {
+		ids := pbtypes.GetStringList(rec.Details, o.Key)
+		if len(ids) > 1 {
+			ids = append([]string{}, ids...)
+			sort.Strings(ids)
+			hash := strings.Join(ids, "")
+			if !uniqMap[hash] {
+				uniqMap[hash] = true
+				groups = append(groups, Group{
+					Id:   hash,
+					Data: GroupData{Ids: ids},
+				})
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+func (o *GroupObject) MakeDataViewGroups() ([]*model.BlockContentDataviewGroup, error) {
+	var result []*model.BlockContentDataviewGroup
+
+	groups, err := o.MakeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(groups)
+
+	for _, g := range groups {
+		result = append(result, &model.BlockContentDataviewGroup{
+			Id: Hash(g.Id),
+			Value: &model.BlockContentDataviewGroupValueOfObject{
+				Object: &model.BlockContentDataviewObject{
+					Ids: g.Data.Ids,
+				}},
+		})
+	}
+
+	result = append([]*model.BlockContentDataviewGroup{{
+		Id: "empty",
+		Value: &model.BlockContentDataviewGroupValueOfObject{
+			Object: &model.BlockContentDataviewObject{
+				Ids: make([]string, 0),
+			}},
+	}}, result...)
+
+	return result, nil
+}
+
+// queryRecordsWithValue returns objects matching the filter that have non-empty value of relation
+func queryRecordsWithValue(store objectstore.ObjectStore, key string, f *database.Filters) ([]database.Record, error) {
+	notEmpty := database.FilterNot{Filter: database.FilterEmpty{Key: key}}
+	if f == nil {
+		f = &database.Filters{FilterObj: notEmpty}
+	} else if f.FilterObj == nil {
+		f.FilterObj = notEmpty
+	} else {
+		f.FilterObj = database.FiltersAnd{f.FilterObj, notEmpty}
+	}
+	return store.QueryRaw(f, 0, 0)
+}
@@ -63,6 +63,14 @@ func (t *GroupTag) InitGroups(spaceID string, f *database.Filters) error {
 	return nil
 }
 
+func (t *GroupTag) GetRecords() []database.Record {
+	return t.Records
+}
+
+func (t *GroupTag) SetRecords(records []database.Record) {
+	t.Records = records
+}
+
 func (t *GroupTag) MakeGroups() (GroupSlice, error) {
 	var groups GroupSlice
 
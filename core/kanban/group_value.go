@@ -0,0 +1,79 @@
+package kanban
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/slice"
+)
+
+var ErrNoGroupValue = errors.New("can't derive relation value for the group")
+
+// GroupValue returns the value of the grouped relation that puts an object into the group.
+// It is used to update objects that are moved between groups
+func GroupValue(group *model.BlockContentDataviewGroup) (*types.Value, error) {
+	switch v := group.GetValue().(type) {
+	case *model.BlockContentDataviewGroupValueOfStatus:
+		if v.Status.GetId() == "" {
+			return pbtypes.Null(), nil
+		}
+		return pbtypes.String(v.Status.Id), nil
+	case *model.BlockContentDataviewGroupValueOfTag:
+		return pbtypes.StringList(v.Tag.GetIds()), nil
+	case *model.BlockContentDataviewGroupValueOfCheckbox:
+		return pbtypes.Bool(v.Checkbox.GetChecked()), nil
+	case *model.BlockContentDataviewGroupValueOfObject:
+		return pbtypes.StringList(v.Object.GetIds()), nil
+	case *model.BlockContentDataviewGroupValueOfDate:
+		if v.Date.GetFrom() == 0 && v.Date.GetTo() == 0 {
+			return pbtypes.Null(), nil
+		}
+		return pbtypes.Int64(v.Date.GetFrom()), nil
+	case *model.BlockContentDataviewGroupValueOfNumber:
+		if !v.Number.GetHasFrom() && !v.Number.GetHasTo() {
+			return pbtypes.Null(), nil
+		}
+		if !v.Number.GetHasFrom() {
+			// the range is open from below, zero is used when it is in the range to keep the value simple
+			return pbtypes.Float64(math.Min(0, v.Number.GetTo()-1)), nil
+		}
+		return pbtypes.Float64(v.Number.GetFrom()), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported group type %T", ErrNoGroupValue, group.GetValue())
+}
+
+// MovedValue returns the value of the grouped relation for the object moved from one group to another.
+// Only ids of the source group are replaced with ids of the target group for tag and object relations,
+// so other values of the object are kept. The whole value is replaced when the source group is unknown
+func MovedValue(current *types.Value, from, to *model.BlockContentDataviewGroup) (*types.Value, error) {
+	toIds, isList := groupIds(to)
+	fromIds, fromIsList := groupIds(from)
+	if !isList || !fromIsList {
+		return GroupValue(to)
+	}
+	ids := slice.Filter(pbtypes.GetStringListValue(current), func(id string) bool {
+		return !slices.Contains(fromIds, id)
+	})
+	for _, id := range toIds {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return pbtypes.StringList(ids), nil
+}
+
+func groupIds(group *model.BlockContentDataviewGroup) (ids []string, isList bool) {
+	switch v := group.GetValue().(type) {
+	case *model.BlockContentDataviewGroupValueOfTag:
+		return v.Tag.GetIds(), true
+	case *model.BlockContentDataviewGroupValueOfObject:
+		return v.Object.GetIds(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,37 @@
+package kanban
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestMovedValue(t *testing.T) {
+	tagGroup := func(ids ...string) *model.BlockContentDataviewGroup {
+		return &model.BlockContentDataviewGroup{Value: &model.BlockContentDataviewGroupValueOfTag{
+			Tag: &model.BlockContentDataviewTag{Ids: ids},
+		}}
+	}
+
+	t.Run("tags of the source group are replaced", func(t *testing.T) {
+		value, err := MovedValue(pbtypes.StringList([]string{"urgent", "backend"}), tagGroup("backend"), tagGroup("frontend"))
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.StringList([]string{"urgent", "frontend"}), value)
+	})
+
+	t.Run("tags are removed when moved to the empty group", func(t *testing.T) {
+		value, err := MovedValue(pbtypes.StringList([]string{"urgent", "backend"}), tagGroup("backend"), tagGroup())
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.StringList([]string{"urgent"}), value)
+	})
+
+	t.Run("value is replaced when the source group is unknown", func(t *testing.T) {
+		value, err := MovedValue(pbtypes.StringList([]string{"urgent", "backend"}), nil, tagGroup("frontend"))
+		require.NoError(t, err)
+		assert.Equal(t, pbtypes.StringList([]string{"frontend"}), value)
+	})
+}
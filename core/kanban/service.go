@@ -18,7 +18,7 @@ const (
 )
 
 type Service interface {
-	Grouper(spaceID string, key string) (Grouper, error)
+	Grouper(spaceID string, key string, params GrouperParams) (Grouper, error)
 
 	app.Component
 }
@@ -29,27 +29,50 @@ type Grouper interface {
 	MakeDataViewGroups() ([]*model.BlockContentDataviewGroup, error)
 }
 
+// RecordGrouper is a Grouper that builds groups from the values of grouped objects,
+// so groups should be rebuilt when these objects change
+type RecordGrouper interface {
+	Grouper
+	GetRecords() []database.Record
+	SetRecords(records []database.Record)
+}
+
+// GrouperParams are view settings that affect grouping of dates and numbers
+type GrouperParams struct {
+	DateBucket       model.BlockContentDataviewViewDateGroupBucket
+	NumberBoundaries []float64
+}
+
 type service struct {
 	objectStore  objectstore.ObjectStore
-	groupColumns map[model.RelationFormat]func(string) Grouper
+	groupColumns map[model.RelationFormat]func(string, GrouperParams) Grouper
 }
 
 func New() Service {
-	return &service{groupColumns: make(map[model.RelationFormat]func(key string) Grouper)}
+	return &service{groupColumns: make(map[model.RelationFormat]func(key string, params GrouperParams) Grouper)}
 }
 
 func (s *service) Init(a *app.App) (err error) {
 	s.objectStore = a.MustComponent(objectstore.CName).(objectstore.ObjectStore)
 
-	s.groupColumns[model.RelationFormat_status] = func(key string) Grouper {
+	s.groupColumns[model.RelationFormat_status] = func(key string, params GrouperParams) Grouper {
 		return &GroupStatus{key: key, store: s.objectStore}
 	}
-	s.groupColumns[model.RelationFormat_tag] = func(key string) Grouper {
+	s.groupColumns[model.RelationFormat_tag] = func(key string, params GrouperParams) Grouper {
 		return &GroupTag{Key: key, store: s.objectStore}
 	}
-	s.groupColumns[model.RelationFormat_checkbox] = func(key string) Grouper {
+	s.groupColumns[model.RelationFormat_checkbox] = func(key string, params GrouperParams) Grouper {
 		return &GroupCheckBox{}
 	}
+	s.groupColumns[model.RelationFormat_object] = func(key string, params GrouperParams) Grouper {
+		return &GroupObject{Key: key, store: s.objectStore}
+	}
+	s.groupColumns[model.RelationFormat_date] = func(key string, params GrouperParams) Grouper {
+		return &GroupDate{Key: key, Bucket: params.DateBucket, store: s.objectStore}
+	}
+	s.groupColumns[model.RelationFormat_number] = func(key string, params GrouperParams) Grouper {
+		return &GroupNumber{Boundaries: params.NumberBoundaries}
+	}
 
 	return nil
 }
@@ -58,7 +81,7 @@ func (s *service) Name() (name string) {
 	return CName
 }
 
-func (s *service) Grouper(spaceID string, key string) (Grouper, error) {
+func (s *service) Grouper(spaceID string, key string, params GrouperParams) (Grouper, error) {
 	rel, err := s.objectStore.FetchRelationByKey(spaceID, key)
 	if err != nil {
 		return nil, fmt.Errorf("can't get relation %s: %w", key, err)
@@ -69,7 +92,7 @@ func (s *service) Grouper(spaceID string, key string) (Grouper, error) {
 		return nil, errors.New("unsupported relation format")
 	}
 
-	return grouperFn(key), nil
+	return grouperFn(key, params), nil
 }
 
 func GroupsToStrSlice(groups []*model.BlockContentDataviewGroup) []string {
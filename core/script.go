@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/anyproto/anytype-heart/core/script"
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+var errInvalidArgs = errors.New("args must be a json object")
+
+func (mw *Middleware) ScriptRun(cctx context.Context, req *pb.RpcScriptRunRequest) *pb.RpcScriptRunResponse {
+	result, err := runScript(cctx, getService[script.Service](mw), req)
+	code := mapErrorCode(err,
+		errToCode(errInvalidArgs, pb.RpcScriptRunResponseError_BAD_INPUT),
+		errToCode(script.ErrNoCode, pb.RpcScriptRunResponseError_BAD_INPUT),
+		errToCode(script.ErrNotFound, pb.RpcScriptRunResponseError_NOT_FOUND),
+		errToCode(script.ErrTimeout, pb.RpcScriptRunResponseError_TIMEOUT),
+		errToCode(script.ErrScript, pb.RpcScriptRunResponseError_SCRIPT_ERROR),
+	)
+	resp := &pb.RpcScriptRunResponse{
+		Error: &pb.RpcScriptRunResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+	if result != nil {
+		resp.ResultJson = result.Json
+		resp.Logs = result.Logs
+	}
+	return resp
+}
+
+func runScript(ctx context.Context, service script.Service, req *pb.RpcScriptRunRequest) (*script.Result, error) {
+	var args map[string]any
+	if req.ArgsJson != "" {
+		if err := json.Unmarshal([]byte(req.ArgsJson), &args); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidArgs, err)
+		}
+	}
+	return service.Run(ctx, req.ScriptId, args)
+}
+
+func (mw *Middleware) ScriptSetPermissions(_ context.Context, req *pb.RpcScriptSetPermissionsRequest) *pb.RpcScriptSetPermissionsResponse {
+	err := getService[script.Service](mw).SetPermissions(req.ScriptId, req.Permissions)
+	code := mapErrorCode(err,
+		errToCode(script.ErrNotFound, pb.RpcScriptSetPermissionsResponseError_NOT_FOUND),
+	)
+	return &pb.RpcScriptSetPermissionsResponse{
+		Error: &pb.RpcScriptSetPermissionsResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+}
+
+func (mw *Middleware) ScriptGetPermissions(_ context.Context, req *pb.RpcScriptGetPermissionsRequest) *pb.RpcScriptGetPermissionsResponse {
+	permissions, err := getService[script.Service](mw).GetPermissions(req.ScriptId)
+	code := mapErrorCode(err,
+		errToCode(script.ErrNotFound, pb.RpcScriptGetPermissionsResponseError_NOT_FOUND),
+	)
+	return &pb.RpcScriptGetPermissionsResponse{
+		Error: &pb.RpcScriptGetPermissionsResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+		Permissions: permissions,
+	}
+}
@@ -0,0 +1,157 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/basic"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/editor/state"
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/block/simple/text"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const defaultSearchLimit = 100
+
+var errForeignObject = errors.New("object is not in the space of the script")
+
+// spaceApi implements the object api for objects of the space of the script
+type spaceApi struct {
+	ctx     context.Context
+	service *service
+	spaceId string
+}
+
+func (a *spaceApi) Search(q searchQuery) ([]*types.Struct, error) {
+	filters := append([]*model.BlockContentDataviewFilter{
+		{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(a.spaceId),
+		},
+		{
+			RelationKey: bundle.RelationKeyIsArchived.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEqual,
+			Value:       pbtypes.Bool(true),
+		},
+	}, q.Filters...)
+	if len(q.Types) > 0 {
+		uniqueKeys := make([]string, 0, len(q.Types))
+		for _, typeKey := range q.Types {
+			uniqueKeys = append(uniqueKeys, domain.TypeKey(typeKey).URL())
+		}
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: database.NestedRelationKey(bundle.RelationKeyType, bundle.RelationKeyUniqueKey),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(uniqueKeys),
+		})
+	}
+	if q.Limit <= 0 || q.Limit > defaultSearchLimit {
+		q.Limit = defaultSearchLimit
+	}
+	records, err := a.service.objectStore.Query(database.Query{
+		FullText: q.Text,
+		Filters:  filters,
+		Limit:    q.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Struct, 0, len(records))
+	for _, rec := range records {
+		result = append(result, rec.Details)
+	}
+	return result, nil
+}
+
+func (a *spaceApi) GetDetails(id string) (*types.Struct, error) {
+	details, err := a.service.objectStore.GetDetails(id)
+	if err != nil {
+		return nil, err
+	}
+	if pbtypes.GetString(details.GetDetails(), bundle.RelationKeySpaceId.String()) != a.spaceId {
+		return nil, errForeignObject
+	}
+	return details.GetDetails(), nil
+}
+
+func (a *spaceApi) SetDetails(id string, details *types.Struct) error {
+	if err := a.checkSpace(id); err != nil {
+		return err
+	}
+	list := make([]*model.Detail, 0, len(details.GetFields()))
+	for key, value := range details.GetFields() {
+		list = append(list, &model.Detail{Key: key, Value: value})
+	}
+	return a.service.blockService.SetDetails(nil, id, list)
+}
+
+func (a *spaceApi) GetBlocks(id string) ([]*model.Block, error) {
+	if err := a.checkSpace(id); err != nil {
+		return nil, err
+	}
+	var blocks []*model.Block
+	err := cache.Do(a.service.blockService, id, func(sb smartblock.SmartBlock) error {
+		return sb.NewState().Iterate(func(b simple.Block) (isContinue bool) {
+			blocks = append(blocks, pbtypes.CopyBlock(b.Model()))
+			return true
+		})
+	})
+	return blocks, err
+}
+
+func (a *spaceApi) AddTextBlock(id string, text string, style model.BlockContentTextStyle) (blockId string, err error) {
+	if err = a.checkSpace(id); err != nil {
+		return "", err
+	}
+	err = cache.DoState(a.service.blockService, id, func(st *state.State, b basic.Creatable) error {
+		blockId, err = b.CreateBlock(st, pb.RpcBlockCreateRequest{
+			ContextId: id,
+			Block: &model.Block{Content: &model.BlockContentOfText{Text: &model.BlockContentText{
+				Text:  text,
+				Style: style,
+			}}},
+		})
+		return err
+	})
+	return blockId, err
+}
+
+func (a *spaceApi) SetBlockText(id string, blockId string, value string) error {
+	if err := a.checkSpace(id); err != nil {
+		return err
+	}
+	return cache.DoState(a.service.blockService, id, func(st *state.State, sb smartblock.SmartBlock) error {
+		tb, ok := st.Get(blockId).(text.Block)
+		if !ok {
+			return fmt.Errorf("text block %s not found", blockId)
+		}
+		tb.SetText(value, nil)
+		return nil
+	})
+}
+
+func (a *spaceApi) CreateObject(typeKey string, details *types.Struct, templateId string) (string, error) {
+	id, _, err := a.service.creator.CreateObject(a.ctx, a.spaceId, objectcreator.CreateObjectRequest{
+		Details:       details,
+		ObjectTypeKey: domain.TypeKey(typeKey),
+		TemplateId:    templateId,
+	})
+	return id, err
+}
+
+func (a *spaceApi) checkSpace(id string) error {
+	_, err := a.GetDetails(id)
+	return err
+}
@@ -0,0 +1,133 @@
+// limits wraps the builtins that allocate memory proportional to their arguments in a single native call,
+// the interpreter can't be interrupted inside them. Intrinsics are captured before the script runs,
+// so the script can't restore or replace them
+(function (maxArrayLength, maxStringLength) {
+    "use strict";
+    const apply = Reflect.apply;
+    const construct = Reflect.construct;
+    const getOwnPropertyDescriptor = Object.getOwnPropertyDescriptor;
+    const getOwnPropertyNames = Object.getOwnPropertyNames;
+    const getPrototypeOf = Object.getPrototypeOf;
+    const defineProperty = Object.defineProperty;
+    const RangeErrorConstructor = RangeError;
+    const TypeErrorConstructor = TypeError;
+
+    const check = (n, max) => {
+        if (n > max) {
+            throw new RangeErrorConstructor("allocation limit of the script is exceeded");
+        }
+    };
+    // lengthOf reads the length the native code will see, accessors could return different values on each read
+    const lengthOf = (obj) => {
+        if (typeof obj === "string") {
+            return obj.length;
+        }
+        if (obj === null || (typeof obj !== "object" && typeof obj !== "function")) {
+            return 0;
+        }
+        for (let o = obj; o !== null; o = getPrototypeOf(o)) {
+            const desc = getOwnPropertyDescriptor(o, "length");
+            if (desc) {
+                if (!("value" in desc)) {
+                    throw new TypeErrorConstructor("length accessors are not supported");
+                }
+                return Number(desc.value) || 0;
+            }
+        }
+        return 0;
+    };
+    const replaceMethod = (target, name, wrap) => {
+        const original = target[name];
+        defineProperty(target, name, {
+            value: wrap(original),
+            writable: true,
+            configurable: true,
+            enumerable: false,
+        });
+    };
+
+    for (const name of getOwnPropertyNames(Array.prototype)) {
+        if (name === "constructor" || typeof Array.prototype[name] !== "function") {
+            continue;
+        }
+        replaceMethod(Array.prototype, name, (original) => function (...args) {
+            const length = lengthOf(this);
+            check(length, maxArrayLength);
+            if (name === "concat") {
+                let total = length;
+                for (const arg of args) {
+                    total += lengthOf(arg) || 1;
+                }
+                check(total, maxArrayLength);
+            }
+            if (name === "join") {
+                // elements could reference the same long string many times
+                let total = length * (args[0] === undefined ? 1 : lengthOf(String(args[0])));
+                for (let i = 0; i < length; i++) {
+                    if (typeof this[i] === "string") {
+                        total += this[i].length;
+                    }
+                }
+                check(total, maxStringLength);
+            }
+            return apply(original, this, args);
+        });
+    }
+
+    const OriginalArray = Array;
+    // the constructor shares the prototype with the original one, so instanceof and subclassing keep working
+    const ArrayConstructor = function Array(...args) {
+        if (args.length === 1 && typeof args[0] === "number") {
+            check(args[0], maxArrayLength);
+        }
+        const target = new.target === undefined || new.target === ArrayConstructor ? OriginalArray : new.target;
+        return construct(OriginalArray, args, target);
+    };
+    for (const name of ["isArray", "of"]) {
+        defineProperty(ArrayConstructor, name, {value: OriginalArray[name], writable: true, configurable: true});
+    }
+    const from = OriginalArray.from;
+    defineProperty(ArrayConstructor, "from", {
+        value: function (source, ...args) {
+            check(lengthOf(source), maxArrayLength);
+            return apply(from, this === ArrayConstructor ? OriginalArray : this, [source, ...args]);
+        },
+        writable: true,
+        configurable: true,
+    });
+    defineProperty(ArrayConstructor, Symbol.species, {
+        get() {
+            return this;
+        },
+        configurable: true,
+    });
+    defineProperty(ArrayConstructor, "prototype", {value: OriginalArray.prototype, writable: false});
+    defineProperty(OriginalArray.prototype, "constructor", {value: ArrayConstructor, writable: true, configurable: true});
+    globalThis.Array = ArrayConstructor;
+
+    replaceMethod(String.prototype, "repeat", (original) => function (count) {
+        check(lengthOf(String(this)) * Number(count), maxStringLength);
+        return apply(original, this, [count]);
+    });
+    for (const name of ["padStart", "padEnd"]) {
+        replaceMethod(String.prototype, name, (original) => function (targetLength, ...args) {
+            check(Number(targetLength), maxStringLength);
+            return apply(original, this, [targetLength, ...args]);
+        });
+    }
+    replaceMethod(String.prototype, "split", (original) => function (separator, limit) {
+        const max = limit === undefined ? maxArrayLength + 1 : Math.min(limit >>> 0, maxArrayLength + 1);
+        const parts = apply(original, this, [separator, max]);
+        check(parts.length, maxArrayLength);
+        return parts;
+    });
+
+    // binary data types allocate their whole size at once, proxies could report any length to the checks above
+    for (const name of [
+        "ArrayBuffer", "SharedArrayBuffer", "DataView", "Proxy",
+        "Int8Array", "Uint8Array", "Uint8ClampedArray", "Int16Array", "Uint16Array",
+        "Int32Array", "Uint32Array", "Float32Array", "Float64Array", "BigInt64Array", "BigUint64Array",
+    ]) {
+        delete globalThis[name];
+    }
+})
@@ -0,0 +1,505 @@
+package script
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const (
+	maxCallStackSize = 1024
+	maxLogs          = 1000
+	maxResponseSize  = 10 << 20
+	maxRedirects     = 10
+
+	defaultMaxMemory    = 256 << 20
+	memoryCheckInterval = 5 * time.Millisecond
+	maxArrayLength      = 1 << 22
+	maxStringLength     = 1 << 26
+)
+
+var (
+	ErrTimeout          = errors.New("script timed out")
+	ErrScript           = errors.New("script error")
+	ErrMemoryLimit      = fmt.Errorf("%w: memory limit exceeded", ErrScript)
+	errPermissionDenied = errors.New("permission denied")
+	errMemoryLimit      = errors.New("memory limit exceeded")
+)
+
+//go:embed limits.js
+var limitsScript string
+
+// runLimiter runs scripts one at a time. The memory limit is checked against the heap of the whole process,
+// so concurrent scripts would count allocations of each other
+var runLimiter = make(chan struct{}, 1)
+
+type searchQuery struct {
+	Text    string
+	Types   []string
+	Filters []*model.BlockContentDataviewFilter
+	Limit   int
+}
+
+// objectApi is available to scripts, implementations restrict it to objects of the space of the script
+type objectApi interface {
+	Search(q searchQuery) ([]*types.Struct, error)
+	GetDetails(id string) (*types.Struct, error)
+	SetDetails(id string, details *types.Struct) error
+	GetBlocks(id string) ([]*model.Block, error)
+	AddTextBlock(id string, text string, style model.BlockContentTextStyle) (blockId string, err error)
+	SetBlockText(id string, blockId string, text string) error
+	CreateObject(typeKey string, details *types.Struct, templateId string) (id string, err error)
+}
+
+// sandbox runs the script in the fresh interpreter. The interpreter has no access to the host except the object api,
+// network and files are available only when they are granted
+type sandbox struct {
+	api         objectApi
+	permissions *model.ScriptPermissions
+	// permissionsOutdated is set when the permissions were granted for another version of the code
+	permissionsOutdated bool
+	filesDir            string
+	httpClient          *http.Client
+	timeout             time.Duration
+	// maxMemory limits the growth of the heap while the script runs
+	maxMemory uint64
+
+	ctx  context.Context
+	vm   *goja.Runtime
+	logs []string
+}
+
+func (s *sandbox) run(ctx context.Context, source string, args map[string]any) (resultJson string, logs []string, err error) {
+	select {
+	case runLimiter <- struct{}{}:
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+	defer func() {
+		<-runLimiter
+	}()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	s.ctx = ctx
+	s.vm = goja.New()
+	s.vm.SetMaxCallStackSize(maxCallStackSize)
+	stop := context.AfterFunc(ctx, func() {
+		s.vm.Interrupt(ctx.Err())
+	})
+	defer stop()
+	stopWatch := s.watchMemory(ctx)
+	defer stopWatch()
+
+	if err = s.limitBuiltins(); err != nil {
+		return "", nil, err
+	}
+	if err = s.registerGlobals(args); err != nil {
+		return "", nil, err
+	}
+	value, err := s.vm.RunString(source)
+	if err != nil {
+		return "", s.logs, s.convertError(err)
+	}
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return "", s.logs, nil
+	}
+	raw, err := json.Marshal(value.Export())
+	if err != nil {
+		return "", s.logs, fmt.Errorf("%w: result is not serializable: %w", ErrScript, err)
+	}
+	return string(raw), s.logs, nil
+}
+
+// limitBuiltins wraps the builtins that allocate memory in a single call, see limits.js
+func (s *sandbox) limitBuiltins() error {
+	value, err := s.vm.RunScript("limits.js", limitsScript)
+	if err != nil {
+		return fmt.Errorf("load limits: %w", err)
+	}
+	limits, ok := goja.AssertFunction(value)
+	if !ok {
+		return errors.New("load limits: not a function")
+	}
+	_, err = limits(goja.Undefined(), s.vm.ToValue(maxArrayLength), s.vm.ToValue(maxStringLength))
+	return err
+}
+
+// watchMemory interrupts the script when the heap grows by more than maxMemory while it runs. The heap is shared
+// with the rest of the process, so the garbage is collected before the script is stopped. Allocations of other
+// goroutines still count against the script, so the limit is approximate
+func (s *sandbox) watchMemory(ctx context.Context) (stop func()) {
+	maxMemory := s.maxMemory
+	if maxMemory == 0 {
+		maxMemory = defaultMaxMemory
+	}
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	heapSize := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+	baseline := heapSize()
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(memoryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if heapSize() <= baseline+maxMemory {
+				continue
+			}
+			runtime.GC()
+			if heapSize() > baseline+maxMemory {
+				s.vm.Interrupt(errMemoryLimit)
+				return
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *sandbox) convertError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if v, ok := interrupted.Value().(error); ok && errors.Is(v, errMemoryLimit) {
+			return ErrMemoryLimit
+		}
+		if errors.Is(s.ctx.Err(), context.DeadlineExceeded) {
+			return ErrTimeout
+		}
+		return s.ctx.Err()
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return fmt.Errorf("%w: %s", ErrScript, exception.Error())
+	}
+	return fmt.Errorf("%w: %w", ErrScript, err)
+}
+
+func (s *sandbox) registerGlobals(args map[string]any) error {
+	if args == nil {
+		args = map[string]any{}
+	}
+	console := s.vm.NewObject()
+	if err := console.Set("log", s.log); err != nil {
+		return err
+	}
+	api := s.vm.NewObject()
+	for name, fn := range map[string]any{
+		"log":          s.log,
+		"search":       s.search,
+		"getObject":    s.getObject,
+		"setDetails":   s.setDetails,
+		"getBlocks":    s.getBlocks,
+		"addBlock":     s.addBlock,
+		"setBlockText": s.setBlockText,
+		"createObject": s.createObject,
+		"fetch":        s.fetch,
+		"readFile":     s.readFile,
+		"writeFile":    s.writeFile,
+	} {
+		if err := api.Set(name, fn); err != nil {
+			return err
+		}
+	}
+	for name, value := range map[string]any{
+		"console": console,
+		"anytype": api,
+		"args":    args,
+	} {
+		if err := s.vm.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sandbox) log(call goja.FunctionCall) goja.Value {
+	if len(s.logs) >= maxLogs {
+		return goja.Undefined()
+	}
+	parts := make([]string, 0, len(call.Arguments))
+	for _, arg := range call.Arguments {
+		parts = append(parts, arg.String())
+	}
+	s.logs = append(s.logs, strings.Join(parts, " "))
+	return goja.Undefined()
+}
+
+// search accepts {query, types, filters, limit}, filters have the format of dataview filters, e.g.
+// {relationKey: "status", condition: "Equal", value: "done"}
+func (s *sandbox) search(params map[string]any) ([]map[string]any, error) {
+	q := searchQuery{}
+	q.Text, _ = params["query"].(string)
+	if limit, ok := params["limit"].(int64); ok {
+		q.Limit = int(limit)
+	}
+	if list, ok := params["types"].([]any); ok {
+		for _, t := range list {
+			if typeKey, ok := t.(string); ok {
+				q.Types = append(q.Types, typeKey)
+			}
+		}
+	}
+	if list, ok := params["filters"].([]any); ok {
+		for _, raw := range list {
+			filter, err := parseFilter(raw)
+			if err != nil {
+				return nil, err
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+	records, err := s.api.Search(q)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(records))
+	for _, details := range records {
+		result = append(result, pbtypes.StructToMap(details))
+	}
+	return result, nil
+}
+
+func parseFilter(raw any) (*model.BlockContentDataviewFilter, error) {
+	// the proto field is named RelationKey, accept the camel case name used everywhere else
+	if m, ok := raw.(map[string]any); ok {
+		if key, ok := m["relationKey"]; ok {
+			m["RelationKey"] = key
+			delete(m, "relationKey")
+		}
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	filter := &model.BlockContentDataviewFilter{}
+	if err = jsonpb.UnmarshalString(string(data), filter); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return filter, nil
+}
+
+func (s *sandbox) getObject(id string) (map[string]any, error) {
+	details, err := s.api.GetDetails(id)
+	if err != nil {
+		return nil, err
+	}
+	return pbtypes.StructToMap(details), nil
+}
+
+func (s *sandbox) setDetails(id string, details map[string]any) error {
+	return s.api.SetDetails(id, pbtypes.ToStruct(details))
+}
+
+func (s *sandbox) getBlocks(id string) ([]map[string]any, error) {
+	blocks, err := s.api.GetBlocks(id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(blocks))
+	for _, b := range blocks {
+		result = append(result, blockToMap(b))
+	}
+	return result, nil
+}
+
+func blockToMap(b *model.Block) map[string]any {
+	m := map[string]any{
+		"id":          b.Id,
+		"childrenIds": b.ChildrenIds,
+	}
+	switch c := b.Content.(type) {
+	case *model.BlockContentOfText:
+		m["type"] = "text"
+		m["text"] = c.Text.Text
+		m["style"] = c.Text.Style.String()
+		m["checked"] = c.Text.Checked
+	case *model.BlockContentOfLink:
+		m["type"] = "link"
+		m["targetId"] = c.Link.TargetBlockId
+	case *model.BlockContentOfFile:
+		m["type"] = "file"
+		m["targetId"] = c.File.TargetObjectId
+	default:
+		m["type"] = "other"
+	}
+	return m
+}
+
+func (s *sandbox) addBlock(id string, text string, style string) (string, error) {
+	textStyle := model.BlockContentText_Paragraph
+	if style != "" {
+		value, ok := model.BlockContentTextStyle_value[style]
+		if !ok {
+			return "", fmt.Errorf("unknown text style %q", style)
+		}
+		textStyle = model.BlockContentTextStyle(value)
+	}
+	return s.api.AddTextBlock(id, text, textStyle)
+}
+
+func (s *sandbox) setBlockText(id string, blockId string, text string) error {
+	return s.api.SetBlockText(id, blockId, text)
+}
+
+// createObject accepts {type, name, details, templateId} and returns the id of the created object
+func (s *sandbox) createObject(params map[string]any) (string, error) {
+	typeKey, _ := params["type"].(string)
+	if typeKey == "" {
+		return "", errors.New("type is required")
+	}
+	details := &types.Struct{Fields: map[string]*types.Value{}}
+	if d, ok := params["details"].(map[string]any); ok {
+		for key, value := range d {
+			details.Fields[key] = pbtypes.ToValue(value)
+		}
+	}
+	if name, ok := params["name"].(string); ok {
+		details.Fields[bundle.RelationKeyName.String()] = pbtypes.String(name)
+	}
+	templateId, _ := params["templateId"].(string)
+	return s.api.CreateObject(typeKey, details, templateId)
+}
+
+// fetch accepts the url and {method, headers, body} options and returns {status, headers, body}
+func (s *sandbox) fetch(rawUrl string, options map[string]any) (map[string]any, error) {
+	if err := s.checkNetwork(rawUrl); err != nil {
+		return nil, err
+	}
+	method, _ := options["method"].(string)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if b, ok := options["body"].(string); ok {
+		body = strings.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(s.ctx, method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	if headers, ok := options["headers"].(map[string]any); ok {
+		for key, value := range headers {
+			req.Header.Set(key, fmt.Sprint(value))
+		}
+	}
+	client := *s.httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("too many redirects")
+		}
+		return s.checkNetwork(req.URL.String())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]any{}
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+	return map[string]any{
+		"status":  resp.StatusCode,
+		"headers": headers,
+		"body":    string(data),
+	}, nil
+}
+
+// checkNetwork allows only the hosts listed in the permissions, "*.example.com" matches subdomains of example.com
+func (s *sandbox) checkNetwork(rawUrl string) error {
+	if !s.permissions.GetNetwork() {
+		return s.permissionDenied("network access is not granted")
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", errPermissionDenied, u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.permissions.GetHosts() {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %s is not allowed", errPermissionDenied, host)
+}
+
+func (s *sandbox) permissionDenied(reason string) error {
+	if s.permissionsOutdated {
+		return fmt.Errorf("%w: %s, permissions were granted for another version of the script", errPermissionDenied, reason)
+	}
+	return fmt.Errorf("%w: %s", errPermissionDenied, reason)
+}
+
+func (s *sandbox) readFile(name string) (string, error) {
+	path, err := s.filePath(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *sandbox) writeFile(name string, data string) error {
+	path, err := s.filePath(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0600)
+}
+
+// filePath resolves the name inside the directory of the script, names that point outside of it are rejected
+func (s *sandbox) filePath(name string) (string, error) {
+	if !s.permissions.GetFiles() || s.filesDir == "" {
+		return "", s.permissionDenied("file access is not granted")
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: path must be relative to the script directory", errPermissionDenied)
+	}
+	return filepath.Join(s.filesDir, name), nil
+}
@@ -0,0 +1,311 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+type fakeApi struct {
+	records []*types.Struct
+	query   searchQuery
+	details map[string]*types.Struct
+	blocks  []string
+	created []string
+}
+
+func (f *fakeApi) Search(q searchQuery) ([]*types.Struct, error) {
+	f.query = q
+	return f.records, nil
+}
+
+func (f *fakeApi) GetDetails(id string) (*types.Struct, error) {
+	return nil, errors.New("object is not in the space of the script")
+}
+
+func (f *fakeApi) SetDetails(id string, details *types.Struct) error {
+	f.details[id] = details
+	return nil
+}
+
+func (f *fakeApi) GetBlocks(string) ([]*model.Block, error) {
+	return nil, nil
+}
+
+func (f *fakeApi) AddTextBlock(_ string, text string, style model.BlockContentTextStyle) (string, error) {
+	f.blocks = append(f.blocks, style.String()+":"+text)
+	return "block", nil
+}
+
+func (f *fakeApi) SetBlockText(string, string, string) error {
+	return nil
+}
+
+func (f *fakeApi) CreateObject(typeKey string, details *types.Struct, _ string) (string, error) {
+	f.created = append(f.created, typeKey+":"+pbtypes.GetString(details, "name"))
+	return "created", nil
+}
+
+func newTestSandbox(api *fakeApi) *sandbox {
+	return &sandbox{
+		api:         api,
+		permissions: &model.ScriptPermissions{},
+		httpClient:  http.DefaultClient,
+		timeout:     time.Second,
+	}
+}
+
+func TestSandbox_Run(t *testing.T) {
+	t.Run("search result is returned as json", func(t *testing.T) {
+		// given
+		api := &fakeApi{records: []*types.Struct{
+			pbtypes.ToStruct(map[string]any{"name": "first"}),
+			pbtypes.ToStruct(map[string]any{"name": "second"}),
+		}}
+		source := `
+			console.log("found", args.limit);
+			anytype.search({
+				types: ["task"],
+				limit: args.limit,
+				filters: [{relationKey: "status", condition: "Equal", value: "done"}],
+			}).map(o => o.name)`
+
+		// when
+		result, logs, err := newTestSandbox(api).run(context.Background(), source, map[string]any{"limit": 5})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `["first","second"]`, result)
+		assert.Equal(t, []string{"found 5"}, logs)
+		assert.Equal(t, []string{"task"}, api.query.Types)
+		assert.Equal(t, 5, api.query.Limit)
+		require.Len(t, api.query.Filters, 1)
+		assert.Equal(t, model.BlockContentDataviewFilter_Equal, api.query.Filters[0].Condition)
+		assert.Equal(t, "done", api.query.Filters[0].Value.GetStringValue())
+	})
+
+	t.Run("objects are changed via api", func(t *testing.T) {
+		// given
+		api := &fakeApi{details: map[string]*types.Struct{}}
+		source := `
+			const id = anytype.createObject({type: "page", name: "Weekly report"});
+			anytype.addBlock(id, "Done this week", "Header2");
+			anytype.setDetails(id, {done: true});`
+
+		// when
+		_, _, err := newTestSandbox(api).run(context.Background(), source, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"page:Weekly report"}, api.created)
+		assert.Equal(t, []string{"Header2:Done this week"}, api.blocks)
+		assert.True(t, pbtypes.GetBool(api.details["created"], "done"))
+	})
+
+	t.Run("api errors are thrown as exceptions", func(t *testing.T) {
+		// given
+		source := `try { anytype.getObject("foreign") } catch (e) { "caught" }`
+
+		// when
+		result, _, err := newTestSandbox(&fakeApi{}).run(context.Background(), source, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `"caught"`, result)
+	})
+
+	t.Run("uncaught exception", func(t *testing.T) {
+		// when
+		_, _, err := newTestSandbox(&fakeApi{}).run(context.Background(), `throw new Error("boom")`, nil)
+
+		// then
+		assert.ErrorIs(t, err, ErrScript)
+		assert.Contains(t, err.Error(), "boom")
+	})
+
+	t.Run("script is interrupted on timeout", func(t *testing.T) {
+		// given
+		sb := newTestSandbox(&fakeApi{})
+		sb.timeout = 50 * time.Millisecond
+
+		// when
+		_, _, err := sb.run(context.Background(), `while (true) {}`, nil)
+
+		// then
+		assert.ErrorIs(t, err, ErrTimeout)
+	})
+}
+
+func TestSandbox_Permissions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	t.Run("network and files are denied by default", func(t *testing.T) {
+		for _, source := range []string{
+			`anytype.fetch("` + server.URL + `", {})`,
+			`anytype.readFile("notes.txt")`,
+		} {
+			// when
+			_, _, err := newTestSandbox(&fakeApi{}).run(context.Background(), source, nil)
+
+			// then
+			assert.ErrorIs(t, err, ErrScript)
+			assert.Contains(t, err.Error(), "permission denied")
+		}
+	})
+
+	t.Run("granted network", func(t *testing.T) {
+		// given
+		sb := newTestSandbox(&fakeApi{})
+		sb.permissions.Network = true
+		sb.permissions.Hosts = []string{"127.0.0.1"}
+
+		// when
+		result, _, err := sb.run(context.Background(), `const r = anytype.fetch("`+server.URL+`", {}); [r.status, r.body]`, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `[200,"pong"]`, result)
+	})
+
+	t.Run("network is limited to the granted hosts", func(t *testing.T) {
+		// given
+		redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://localhost/", http.StatusFound)
+		}))
+		defer redirect.Close()
+		sb := newTestSandbox(&fakeApi{})
+		sb.permissions.Network = true
+		sb.permissions.Hosts = []string{"*.example.com", "127.0.0.1"}
+
+		for _, source := range []string{
+			`anytype.fetch("https://example.org", {})`,
+			`anytype.fetch("https://example.com.evil.org", {})`,
+			`anytype.fetch("file:///etc/passwd", {})`,
+			`anytype.fetch("` + redirect.URL + `", {})`,
+		} {
+			// when
+			_, _, err := sb.run(context.Background(), source, nil)
+
+			// then
+			assert.ErrorContains(t, err, "permission denied", source)
+		}
+		assert.NoError(t, sb.checkNetwork("https://api.example.com/v1"))
+	})
+
+	t.Run("outdated permissions", func(t *testing.T) {
+		// given
+		sb := newTestSandbox(&fakeApi{})
+		sb.permissionsOutdated = true
+
+		// when
+		_, _, err := sb.run(context.Background(), `anytype.fetch("`+server.URL+`", {})`, nil)
+
+		// then
+		assert.ErrorContains(t, err, "another version of the script")
+	})
+
+	t.Run("granted files are limited to the script directory", func(t *testing.T) {
+		// given
+		sb := newTestSandbox(&fakeApi{})
+		sb.permissions.Files = true
+		sb.filesDir = t.TempDir()
+
+		// when
+		result, _, err := sb.run(context.Background(), `anytype.writeFile("reports/week.md", "# Week"); anytype.readFile("reports/week.md")`, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `"# Week"`, result)
+
+		// when
+		_, _, err = sb.run(context.Background(), `anytype.readFile("../secret")`, nil)
+
+		// then
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
+
+func TestSandbox_MemoryLimits(t *testing.T) {
+	t.Run("single allocations are checked", func(t *testing.T) {
+		for _, source := range []string{
+			`"x".repeat(1e9)`,
+			`"x".padStart(1e9)`,
+			`new Array(1e9).fill(0)`,
+			`Array(1e9)`,
+			`Array.from({length: 1e9})`,
+			`const a = []; a.length = 1e9; a.map(x => x)`,
+			`const s = "x".repeat(1e6); new Array(1e3).fill(s).join("")`,
+			`"ab".repeat(1e7).split("")`,
+			`Array.prototype.fill.call({get length() { return 1e9 }}, 0)`,
+			`new Uint8Array(1e9)`,
+		} {
+			// when
+			_, _, err := newTestSandbox(&fakeApi{}).run(context.Background(), source, nil)
+
+			// then
+			assert.ErrorIs(t, err, ErrScript, source)
+		}
+	})
+
+	t.Run("growing heap is interrupted", func(t *testing.T) {
+		// given
+		sb := newTestSandbox(&fakeApi{})
+		sb.timeout = 10 * time.Second
+		sb.maxMemory = 16 << 20
+
+		// when
+		_, _, err := sb.run(context.Background(), `const a = []; while (true) { a.push("x".repeat(1000) + a.length) }`, nil)
+
+		// then
+		assert.ErrorIs(t, err, ErrMemoryLimit)
+	})
+
+	t.Run("scripts wait for the running one", func(t *testing.T) {
+		// given
+		runLimiter <- struct{}{}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// when
+		_, _, err := newTestSandbox(&fakeApi{}).run(ctx, `1`, nil)
+		<-runLimiter
+
+		// then
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("builtins work within limits", func(t *testing.T) {
+		// given
+		source := `
+			class List extends Array {}
+			const list = List.from([3, 1, 2]).sort();
+			[
+				new Array(3).fill("a").join("-"),
+				"a,b".split(","),
+				[1, 2].concat([3], 4).map(x => x * 2),
+				list instanceof List,
+				[] instanceof Array,
+				"ab".repeat(2).padStart(6, "."),
+			]`
+
+		// when
+		result, _, err := newTestSandbox(&fakeApi{}).run(context.Background(), source, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `["a-a-a",["a","b"],[2,4,6,8],true,true,"..abab"]`, result)
+	})
+}
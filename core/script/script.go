@@ -0,0 +1,220 @@
+// Package script runs user-defined JavaScript commands stored in code blocks of objects of script type.
+// Scripts are run one at a time in the sandboxed interpreter with time and memory limits and get the api restricted
+// to objects of the space of the script. The memory limit is approximate, it is measured as the growth of the heap
+// of the whole process while the script runs. Network and file access are granted per script and kept locally,
+// so they are not synced. Grants are bound to the code they were given for, because the code could be changed
+// by other members of the space
+package script
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/anyproto/anytype-heart/core/block"
+	"github.com/anyproto/anytype-heart/core/block/cache"
+	"github.com/anyproto/anytype-heart/core/block/editor/smartblock"
+	"github.com/anyproto/anytype-heart/core/block/object/objectcreator"
+	"github.com/anyproto/anytype-heart/core/block/simple"
+	"github.com/anyproto/anytype-heart/core/wallet"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const CName = "core.script"
+
+const (
+	defaultTimeout = 10 * time.Second
+	scriptsDir     = "scripts"
+)
+
+var (
+	ErrNotFound = errors.New("script not found")
+	ErrNoCode   = errors.New("script has no code blocks")
+)
+
+type Result struct {
+	Json string
+	Logs []string
+}
+
+type Service interface {
+	// Run runs the code of the script object, args are available to the script as the args global
+	Run(ctx context.Context, scriptId string, args map[string]any) (*Result, error)
+	SetPermissions(scriptId string, permissions *model.ScriptPermissions) error
+	GetPermissions(scriptId string) (*model.ScriptPermissions, error)
+	app.Component
+}
+
+func New() Service {
+	return &service{}
+}
+
+type service struct {
+	objectStore  objectstore.ObjectStore
+	blockService *block.Service
+	creator      objectcreator.Service
+	permissions  keyvaluestore.Store[*model.ScriptPermissions]
+	repoPath     string
+	httpClient   *http.Client
+}
+
+func (s *service) Init(a *app.App) error {
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	s.blockService = app.MustComponent[*block.Service](a)
+	s.creator = app.MustComponent[objectcreator.Service](a)
+	s.repoPath = app.MustComponent[wallet.Wallet](a).RepoPath()
+	db, err := app.MustComponent[datastore.Datastore](a).LocalStorage()
+	if err != nil {
+		return fmt.Errorf("get badger: %w", err)
+	}
+	s.permissions = keyvaluestore.New(db, []byte("script/permissions/"), marshalPermissions, unmarshalPermissions)
+	s.httpClient = &http.Client{Timeout: defaultTimeout}
+	return nil
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(ctx context.Context, scriptId string, args map[string]any) (*Result, error) {
+	details, err := s.getScriptDetails(scriptId)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.readSource(scriptId)
+	if err != nil {
+		return nil, err
+	}
+	permissions, outdated, err := s.permissionsFor(scriptId, source)
+	if err != nil {
+		return nil, err
+	}
+	spaceId := pbtypes.GetString(details, bundle.RelationKeySpaceId.String())
+	sb := &sandbox{
+		api:                 &spaceApi{ctx: ctx, service: s, spaceId: spaceId},
+		permissions:         permissions,
+		permissionsOutdated: outdated,
+		filesDir:            filepath.Join(s.repoPath, scriptsDir, scriptId),
+		httpClient:          s.httpClient,
+		timeout:             defaultTimeout,
+	}
+	resultJson, logs, err := sb.run(ctx, source, args)
+	return &Result{Json: resultJson, Logs: logs}, err
+}
+
+// SetPermissions grants the permissions to the current code of the script
+func (s *service) SetPermissions(scriptId string, permissions *model.ScriptPermissions) error {
+	source, err := s.currentSource(scriptId)
+	if err != nil {
+		return err
+	}
+	granted := &model.ScriptPermissions{
+		Network:    permissions.GetNetwork(),
+		Files:      permissions.GetFiles(),
+		Hosts:      permissions.GetHosts(),
+		SourceHash: sourceHash(source),
+	}
+	return s.permissions.Set(scriptId, granted)
+}
+
+// GetPermissions returns the permissions granted to the current code of the script
+func (s *service) GetPermissions(scriptId string) (*model.ScriptPermissions, error) {
+	source, err := s.currentSource(scriptId)
+	if err != nil {
+		return nil, err
+	}
+	permissions, _, err := s.permissionsFor(scriptId, source)
+	return permissions, err
+}
+
+// currentSource returns the code of the script, the script could have no code blocks yet
+func (s *service) currentSource(scriptId string) (string, error) {
+	if _, err := s.getScriptDetails(scriptId); err != nil {
+		return "", err
+	}
+	source, err := s.readSource(scriptId)
+	if err != nil && !errors.Is(err, ErrNoCode) {
+		return "", err
+	}
+	return source, nil
+}
+
+// permissionsFor returns the permissions granted to the source. When the code was changed after the grant,
+// only the hosts are kept, so the client could ask the user again with the same list
+func (s *service) permissionsFor(scriptId string, source string) (permissions *model.ScriptPermissions, outdated bool, err error) {
+	permissions, err = s.permissions.Get(scriptId)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		return &model.ScriptPermissions{}, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get permissions: %w", err)
+	}
+	if permissions.SourceHash != sourceHash(source) {
+		return &model.ScriptPermissions{Hosts: permissions.Hosts}, true, nil
+	}
+	return permissions, false, nil
+}
+
+func sourceHash(source string) string {
+	hash := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(hash[:])
+}
+
+func (s *service) getScriptDetails(id string) (*types.Struct, error) {
+	details, err := s.objectStore.GetDetails(id)
+	if err != nil {
+		return nil, fmt.Errorf("get details: %w", err)
+	}
+	typeDetails, err := s.objectStore.GetDetails(pbtypes.GetString(details.GetDetails(), bundle.RelationKeyType.String()))
+	if err != nil {
+		return nil, fmt.Errorf("get type details: %w", err)
+	}
+	if pbtypes.GetString(typeDetails.GetDetails(), bundle.RelationKeyUniqueKey.String()) != bundle.TypeKeyScript.URL() {
+		return nil, ErrNotFound
+	}
+	return details.GetDetails(), nil
+}
+
+// readSource joins the code of all code blocks of the script object
+func (s *service) readSource(id string) (string, error) {
+	var parts []string
+	err := cache.Do(s.blockService, id, func(sb smartblock.SmartBlock) error {
+		return sb.NewState().Iterate(func(b simple.Block) (isContinue bool) {
+			if t := b.Model().GetText(); t != nil && t.Style == model.BlockContentText_Code {
+				parts = append(parts, t.Text)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("read script: %w", err)
+	}
+	if len(parts) == 0 {
+		return "", ErrNoCode
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+func marshalPermissions(p *model.ScriptPermissions) ([]byte, error) {
+	return proto.Marshal(p)
+}
+
+func unmarshalPermissions(data []byte) (*model.ScriptPermissions, error) {
+	p := &model.ScriptPermissions{}
+	return p, proto.Unmarshal(data, p)
+}
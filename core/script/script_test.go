@@ -0,0 +1,50 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+)
+
+func TestService_PermissionsFor(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLoggingLevel(badger.ERROR))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	s := &service{permissions: keyvaluestore.New(db, []byte("script/permissions/"), marshalPermissions, unmarshalPermissions)}
+	source := `anytype.fetch("https://example.com", {})`
+	require.NoError(t, s.permissions.Set("script", &model.ScriptPermissions{
+		Network:    true,
+		Hosts:      []string{"example.com"},
+		SourceHash: sourceHash(source),
+	}))
+
+	t.Run("not granted", func(t *testing.T) {
+		permissions, outdated, err := s.permissionsFor("other", source)
+
+		require.NoError(t, err)
+		assert.False(t, outdated)
+		assert.False(t, permissions.Network)
+	})
+
+	t.Run("granted to the same code", func(t *testing.T) {
+		permissions, outdated, err := s.permissionsFor("script", source)
+
+		require.NoError(t, err)
+		assert.False(t, outdated)
+		assert.True(t, permissions.Network)
+	})
+
+	t.Run("code is changed after the grant", func(t *testing.T) {
+		permissions, outdated, err := s.permissionsFor("script", source+`; anytype.fetch("https://evil.org", {})`)
+
+		require.NoError(t, err)
+		assert.True(t, outdated)
+		assert.False(t, permissions.Network)
+		assert.Equal(t, []string{"example.com"}, permissions.Hosts)
+	})
+}
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"context"
+
 	"github.com/anyproto/anytype-heart/pb"
 )
 
@@ -18,6 +20,8 @@ type sessionContext struct {
 	traceId      string
 	messages     []*pb.EventMessage
 	sessionToken string
+	// parent is the context of the request, it carries the span of the request to operations of the context
+	parent context.Context
 }
 
 func NewContext(opts ...ContextOption) Context {
@@ -34,6 +38,7 @@ func NewChildContext(parent Context) Context {
 		smartBlockId: parent.ObjectID(),
 		traceId:      parent.TraceID(),
 		sessionToken: parent.ID(),
+		parent:       ParentContext(parent),
 	}
 	return child
 }
@@ -52,6 +57,21 @@ func WithTraceId(traceId string) ContextOption {
 	}
 }
 
+// WithParent sets the context of the request the session context is created for
+func WithParent(parent context.Context) ContextOption {
+	return func(ctx *sessionContext) {
+		ctx.parent = parent
+	}
+}
+
+// ParentContext returns the context of the request or context.Background() if it's not set
+func ParentContext(ctx Context) context.Context {
+	if sc, ok := ctx.(*sessionContext); ok && sc.parent != nil {
+		return sc.parent
+	}
+	return context.Background()
+}
+
 type Closer interface {
 	CloseSession(token string)
 }
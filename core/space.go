@@ -10,6 +10,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/acl"
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/inviteservice"
+	"github.com/anyproto/anytype-heart/core/spaceactivity"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/space"
@@ -105,12 +106,97 @@ func (mw *Middleware) SpaceInviteGetCurrent(cctx context.Context, req *pb.RpcSpa
 	}
 }
 
+func (mw *Middleware) SpaceInviteCreate(cctx context.Context, req *pb.RpcSpaceInviteCreateRequest) *pb.RpcSpaceInviteCreateResponse {
+	aclService := getService[acl.AclService](mw)
+	invite, err := aclService.CreateInvite(cctx, req.SpaceId, domain.InviteParams{
+		Label:       req.Label,
+		Permissions: req.Permissions,
+		ExpireDate:  req.ExpireDate,
+		MaxUses:     int(req.MaxUses),
+	})
+	if err != nil {
+		code := mapErrorCode(err,
+			errToCode(space.ErrSpaceDeleted, pb.RpcSpaceInviteCreateResponseError_SPACE_IS_DELETED),
+			errToCode(space.ErrSpaceNotExists, pb.RpcSpaceInviteCreateResponseError_NO_SUCH_SPACE),
+			errToCode(acl.ErrPersonalSpace, pb.RpcSpaceInviteCreateResponseError_BAD_INPUT),
+			errToCode(acl.ErrInvalidInviteParams, pb.RpcSpaceInviteCreateResponseError_BAD_INPUT),
+			errToCode(acl.ErrAclRequestFailed, pb.RpcSpaceInviteCreateResponseError_REQUEST_FAILED),
+			errToCode(acl.ErrLimitReached, pb.RpcSpaceInviteCreateResponseError_LIMIT_REACHED),
+			errToCode(acl.ErrNotShareable, pb.RpcSpaceInviteCreateResponseError_NOT_SHAREABLE),
+		)
+		return &pb.RpcSpaceInviteCreateResponse{
+			Error: &pb.RpcSpaceInviteCreateResponseError{
+				Code:        code,
+				Description: getErrorDescription(err),
+			},
+		}
+	}
+	return &pb.RpcSpaceInviteCreateResponse{
+		Invite: inviteToProto(invite),
+	}
+}
+
+func (mw *Middleware) SpaceInviteList(cctx context.Context, req *pb.RpcSpaceInviteListRequest) *pb.RpcSpaceInviteListResponse {
+	aclService := getService[acl.AclService](mw)
+	invites, joins, err := aclService.ListInvites(cctx, req.SpaceId)
+	if err != nil {
+		code := mapErrorCode(err,
+			errToCode(space.ErrSpaceDeleted, pb.RpcSpaceInviteListResponseError_SPACE_IS_DELETED),
+			errToCode(space.ErrSpaceNotExists, pb.RpcSpaceInviteListResponseError_NO_SUCH_SPACE),
+			errToCode(acl.ErrAclRequestFailed, pb.RpcSpaceInviteListResponseError_REQUEST_FAILED),
+		)
+		return &pb.RpcSpaceInviteListResponse{
+			Error: &pb.RpcSpaceInviteListResponseError{
+				Code:        code,
+				Description: getErrorDescription(err),
+			},
+		}
+	}
+	resp := &pb.RpcSpaceInviteListResponse{
+		Invites: make([]*pb.RpcSpaceInviteListInvite, 0, len(invites)),
+		Joins:   make([]*pb.RpcSpaceInviteListInviteJoin, 0, len(joins)),
+	}
+	for _, invite := range invites {
+		resp.Invites = append(resp.Invites, inviteToProto(invite))
+	}
+	for _, join := range joins {
+		resp.Joins = append(resp.Joins, &pb.RpcSpaceInviteListInviteJoin{
+			Identity:       join.Identity,
+			InviteRecordId: join.InviteRecordId,
+			RequestDate:    join.RequestDate,
+			Accepted:       join.Accepted,
+		})
+	}
+	return resp
+}
+
+func inviteToProto(invite domain.Invite) *pb.RpcSpaceInviteListInvite {
+	return &pb.RpcSpaceInviteListInvite{
+		RecordId:      invite.RecordId,
+		InviteCid:     invite.InviteFileCid,
+		InviteFileKey: invite.InviteFileKey,
+		Label:         invite.Label,
+		Permissions:   invite.Permissions,
+		ExpireDate:    invite.ExpireDate,
+		MaxUses:       int32(invite.MaxUses),
+		Uses:          int32(invite.Uses),
+		CreatedDate:   invite.CreatedDate,
+		RevokedDate:   invite.RevokedDate,
+	}
+}
+
 func (mw *Middleware) SpaceInviteRevoke(cctx context.Context, req *pb.RpcSpaceInviteRevokeRequest) *pb.RpcSpaceInviteRevokeResponse {
 	aclService := mw.applicationService.GetApp().MustComponent(acl.CName).(acl.AclService)
-	err := aclService.RevokeInvite(cctx, req.SpaceId)
+	var err error
+	if req.InviteRecordId != "" {
+		err = aclService.RevokeInviteById(cctx, req.SpaceId, req.InviteRecordId)
+	} else {
+		err = aclService.RevokeInvite(cctx, req.SpaceId)
+	}
 	code := mapErrorCode(err,
 		errToCode(space.ErrSpaceDeleted, pb.RpcSpaceInviteRevokeResponseError_SPACE_IS_DELETED),
 		errToCode(space.ErrSpaceNotExists, pb.RpcSpaceInviteRevokeResponseError_NO_SUCH_SPACE),
+		errToCode(inviteservice.ErrInviteNotExists, pb.RpcSpaceInviteRevokeResponseError_BAD_INPUT),
 		errToCode(acl.ErrAclRequestFailed, pb.RpcSpaceInviteRevokeResponseError_REQUEST_FAILED),
 		errToCode(acl.ErrLimitReached, pb.RpcSpaceInviteRevokeResponseError_LIMIT_REACHED),
 		errToCode(acl.ErrNotShareable, pb.RpcSpaceInviteRevokeResponseError_NOT_SHAREABLE),
@@ -132,6 +218,7 @@ func (mw *Middleware) SpaceInviteView(cctx context.Context, req *pb.RpcSpaceInvi
 			errToCode(inviteservice.ErrInviteGet, pb.RpcSpaceInviteViewResponseError_INVITE_NOT_FOUND),
 			errToCode(inviteservice.ErrInviteBadContent, pb.RpcSpaceInviteViewResponseError_INVITE_BAD_CONTENT),
 			errToCode(space.ErrSpaceDeleted, pb.RpcSpaceInviteViewResponseError_SPACE_IS_DELETED),
+			errToCode(inviteservice.ErrInviteExpired, pb.RpcSpaceInviteViewResponseError_INVITE_EXPIRED),
 		)
 		return &pb.RpcSpaceInviteViewResponse{
 			Error: &pb.RpcSpaceInviteViewResponseError{
@@ -145,6 +232,8 @@ func (mw *Middleware) SpaceInviteView(cctx context.Context, req *pb.RpcSpaceInvi
 		SpaceId:      inviteView.SpaceId,
 		SpaceName:    inviteView.SpaceName,
 		SpaceIconCid: inviteView.SpaceIconCid,
+		Permissions:  inviteView.Permissions,
+		ExpireDate:   inviteView.ExpireDate,
 	}
 }
 
@@ -173,6 +262,7 @@ func (mw *Middleware) SpaceJoin(cctx context.Context, req *pb.RpcSpaceJoinReques
 		errToCode(inviteservice.ErrInviteBadContent, pb.RpcSpaceJoinResponseError_INVITE_BAD_CONTENT),
 		errToCode(acl.ErrNotShareable, pb.RpcSpaceJoinResponseError_NOT_SHAREABLE),
 		errToCode(acl.ErrDifferentNetwork, pb.RpcSpaceJoinResponseError_DIFFERENT_NETWORK),
+		errToCode(inviteservice.ErrInviteExpired, pb.RpcSpaceJoinResponseError_INVITE_EXPIRED),
 	)
 	return &pb.RpcSpaceJoinResponse{
 		Error: &pb.RpcSpaceJoinResponseError{
@@ -229,6 +319,9 @@ func (mw *Middleware) SpaceRequestApprove(cctx context.Context, req *pb.RpcSpace
 		errToCode(acl.ErrAclRequestFailed, pb.RpcSpaceRequestApproveResponseError_REQUEST_FAILED),
 		errToCode(acl.ErrLimitReached, pb.RpcSpaceRequestApproveResponseError_LIMIT_REACHED),
 		errToCode(acl.ErrNotShareable, pb.RpcSpaceRequestApproveResponseError_NOT_SHAREABLE),
+		errToCode(inviteservice.ErrInviteExpired, pb.RpcSpaceRequestApproveResponseError_INVITE_EXPIRED),
+		errToCode(inviteservice.ErrInviteExhausted, pb.RpcSpaceRequestApproveResponseError_INVITE_EXHAUSTED),
+		errToCode(inviteservice.ErrInviteRevoked, pb.RpcSpaceRequestApproveResponseError_INVITE_REVOKED),
 	)
 	return &pb.RpcSpaceRequestApproveResponse{
 		Error: &pb.RpcSpaceRequestApproveResponseError{
@@ -381,3 +474,65 @@ func permissionsChange(ctx context.Context, spaceId string, changes []*model.Par
 	}
 	return aclService.ChangePermissions(ctx, spaceId, accPermissions)
 }
+
+func (mw *Middleware) SpaceActivityList(cctx context.Context, req *pb.RpcSpaceActivityListRequest) *pb.RpcSpaceActivityListResponse {
+	sessions, err := getService[spaceactivity.Service](mw).List(cctx, activityFilter(req.SpaceId, req.ParticipantIds, req.ObjectTypeIds, req.DateFrom, req.DateTo, req.Limit))
+	if err != nil {
+		code := mapErrorCode(err,
+			errToCode(space.ErrSpaceNotExists, pb.RpcSpaceActivityListResponseError_NO_SUCH_SPACE),
+		)
+		return &pb.RpcSpaceActivityListResponse{
+			Error: &pb.RpcSpaceActivityListResponseError{
+				Code:        code,
+				Description: getErrorDescription(err),
+			},
+		}
+	}
+	return &pb.RpcSpaceActivityListResponse{
+		Sessions: spaceactivity.SessionsToProto(sessions),
+	}
+}
+
+func (mw *Middleware) SpaceActivitySubscribe(cctx context.Context, req *pb.RpcSpaceActivitySubscribeRequest) *pb.RpcSpaceActivitySubscribeResponse {
+	filter := activityFilter(req.SpaceId, req.ParticipantIds, req.ObjectTypeIds, req.DateFrom, req.DateTo, req.Limit)
+	subId, sessions, err := getService[spaceactivity.Service](mw).Subscribe(mw.newContext(cctx), req.SubId, filter)
+	if err != nil {
+		code := mapErrorCode(err,
+			errToCode(space.ErrSpaceNotExists, pb.RpcSpaceActivitySubscribeResponseError_NO_SUCH_SPACE),
+		)
+		return &pb.RpcSpaceActivitySubscribeResponse{
+			Error: &pb.RpcSpaceActivitySubscribeResponseError{
+				Code:        code,
+				Description: getErrorDescription(err),
+			},
+		}
+	}
+	return &pb.RpcSpaceActivitySubscribeResponse{
+		SubId:    subId,
+		Sessions: spaceactivity.SessionsToProto(sessions),
+	}
+}
+
+func (mw *Middleware) SpaceActivityUnsubscribe(_ context.Context, req *pb.RpcSpaceActivityUnsubscribeRequest) *pb.RpcSpaceActivityUnsubscribeResponse {
+	err := getService[spaceactivity.Service](mw).Unsubscribe(req.SubId)
+	code := mapErrorCode(err,
+		errToCode(spaceactivity.ErrSubscriptionNotFound, pb.RpcSpaceActivityUnsubscribeResponseError_BAD_INPUT),
+	)
+	return &pb.RpcSpaceActivityUnsubscribeResponse{
+		Error: &pb.RpcSpaceActivityUnsubscribeResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+}
+
+func activityFilter(spaceId string, participantIds, objectTypeIds []string, dateFrom, dateTo int64, limit int32) spaceactivity.Filter {
+	return spaceactivity.Filter{
+		SpaceId:        spaceId,
+		ParticipantIds: participantIds,
+		ObjectTypeIds:  objectTypeIds,
+		DateFrom:       dateFrom,
+		DateTo:         dateTo,
+		Limit:          int(limit),
+	}
+}
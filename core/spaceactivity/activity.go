@@ -0,0 +1,366 @@
+package spaceactivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/anyproto/any-sync/commonspace/objecttreebuilder"
+	"github.com/anyproto/any-sync/util/periodicsync"
+	"github.com/globalsign/mgo/bson"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/block/source"
+	"github.com/anyproto/anytype-heart/core/domain"
+	"github.com/anyproto/anytype-heart/core/event"
+	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/space"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+const CName = "core.spaceactivity"
+
+var log = logging.Logger(CName).Desugar()
+
+const (
+	// sessionGap is the maximum pause between changes of the same session, the same as for history versions
+	sessionGap = int64(5 * 60)
+
+	defaultLimit        = 100
+	updateIntervalSecs  = 5
+	updateTimeout       = time.Minute
+	maxObjectsPerUpdate = 100
+	// maxObjectsPerList limits the number of history trees built for a single feed request
+	maxObjectsPerList = 500
+)
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+type Filter struct {
+	SpaceId        string
+	ParticipantIds []string
+	ObjectTypeIds  []string
+	// DateFrom and DateTo limit the feed by the date of changes, zero values mean no limit
+	DateFrom int64
+	DateTo   int64
+	Limit    int
+}
+
+// Service builds the activity feed of a space from tree changes of its objects
+type Service interface {
+	app.ComponentRunnable
+
+	List(ctx context.Context, filter Filter) ([]Session, error)
+	// Subscribe returns the current feed and sends SpaceActivityUpdate events with new or grown sessions to the session of the context.
+	// Subscriptions are removed when their session is closed
+	Subscribe(ctx session.Context, subId string, filter Filter) (string, []Session, error)
+	Unsubscribe(subId string) error
+	CloseSession(token string)
+}
+
+type subscription struct {
+	id           string
+	sessionToken string
+	filter       Filter
+	lastCheck    int64
+}
+
+type service struct {
+	objectStore  objectstore.ObjectStore
+	spaceService space.Service
+	eventSender  event.Sender
+	periodic     periodicsync.PeriodicSync
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+
+	now func() time.Time
+}
+
+func New() Service {
+	return &service{
+		subscriptions: map[string]*subscription{},
+		now:           time.Now,
+	}
+}
+
+func (s *service) Init(a *app.App) error {
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	s.spaceService = app.MustComponent[space.Service](a)
+	s.eventSender = app.MustComponent[event.Sender](a)
+	s.periodic = periodicsync.NewPeriodicSync(updateIntervalSecs, updateTimeout, s.sendUpdates, logger.CtxLogger{Logger: log})
+	return nil
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(_ context.Context) error {
+	s.periodic.Run()
+	return nil
+}
+
+func (s *service) Close(_ context.Context) error {
+	if s.periodic != nil {
+		s.periodic.Close()
+	}
+	return nil
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]Session, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	records, err := s.queryObjects(filter, filter.DateFrom)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > maxObjectsPerList {
+		records = records[:maxObjectsPerList]
+	}
+	var sessions []Session
+	for _, rec := range records {
+		// objects are sorted by the last modification, so the rest of them can't have more recent sessions
+		if len(sessions) >= filter.Limit &&
+			pbtypes.GetInt64(rec.Details, bundle.RelationKeyLastModifiedDate.String()) < sessions[filter.Limit-1].EndDate {
+			break
+		}
+		objectSessions, err := s.objectSessions(ctx, filter, pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()))
+		if err != nil {
+			log.Warn("build activity sessions", zap.String("objectId", pbtypes.GetString(rec.Details, bundle.RelationKeyId.String())), zap.Error(err))
+			continue
+		}
+		sessions = mergeSessions(sessions, objectSessions)
+	}
+	if len(sessions) > filter.Limit {
+		sessions = sessions[:filter.Limit]
+	}
+	return sessions, nil
+}
+
+func (s *service) Subscribe(ctx session.Context, subId string, filter Filter) (string, []Session, error) {
+	if subId == "" {
+		subId = bson.NewObjectId().Hex()
+	}
+	lastCheck := s.now().Unix()
+	sessions, err := s.List(session.ParentContext(ctx), filter)
+	if err != nil {
+		return "", nil, err
+	}
+	s.mu.Lock()
+	s.subscriptions[subId] = &subscription{id: subId, sessionToken: ctx.ID(), filter: filter, lastCheck: lastCheck}
+	s.mu.Unlock()
+	return subId, sessions, nil
+}
+
+func (s *service) Unsubscribe(subId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[subId]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subscriptions, subId)
+	return nil
+}
+
+func (s *service) CloseSession(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sub := range s.subscriptions {
+		if sub.sessionToken == token {
+			delete(s.subscriptions, id)
+		}
+	}
+}
+
+func (s *service) sendUpdates(ctx context.Context) error {
+	s.mu.Lock()
+	subs := lo.Values(s.subscriptions)
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		now := s.now().Unix()
+		sessions, err := s.changedSessions(ctx, sub)
+		if err != nil {
+			log.Warn("update activity subscription", zap.String("subId", sub.id), zap.Error(err))
+			continue
+		}
+		s.mu.Lock()
+		if _, ok := s.subscriptions[sub.id]; !ok {
+			s.mu.Unlock()
+			continue
+		}
+		sub.lastCheck = now
+		s.mu.Unlock()
+		if len(sessions) == 0 {
+			continue
+		}
+		s.eventSender.SendToSession(sub.sessionToken, &pb.Event{
+			Messages: []*pb.EventMessage{
+				{
+					Value: &pb.EventMessageValueOfSpaceActivityUpdate{
+						SpaceActivityUpdate: &pb.EventSpaceActivityUpdate{
+							SubId:    sub.id,
+							Sessions: SessionsToProto(sessions),
+						},
+					},
+				},
+			},
+		})
+	}
+	return nil
+}
+
+// changedSessions returns sessions of objects modified since the last check
+func (s *service) changedSessions(ctx context.Context, sub *subscription) ([]Session, error) {
+	if sub.filter.DateTo > 0 && sub.filter.DateTo < sub.lastCheck {
+		return nil, nil
+	}
+	// lastModifiedDate has seconds precision, so objects modified during the second of the last check are checked again
+	records, err := s.queryObjects(sub.filter, sub.lastCheck)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > maxObjectsPerUpdate {
+		records = records[:maxObjectsPerUpdate]
+	}
+	var sessions []Session
+	for _, rec := range records {
+		objectSessions, err := s.objectSessions(ctx, sub.filter, pbtypes.GetString(rec.Details, bundle.RelationKeyId.String()))
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range objectSessions {
+			if session.EndDate >= sub.lastCheck {
+				sessions = append(sessions, session)
+			}
+		}
+	}
+	return sessions, nil
+}
+
+func (s *service) queryObjects(filter Filter, modifiedSince int64) ([]database.Record, error) {
+	filters := []*model.BlockContentDataviewFilter{
+		{
+			RelationKey: bundle.RelationKeySpaceId.String(),
+			Condition:   model.BlockContentDataviewFilter_Equal,
+			Value:       pbtypes.String(filter.SpaceId),
+		},
+		{
+			RelationKey: bundle.RelationKeyLastModifiedDate.String(),
+			Condition:   model.BlockContentDataviewFilter_NotEmpty,
+		},
+	}
+	if modifiedSince > 0 {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeyLastModifiedDate.String(),
+			Condition:   model.BlockContentDataviewFilter_GreaterOrEqual,
+			Value:       pbtypes.Int64(modifiedSince),
+		})
+	}
+	if len(filter.ObjectTypeIds) > 0 {
+		filters = append(filters, &model.BlockContentDataviewFilter{
+			RelationKey: bundle.RelationKeyType.String(),
+			Condition:   model.BlockContentDataviewFilter_In,
+			Value:       pbtypes.StringList(filter.ObjectTypeIds),
+		})
+	}
+	records, err := s.objectStore.Query(database.Query{
+		Filters: filters,
+		Sorts: []*model.BlockContentDataviewSort{
+			{
+				RelationKey: bundle.RelationKeyLastModifiedDate.String(),
+				Type:        model.BlockContentDataviewSort_Desc,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query modified objects: %w", err)
+	}
+	return records, nil
+}
+
+func (s *service) objectSessions(ctx context.Context, filter Filter, objectId string) ([]Session, error) {
+	spc, err := s.spaceService.Get(ctx, filter.SpaceId)
+	if err != nil {
+		return nil, fmt.Errorf("get space: %w", err)
+	}
+	tree, err := spc.TreeBuilder().BuildHistoryTree(ctx, objectId, objecttreebuilder.HistoryTreeOpts{BuildFullTree: true})
+	if err != nil {
+		return nil, fmt.Errorf("build history tree: %w", err)
+	}
+
+	var changes []*change
+	err = tree.IterateFrom(tree.Root().Id, source.UnmarshalChange, func(c *objecttree.Change) (isContinue bool) {
+		if c.Id == tree.Id() {
+			return true
+		}
+		if filter.DateFrom > 0 && c.Timestamp < filter.DateFrom || filter.DateTo > 0 && c.Timestamp > filter.DateTo {
+			return true
+		}
+		participantId := domain.NewParticipantId(filter.SpaceId, c.Identity.Account())
+		if len(filter.ParticipantIds) > 0 && !lo.Contains(filter.ParticipantIds, participantId) {
+			return true
+		}
+		ch := &change{
+			id:            c.Id,
+			objectId:      objectId,
+			participantId: participantId,
+			timestamp:     c.Timestamp,
+			created:       len(c.PreviousIds) == 1 && c.PreviousIds[0] == tree.Id(),
+		}
+		if pbChange, ok := c.Model.(*pb.Change); ok {
+			ch.fillFromContent(pbChange.Content)
+		}
+		changes = append(changes, ch)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate changes: %w", err)
+	}
+	return groupSessions(changes, sessionGap), nil
+}
+
+// mergeSessions merges two lists sorted from the most recent session
+func mergeSessions(a, b []Session) []Session {
+	res := append(a, b...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].EndDate > res[j].EndDate
+	})
+	return res
+}
+
+func SessionsToProto(sessions []Session) []*pb.EventSpaceActivitySession {
+	res := make([]*pb.EventSpaceActivitySession, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, &pb.EventSpaceActivitySession{
+			Id:            session.Id(),
+			ObjectId:      session.ObjectId,
+			ParticipantId: session.ParticipantId,
+			StartDate:     session.StartDate,
+			EndDate:       session.EndDate,
+			ChangesCount:  int32(session.ChangesCount),
+			Created:       session.Created,
+			BlocksAdded:   int32(session.BlocksAdded),
+			BlocksChanged: int32(session.BlocksChanged),
+			BlocksRemoved: int32(session.BlocksRemoved),
+			DetailKeys:    session.DetailKeys,
+			LastChangeId:  session.LastChangeId,
+		})
+	}
+	return res
+}
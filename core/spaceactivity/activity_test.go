@@ -0,0 +1,24 @@
+package spaceactivity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CloseSession(t *testing.T) {
+	t.Run("subscriptions of closed session are removed", func(t *testing.T) {
+		// given
+		s := New().(*service)
+		s.subscriptions["sub1"] = &subscription{id: "sub1", sessionToken: "token1"}
+		s.subscriptions["sub2"] = &subscription{id: "sub2", sessionToken: "token2"}
+
+		// when
+		s.CloseSession("token1")
+
+		// then
+		assert.Len(t, s.subscriptions, 1)
+		assert.Contains(t, s.subscriptions, "sub2")
+		assert.ErrorIs(t, s.Unsubscribe("sub1"), ErrSubscriptionNotFound)
+	})
+}
@@ -0,0 +1,241 @@
+package spaceactivity
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+)
+
+// change is a digest of a single tree change of an object
+type change struct {
+	id            string
+	objectId      string
+	participantId string
+	timestamp     int64
+	created       bool
+
+	blocksAdded   []string
+	blocksChanged []string
+	blocksRemoved []string
+	detailKeys    []string
+	storeChanged  bool
+}
+
+func (c *change) isEmpty() bool {
+	return !c.created && len(c.blocksAdded) == 0 && len(c.blocksChanged) == 0 && len(c.blocksRemoved) == 0 &&
+		len(c.detailKeys) == 0 && !c.storeChanged
+}
+
+// fillFromContent collects affected blocks and details from the change content.
+// Service contents like file keys, notifications or devices are not considered as an activity
+func (c *change) fillFromContent(contents []*pb.ChangeContent) {
+	for _, content := range contents {
+		switch {
+		case content.GetBlockCreate() != nil:
+			for _, b := range content.GetBlockCreate().Blocks {
+				c.blocksAdded = append(c.blocksAdded, b.Id)
+			}
+		case content.GetBlockDuplicate() != nil:
+			c.blocksAdded = append(c.blocksAdded, content.GetBlockDuplicate().Ids...)
+		case content.GetBlockRemove() != nil:
+			c.blocksRemoved = append(c.blocksRemoved, content.GetBlockRemove().Ids...)
+		case content.GetBlockMove() != nil:
+			c.blocksChanged = append(c.blocksChanged, content.GetBlockMove().Ids...)
+		case content.GetBlockUpdate() != nil:
+			for _, event := range content.GetBlockUpdate().Events {
+				if id := changedBlockId(event); id != "" {
+					c.blocksChanged = append(c.blocksChanged, id)
+				}
+			}
+		case content.GetDetailsSet() != nil:
+			c.detailKeys = append(c.detailKeys, content.GetDetailsSet().Key)
+		case content.GetDetailsUnset() != nil:
+			c.detailKeys = append(c.detailKeys, content.GetDetailsUnset().Key)
+		case content.GetRelationAdd() != nil:
+			for _, link := range content.GetRelationAdd().RelationLinks {
+				c.detailKeys = append(c.detailKeys, link.Key)
+			}
+		case content.GetRelationRemove() != nil:
+			c.detailKeys = append(c.detailKeys, content.GetRelationRemove().RelationKey...)
+		case content.GetObjectTypeAdd() != nil, content.GetObjectTypeRemove() != nil:
+			c.detailKeys = append(c.detailKeys, bundle.RelationKeyType.String())
+		case content.GetStoreKeySet() != nil, content.GetStoreKeyUnset() != nil, content.GetStoreSliceUpdate() != nil:
+			c.storeChanged = true
+		}
+	}
+}
+
+func changedBlockId(event *pb.EventMessage) string {
+	switch v := event.Value.(type) {
+	case *pb.EventMessageValueOfBlockSetText:
+		return v.BlockSetText.Id
+	case *pb.EventMessageValueOfBlockSetFields:
+		return v.BlockSetFields.Id
+	case *pb.EventMessageValueOfBlockSetBackgroundColor:
+		return v.BlockSetBackgroundColor.Id
+	case *pb.EventMessageValueOfBlockSetAlign:
+		return v.BlockSetAlign.Id
+	case *pb.EventMessageValueOfBlockSetVerticalAlign:
+		return v.BlockSetVerticalAlign.Id
+	case *pb.EventMessageValueOfBlockSetFile:
+		return v.BlockSetFile.Id
+	case *pb.EventMessageValueOfBlockSetLink:
+		return v.BlockSetLink.Id
+	case *pb.EventMessageValueOfBlockSetBookmark:
+		return v.BlockSetBookmark.Id
+	case *pb.EventMessageValueOfBlockSetDiv:
+		return v.BlockSetDiv.Id
+	case *pb.EventMessageValueOfBlockSetLatex:
+		return v.BlockSetLatex.Id
+	case *pb.EventMessageValueOfBlockSetRelation:
+		return v.BlockSetRelation.Id
+	case *pb.EventMessageValueOfBlockSetTableRow:
+		return v.BlockSetTableRow.Id
+	case *pb.EventMessageValueOfBlockSetWidget:
+		return v.BlockSetWidget.Id
+	case *pb.EventMessageValueOfBlockSetChildrenIds:
+		return v.BlockSetChildrenIds.Id
+	case *pb.EventMessageValueOfBlockDataviewViewSet:
+		return v.BlockDataviewViewSet.Id
+	case *pb.EventMessageValueOfBlockDataviewViewUpdate:
+		return v.BlockDataviewViewUpdate.Id
+	case *pb.EventMessageValueOfBlockDataviewViewDelete:
+		return v.BlockDataviewViewDelete.Id
+	case *pb.EventMessageValueOfBlockDataviewViewOrder:
+		return v.BlockDataviewViewOrder.Id
+	case *pb.EventMessageValueOfBlockDataviewRelationSet:
+		return v.BlockDataviewRelationSet.Id
+	case *pb.EventMessageValueOfBlockDataviewRelationDelete:
+		return v.BlockDataviewRelationDelete.Id
+	case *pb.EventMessageValueOfBlockDataViewObjectOrderUpdate:
+		return v.BlockDataViewObjectOrderUpdate.Id
+	case *pb.EventMessageValueOfBlockDataViewGroupOrderUpdate:
+		return v.BlockDataViewGroupOrderUpdate.Id
+	}
+	return ""
+}
+
+// Session is a group of consecutive changes made by one participant in one object
+type Session struct {
+	ObjectId      string
+	ParticipantId string
+	StartDate     int64
+	EndDate       int64
+	ChangesCount  int
+	// Created is true when the object was created during the session
+	Created       bool
+	BlocksAdded   int
+	BlocksChanged int
+	BlocksRemoved int
+	// DetailKeys are keys of relations changed during the session
+	DetailKeys   []string
+	LastChangeId string
+}
+
+// Id identifies the session, it stays the same when the session grows with new changes
+func (s Session) Id() string {
+	return fmt.Sprintf("%s/%s/%d", s.ObjectId, s.ParticipantId, s.StartDate)
+}
+
+type sessionBuilder struct {
+	Session
+	added, changed, removed, details map[string]struct{}
+}
+
+func newSessionBuilder(c *change) *sessionBuilder {
+	return &sessionBuilder{
+		Session: Session{
+			ObjectId:      c.objectId,
+			ParticipantId: c.participantId,
+			StartDate:     c.timestamp,
+		},
+		added:   map[string]struct{}{},
+		changed: map[string]struct{}{},
+		removed: map[string]struct{}{},
+		details: map[string]struct{}{},
+	}
+}
+
+func (b *sessionBuilder) add(c *change) {
+	b.EndDate = c.timestamp
+	b.LastChangeId = c.id
+	b.ChangesCount++
+	b.Created = b.Created || c.created
+	for _, id := range c.blocksAdded {
+		b.added[id] = struct{}{}
+	}
+	for _, id := range c.blocksChanged {
+		if _, ok := b.added[id]; !ok {
+			b.changed[id] = struct{}{}
+		}
+	}
+	for _, id := range c.blocksRemoved {
+		if _, ok := b.added[id]; ok {
+			// block was added and removed during the same session
+			delete(b.added, id)
+			continue
+		}
+		delete(b.changed, id)
+		b.removed[id] = struct{}{}
+	}
+	for _, key := range c.detailKeys {
+		b.details[key] = struct{}{}
+	}
+}
+
+func (b *sessionBuilder) build() Session {
+	s := b.Session
+	s.BlocksAdded = len(b.added)
+	s.BlocksChanged = len(b.changed)
+	s.BlocksRemoved = len(b.removed)
+	s.DetailKeys = make([]string, 0, len(b.details))
+	for key := range b.details {
+		s.DetailKeys = append(s.DetailKeys, key)
+	}
+	sort.Strings(s.DetailKeys)
+	return s
+}
+
+// groupSessions groups changes by object and participant. Changes of the same participant
+// go to the same session unless the pause between them is longer than gap.
+// Sessions are sorted from the most recent to the oldest one
+func groupSessions(changes []*change, gap int64) []Session {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].timestamp < changes[j].timestamp
+	})
+	type key struct {
+		objectId, participantId string
+	}
+	var (
+		current  = map[key]*sessionBuilder{}
+		sessions []Session
+	)
+	for _, c := range changes {
+		if c.isEmpty() {
+			continue
+		}
+		k := key{objectId: c.objectId, participantId: c.participantId}
+		b, ok := current[k]
+		if ok && c.timestamp-b.EndDate > gap {
+			sessions = append(sessions, b.build())
+			ok = false
+		}
+		if !ok {
+			b = newSessionBuilder(c)
+			current[k] = b
+		}
+		b.add(c)
+	}
+	for _, b := range current {
+		sessions = append(sessions, b.build())
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].EndDate == sessions[j].EndDate {
+			return sessions[i].Id() < sessions[j].Id()
+		}
+		return sessions[i].EndDate > sessions[j].EndDate
+	})
+	return sessions
+}
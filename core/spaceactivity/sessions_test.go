@@ -0,0 +1,120 @@
+package spaceactivity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func givenChange(id, objectId, participantId string, timestamp int64, contents ...*pb.ChangeContent) *change {
+	c := &change{id: id, objectId: objectId, participantId: participantId, timestamp: timestamp}
+	c.fillFromContent(contents)
+	return c
+}
+
+func blockCreate(ids ...string) *pb.ChangeContent {
+	blocks := make([]*model.Block, 0, len(ids))
+	for _, id := range ids {
+		blocks = append(blocks, &model.Block{Id: id})
+	}
+	return &pb.ChangeContent{Value: &pb.ChangeContentValueOfBlockCreate{BlockCreate: &pb.ChangeBlockCreate{Blocks: blocks}}}
+}
+
+func blockRemove(ids ...string) *pb.ChangeContent {
+	return &pb.ChangeContent{Value: &pb.ChangeContentValueOfBlockRemove{BlockRemove: &pb.ChangeBlockRemove{Ids: ids}}}
+}
+
+func textSet(id string) *pb.ChangeContent {
+	return &pb.ChangeContent{Value: &pb.ChangeContentValueOfBlockUpdate{BlockUpdate: &pb.ChangeBlockUpdate{
+		Events: []*pb.EventMessage{{Value: &pb.EventMessageValueOfBlockSetText{BlockSetText: &pb.EventBlockSetText{Id: id}}}},
+	}}}
+}
+
+func detailsSet(key string) *pb.ChangeContent {
+	return &pb.ChangeContent{Value: &pb.ChangeContentValueOfDetailsSet{DetailsSet: &pb.ChangeDetailsSet{Key: key}}}
+}
+
+func TestGroupSessions(t *testing.T) {
+	t.Run("changes of one participant are grouped", func(t *testing.T) {
+		// given
+		changes := []*change{
+			givenChange("c1", "obj1", "p1", 100, blockCreate("b1", "b2")),
+			givenChange("c2", "obj1", "p1", 150, textSet("b1"), textSet("b3")),
+			givenChange("c3", "obj1", "p1", 200, blockRemove("b2", "b4"), detailsSet(bundle.RelationKeyName.String())),
+		}
+
+		// when
+		sessions := groupSessions(changes, sessionGap)
+
+		// then
+		require.Len(t, sessions, 1)
+		assert.Equal(t, Session{
+			ObjectId:      "obj1",
+			ParticipantId: "p1",
+			StartDate:     100,
+			EndDate:       200,
+			ChangesCount:  3,
+			BlocksAdded:   1,
+			BlocksChanged: 1,
+			BlocksRemoved: 1,
+			DetailKeys:    []string{bundle.RelationKeyName.String()},
+			LastChangeId:  "c3",
+		}, sessions[0])
+		assert.Equal(t, "obj1/p1/100", sessions[0].Id())
+	})
+
+	t.Run("long pause starts a new session", func(t *testing.T) {
+		// given
+		changes := []*change{
+			givenChange("c2", "obj1", "p1", 100+sessionGap+1, textSet("b1")),
+			givenChange("c1", "obj1", "p1", 100, textSet("b1")),
+		}
+
+		// when
+		sessions := groupSessions(changes, sessionGap)
+
+		// then
+		require.Len(t, sessions, 2)
+		assert.Equal(t, "c2", sessions[0].LastChangeId)
+		assert.Equal(t, "c1", sessions[1].LastChangeId)
+	})
+
+	t.Run("participants and objects have separate sessions", func(t *testing.T) {
+		// given
+		changes := []*change{
+			givenChange("c1", "obj1", "p1", 100, textSet("b1")),
+			givenChange("c2", "obj1", "p2", 110, textSet("b1")),
+			givenChange("c3", "obj2", "p1", 120, textSet("b1")),
+			givenChange("c4", "obj1", "p1", 130, textSet("b1")),
+		}
+
+		// when
+		sessions := groupSessions(changes, sessionGap)
+
+		// then
+		require.Len(t, sessions, 3)
+		assert.Equal(t, []string{"obj1/p1/100", "obj2/p1/120", "obj1/p2/110"}, []string{sessions[0].Id(), sessions[1].Id(), sessions[2].Id()})
+		assert.Equal(t, 2, sessions[0].ChangesCount)
+	})
+
+	t.Run("empty changes are skipped", func(t *testing.T) {
+		// given
+		changes := []*change{
+			givenChange("c1", "obj1", "p1", 100),
+			{id: "c2", objectId: "obj2", participantId: "p1", timestamp: 100, created: true},
+		}
+
+		// when
+		sessions := groupSessions(changes, sessionGap)
+
+		// then
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "obj2", sessions[0].ObjectId)
+		assert.True(t, sessions[0].Created)
+	})
+}
@@ -37,9 +37,10 @@ type opCounter struct {
 }
 
 type opGroup struct {
-	subId  string
-	group  *model.BlockContentDataviewGroup
-	remove bool
+	subId    string
+	group    *model.BlockContentDataviewGroup
+	remove   bool
+	subGroup bool
 }
 
 type opCtx struct {
@@ -136,9 +137,10 @@ func (ctx *opCtx) apply() (event *pb.Event) {
 		subMsgs = append(subMsgs, &pb.EventMessage{
 			Value: &pb.EventMessageValueOfSubscriptionGroups{
 				SubscriptionGroups: &pb.EventObjectSubscriptionGroups{
-					SubId:  opGroup.subId,
-					Group:  opGroup.group,
-					Remove: opGroup.remove,
+					SubId:    opGroup.subId,
+					Group:    opGroup.group,
+					Remove:   opGroup.remove,
+					SubGroup: opGroup.subGroup,
 				},
 			},
 		})
@@ -10,21 +10,32 @@ import (
 	"github.com/anyproto/anytype-heart/util/slice"
 )
 
-func (s *service) newGroupSub(id string, relKey string, f *database.Filters, groups []*model.BlockContentDataviewGroup) *groupSub {
+func (s *service) newGroupSub(id string, relKey string, f *database.Filters, groups []*model.BlockContentDataviewGroup, grouper kanban.RecordGrouper) *groupSub {
 	sub := &groupSub{
-		id:     id,
-		relKey: relKey,
-		cache:  s.cache,
-		set:    make(map[string]struct{}),
-		filter: f,
-		groups: groups,
+		id:      id,
+		relKey:  relKey,
+		cache:   s.cache,
+		set:     make(map[string]struct{}),
+		filter:  f,
+		groups:  groups,
+		grouper: grouper,
 	}
 	return sub
 }
 
+// newSubGroupSub creates subscription for the second level of groups. It is registered in cache under its own id,
+// but group changes are reported with id of the parent subscription
+func (s *service) newSubGroupSub(parentId string, relKey string, f *database.Filters, groups []*model.BlockContentDataviewGroup, grouper kanban.RecordGrouper) *groupSub {
+	sub := s.newGroupSub(parentId+"/subGroups", relKey, f, groups, grouper)
+	sub.parentId = parentId
+	return sub
+}
+
 type groupSub struct {
 	id     string
 	relKey string
+	// parentId is set for the second level of groups
+	parentId string
 
 	cache *cache
 
@@ -32,7 +43,8 @@ type groupSub struct {
 
 	filter *database.Filters
 
-	groups []*model.BlockContentDataviewGroup
+	groups  []*model.BlockContentDataviewGroup
+	grouper kanban.RecordGrouper
 }
 
 func (gs *groupSub) init(entries []*entry) (err error) {
@@ -55,9 +67,9 @@ func (gs *groupSub) onChange(ctx *opCtx) {
 		if _, inSet := gs.set[ctxEntry.id]; inSet {
 			cacheEntry := gs.cache.Get(ctxEntry.id)
 			if !checkGroups && cacheEntry != nil {
-				oldList := pbtypes.GetStringList(cacheEntry.data, gs.relKey)
-				newList := pbtypes.GetStringList(ctxEntry.data, gs.relKey)
-				checkGroups = !slice.UnsortedEqual(oldList, newList)
+				oldValue := pbtypes.Get(cacheEntry.data, gs.relKey)
+				newValue := pbtypes.Get(ctxEntry.data, gs.relKey)
+				checkGroups = !oldValue.Equal(newValue)
 			}
 			if !inFilter {
 				gs.cache.RemoveSubId(ctxEntry.id, gs.id)
@@ -82,9 +94,9 @@ func (gs *groupSub) onChange(ctx *opCtx) {
 			}
 		}
 
-		tag := kanban.GroupTag{Key: gs.relKey, Records: records}
+		gs.grouper.SetRecords(records)
 
-		newGroups, err := tag.MakeDataViewGroups()
+		newGroups, err := gs.grouper.MakeDataViewGroups()
 		if err != nil {
 			log.Errorf("fail to make groups for kanban: %s", err)
 		}
@@ -98,7 +110,7 @@ func (gs *groupSub) onChange(ctx *opCtx) {
 			for _, removedGroup := range removedIds {
 				for _, g := range gs.groups {
 					if removedGroup == g.Id {
-						ctx.groups = append(ctx.groups, opGroup{subId: gs.id, group: g, remove: true})
+						ctx.groups = append(ctx.groups, gs.makeOpGroup(g, true))
 					}
 				}
 			}
@@ -106,7 +118,7 @@ func (gs *groupSub) onChange(ctx *opCtx) {
 			for _, addGroupId := range addedIds {
 				for _, g := range newGroups {
 					if addGroupId == g.Id {
-						ctx.groups = append(ctx.groups, opGroup{subId: gs.id, group: g})
+						ctx.groups = append(ctx.groups, gs.makeOpGroup(g, false))
 					}
 				}
 			}
@@ -115,6 +127,13 @@ func (gs *groupSub) onChange(ctx *opCtx) {
 	}
 }
 
+func (gs *groupSub) makeOpGroup(group *model.BlockContentDataviewGroup, remove bool) opGroup {
+	if gs.parentId != "" {
+		return opGroup{subId: gs.parentId, group: group, remove: remove, subGroup: true}
+	}
+	return opGroup{subId: gs.id, group: group, remove: remove}
+}
+
 func (gs *groupSub) getActiveRecords() (res []*types.Struct) {
 	return
 }
@@ -129,3 +148,42 @@ func (gs *groupSub) close() {
 	}
 	return
 }
+
+// subGroupedSub tracks both levels of groups of a view grouped by two relations,
+// e.g. columns and swimlanes of a kanban board
+type subGroupedSub struct {
+	groupSubs []*groupSub
+}
+
+func (s *subGroupedSub) init(entries []*entry) (err error) {
+	for _, gs := range s.groupSubs {
+		if err = gs.init(entries); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (s *subGroupedSub) counters() (prev, next int) {
+	return 0, 0
+}
+
+func (s *subGroupedSub) onChange(ctx *opCtx) {
+	for _, gs := range s.groupSubs {
+		gs.onChange(ctx)
+	}
+}
+
+func (s *subGroupedSub) getActiveRecords() (res []*types.Struct) {
+	return
+}
+
+func (s *subGroupedSub) hasDep() bool {
+	return false
+}
+
+func (s *subGroupedSub) close() {
+	for _, gs := range s.groupSubs {
+		gs.close()
+	}
+}
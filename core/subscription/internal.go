@@ -0,0 +1,79 @@
+package subscription
+
+import (
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+// dispatchInternal passes messages of internal subscriptions to their handlers and returns the event for clients
+func (s *service) dispatchInternal(event *pb.Event) *pb.Event {
+	if len(s.internalHandlers) == 0 {
+		return event
+	}
+	var (
+		internalMsgs = map[string][]*pb.EventMessage{}
+		clientMsgs   = make([]*pb.EventMessage, 0, len(event.Messages))
+	)
+	for _, msg := range event.Messages {
+		subIds := messageSubIds(msg)
+		clientSubIds := make([]string, 0, len(subIds))
+		for _, subId := range subIds {
+			if _, ok := s.internalHandlers[subId]; ok {
+				internalMsgs[subId] = append(internalMsgs[subId], msg)
+			} else {
+				clientSubIds = append(clientSubIds, subId)
+			}
+		}
+		switch {
+		case len(clientSubIds) == len(subIds):
+			clientMsgs = append(clientMsgs, msg)
+		case len(clientSubIds) > 0:
+			clientMsgs = append(clientMsgs, withSubIds(msg, clientSubIds))
+		}
+	}
+	for subId, msgs := range internalMsgs {
+		s.internalHandlers[subId](msgs)
+	}
+	return &pb.Event{Messages: clientMsgs}
+}
+
+func messageSubIds(msg *pb.EventMessage) []string {
+	switch v := msg.Value.(type) {
+	case *pb.EventMessageValueOfObjectDetailsSet:
+		return v.ObjectDetailsSet.SubIds
+	case *pb.EventMessageValueOfObjectDetailsAmend:
+		return v.ObjectDetailsAmend.SubIds
+	case *pb.EventMessageValueOfObjectDetailsUnset:
+		return v.ObjectDetailsUnset.SubIds
+	case *pb.EventMessageValueOfSubscriptionAdd:
+		return []string{v.SubscriptionAdd.SubId}
+	case *pb.EventMessageValueOfSubscriptionRemove:
+		return []string{v.SubscriptionRemove.SubId}
+	case *pb.EventMessageValueOfSubscriptionPosition:
+		return []string{v.SubscriptionPosition.SubId}
+	case *pb.EventMessageValueOfSubscriptionCounters:
+		return []string{v.SubscriptionCounters.SubId}
+	case *pb.EventMessageValueOfSubscriptionGroups:
+		return []string{v.SubscriptionGroups.SubId}
+	}
+	return nil
+}
+
+// withSubIds copies the details message with the given subscription ids, messages are shared between handlers,
+// so they are not modified in place
+func withSubIds(msg *pb.EventMessage, subIds []string) *pb.EventMessage {
+	switch v := msg.Value.(type) {
+	case *pb.EventMessageValueOfObjectDetailsSet:
+		set := *v.ObjectDetailsSet
+		set.SubIds = subIds
+		return &pb.EventMessage{Value: &pb.EventMessageValueOfObjectDetailsSet{ObjectDetailsSet: &set}}
+	case *pb.EventMessageValueOfObjectDetailsAmend:
+		amend := *v.ObjectDetailsAmend
+		amend.SubIds = subIds
+		return &pb.EventMessage{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &amend}}
+	case *pb.EventMessageValueOfObjectDetailsUnset:
+		unset := *v.ObjectDetailsUnset
+		unset.SubIds = subIds
+		return &pb.EventMessage{Value: &pb.EventMessageValueOfObjectDetailsUnset{ObjectDetailsUnset: &unset}}
+	}
+	return msg
+}
@@ -0,0 +1,39 @@
+package subscription
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+func TestService_DispatchInternal(t *testing.T) {
+	// given
+	var received []*pb.EventMessage
+	s := &service{internalHandlers: map[string]InternalHandler{
+		"internal": func(msgs []*pb.EventMessage) {
+			received = append(received, msgs...)
+		},
+	}}
+	shared := &pb.EventMessage{Value: &pb.EventMessageValueOfObjectDetailsSet{
+		ObjectDetailsSet: &pb.EventObjectDetailsSet{Id: "1", SubIds: []string{"client", "internal"}},
+	}}
+	internalAdd := &pb.EventMessage{Value: &pb.EventMessageValueOfSubscriptionAdd{
+		SubscriptionAdd: &pb.EventObjectSubscriptionAdd{Id: "1", SubId: "internal"},
+	}}
+	clientAdd := &pb.EventMessage{Value: &pb.EventMessageValueOfSubscriptionAdd{
+		SubscriptionAdd: &pb.EventObjectSubscriptionAdd{Id: "1", SubId: "client"},
+	}}
+
+	// when
+	event := s.dispatchInternal(&pb.Event{Messages: []*pb.EventMessage{shared, internalAdd, clientAdd}})
+
+	// then
+	assert.Equal(t, []*pb.EventMessage{shared, internalAdd}, received)
+	require.Len(t, event.Messages, 2)
+	assert.Equal(t, []string{"client"}, event.Messages[0].GetObjectDetailsSet().SubIds)
+	assert.Equal(t, clientAdd, event.Messages[1])
+	assert.Equal(t, []string{"client", "internal"}, shared.GetObjectDetailsSet().SubIds)
+}
@@ -13,6 +13,8 @@ import (
 
 	session "github.com/anyproto/anytype-heart/core/session"
 
+	subscription "github.com/anyproto/anytype-heart/core/subscription"
+
 	types "github.com/gogo/protobuf/types"
 )
 
@@ -270,6 +272,65 @@ func (_c *MockService_Search_Call) RunAndReturn(run func(pb.RpcObjectSearchSubsc
 	return _c
 }
 
+// SearchInternal provides a mock function with given fields: req, handler
+func (_m *MockService) SearchInternal(req pb.RpcObjectSearchSubscribeRequest, handler subscription.InternalHandler) (*pb.RpcObjectSearchSubscribeResponse, error) {
+	ret := _m.Called(req, handler)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchInternal")
+	}
+
+	var r0 *pb.RpcObjectSearchSubscribeResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(pb.RpcObjectSearchSubscribeRequest, subscription.InternalHandler) (*pb.RpcObjectSearchSubscribeResponse, error)); ok {
+		return rf(req, handler)
+	}
+	if rf, ok := ret.Get(0).(func(pb.RpcObjectSearchSubscribeRequest, subscription.InternalHandler) *pb.RpcObjectSearchSubscribeResponse); ok {
+		r0 = rf(req, handler)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.RpcObjectSearchSubscribeResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(pb.RpcObjectSearchSubscribeRequest, subscription.InternalHandler) error); ok {
+		r1 = rf(req, handler)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SearchInternal_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchInternal'
+type MockService_SearchInternal_Call struct {
+	*mock.Call
+}
+
+// SearchInternal is a helper method to define mock.On call
+//   - req pb.RpcObjectSearchSubscribeRequest
+//   - handler subscription.InternalHandler
+func (_e *MockService_Expecter) SearchInternal(req interface{}, handler interface{}) *MockService_SearchInternal_Call {
+	return &MockService_SearchInternal_Call{Call: _e.mock.On("SearchInternal", req, handler)}
+}
+
+func (_c *MockService_SearchInternal_Call) Run(run func(req pb.RpcObjectSearchSubscribeRequest, handler subscription.InternalHandler)) *MockService_SearchInternal_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(pb.RpcObjectSearchSubscribeRequest), args[1].(subscription.InternalHandler))
+	})
+	return _c
+}
+
+func (_c *MockService_SearchInternal_Call) Return(_a0 *pb.RpcObjectSearchSubscribeResponse, _a1 error) *MockService_SearchInternal_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SearchInternal_Call) RunAndReturn(run func(pb.RpcObjectSearchSubscribeRequest, subscription.InternalHandler) (*pb.RpcObjectSearchSubscribeResponse, error)) *MockService_SearchInternal_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SubscribeGroups provides a mock function with given fields: ctx, req
 func (_m *MockService) SubscribeGroups(ctx session.Context, req pb.RpcObjectGroupsSubscribeRequest) (*pb.RpcObjectGroupsSubscribeResponse, error) {
 	ret := _m.Called(ctx, req)
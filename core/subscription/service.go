@@ -11,12 +11,14 @@ import (
 	"github.com/globalsign/mgo/bson"
 	"github.com/gogo/protobuf/types"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/anyproto/anytype-heart/core/domain"
 
 	"github.com/anyproto/anytype-heart/core/event"
 	"github.com/anyproto/anytype-heart/core/kanban"
 	"github.com/anyproto/anytype-heart/core/session"
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/pb"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	"github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
@@ -34,12 +36,20 @@ var log = logging.Logger("anytype-mw-subscription")
 
 var batchTime = 50 * time.Millisecond
 
+const (
+	entriesCountKey       = attribute.Key("anytype.subscription.entries")
+	subscriptionsCountKey = attribute.Key("anytype.subscription.count")
+)
+
 func New() Service {
 	return &service{}
 }
 
 type Service interface {
 	Search(req pb.RpcObjectSearchSubscribeRequest) (resp *pb.RpcObjectSearchSubscribeResponse, err error)
+	// SearchInternal subscribes like Search, but events of the subscription are passed to the handler instead of clients.
+	// The handler is called under the service lock, so it must not block or call the service
+	SearchInternal(req pb.RpcObjectSearchSubscribeRequest, handler InternalHandler) (resp *pb.RpcObjectSearchSubscribeResponse, err error)
 	SubscribeIdsReq(req pb.RpcObjectSubscribeIdsRequest) (resp *pb.RpcObjectSubscribeIdsResponse, err error)
 	SubscribeIds(subId string, ids []string) (records []*types.Struct, err error)
 	SubscribeGroups(ctx session.Context, req pb.RpcObjectGroupsSubscribeRequest) (*pb.RpcObjectGroupsSubscribeResponse, error)
@@ -50,6 +60,8 @@ type Service interface {
 	app.ComponentRunnable
 }
 
+type InternalHandler func(msgs []*pb.EventMessage)
+
 type subscription interface {
 	init(entries []*entry) (err error)
 	counters() (prev, next int)
@@ -68,7 +80,9 @@ type service struct {
 	cache         *cache
 	ds            *dependencyService
 	subscriptions map[string]subscription
-	recBatch      *mb.MB
+	// internalHandlers receive events of internal subscriptions by their ids
+	internalHandlers map[string]InternalHandler
+	recBatch         *mb.MB
 
 	objectStore       objectstore.ObjectStore
 	kanban            kanban.Service
@@ -85,6 +99,7 @@ func (s *service) Init(a *app.App) (err error) {
 	s.cache = newCache()
 	s.ds = newDependencyService(s)
 	s.subscriptions = make(map[string]subscription)
+	s.internalHandlers = make(map[string]InternalHandler)
 	s.objectStore = a.MustComponent(objectstore.CName).(objectstore.ObjectStore)
 	s.kanban = a.MustComponent(kanban.CName).(kanban.Service)
 	s.recBatch = mb.New(0)
@@ -152,6 +167,25 @@ func (s *service) Search(req pb.RpcObjectSearchSubscribeRequest) (*pb.RpcObjectS
 	return s.subscribeForQuery(req, f, filterDepIds)
 }
 
+func (s *service) SearchInternal(req pb.RpcObjectSearchSubscribeRequest, handler InternalHandler) (*pb.RpcObjectSearchSubscribeResponse, error) {
+	if req.SubId == "" {
+		req.SubId = bson.NewObjectId().Hex()
+	}
+	// the handler is registered before the subscription, so no changes are sent to clients
+	s.m.Lock()
+	s.internalHandlers[req.SubId] = handler
+	s.m.Unlock()
+
+	resp, err := s.Search(req)
+	if err != nil {
+		s.m.Lock()
+		delete(s.internalHandlers, req.SubId)
+		s.m.Unlock()
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (s *service) subscribeForQuery(req pb.RpcObjectSearchSubscribeRequest, f *database.Filters, filterDepIds []string) (*pb.RpcObjectSearchSubscribeResponse, error) {
 	sub := s.newSortedSub(req.SubId, req.Keys, f.FilterObj, f.Order, int(req.Limit), int(req.Offset))
 	if req.NoDepSubscription {
@@ -314,94 +348,155 @@ func (s *service) SubscribeGroups(ctx session.Context, req pb.RpcObjectGroupsSub
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	q := database.Query{
-		Filters: req.Filters,
-	}
-
-	flt, err := database.NewFilters(q, s.objectStore)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(req.Source) > 0 {
-		sourceFilter, err := s.filtersFromSource(req.Source)
-		if err != nil {
-			return nil, fmt.Errorf("can't make filter from source: %w", err)
-		}
-		flt.FilterObj = database.FiltersAnd{flt.FilterObj, sourceFilter}
-	}
-
-	var colObserver *collectionObserver
+	var (
+		colObserver *collectionObserver
+		stored      bool
+	)
 	if req.CollectionId != "" {
+		var err error
 		colObserver, err = s.newCollectionObserver(req.CollectionId, req.SubId)
 		if err != nil {
 			return nil, err
 		}
-		if flt == nil {
-			flt = &database.Filters{}
-		}
-		if flt.FilterObj == nil {
-			flt.FilterObj = colObserver
-		} else {
-			flt.FilterObj = database.FiltersAnd{colObserver, flt.FilterObj}
-		}
+		// the observer is owned by the subscription only when the subscription is stored
+		defer func() {
+			if !stored {
+				colObserver.close()
+			}
+		}()
 	}
 
-	grouper, err := s.kanban.Grouper(req.SpaceId, req.RelationKey)
+	flt, err := s.makeGroupFilters(req, colObserver)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := grouper.InitGroups(req.SpaceId, flt); err != nil {
-		return nil, err
+	params := kanban.GrouperParams{
+		DateBucket:       req.DateBucket,
+		NumberBoundaries: req.NumberBoundaries,
 	}
-
-	dataViewGroups, err := grouper.MakeDataViewGroups()
+	grouper, dataViewGroups, err := s.makeGroups(req.SpaceId, req.RelationKey, params, flt)
 	if err != nil {
 		return nil, err
 	}
 
-	if tagGrouper, ok := grouper.(*kanban.GroupTag); ok {
-		groups, err := tagGrouper.MakeDataViewGroups()
+	var (
+		subFlt     *database.Filters
+		subGrouper kanban.Grouper
+		subGroups  []*model.BlockContentDataviewGroup
+	)
+	if req.SubGroupRelationKey != "" {
+		// groupers add their own conditions to filters, so every level of groups has its own filters
+		if subFlt, err = s.makeGroupFilters(req, colObserver); err != nil {
+			return nil, err
+		}
+		subParams := kanban.GrouperParams{
+			DateBucket:       req.SubGroupDateBucket,
+			NumberBoundaries: req.SubGroupNumberBoundaries,
+		}
+		subGrouper, subGroups, err = s.makeGroups(req.SpaceId, req.SubGroupRelationKey, subParams, subFlt)
 		if err != nil {
 			return nil, err
 		}
+	}
 
+	recordGrouper, _ := grouper.(kanban.RecordGrouper)
+	subRecordGrouper, _ := subGrouper.(kanban.RecordGrouper)
+	if recordGrouper != nil || subRecordGrouper != nil {
 		subId = req.SubId
 		if subId == "" {
 			subId = bson.NewObjectId().Hex()
 		}
 
-		var sub subscription
-		if colObserver != nil {
-			sub = s.newCollectionGroupSub(subId, req.RelationKey, flt, groups, colObserver)
-		} else {
-			sub = s.newGroupSub(subId, req.RelationKey, flt, groups)
+		var groupSubs []*groupSub
+		if recordGrouper != nil {
+			gs := s.newGroupSub(subId, req.RelationKey, flt, dataViewGroups, recordGrouper)
+			if err := gs.init(recordsToEntries(recordGrouper.GetRecords())); err != nil {
+				return nil, err
+			}
+			groupSubs = append(groupSubs, gs)
 		}
-
-		entries := make([]*entry, 0, len(tagGrouper.Records))
-		for _, r := range tagGrouper.Records {
-			entries = append(entries, &entry{
-				id:   pbtypes.GetString(r.Details, "id"),
-				data: r.Details,
-			})
+		if subRecordGrouper != nil {
+			gs := s.newSubGroupSub(subId, req.SubGroupRelationKey, subFlt, subGroups, subRecordGrouper)
+			if err := gs.init(recordsToEntries(subRecordGrouper.GetRecords())); err != nil {
+				return nil, err
+			}
+			groupSubs = append(groupSubs, gs)
 		}
 
-		if err := sub.init(entries); err != nil {
-			return nil, err
+		var sub subscription = groupSubs[0]
+		if len(groupSubs) > 1 {
+			sub = &subGroupedSub{groupSubs: groupSubs}
+		}
+		if colObserver != nil {
+			sub = s.newCollectionGroupSub(sub, colObserver)
 		}
 		s.subscriptions[subId] = sub
-	} else if colObserver != nil {
-		colObserver.close()
+		stored = true
 	}
 
 	return &pb.RpcObjectGroupsSubscribeResponse{
-		Error:  &pb.RpcObjectGroupsSubscribeResponseError{},
-		Groups: dataViewGroups,
-		SubId:  subId,
+		Error:     &pb.RpcObjectGroupsSubscribeResponseError{},
+		Groups:    dataViewGroups,
+		SubGroups: subGroups,
+		SubId:     subId,
 	}, nil
 }
 
+func (s *service) makeGroupFilters(req pb.RpcObjectGroupsSubscribeRequest, colObserver *collectionObserver) (*database.Filters, error) {
+	flt, err := database.NewFilters(database.Query{Filters: req.Filters}, s.objectStore)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Source) > 0 {
+		sourceFilter, err := s.filtersFromSource(req.Source)
+		if err != nil {
+			return nil, fmt.Errorf("can't make filter from source: %w", err)
+		}
+		flt.FilterObj = database.FiltersAnd{flt.FilterObj, sourceFilter}
+	}
+
+	if colObserver != nil {
+		if flt == nil {
+			flt = &database.Filters{}
+		}
+		if flt.FilterObj == nil {
+			flt.FilterObj = colObserver
+		} else {
+			flt.FilterObj = database.FiltersAnd{colObserver, flt.FilterObj}
+		}
+	}
+	return flt, nil
+}
+
+func (s *service) makeGroups(spaceId string, relationKey string, params kanban.GrouperParams, flt *database.Filters) (kanban.Grouper, []*model.BlockContentDataviewGroup, error) {
+	grouper, err := s.kanban.Grouper(spaceId, relationKey, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := grouper.InitGroups(spaceId, flt); err != nil {
+		return nil, nil, err
+	}
+
+	groups, err := grouper.MakeDataViewGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+	return grouper, groups, nil
+}
+
+func recordsToEntries(records []database.Record) []*entry {
+	entries := make([]*entry, 0, len(records))
+	for _, r := range records {
+		entries = append(entries, &entry{
+			id:   pbtypes.GetString(r.Details, "id"),
+			data: r.Details,
+		})
+	}
+	return entries
+}
+
 func (s *service) SubscribeIds(subId string, ids []string) (records []*types.Struct, err error) {
 	return
 }
@@ -414,6 +509,7 @@ func (s *service) Unsubscribe(subIds ...string) (err error) {
 			sub.close()
 			delete(s.subscriptions, subId)
 		}
+		delete(s.internalHandlers, subId)
 	}
 	return
 }
@@ -421,10 +517,14 @@ func (s *service) Unsubscribe(subIds ...string) (err error) {
 func (s *service) UnsubscribeAll() (err error) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	for _, sub := range s.subscriptions {
+	for subId, sub := range s.subscriptions {
+		// internal subscriptions are owned by services, not by clients
+		if _, ok := s.internalHandlers[subId]; ok {
+			continue
+		}
 		sub.close()
+		delete(s.subscriptions, subId)
 	}
-	s.subscriptions = make(map[string]subscription)
 	return
 }
 
@@ -474,6 +574,8 @@ func (s *service) recordsHandler() {
 }
 
 func (s *service) onChange(entries []*entry) time.Duration {
+	_, span := tracing.Start(context.Background(), "subscription.onChange", entriesCountKey.Int(len(entries)))
+	defer span.End()
 	s.m.Lock()
 	defer s.m.Unlock()
 	var subCount, depCount int
@@ -488,7 +590,8 @@ func (s *service) onChange(entries []*entry) time.Duration {
 		}
 	}
 	handleTime := time.Since(st)
-	event := s.ctxBuf.apply()
+	span.SetAttributes(subscriptionsCountKey.Int(subCount))
+	event := s.dispatchInternal(s.ctxBuf.apply())
 	dur := time.Since(st)
 
 	s.debugEvents(event)
@@ -771,6 +771,31 @@ func TestService_Search(t *testing.T) {
 		tagGroup := groups.Groups[0].Value.(*model.BlockContentDataviewGroupValueOfTag)
 		assert.Len(t, tagGroup.Tag.Ids, 0)
 	})
+	t.Run("SubscribeGroup: collection observer is closed on error", func(t *testing.T) {
+		// given
+		fx := newFixtureWithRealObjectStore(t)
+
+		subID := "subId"
+		collectionID := "collectionId"
+
+		fx.collectionService.EXPECT().SubscribeForCollection(collectionID, subID).Return([]string{"1"}, nil, nil).Times(1)
+		fx.collectionService.EXPECT().UnsubscribeFromCollection(collectionID, subID).Return().Times(1)
+
+		defer fx.a.Close(context.Background())
+		defer fx.ctrl.Finish()
+
+		// when
+		_, err := fx.SubscribeGroups(nil, pb.RpcObjectGroupsSubscribeRequest{
+			SpaceId:      "spaceId",
+			RelationKey:  "unknownKey",
+			Source:       []string{"source"},
+			SubId:        subID,
+			CollectionId: collectionID,
+		})
+
+		// then
+		assert.Error(t, err)
+	})
 	t.Run("SubscribeGroup: status group", func(t *testing.T) {
 		// given
 		fx := newFixtureWithRealObjectStore(t)
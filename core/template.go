@@ -73,3 +73,22 @@ func (mw *Middleware) TemplateExportAll(ctx context.Context, req *pb.RpcTemplate
 	path, err := getService[template.Service](mw).TemplateExportAll(ctx, req.Path)
 	return response(path, err)
 }
+
+func (mw *Middleware) TemplateSnippetInsert(cctx context.Context, req *pb.RpcTemplateSnippetInsertRequest) *pb.RpcTemplateSnippetInsertResponse {
+	ctx := mw.newContext(cctx)
+	response := func(blockIds []string, err error) *pb.RpcTemplateSnippetInsertResponse {
+		m := &pb.RpcTemplateSnippetInsertResponse{
+			Error:    &pb.RpcTemplateSnippetInsertResponseError{Code: pb.RpcTemplateSnippetInsertResponseError_NULL},
+			BlockIds: blockIds,
+		}
+		if err != nil {
+			m.Error.Code = pb.RpcTemplateSnippetInsertResponseError_UNKNOWN_ERROR
+			m.Error.Description = err.Error()
+		} else {
+			m.Event = mw.getResponseEvent(ctx)
+		}
+		return m
+	}
+	blockIds, err := getService[template.Service](mw).SnippetInsert(ctx, *req)
+	return response(blockIds, err)
+}
@@ -27,6 +27,11 @@ type AppLinkPayload struct {
 	AppPath           string `json:"app_path"`   // for now, it is not verified
 	CreatedAt         int64  `json:"created_at"` // unix timestamp
 	ExpireAt          int64  `json:"expire_at"`  // unix timestamp
+	Scope             int32  `json:"scope"`      // model.AccountAuthLocalApiScope
+	// SpaceIds limits the app to the given spaces, all spaces are available when it's empty
+	SpaceIds []string `json:"space_ids,omitempty"`
+	// UnredactedEvents allows the app to read events of the local API without anonymization
+	UnredactedEvents bool `json:"unredacted_events,omitempty"`
 }
 
 type appLinkFileEncrypted struct {
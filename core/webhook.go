@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+
+	"github.com/anyproto/anytype-heart/core/webhook"
+	"github.com/anyproto/anytype-heart/pb"
+)
+
+func (mw *Middleware) WebhookCreate(_ context.Context, req *pb.RpcWebhookCreateRequest) *pb.RpcWebhookCreateResponse {
+	w, err := getService[webhook.Service](mw).Create(req.SpaceId, req.Url, req.Filters, req.Keys)
+	code := mapErrorCode(err,
+		errToCode(webhook.ErrInvalidUrl, pb.RpcWebhookCreateResponseError_BAD_INPUT),
+		errToCode(webhook.ErrNoSpace, pb.RpcWebhookCreateResponseError_BAD_INPUT),
+	)
+	return &pb.RpcWebhookCreateResponse{
+		Error: &pb.RpcWebhookCreateResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+		Webhook: w,
+	}
+}
+
+func (mw *Middleware) WebhookDelete(_ context.Context, req *pb.RpcWebhookDeleteRequest) *pb.RpcWebhookDeleteResponse {
+	err := getService[webhook.Service](mw).Delete(req.WebhookId)
+	code := mapErrorCode(err,
+		errToCode(webhook.ErrNotFound, pb.RpcWebhookDeleteResponseError_NOT_FOUND),
+	)
+	return &pb.RpcWebhookDeleteResponse{
+		Error: &pb.RpcWebhookDeleteResponseError{
+			Code:        code,
+			Description: getErrorDescription(err),
+		},
+	}
+}
+
+func (mw *Middleware) WebhookList(_ context.Context, req *pb.RpcWebhookListRequest) *pb.RpcWebhookListResponse {
+	webhooks, err := getService[webhook.Service](mw).List(req.SpaceId)
+	return &pb.RpcWebhookListResponse{
+		Error: &pb.RpcWebhookListResponseError{
+			Code:        mapErrorCode[pb.RpcWebhookListResponseErrorCode](err),
+			Description: getErrorDescription(err),
+		},
+		Webhooks: webhooks,
+	}
+}
@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
+)
+
+const (
+	EventObjectCreated   = "object.created"
+	EventObjectUpdated   = "object.updated"
+	EventObjectDeleted   = "object.deleted"
+	EventObjectMatched   = "object.matched"
+	EventObjectUnmatched = "object.unmatched"
+
+	SignatureHeader = "X-Anytype-Signature"
+	EventHeader     = "X-Anytype-Event"
+	DeliveryHeader  = "X-Anytype-Delivery"
+)
+
+const (
+	maxAttempts  = 10
+	firstBackoff = 10 * time.Second
+	maxBackoff   = time.Hour
+)
+
+// delivery is the item of the persistent queue, it keeps the attempts counter between restarts
+type delivery struct {
+	Id            string          `json:"id"`
+	WebhookId     string          `json:"webhookId"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	CreatedAt     int64           `json:"createdAt"`
+	NextAttemptAt int64           `json:"nextAttemptAt"`
+}
+
+func makeDelivery() *delivery {
+	return &delivery{}
+}
+
+func (d *delivery) Key() string {
+	return d.Id
+}
+
+func (d *delivery) Less(other persistentqueue.OrderedItem) bool {
+	return d.CreatedAt < other.(*delivery).CreatedAt
+}
+
+type payload struct {
+	Id          string         `json:"id"`
+	WebhookId   string         `json:"webhook_id"`
+	Event       string         `json:"event"`
+	SpaceId     string         `json:"space_id"`
+	ObjectId    string         `json:"object_id"`
+	ChangedKeys []string       `json:"changed_keys,omitempty"`
+	Details     map[string]any `json:"details,omitempty"`
+	Timestamp   int64          `json:"timestamp"`
+}
+
+// changes of the objects in the subscription, the order of objects is kept
+type changes struct {
+	added   []string
+	removed []string
+	// changed keys by object id, objects added in the same batch are not included
+	changed   map[string][]string
+	changeIds []string
+}
+
+// collectChanges converts the subscription messages to the list of added, removed and changed objects
+func collectChanges(msgs []*pb.EventMessage) changes {
+	c := changes{changed: map[string][]string{}}
+	isAdded := map[string]bool{}
+	addChanged := func(id string, keys ...string) {
+		if isAdded[id] {
+			return
+		}
+		if _, ok := c.changed[id]; !ok {
+			c.changeIds = append(c.changeIds, id)
+		}
+		c.changed[id] = appendUnique(c.changed[id], keys...)
+	}
+	for _, msg := range msgs {
+		if add := msg.GetSubscriptionAdd(); add != nil {
+			c.added = append(c.added, add.Id)
+			isAdded[add.Id] = true
+		}
+	}
+	for _, msg := range msgs {
+		switch {
+		case msg.GetSubscriptionRemove() != nil:
+			c.removed = append(c.removed, msg.GetSubscriptionRemove().Id)
+		case msg.GetObjectDetailsAmend() != nil:
+			amend := msg.GetObjectDetailsAmend()
+			keys := make([]string, 0, len(amend.Details))
+			for _, kv := range amend.Details {
+				keys = append(keys, kv.Key)
+			}
+			addChanged(amend.Id, keys...)
+		case msg.GetObjectDetailsUnset() != nil:
+			addChanged(msg.GetObjectDetailsUnset().Id, msg.GetObjectDetailsUnset().Keys...)
+		case msg.GetObjectDetailsSet() != nil:
+			set := msg.GetObjectDetailsSet()
+			keys := make([]string, 0, len(set.GetDetails().GetFields()))
+			for key := range set.GetDetails().GetFields() {
+				keys = append(keys, key)
+			}
+			addChanged(set.Id, keys...)
+		}
+	}
+	return c
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		var exists bool
+		for _, existing := range list {
+			if existing == v {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func (s *service) enqueueChanges(webhookId string, c changes) error {
+	q := s.getQueue(webhookId)
+	if q == nil {
+		return nil
+	}
+	w, err := s.webhooks.Get(webhookId)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get webhook: %w", err)
+	}
+	detailKeys := append([]string{
+		bundle.RelationKeyId.String(),
+		bundle.RelationKeyName.String(),
+		bundle.RelationKeyType.String(),
+	}, w.Keys...)
+
+	enqueue := func(event, objectId string, changedKeys []string) error {
+		p := payload{
+			Id:          bson.NewObjectId().Hex(),
+			WebhookId:   w.Id,
+			Event:       event,
+			SpaceId:     w.SpaceId,
+			ObjectId:    objectId,
+			ChangedKeys: changedKeys,
+			Timestamp:   s.now().Unix(),
+		}
+		if event != EventObjectDeleted {
+			details, err := s.objectStore.GetDetails(objectId)
+			if err != nil {
+				return fmt.Errorf("get details: %w", err)
+			}
+			p.Details = pbtypes.StructToMap(pbtypes.Map(details.GetDetails(), detailKeys...))
+		}
+		raw, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+		return q.Add(&delivery{
+			Id:        p.Id,
+			WebhookId: w.Id,
+			Event:     event,
+			Payload:   raw,
+			CreatedAt: s.now().UnixNano(),
+		})
+	}
+
+	for _, id := range c.added {
+		event := EventObjectMatched
+		if details, err := s.objectStore.GetDetails(id); err == nil &&
+			pbtypes.GetInt64(details.GetDetails(), bundle.RelationKeyCreatedDate.String()) >= s.startedAt.Unix() {
+			event = EventObjectCreated
+		}
+		if err = enqueue(event, id, nil); err != nil {
+			return err
+		}
+	}
+	for _, id := range c.changeIds {
+		if err = enqueue(EventObjectUpdated, id, c.changed[id]); err != nil {
+			return err
+		}
+	}
+	for _, id := range c.removed {
+		event := EventObjectUnmatched
+		details, err := s.objectStore.GetDetails(id)
+		if err != nil || pbtypes.IsStructEmpty(details.GetDetails()) ||
+			pbtypes.GetBool(details.GetDetails(), bundle.RelationKeyIsDeleted.String()) ||
+			pbtypes.GetBool(details.GetDetails(), bundle.RelationKeyIsArchived.String()) {
+			event = EventObjectDeleted
+		}
+		if err = enqueue(event, id, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver is the handler of the queue, failed deliveries are retried with exponential backoff.
+// Deliveries of the webhook are posted one by one in the order of changes
+func (s *service) deliver(ctx context.Context, d *delivery) (persistentqueue.Action, error) {
+	if s.now().UnixNano() < d.NextAttemptAt {
+		return persistentqueue.ActionRetry, nil
+	}
+	w, err := s.webhooks.Get(d.WebhookId)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		return persistentqueue.ActionDone, nil
+	}
+	if err != nil {
+		return persistentqueue.ActionRetry, fmt.Errorf("get webhook: %w", err)
+	}
+
+	err = s.post(ctx, w, d)
+	if err == nil {
+		return persistentqueue.ActionDone, nil
+	}
+	d.Attempts++
+	if d.Attempts >= maxAttempts {
+		log.Warn("webhook delivery dropped", zap.String("webhookId", w.Id), zap.String("deliveryId", d.Id), zap.Error(err))
+		return persistentqueue.ActionDone, nil
+	}
+	d.NextAttemptAt = s.now().Add(retryDelay(d.Attempts)).UnixNano()
+	return persistentqueue.ActionRetry, fmt.Errorf("deliver %s: %w", d.Id, err)
+}
+
+func (s *service) post(ctx context.Context, w *model.Webhook, d *delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.Id)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, d.Payload))
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the value of the signature header, receivers compute HMAC-SHA256 of the request body
+// with the webhook secret and compare it with the header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := firstBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
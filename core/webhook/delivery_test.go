@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
+)
+
+func newTestService(t *testing.T) *service {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLoggingLevel(badger.ERROR))
+	require.NoError(t, err)
+	s := &service{}
+	s.init(db)
+	s.now = func() time.Time {
+		return time.Unix(1000, 0)
+	}
+	t.Cleanup(func() {
+		for id := range s.queues {
+			s.stopQueue(id)
+		}
+		db.Close()
+	})
+	return s
+}
+
+func TestCollectChanges(t *testing.T) {
+	// given
+	msgs := []*pb.EventMessage{
+		{Value: &pb.EventMessageValueOfObjectDetailsSet{ObjectDetailsSet: &pb.EventObjectDetailsSet{
+			Id:      "new",
+			Details: pbtypes.ToStruct(map[string]any{"name": "task"}),
+		}}},
+		{Value: &pb.EventMessageValueOfSubscriptionAdd{SubscriptionAdd: &pb.EventObjectSubscriptionAdd{Id: "new"}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsAmend{ObjectDetailsAmend: &pb.EventObjectDetailsAmend{
+			Id:      "existing",
+			Details: []*pb.EventObjectDetailsAmendKeyValue{{Key: "status", Value: pbtypes.String("done")}},
+		}}},
+		{Value: &pb.EventMessageValueOfObjectDetailsUnset{ObjectDetailsUnset: &pb.EventObjectDetailsUnset{
+			Id:   "existing",
+			Keys: []string{"assignee", "status"},
+		}}},
+		{Value: &pb.EventMessageValueOfSubscriptionRemove{SubscriptionRemove: &pb.EventObjectSubscriptionRemove{Id: "old"}}},
+	}
+
+	// when
+	c := collectChanges(msgs)
+
+	// then
+	assert.Equal(t, []string{"new"}, c.added)
+	assert.Equal(t, []string{"old"}, c.removed)
+	assert.Equal(t, []string{"existing"}, c.changeIds)
+	assert.Equal(t, map[string][]string{"existing": {"status", "assignee"}}, c.changed)
+}
+
+func TestService_Deliver(t *testing.T) {
+	t.Run("signed payload is posted", func(t *testing.T) {
+		// given
+		s := newTestService(t)
+		var (
+			body    []byte
+			headers http.Header
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			headers = r.Header
+		}))
+		defer server.Close()
+		require.NoError(t, s.webhooks.Set("w1", &model.Webhook{Id: "w1", Url: server.URL, Secret: "secret"}))
+
+		// when
+		action, err := s.deliver(context.Background(), &delivery{Id: "d1", WebhookId: "w1", Event: EventObjectUpdated, Payload: []byte(`{"id":"d1"}`)})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, persistentqueue.ActionDone, action)
+		assert.Equal(t, `{"id":"d1"}`, string(body))
+		assert.Equal(t, Sign("secret", body), headers.Get(SignatureHeader))
+		assert.Equal(t, EventObjectUpdated, headers.Get(EventHeader))
+		assert.Equal(t, "d1", headers.Get(DeliveryHeader))
+	})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		// given
+		s := newTestService(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		require.NoError(t, s.webhooks.Set("w1", &model.Webhook{Id: "w1", Url: server.URL, Secret: "secret"}))
+		d := &delivery{Id: "d1", WebhookId: "w1", Payload: []byte(`{}`), Attempts: 2}
+
+		// when
+		action, err := s.deliver(context.Background(), d)
+
+		// then
+		assert.Error(t, err)
+		assert.Equal(t, persistentqueue.ActionRetry, action)
+		assert.Equal(t, 3, d.Attempts)
+		assert.Equal(t, s.now().Add(40*time.Second).UnixNano(), d.NextAttemptAt)
+
+		// when
+		action, err = s.deliver(context.Background(), d)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, persistentqueue.ActionRetry, action)
+		assert.Equal(t, 3, d.Attempts)
+	})
+
+	t.Run("deliveries of deleted webhook are dropped", func(t *testing.T) {
+		// given
+		s := newTestService(t)
+
+		// when
+		action, err := s.deliver(context.Background(), &delivery{Id: "d1", WebhookId: "deleted"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, persistentqueue.ActionDone, action)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 10*time.Second, retryDelay(1))
+	assert.Equal(t, 80*time.Second, retryDelay(4))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}
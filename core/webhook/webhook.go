@@ -0,0 +1,362 @@
+// Package webhook delivers changes of objects matching the search filters to the registered urls.
+// Changes come from the internal subscriptions of the subscription service, deliveries are kept
+// in the persistent queue, so they are retried with backoff after failures and restarts.
+// Every webhook has its own queue, so a slow or unavailable receiver delays only its own deliveries
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/cheggaaa/mb/v3"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/globalsign/mgo/bson"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-heart/core/subscription"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/datastore"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+	"github.com/anyproto/anytype-heart/util/keyvaluestore"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+	"github.com/anyproto/anytype-heart/util/persistentqueue"
+)
+
+const CName = "core.webhook"
+
+var log = logging.Logger(CName).Desugar()
+
+const (
+	subIdPrefix     = "webhook-"
+	deliveryTimeout = 10 * time.Second
+	idsKey          = "ids"
+)
+
+var (
+	ErrNotFound   = errors.New("webhook not found")
+	ErrInvalidUrl = errors.New("invalid url: only absolute http and https urls are supported")
+	ErrNoSpace    = errors.New("space id is required")
+)
+
+type Service interface {
+	// Create registers the webhook and starts delivering changes of the objects matching the filters
+	Create(spaceId, url string, filters []*model.BlockContentDataviewFilter, keys []string) (*model.Webhook, error)
+	Delete(id string) error
+	// List returns webhooks of the space without secrets
+	List(spaceId string) ([]*model.Webhook, error)
+	app.ComponentRunnable
+}
+
+func New() Service {
+	return &service{}
+}
+
+type subscriptionMessages struct {
+	webhookId string
+	msgs      []*pb.EventMessage
+}
+
+type service struct {
+	subscription subscription.Service
+	objectStore  objectstore.ObjectStore
+
+	db         *badger.DB
+	webhooks   keyvaluestore.Store[*model.Webhook]
+	ids        keyvaluestore.Store[[]string]
+	messages   *mb.MB[subscriptionMessages]
+	httpClient *http.Client
+
+	// queuesMu guards queues, the map is read by the goroutine handling subscription messages
+	queuesMu sync.Mutex
+	queues   map[string]*persistentqueue.Queue[*delivery]
+
+	mu sync.Mutex
+	// startedAt is used to tell created objects from objects that started to match the filters
+	startedAt time.Time
+	now       func() time.Time
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+}
+
+func (s *service) Init(a *app.App) error {
+	s.subscription = app.MustComponent[subscription.Service](a)
+	s.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	db, err := app.MustComponent[datastore.Datastore](a).LocalStorage()
+	if err != nil {
+		return fmt.Errorf("get badger: %w", err)
+	}
+	s.init(db)
+	return nil
+}
+
+func (s *service) init(db *badger.DB) {
+	s.db = db
+	s.webhooks = keyvaluestore.New(db, []byte("webhook/webhooks/"), marshalWebhook, unmarshalWebhook)
+	s.ids = keyvaluestore.NewJson[[]string](db, []byte("webhook/"))
+	s.queues = map[string]*persistentqueue.Queue[*delivery]{}
+	s.messages = mb.New[subscriptionMessages](0)
+	s.httpClient = &http.Client{Timeout: deliveryTimeout}
+	s.now = time.Now
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+}
+
+func (s *service) Name() string {
+	return CName
+}
+
+func (s *service) Run(_ context.Context) error {
+	s.startedAt = s.now()
+	ids, err := s.listIds()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		w, err := s.webhooks.Get(id)
+		if err != nil {
+			log.Error("get webhook", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		s.startQueue(id)
+		if err = s.subscribe(w); err != nil {
+			log.Error("subscribe webhook", zap.String("id", id), zap.Error(err))
+		}
+	}
+	go s.handleMessages()
+	return nil
+}
+
+func (s *service) Close(_ context.Context) error {
+	if s.ctxCancel != nil {
+		s.ctxCancel()
+	}
+	if ids, err := s.listIds(); err == nil {
+		subIds := make([]string, 0, len(ids))
+		for _, id := range ids {
+			subIds = append(subIds, subIdPrefix+id)
+		}
+		if err = s.subscription.Unsubscribe(subIds...); err != nil {
+			log.Warn("unsubscribe", zap.Error(err))
+		}
+	}
+	if err := s.messages.Close(); err != nil {
+		log.Warn("close messages", zap.Error(err))
+	}
+	s.queuesMu.Lock()
+	queues := s.queues
+	s.queues = map[string]*persistentqueue.Queue[*delivery]{}
+	s.queuesMu.Unlock()
+	for _, q := range queues {
+		if err := q.Close(); err != nil {
+			log.Warn("close queue", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *service) Create(spaceId, rawUrl string, filters []*model.BlockContentDataviewFilter, keys []string) (*model.Webhook, error) {
+	if spaceId == "" {
+		return nil, ErrNoSpace
+	}
+	if u, err := url.Parse(rawUrl); err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidUrl
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate secret: %w", err)
+	}
+	if len(keys) == 0 {
+		keys = []string{bundle.RelationKeyName.String()}
+	}
+	w := &model.Webhook{
+		Id:          bson.NewObjectId().Hex(),
+		SpaceId:     spaceId,
+		Url:         rawUrl,
+		Secret:      hex.EncodeToString(secret),
+		Filters:     filters,
+		Keys:        keys,
+		CreatedDate: s.now().Unix(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.listIds()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.webhooks.Set(w.Id, w); err != nil {
+		return nil, fmt.Errorf("save webhook: %w", err)
+	}
+	if err = s.ids.Set(idsKey, append(slices.Clip(ids), w.Id)); err != nil {
+		s.rollbackCreate(w.Id, nil)
+		return nil, fmt.Errorf("save webhook ids: %w", err)
+	}
+	// the queue is started before subscribing, so changes from the initial subscription messages are not lost
+	s.startQueue(w.Id)
+	if err = s.subscribe(w); err != nil {
+		s.rollbackCreate(w.Id, ids)
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+	return w, nil
+}
+
+// rollbackCreate removes the webhook that failed to be created, ids are restored only if they have been saved
+func (s *service) rollbackCreate(id string, ids []string) {
+	s.stopQueue(id)
+	if ids != nil {
+		if err := s.ids.Set(idsKey, ids); err != nil {
+			log.Error("restore webhook ids", zap.String("id", id), zap.Error(err))
+		}
+	}
+	if err := s.webhooks.Delete(id); err != nil {
+		log.Error("delete webhook", zap.String("id", id), zap.Error(err))
+	}
+}
+
+func (s *service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.listIds()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(ids, id) {
+		return ErrNotFound
+	}
+	if err = s.subscription.Unsubscribe(subIdPrefix + id); err != nil {
+		return fmt.Errorf("unsubscribe: %w", err)
+	}
+	if err = s.ids.Set(idsKey, slices.DeleteFunc(ids, func(existing string) bool { return existing == id })); err != nil {
+		return fmt.Errorf("save webhook ids: %w", err)
+	}
+	s.stopQueue(id)
+	return s.webhooks.Delete(id)
+}
+
+func (s *service) List(spaceId string) ([]*model.Webhook, error) {
+	ids, err := s.listIds()
+	if err != nil {
+		return nil, err
+	}
+	var webhooks []*model.Webhook
+	for _, id := range ids {
+		w, err := s.webhooks.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("get webhook %s: %w", id, err)
+		}
+		if spaceId == "" || w.SpaceId == spaceId {
+			w.Secret = ""
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *service) listIds() ([]string, error) {
+	ids, err := s.ids.Get(idsKey)
+	if errors.Is(err, keyvaluestore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get webhook ids: %w", err)
+	}
+	return ids, nil
+}
+
+// startQueue starts the delivery queue of the webhook, deliveries left from the previous run are restored from the storage
+func (s *service) startQueue(webhookId string) {
+	q := persistentqueue.New(
+		persistentqueue.NewBadgerStorage(s.db, []byte("queue/webhook/"+webhookId+"/"), makeDelivery),
+		log,
+		s.deliver,
+		persistentqueue.WithRetryBackoff(func(d *delivery) time.Duration {
+			return time.Unix(0, d.NextAttemptAt).Sub(s.now())
+		}),
+	)
+	q.Run()
+	s.queuesMu.Lock()
+	s.queues[webhookId] = q
+	s.queuesMu.Unlock()
+}
+
+// stopQueue drops pending deliveries of the deleted webhook and stops its queue. Closing cancels the delivery in progress
+func (s *service) stopQueue(webhookId string) {
+	s.queuesMu.Lock()
+	q, ok := s.queues[webhookId]
+	delete(s.queues, webhookId)
+	s.queuesMu.Unlock()
+	if !ok {
+		return
+	}
+	for _, key := range q.ListKeys() {
+		if err := q.Remove(key); err != nil {
+			log.Warn("remove delivery", zap.String("webhookId", webhookId), zap.Error(err))
+		}
+	}
+	if err := q.Close(); err != nil {
+		log.Warn("close queue", zap.String("webhookId", webhookId), zap.Error(err))
+	}
+}
+
+func (s *service) getQueue(webhookId string) *persistentqueue.Queue[*delivery] {
+	s.queuesMu.Lock()
+	defer s.queuesMu.Unlock()
+	return s.queues[webhookId]
+}
+
+func (s *service) subscribe(w *model.Webhook) error {
+	filters := append([]*model.BlockContentDataviewFilter{{
+		RelationKey: bundle.RelationKeySpaceId.String(),
+		Condition:   model.BlockContentDataviewFilter_Equal,
+		Value:       pbtypes.String(w.SpaceId),
+	}}, w.Filters...)
+	webhookId := w.Id
+	_, err := s.subscription.SearchInternal(pb.RpcObjectSearchSubscribeRequest{
+		SubId:             subIdPrefix + w.Id,
+		Filters:           filters,
+		Keys:              append([]string{bundle.RelationKeyId.String()}, w.Keys...),
+		NoDepSubscription: true,
+	}, func(msgs []*pb.EventMessage) {
+		// the handler is called under the subscription service lock, so messages are handled in the separate goroutine
+		if err := s.messages.Add(s.ctx, subscriptionMessages{webhookId: webhookId, msgs: msgs}); err != nil {
+			log.Warn("add subscription messages", zap.Error(err))
+		}
+	})
+	return err
+}
+
+func (s *service) handleMessages() {
+	for {
+		batch, err := s.messages.Wait(s.ctx)
+		if err != nil {
+			return
+		}
+		for _, m := range batch {
+			if err = s.enqueueChanges(m.webhookId, collectChanges(m.msgs)); err != nil {
+				log.Error("enqueue webhook deliveries", zap.String("webhookId", m.webhookId), zap.Error(err))
+			}
+		}
+	}
+}
+
+func marshalWebhook(w *model.Webhook) ([]byte, error) {
+	return proto.Marshal(w)
+}
+
+func unmarshalWebhook(data []byte) (*model.Webhook, error) {
+	w := &model.Webhook{}
+	return w, proto.Unmarshal(data, w)
+}
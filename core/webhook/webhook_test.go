@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/subscription/mock_subscription"
+	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+)
+
+func TestService_Create(t *testing.T) {
+	t.Run("webhook is saved and its queue is started", func(t *testing.T) {
+		// given
+		s := newTestService(t)
+		subscriptionService := mock_subscription.NewMockService(t)
+		subscriptionService.EXPECT().SearchInternal(mock.Anything, mock.Anything).Return(&pb.RpcObjectSearchSubscribeResponse{}, nil)
+		s.subscription = subscriptionService
+
+		// when
+		w, err := s.Create("space1", "https://example.com/hook", nil, nil)
+
+		// then
+		require.NoError(t, err)
+		webhooks, err := s.List("space1")
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, w.Id, webhooks[0].Id)
+		assert.NotNil(t, s.getQueue(w.Id))
+	})
+
+	t.Run("webhook is removed when subscribe fails", func(t *testing.T) {
+		// given
+		s := newTestService(t)
+		require.NoError(t, s.webhooks.Set("existing", &model.Webhook{Id: "existing", SpaceId: "space1"}))
+		require.NoError(t, s.ids.Set(idsKey, []string{"existing"}))
+		subscriptionService := mock_subscription.NewMockService(t)
+		subscriptionService.EXPECT().SearchInternal(mock.Anything, mock.Anything).Return(nil, errors.New("subscribe error"))
+		s.subscription = subscriptionService
+
+		// when
+		_, err := s.Create("space1", "https://example.com/hook", nil, nil)
+
+		// then
+		require.Error(t, err)
+		ids, err := s.listIds()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"existing"}, ids)
+		webhooks, err := s.List("")
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, "existing", webhooks[0].Id)
+		assert.Empty(t, s.queues)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	// given
+	s := newTestService(t)
+	subscriptionService := mock_subscription.NewMockService(t)
+	subscriptionService.EXPECT().SearchInternal(mock.Anything, mock.Anything).Return(&pb.RpcObjectSearchSubscribeResponse{}, nil)
+	subscriptionService.EXPECT().Unsubscribe(mock.Anything).Return(nil)
+	s.subscription = subscriptionService
+	w, err := s.Create("space1", "https://example.com/hook", nil, nil)
+	require.NoError(t, err)
+
+	// when
+	err = s.Delete(w.Id)
+
+	// then
+	require.NoError(t, err)
+	assert.Nil(t, s.getQueue(w.Id))
+	_, err = s.webhooks.Get(w.Id)
+	assert.Error(t, err)
+	assert.ErrorIs(t, s.Delete(w.Id), ErrNotFound)
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+
+	"github.com/anyproto/anytype-heart/util/reflection"
+)
+
+const (
+	rpcMethodKey    = attribute.Key("rpc.method")
+	rpcErrorCodeKey = attribute.Key("anytype.rpc.error_code")
+)
+
+// UnaryServerInterceptor starts the span of the gRPC call
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return SharedRpcInterceptor(ctx, req, path.Base(info.FullMethod), handler)
+}
+
+// SharedRpcInterceptor starts the span of the RPC call, ids of the request are added as attributes.
+// Errors of the response are recorded, so failed calls could be found by the span status
+func SharedRpcInterceptor(ctx context.Context, req any, methodName string, actualCall func(ctx context.Context, req any) (any, error)) (any, error) {
+	ctx, span := Start(ctx, "rpc/"+methodName, append(requestAttributes(req), rpcMethodKey.String(methodName))...)
+	resp, err := actualCall(ctx, req)
+	if err == nil {
+		if code, description, parseErr := reflection.GetError(resp); parseErr == nil && code > 0 {
+			span.SetAttributes(rpcErrorCodeKey.Int64(code))
+			span.SetStatus(codes.Error, fmt.Sprintf("code %d: %s", code, description))
+		}
+	}
+	End(span, err)
+	return resp, err
+}
+
+// requestAttributes returns ids of the request fields that are common for the requests
+func requestAttributes(req any) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if r, ok := req.(interface{ GetContextId() string }); ok && r.GetContextId() != "" {
+		attrs = append(attrs, ContextIdKey.String(r.GetContextId()))
+	}
+	if r, ok := req.(interface{ GetObjectId() string }); ok && r.GetObjectId() != "" {
+		attrs = append(attrs, ObjectId(r.GetObjectId()))
+	}
+	if r, ok := req.(interface{ GetSpaceId() string }); ok && r.GetSpaceId() != "" {
+		attrs = append(attrs, SpaceId(r.GetSpaceId()))
+	}
+	return attrs
+}
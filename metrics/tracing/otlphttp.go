@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	exportTimeout      = 10 * time.Second
+	maxErrorBodySize   = 1 << 10
+	resourceSpansField = 1 // resource_spans field of ExportTraceServiceRequest
+)
+
+// httpClient sends spans to OTLP/HTTP receivers in the protobuf encoding. The request message is encoded by hand,
+// so the collector packages with their grpc-gateway dependencies are not needed
+type httpClient struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newHttpClient(endpoint string, headers map[string]string) *httpClient {
+	return &httpClient{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: exportTimeout},
+	}
+}
+
+func (c *httpClient) Start(_ context.Context) error {
+	return nil
+}
+
+func (c *httpClient) Stop(_ context.Context) error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+func (c *httpClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	body, err := encodeExportRequest(protoSpans)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("export spans: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// encodeExportRequest encodes ExportTraceServiceRequest of the spans
+func encodeExportRequest(protoSpans []*tracepb.ResourceSpans) ([]byte, error) {
+	var buf []byte
+	for _, rs := range protoSpans {
+		data, err := proto.Marshal(rs)
+		if err != nil {
+			return nil, fmt.Errorf("marshal spans: %w", err)
+		}
+		buf = protowire.AppendTag(buf, resourceSpansField, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, data)
+	}
+	return buf, nil
+}
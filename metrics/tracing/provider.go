@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"github.com/anyproto/anytype-heart/util/vcs"
+)
+
+const (
+	defaultServiceName = "anytype-heart"
+	tracesPath         = "/v1/traces"
+)
+
+// Config of the exporters, spans are exported to every configured destination
+type Config struct {
+	// Endpoint is the url of OTLP/HTTP traces receiver, e.g. http://localhost:4318/v1/traces
+	Endpoint string
+	// Headers are added to the export requests, e.g. for authorization
+	Headers map[string]string
+	// File is the path spans are appended to as json objects, one per line, for offline analysis
+	File        string
+	ServiceName string
+	// SampleRatio is the fraction of traces recorded, traces are sampled by the root span. Zero means all traces
+	SampleRatio float64
+}
+
+// ConfigFromEnv reads the config from the standard OTEL_* variables and ANYTYPE_TRACE_FILE
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		Headers:     parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		File:        os.Getenv("ANYTYPE_TRACE_FILE"),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+	}
+	if cfg.Endpoint == "" {
+		if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+			cfg.Endpoint = strings.TrimSuffix(endpoint, "/") + tracesPath
+		}
+	}
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil {
+		cfg.SampleRatio = ratio
+	}
+	return cfg
+}
+
+// parseHeaders reads headers in the key1=value1,key2=value2 format
+func parseHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); ok && key != "" {
+			headers[key] = strings.TrimSpace(val)
+		}
+	}
+	return headers
+}
+
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || c.File != ""
+}
+
+// Init sets the global tracer provider exporting spans according to the config.
+// shutdown flushes the spans and closes exporters, it should be called before exit
+func Init(ctx context.Context, cfg Config) (shutdown func(ctx context.Context) error, err error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("no trace exporters configured")
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(vcs.GetVCSInfo().Version()),
+	)
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	}
+
+	var closers []func() error
+	closeAll := func() {
+		for _, c := range closers {
+			_ = c()
+		}
+	}
+	if cfg.File != "" {
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		closers = append(closers, f.Close)
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("create file exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	if cfg.Endpoint != "" {
+		exporter, err := otlptrace.New(ctx, newHttpClient(cfg.Endpoint, cfg.Headers))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		closeAll()
+		return err
+	}, nil
+}
+
+// Flush exports the spans buffered by the current provider. It is used where the process has no exit hook
+// and the provider can't be shut down, because the app could be started again
+func Flush(ctx context.Context) error {
+	if provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		return provider.ForceFlush(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Package tracing provides OpenTelemetry spans of RPC handlers, object applies, indexing, subscription updates,
+// file operations and space loading. Spans are no-op until Init is called, so the instrumented code doesn't
+// depend on whether tracing is configured
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/anyproto/anytype-heart"
+
+const (
+	ObjectIdKey  = attribute.Key("anytype.object.id")
+	SpaceIdKey   = attribute.Key("anytype.space.id")
+	ContextIdKey = attribute.Key("anytype.context.id")
+)
+
+func ObjectId(id string) attribute.KeyValue {
+	return ObjectIdKey.String(id)
+}
+
+func SpaceId(id string) attribute.KeyValue {
+	return SpaceIdKey.String(id)
+}
+
+// Start starts the span as a child of the span of ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// the tracer is taken from the current provider, so spans started before Init are not recorded
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks the span as failed if err is not nil and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// testRequest and testResponse follow the shape of the generated rpc messages
+type testRequest struct {
+	ContextId, ObjectId, SpaceId string
+}
+
+func (r *testRequest) GetContextId() string { return r.ContextId }
+func (r *testRequest) GetObjectId() string  { return r.ObjectId }
+func (r *testRequest) GetSpaceId() string   { return r.SpaceId }
+
+type testResponseError struct {
+	Code        int32
+	Description string
+}
+
+type testResponse struct {
+	Error *testResponseError
+}
+
+const testNotFoundCode = 3
+
+func newTestExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+	})
+	return exporter
+}
+
+func attributes(span tracetest.SpanStub) map[string]any {
+	res := map[string]any{}
+	for _, attr := range span.Attributes {
+		res[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return res
+}
+
+func TestSharedRpcInterceptor(t *testing.T) {
+	t.Run("span of the call", func(t *testing.T) {
+		// given
+		exporter := newTestExporter(t)
+		req := &testRequest{ContextId: "ctx", ObjectId: "page", SpaceId: "space"}
+
+		// when
+		_, err := SharedRpcInterceptor(context.Background(), req, "ObjectOpen", func(ctx context.Context, req any) (any, error) {
+			_, span := Start(ctx, "smartblock.Apply")
+			End(span, nil)
+			return &testResponse{Error: &testResponseError{}}, nil
+		})
+
+		// then
+		require.NoError(t, err)
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		apply, rpc := spans[0], spans[1]
+		assert.Equal(t, "rpc/ObjectOpen", rpc.Name)
+		assert.Equal(t, map[string]any{
+			"rpc.method":         "ObjectOpen",
+			"anytype.context.id": "ctx",
+			"anytype.object.id":  "page",
+			"anytype.space.id":   "space",
+		}, attributes(rpc))
+		assert.Equal(t, codes.Unset, rpc.Status.Code)
+		assert.Equal(t, rpc.SpanContext.SpanID(), apply.Parent.SpanID())
+	})
+
+	t.Run("error of the response", func(t *testing.T) {
+		// given
+		exporter := newTestExporter(t)
+
+		// when
+		_, _ = SharedRpcInterceptor(context.Background(), &testRequest{}, "ObjectOpen", func(ctx context.Context, req any) (any, error) {
+			return &testResponse{Error: &testResponseError{Code: testNotFoundCode, Description: "object not found"}}, nil
+		})
+
+		// then
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Contains(t, spans[0].Status.Description, "object not found")
+		assert.Equal(t, int64(testNotFoundCode), attributes(spans[0])["anytype.rpc.error_code"])
+	})
+
+	t.Run("error of the call", func(t *testing.T) {
+		// given
+		exporter := newTestExporter(t)
+
+		// when
+		_, err := SharedRpcInterceptor(context.Background(), &testRequest{}, "ObjectOpen", func(ctx context.Context, req any) (any, error) {
+			return nil, errors.New("panic recovered")
+		})
+
+		// then
+		assert.Error(t, err)
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+}
+
+func TestInit(t *testing.T) {
+	t.Run("no exporters", func(t *testing.T) {
+		_, err := Init(context.Background(), Config{})
+		assert.Error(t, err)
+	})
+
+	t.Run("file and otlp exporters", func(t *testing.T) {
+		// given
+		received := make(chan []*tracepb.ResourceSpans, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/traces", r.URL.Path)
+			assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+			assert.Equal(t, "secret", r.Header.Get("Authorization"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received <- decodeExportRequest(t, body)
+		}))
+		defer server.Close()
+		file := filepath.Join(t.TempDir(), "traces.jsonl")
+		prev := otel.GetTracerProvider()
+		defer otel.SetTracerProvider(prev)
+
+		// when
+		shutdown, err := Init(context.Background(), Config{
+			Endpoint: server.URL + tracesPath,
+			Headers:  map[string]string{"Authorization": "secret"},
+			File:     file,
+		})
+		require.NoError(t, err)
+		_, span := Start(context.Background(), "indexer.Index", ObjectId("page"), SpaceId("space"))
+		End(span, nil)
+		require.NoError(t, shutdown(context.Background()))
+
+		// then
+		resourceSpans := <-received
+		require.Len(t, resourceSpans, 1)
+		require.Len(t, resourceSpans[0].ScopeSpans, 1)
+		exported := resourceSpans[0].ScopeSpans[0].Spans
+		require.Len(t, exported, 1)
+		assert.Equal(t, "indexer.Index", exported[0].Name)
+		assert.Equal(t, instrumentationName, resourceSpans[0].ScopeSpans[0].Scope.Name)
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1)
+		var stub struct {
+			Name       string
+			Attributes []struct{ Key string }
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &stub))
+		assert.Equal(t, "indexer.Index", stub.Name)
+		assert.Len(t, stub.Attributes, 2)
+	})
+
+	t.Run("flush without shutdown", func(t *testing.T) {
+		// given
+		file := filepath.Join(t.TempDir(), "traces.jsonl")
+		prev := otel.GetTracerProvider()
+		defer otel.SetTracerProvider(prev)
+		shutdown, err := Init(context.Background(), Config{File: file})
+		require.NoError(t, err)
+		defer shutdown(context.Background())
+
+		// when
+		_, span := Start(context.Background(), "rpc/AppShutdown")
+		End(span, nil)
+		require.NoError(t, Flush(context.Background()))
+
+		// then
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "rpc/AppShutdown")
+	})
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer token, x-team = mw")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("ANYTYPE_TRACE_FILE", "/tmp/traces.jsonl")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+
+	cfg := ConfigFromEnv()
+
+	assert.Equal(t, Config{
+		Endpoint:    "http://localhost:4318/v1/traces",
+		Headers:     map[string]string{"Authorization": "Bearer token", "x-team": "mw"},
+		File:        "/tmp/traces.jsonl",
+		SampleRatio: 0.25,
+	}, cfg)
+	assert.True(t, cfg.Enabled())
+}
+
+func decodeExportRequest(t *testing.T, data []byte) []*tracepb.ResourceSpans {
+	var res []*tracepb.ResourceSpans
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		require.GreaterOrEqual(t, n, 0)
+		require.Equal(t, protowire.Number(resourceSpansField), num)
+		require.Equal(t, protowire.BytesType, typ)
+		data = data[n:]
+		value, n := protowire.ConsumeBytes(data)
+		require.GreaterOrEqual(t, n, 0)
+		data = data[n:]
+		rs := &tracepb.ResourceSpans{}
+		require.NoError(t, proto.Unmarshal(value, rs))
+		res = append(res, rs)
+	}
+	return res
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 )
 
-const RelationChecksum = "e7b7dc1196702d61efece57fd1db120838fb3d3f40ca3ca5670092f609d56538"
+const RelationChecksum = "03c0320949d0f18ab1b4cbcab28ee6fc620fbcd12ee3d7d38b365f70ccc85358"
 const (
 	RelationKeyTag                       domain.RelationKey = "tag"
 	RelationKeyCamera                    domain.RelationKey = "camera"
@@ -187,6 +187,28 @@ const (
 	RelationKeySyncStatus                domain.RelationKey = "syncStatus"
 	RelationKeySyncDate                  domain.RelationKey = "syncDate"
 	RelationKeySyncError                 domain.RelationKey = "syncError"
+	RelationKeyTemplateCounter           domain.RelationKey = "templateCounter"
+	RelationKeyTemplateParent            domain.RelationKey = "templateParent"
+	RelationKeyMediaDuration             domain.RelationKey = "mediaDuration"
+	RelationKeyMediaCodec                domain.RelationKey = "mediaCodec"
+	RelationKeyMediaBitrate              domain.RelationKey = "mediaBitrate"
+	RelationKeyPdfPageCount              domain.RelationKey = "pdfPageCount"
+	RelationKeyPdfTitle                  domain.RelationKey = "pdfTitle"
+	RelationKeyPdfAuthor                 domain.RelationKey = "pdfAuthor"
+	RelationKeyFileKeepOffline           domain.RelationKey = "fileKeepOffline"
+	RelationKeyIsLockedForWriters        domain.RelationKey = "isLockedForWriters"
+	RelationKeyIsHiddenFromReaders       domain.RelationKey = "isHiddenFromReaders"
+	RelationKeyAutomationRule            domain.RelationKey = "automationRule"
+	RelationKeyAutomationDisabled        domain.RelationKey = "automationDisabled"
+	RelationKeyAutomationDevice          domain.RelationKey = "automationDevice"
+	RelationKeyAutomationState           domain.RelationKey = "automationState"
+	RelationKeyMessageFrom               domain.RelationKey = "messageFrom"
+	RelationKeyMessageTo                 domain.RelationKey = "messageTo"
+	RelationKeyMessageCc                 domain.RelationKey = "messageCc"
+	RelationKeyMessageDate               domain.RelationKey = "messageDate"
+	RelationKeyMessageId                 domain.RelationKey = "messageId"
+	RelationKeyMessageThreadId           domain.RelationKey = "messageThreadId"
+	RelationKeyMessageReplyTo            domain.RelationKey = "messageReplyTo"
 )
 
 var (
@@ -362,6 +384,61 @@ var (
 			Revision:         1,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyAutomationDevice: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Device which executes the automation rule",
+			Format:           model.RelationFormat_shorttext,
+			Hidden:           true,
+			Id:               "_brautomationDevice",
+			Key:              "automationDevice",
+			MaxCount:         1,
+			Name:             "Automation device",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyAutomationDisabled: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Automation rule is not executed",
+			Format:           model.RelationFormat_checkbox,
+			Id:               "_brautomationDisabled",
+			Key:              "automationDisabled",
+			MaxCount:         1,
+			Name:             "Disabled",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyAutomationRule: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Trigger, conditions and actions of the automation rule",
+			Format:           model.RelationFormat_longtext,
+			Hidden:           true,
+			Id:               "_brautomationRule",
+			Key:              "automationRule",
+			MaxCount:         1,
+			Name:             "Automation rule",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyAutomationState: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Execution state of the automation rule, it prevents repeated runs",
+			Format:           model.RelationFormat_longtext,
+			Hidden:           true,
+			Id:               "_brautomationState",
+			Key:              "automationState",
+			MaxCount:         1,
+			Name:             "Automation state",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyBacklinks: {
 
 			DataSource:       model.Relation_local,
@@ -797,6 +874,19 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyFileKeepOffline: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Keep file available offline. Storage policies never offload such files",
+			Format:           model.RelationFormat_checkbox,
+			Id:               "_brfileKeepOffline",
+			Key:              "fileKeepOffline",
+			MaxCount:         1,
+			Name:             "Keep offline",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyFileMimeType: {
 
 			DataSource:       model.Relation_details,
@@ -1176,6 +1266,20 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyIsHiddenFromReaders: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Object is hidden from readers of the shared space",
+			Format:           model.RelationFormat_checkbox,
+			Hidden:           true,
+			Id:               "_brisHiddenFromReaders",
+			Key:              "isHiddenFromReaders",
+			MaxCount:         1,
+			Name:             "Hidden from readers",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyIsHighlighted: {
 
 			DataSource:       model.Relation_account,
@@ -1190,6 +1294,20 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyIsLockedForWriters: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Object is read-only for writers of the shared space, only owners can edit it",
+			Format:           model.RelationFormat_checkbox,
+			Hidden:           true,
+			Id:               "_brisLockedForWriters",
+			Key:              "isLockedForWriters",
+			MaxCount:         1,
+			Name:             "Locked for writers",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyIsReadonly: {
 
 			DataSource:       model.Relation_derived,
@@ -1447,6 +1565,138 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyMediaBitrate: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Bitrate of audio or video in bits per second",
+			Format:           model.RelationFormat_number,
+			Id:               "_brmediaBitrate",
+			Key:              "mediaBitrate",
+			MaxCount:         1,
+			Name:             "Bitrate",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMediaCodec: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Codecs of audio or video streams",
+			Format:           model.RelationFormat_longtext,
+			Id:               "_brmediaCodec",
+			Key:              "mediaCodec",
+			MaxCount:         1,
+			Name:             "Codec",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMediaDuration: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Duration of audio or video in seconds",
+			Format:           model.RelationFormat_number,
+			Id:               "_brmediaDuration",
+			Key:              "mediaDuration",
+			MaxCount:         1,
+			Name:             "Duration",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageCc: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Carbon copy recipients of the message",
+			Format:           model.RelationFormat_longtext,
+			Id:               "_brmessageCc",
+			Key:              "messageCc",
+			MaxCount:         1,
+			Name:             "Cc",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageDate: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Date when the message was sent",
+			Format:           model.RelationFormat_date,
+			Id:               "_brmessageDate",
+			Key:              "messageDate",
+			MaxCount:         1,
+			Name:             "Sent",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageFrom: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Sender of the message",
+			Format:           model.RelationFormat_shorttext,
+			Id:               "_brmessageFrom",
+			Key:              "messageFrom",
+			MaxCount:         1,
+			Name:             "From",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageId: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Message-ID header of the message, used to skip messages that are already imported",
+			Format:           model.RelationFormat_shorttext,
+			Hidden:           true,
+			Id:               "_brmessageId",
+			Key:              "messageId",
+			MaxCount:         1,
+			Name:             "Message ID",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageReplyTo: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Message this message replies to",
+			Format:           model.RelationFormat_object,
+			Id:               "_brmessageReplyTo",
+			Key:              "messageReplyTo",
+			MaxCount:         1,
+			Name:             "In reply to",
+			ObjectTypes:      []string{TypePrefix + "message"},
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageThreadId: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Message-ID of the first message of the conversation, messages of the same conversation share it",
+			Format:           model.RelationFormat_shorttext,
+			Id:               "_brmessageThreadId",
+			Key:              "messageThreadId",
+			MaxCount:         1,
+			Name:             "Thread",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyMessageTo: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Recipients of the message",
+			Format:           model.RelationFormat_longtext,
+			Id:               "_brmessageTo",
+			Key:              "messageTo",
+			MaxCount:         1,
+			Name:             "To",
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyMood: {
 
 			DataSource:       model.Relation_details,
@@ -1554,6 +1804,45 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyPdfAuthor: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Author from PDF document information",
+			Format:           model.RelationFormat_longtext,
+			Id:               "_brpdfAuthor",
+			Key:              "pdfAuthor",
+			MaxCount:         1,
+			Name:             "Document author",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyPdfPageCount: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Number of pages in PDF document",
+			Format:           model.RelationFormat_number,
+			Id:               "_brpdfPageCount",
+			Key:              "pdfPageCount",
+			MaxCount:         1,
+			Name:             "Pages",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
+		RelationKeyPdfTitle: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Title from PDF document information",
+			Format:           model.RelationFormat_longtext,
+			Id:               "_brpdfTitle",
+			Key:              "pdfTitle",
+			MaxCount:         1,
+			Name:             "Document title",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyPhone: {
 
 			DataSource:       model.Relation_details,
@@ -2361,6 +2650,20 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyTemplateCounter: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Value of the {{counter}} template variable: the last one for object types and the assigned one for objects",
+			Format:           model.RelationFormat_number,
+			Hidden:           true,
+			Id:               "_brtemplateCounter",
+			Key:              "templateCounter",
+			MaxCount:         1,
+			Name:             "Template counter",
+			ReadOnly:         true,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyTemplateIsBundled: {
 
 			DataSource:       model.Relation_derived,
@@ -2375,6 +2678,21 @@ var (
 			ReadOnlyRelation: true,
 			Scope:            model.Relation_type,
 		},
+		RelationKeyTemplateParent: {
+
+			DataSource:       model.Relation_details,
+			Description:      "Template that is extended by this template. Blocks of the parent template are replaced by blocks of this template with the same section",
+			Format:           model.RelationFormat_object,
+			Hidden:           true,
+			Id:               "_brtemplateParent",
+			Key:              "templateParent",
+			MaxCount:         1,
+			Name:             "Parent template",
+			ObjectTypes:      []string{TypePrefix + "template"},
+			ReadOnly:         false,
+			ReadOnlyRelation: true,
+			Scope:            model.Relation_type,
+		},
 		RelationKeyThumbnailImage: {
 
 			DataSource:       model.Relation_details,
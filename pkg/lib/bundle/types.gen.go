@@ -9,7 +9,7 @@ import (
 	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 )
 
-const TypeChecksum = "fea3b06c998f4c974f06b3b0714b26dd2c5a7fa35ce9dc8b8f17d7c619215f7b"
+const TypeChecksum = "4e4f2b0c5d3cfd4ad506a3b7d0e3a9f25b42edb1225f5758db27f6d3ee79841c"
 const (
 	TypePrefix = "_ot"
 )
@@ -48,6 +48,9 @@ const (
 	TypeKeyDocument       domain.TypeKey = "document"
 	TypeKeyFile           domain.TypeKey = "file"
 	TypeKeyProject        domain.TypeKey = "project"
+	TypeKeyAutomation     domain.TypeKey = "automation"
+	TypeKeyScript         domain.TypeKey = "script"
+	TypeKeyMessage        domain.TypeKey = "message"
 )
 
 var (
@@ -59,11 +62,22 @@ var (
 			Layout:                 model.ObjectType_file,
 			Name:                   "Audio",
 			Readonly:               true,
-			RelationLinks:          []*model.RelationLink{MustGetRelationLink(RelationKeyArtist), MustGetRelationLink(RelationKeyAudioAlbum), MustGetRelationLink(RelationKeyAudioAlbumTrackNumber), MustGetRelationLink(RelationKeyAudioGenre), MustGetRelationLink(RelationKeyReleasedYear), MustGetRelationLink(RelationKeyThumbnailImage), MustGetRelationLink(RelationKeyComposer), MustGetRelationLink(RelationKeySizeInBytes), MustGetRelationLink(RelationKeyFileMimeType), MustGetRelationLink(RelationKeyAddedDate), MustGetRelationLink(RelationKeyFileExt), MustGetRelationLink(RelationKeyAudioArtist), MustGetRelationLink(RelationKeyAudioLyrics)},
+			RelationLinks:          []*model.RelationLink{MustGetRelationLink(RelationKeyArtist), MustGetRelationLink(RelationKeyAudioAlbum), MustGetRelationLink(RelationKeyAudioAlbumTrackNumber), MustGetRelationLink(RelationKeyAudioGenre), MustGetRelationLink(RelationKeyReleasedYear), MustGetRelationLink(RelationKeyThumbnailImage), MustGetRelationLink(RelationKeyComposer), MustGetRelationLink(RelationKeySizeInBytes), MustGetRelationLink(RelationKeyFileMimeType), MustGetRelationLink(RelationKeyAddedDate), MustGetRelationLink(RelationKeyFileExt), MustGetRelationLink(RelationKeyAudioArtist), MustGetRelationLink(RelationKeyAudioLyrics), MustGetRelationLink(RelationKeyMediaDuration), MustGetRelationLink(RelationKeyMediaCodec), MustGetRelationLink(RelationKeyMediaBitrate)},
 			RestrictObjectCreation: true,
 			Types:                  []model.SmartBlockType{model.SmartBlockType_File},
 			Url:                    TypePrefix + "audio",
 		},
+		TypeKeyAutomation: {
+
+			Description:   "Rule that runs actions when objects are created, changed, added to a collection or their date is reached",
+			IconEmoji:     "⚡",
+			Layout:        model.ObjectType_basic,
+			Name:          "Automation",
+			Readonly:      true,
+			RelationLinks: []*model.RelationLink{MustGetRelationLink(RelationKeyAutomationDisabled)},
+			Types:         []model.SmartBlockType{model.SmartBlockType_Page},
+			Url:           TypePrefix + "automation",
+		},
 		TypeKeyBook: {
 
 			Description:   "A book is a medium for recording information in the form of writing or images, typically composed of many pages bound together and protected by a cover",
@@ -243,6 +257,17 @@ var (
 			Types:                  []model.SmartBlockType{model.SmartBlockType_File},
 			Url:                    TypePrefix + "image",
 		},
+		TypeKeyMessage: {
+
+			Description:   "Email message imported from a mailbox, linked to the conversation it belongs to",
+			IconEmoji:     "✉️",
+			Layout:        model.ObjectType_basic,
+			Name:          "Message",
+			Readonly:      true,
+			RelationLinks: []*model.RelationLink{MustGetRelationLink(RelationKeyMessageFrom), MustGetRelationLink(RelationKeyMessageTo), MustGetRelationLink(RelationKeyMessageCc), MustGetRelationLink(RelationKeyMessageDate), MustGetRelationLink(RelationKeyMessageThreadId), MustGetRelationLink(RelationKeyMessageReplyTo)},
+			Types:         []model.SmartBlockType{model.SmartBlockType_Page},
+			Url:           TypePrefix + "message",
+		},
 		TypeKeyMovie: {
 
 			Description:   "Motion picture or Moving picture, is a work of visual art used to simulate experiences that communicate ideas, stories, perceptions, feelings, beauty, or atmosphere through the use of moving images",
@@ -356,6 +381,16 @@ var (
 			Types:       []model.SmartBlockType{model.SmartBlockType_SubObject},
 			Url:         TypePrefix + "relationOption",
 		},
+		TypeKeyScript: {
+
+			Description: "JavaScript code in code blocks, run on demand or by automations with access to objects of the space",
+			IconEmoji:   "📜",
+			Layout:      model.ObjectType_basic,
+			Name:        "Script",
+			Readonly:    true,
+			Types:       []model.SmartBlockType{model.SmartBlockType_Page},
+			Url:         TypePrefix + "script",
+		},
 		TypeKeySet: {
 
 			Description:   "Query all objects in your space based on types and relations",
@@ -422,7 +457,7 @@ var (
 			Layout:                 model.ObjectType_file,
 			Name:                   "Video",
 			Readonly:               true,
-			RelationLinks:          []*model.RelationLink{MustGetRelationLink(RelationKeySizeInBytes), MustGetRelationLink(RelationKeyFileMimeType), MustGetRelationLink(RelationKeyCamera), MustGetRelationLink(RelationKeyThumbnailImage), MustGetRelationLink(RelationKeyHeightInPixels), MustGetRelationLink(RelationKeyWidthInPixels), MustGetRelationLink(RelationKeyCameraIso), MustGetRelationLink(RelationKeyAperture), MustGetRelationLink(RelationKeyExposure), MustGetRelationLink(RelationKeyAddedDate), MustGetRelationLink(RelationKeyFileExt)},
+			RelationLinks:          []*model.RelationLink{MustGetRelationLink(RelationKeySizeInBytes), MustGetRelationLink(RelationKeyFileMimeType), MustGetRelationLink(RelationKeyCamera), MustGetRelationLink(RelationKeyThumbnailImage), MustGetRelationLink(RelationKeyHeightInPixels), MustGetRelationLink(RelationKeyWidthInPixels), MustGetRelationLink(RelationKeyCameraIso), MustGetRelationLink(RelationKeyAperture), MustGetRelationLink(RelationKeyExposure), MustGetRelationLink(RelationKeyAddedDate), MustGetRelationLink(RelationKeyFileExt), MustGetRelationLink(RelationKeyMediaDuration), MustGetRelationLink(RelationKeyMediaCodec), MustGetRelationLink(RelationKeyMediaBitrate)},
 			RestrictObjectCreation: true,
 			Types:                  []model.SmartBlockType{model.SmartBlockType_File},
 			Url:                    TypePrefix + "video",
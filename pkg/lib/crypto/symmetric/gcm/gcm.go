@@ -40,7 +40,8 @@ func (e *GCMEncryptDecryptor) EncryptReader(r io.Reader) (io.Reader, error) {
 	return bytes.NewReader(b), nil
 }
 
-// Decrypt uses key to perform AES-256 GCM decryption on ciphertext
+// Decrypt uses key to perform AES-256 GCM decryption on ciphertext.
+// The whole ciphertext is authenticated at once, so it is read and decrypted in memory
 func (e *GCMEncryptDecryptor) DecryptReader(r io.ReadSeeker) (symmetric.ReadSeekCloser, error) {
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
@@ -108,12 +108,44 @@ func NewFilters(qry Query, store ObjectStore) (filters *Filters, err error) {
 	if err != nil {
 		return
 	}
+	if hidden := hiddenObjectsFilter(store); hidden != nil {
+		filterObj = append(filterObj, hidden)
+	}
 
 	filters.FilterObj = filterObj
 	filters.Order = extractOrder(spaceID, qry.Sorts, store)
 	return
 }
 
+// readerSpacesProvider is implemented by the object store, it returns spaces where the account can't edit objects
+type readerSpacesProvider interface {
+	ReaderSpaceIds() []string
+}
+
+// hiddenObjectsFilter excludes objects hidden from readers in spaces where the account is a reader.
+// It is added to every query, so hidden objects don't appear in search, subscriptions and exports
+func hiddenObjectsFilter(store ObjectStore) Filter {
+	provider, ok := store.(readerSpacesProvider)
+	if !ok {
+		return nil
+	}
+	spaceIds := provider.ReaderSpaceIds()
+	if len(spaceIds) == 0 {
+		return nil
+	}
+	return FilterNot{FiltersAnd{
+		FilterEq{
+			Key:   bundle.RelationKeyIsHiddenFromReaders.String(),
+			Cond:  model.BlockContentDataviewFilter_Equal,
+			Value: pbtypes.Bool(true),
+		},
+		FilterIn{
+			Key:   bundle.RelationKeySpaceId.String(),
+			Value: pbtypes.StringList(spaceIds).GetListValue(),
+		},
+	}}
+}
+
 func getSpaceIDFromFilters(filters []*model.BlockContentDataviewFilter) string {
 	for _, f := range filters {
 		if f.RelationKey == bundle.RelationKeySpaceId.String() {
@@ -1,13 +1,17 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	goimage "image"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,8 +24,13 @@ import (
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/core/files"
 	"github.com/anyproto/anytype-heart/core/files/fileobject"
+	"github.com/anyproto/anytype-heart/core/files/storagepolicy"
 	"github.com/anyproto/anytype-heart/pb"
+	"github.com/anyproto/anytype-heart/pkg/lib/core"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
 	"github.com/anyproto/anytype-heart/pkg/lib/logging"
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/storage"
 	"github.com/anyproto/anytype-heart/util/netutil"
 )
 
@@ -31,10 +40,23 @@ const (
 	defaultPort    = 47800
 	getFileTimeout = 1 * time.Minute
 	requestLimit   = 32
+
+	// fileReadIdleTimeout limits the time without progress while streaming a file
+	fileReadIdleTimeout = 1 * time.Minute
+	// maxGCMFileBytes limits the size of files encrypted with AES-GCM, they are decrypted in memory as a whole
+	maxGCMFileBytes = 64 * 1024 * 1024
+
+	imageCacheDir      = "gateway-images"
+	imageCacheMaxBytes = 256 * 1024 * 1024
 )
 
 var log = logging.Logger("anytype-gateway")
 
+var (
+	errObjectHidden = errors.New("object is hidden from readers")
+	errFileTooLarge = errors.New("file encrypted with AES-GCM is too large to be served")
+)
+
 func New() Gateway {
 	return new(gateway)
 }
@@ -46,9 +68,18 @@ type Gateway interface {
 	app.ComponentStatable
 }
 
+// openedMarker updates the last opened date of files, so files accessed via gateway are not offloaded as not opened
+type openedMarker interface {
+	MarkOpened(objectId string)
+}
+
 type gateway struct {
 	fileService       files.Service
 	fileObjectService fileobject.Service
+	objectStore       objectstore.ObjectStore
+	accessStore       objectstore.AccessStore
+	openedMarker      openedMarker
+	imageCache        *imageCache
 	server            *http.Server
 	listener          net.Listener
 	handler           *http.ServeMux
@@ -75,6 +106,11 @@ func GatewayAddr() string {
 func (g *gateway) Init(a *app.App) (err error) {
 	g.fileService = app.MustComponent[files.Service](a)
 	g.fileObjectService = app.MustComponent[fileobject.Service](a)
+	g.objectStore = app.MustComponent[objectstore.ObjectStore](a)
+	g.accessStore = app.MustComponent[objectstore.AccessStore](a)
+	g.openedMarker, _ = a.Component(storagepolicy.CName).(openedMarker)
+	tempDir := app.MustComponent[core.TempDirProvider](a).TempDir()
+	g.imageCache = newImageCache(filepath.Join(tempDir, imageCacheDir), imageCacheMaxBytes)
 	g.addr = GatewayAddr()
 	log.Debugf("gateway.Init: %s", g.addr)
 	return nil
@@ -193,6 +229,8 @@ func (g *gateway) stopServer() error {
 func enableCors(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	w.Header().Set("Access-Control-Allow-Headers", "Range, If-None-Match, If-Range")
+	w.Header().Set("Access-Control-Expose-Headers", "Accept-Ranges, Content-Length, Content-Range, ETag")
 }
 
 func (g *gateway) readLimitCh() {
@@ -212,22 +250,46 @@ func (g *gateway) fileHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx, cancel := context.WithTimeout(r.Context(), getFileTimeout)
 	defer cancel()
-	file, reader, err := g.getFile(ctx, r)
+	file, err := g.getFile(ctx, r)
 	if err != nil {
 		log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error getting file: %s", err)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	// GCM is used only by legacy files, it authenticates the whole file at once, so it can't be decrypted by blocks
+	if info := file.Info(); info != nil && info.Key != "" && info.EncMode == storage.FileInfo_AES_GCM && file.Meta().Size > maxGCMFileBytes {
+		http.Error(w, errFileTooLarge.Error(), errorStatus(errFileTooLarge))
+		return
+	}
+	// reader is not bound to getFileTimeout, because streaming of large media could take much longer.
+	// Instead, the stream is canceled when it makes no progress. Blocks are fetched on demand, so only the requested range is downloaded
+	streamCtx, streamCancel := context.WithCancel(r.Context())
+	defer streamCancel()
+	reader, err := file.Reader(streamCtx)
+	if err != nil {
+		log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error getting file reader: %s", err)
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	idleReader := newIdleTimeoutReader(reader, fileReadIdleTimeout, streamCancel)
+	defer idleReader.Stop()
+
 	meta := file.Meta()
 	w.Header().Set("Content-Type", meta.Media)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", meta.Name))
+	// content is addressed by its hash, so it never changes for the same file
+	if info := file.Info(); info != nil && info.Hash != "" {
+		w.Header().Set("ETag", `"`+info.Hash+`"`)
+	}
 
-	// todo: inside textile it still requires the file to be fully downloaded and decrypted(consuming 2xSize in ram) to provide the ReadSeeker interface
-	// 	need to find a way to use ReadSeeker all the way from downloading files from IPFS to writing the decrypted chunk to the HTTP
-	http.ServeContent(w, r, meta.Name, meta.Added, reader)
+	// ServeContent handles Range, If-Range and If-None-Match headers
+	http.ServeContent(w, r, meta.Name, meta.Added, newLazySeekReader(idleReader, meta.Size))
 }
 
-func (g *gateway) getFile(ctx context.Context, r *http.Request) (files.File, io.ReadSeeker, error) {
+func (g *gateway) getFile(ctx context.Context, r *http.Request) (files.File, error) {
 	fileIdAndPath := strings.TrimPrefix(r.URL.Path, "/file/")
 	parts := strings.Split(fileIdAndPath, "/")
 	fileId := parts[0]
@@ -240,19 +302,17 @@ func (g *gateway) getFile(ctx context.Context, r *http.Request) (files.File, io.
 		}
 	} else {
 		var err error
-		id, err = g.fileObjectService.GetFileIdFromObjectWaitLoad(ctx, fileId)
+		id, err = g.getFileIdFromObject(ctx, fileId)
 		if err != nil {
-			return nil, nil, fmt.Errorf("get file hash from object id: %w", err)
+			return nil, fmt.Errorf("get file hash from object id: %w", err)
 		}
 	}
 
 	file, err := g.fileService.FileByHash(ctx, id)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get file by hash: %w", err)
+		return nil, fmt.Errorf("get file by hash: %w", err)
 	}
-
-	reader, err := file.Reader(ctx)
-	return file, reader, err
+	return file, nil
 }
 
 // imageHandler gets image meta from the DB, gets the corresponding data from the IPFS and decrypts it
@@ -269,10 +329,20 @@ func (g *gateway) imageHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), getFileTimeout)
 	defer cancel()
 
+	transformOpts, transform, err := parseImageTransformOpts(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if transform {
+		g.serveTransformedImage(ctx, w, r, transformOpts)
+		return
+	}
+
 	file, reader, err := g.getImage(ctx, r)
 	if err != nil {
 		log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error getting image: %s", err)
-		http.Error(w, err.Error(), 500)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -286,23 +356,63 @@ func (g *gateway) imageHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (g *gateway) getImage(ctx context.Context, r *http.Request) (files.File, io.ReadSeeker, error) {
+	id, err := g.getImageFileId(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g.getImageFile(ctx, id, func(image files.Image) (files.File, error) {
+		return selectImageFile(image, r)
+	})
+}
+
+func (g *gateway) getImageFileId(ctx context.Context, r *http.Request) (domain.FullFileId, error) {
 	urlParts := strings.Split(r.URL.Path, "/")
 	imageId := urlParts[2]
 
-	var id domain.FullFileId
 	// Treat id as fileId. We need to handle raw fileIds for backward compatibility in case of spaceview. See editor.SpaceView for details.
 	if domain.IsFileId(imageId) {
-		id = domain.FullFileId{
+		return domain.FullFileId{
 			FileId: domain.FileId(imageId),
-		}
-	} else {
-		var err error
-		id, err = g.fileObjectService.GetFileIdFromObjectWaitLoad(ctx, imageId)
-		if err != nil {
-			return nil, nil, fmt.Errorf("get file hash from object id: %w", err)
-		}
+		}, nil
 	}
+	id, err := g.getFileIdFromObject(ctx, imageId)
+	if err != nil {
+		return domain.FullFileId{}, fmt.Errorf("get file hash from object id: %w", err)
+	}
+	return id, nil
+}
+
+// getFileIdFromObject resolves the file of the file object. Objects hidden from the account are not served,
+// they are checked after loading, so the details of just loaded objects are indexed
+func (g *gateway) getFileIdFromObject(ctx context.Context, objectId string) (domain.FullFileId, error) {
+	id, err := g.fileObjectService.GetFileIdFromObjectWaitLoad(ctx, objectId)
+	if err != nil {
+		return domain.FullFileId{}, err
+	}
+	details, err := g.objectStore.GetDetails(objectId)
+	if err != nil {
+		return domain.FullFileId{}, fmt.Errorf("get details: %w", err)
+	}
+	if g.accessStore.IsHidden(details.GetDetails()) {
+		return domain.FullFileId{}, errObjectHidden
+	}
+	if g.openedMarker != nil {
+		g.openedMarker.MarkOpened(objectId)
+	}
+	return id, nil
+}
 
+func errorStatus(err error) int {
+	if errors.Is(err, errObjectHidden) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, errFileTooLarge) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+func (g *gateway) getImageFile(ctx context.Context, id domain.FullFileId, selectFile func(image files.Image) (files.File, error)) (files.File, io.ReadSeeker, error) {
 	retryOptions := []retry.Option{
 		retry.Context(ctx),
 		retry.Attempts(0),
@@ -313,7 +423,7 @@ func (g *gateway) getImage(ctx context.Context, r *http.Request) (files.File, io
 	}
 
 	result, err := retry.DoWithData(func() (*getImageReaderResult, error) {
-		res, err := g.getImageReader(ctx, id, r)
+		res, err := g.getImageReader(ctx, id, selectFile)
 		if err != nil {
 			return nil, err
 		}
@@ -362,34 +472,137 @@ func (r *retryReadSeeker) Seek(offset int64, whence int) (int64, error) {
 	}, r.options...)
 }
 
-func (g *gateway) getImageReader(ctx context.Context, id domain.FullFileId, req *http.Request) (*getImageReaderResult, error) {
+func (g *gateway) getImageReader(ctx context.Context, id domain.FullFileId, selectFile func(image files.Image) (files.File, error)) (*getImageReaderResult, error) {
 	image, err := g.fileService.ImageByHash(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get image by hash: %w", err)
 	}
-	var file files.File
-	query := req.URL.Query()
-	wantWidthStr := query.Get("width")
+	file, err := selectFile(image)
+	if err != nil {
+		return nil, fmt.Errorf("get image file: %w", err)
+	}
+	reader, err := file.Reader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get image reader: %w", err)
+	}
+	return &getImageReaderResult{file: file, reader: reader}, nil
+}
+
+func selectImageFile(image files.Image, req *http.Request) (files.File, error) {
+	wantWidthStr := req.URL.Query().Get("width")
 	if wantWidthStr == "" {
-		file, err = image.GetOriginalFile()
-		if err != nil {
-			return nil, fmt.Errorf("get image file: %w", err)
+		return image.GetOriginalFile()
+	}
+	wantWidth, err := strconv.Atoi(wantWidthStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse width: %w", err)
+	}
+	return image.GetFileForWidth(wantWidth)
+}
+
+// parseImageTransformOpts parses transformation query parameters. Request with only width is served
+// from the nearest stored variant without transformation, for backward compatibility
+func parseImageTransformOpts(query url.Values) (opts mill.ImageTransformOpts, transform bool, err error) {
+	parseInt := func(key string) (int, error) {
+		value := query.Get(key)
+		if value == "" {
+			return 0, nil
 		}
-	} else {
-		wantWidth, err := strconv.Atoi(wantWidthStr)
+		transform = transform || key != "width"
+		n, err := strconv.Atoi(value)
 		if err != nil {
-			return nil, fmt.Errorf("parse width: %w", err)
+			return 0, fmt.Errorf("parse %s: %w", key, err)
 		}
-		file, err = image.GetFileForWidth(wantWidth)
-		if err != nil {
-			return nil, fmt.Errorf("get image file: %w", err)
+		return n, nil
+	}
+	if opts.Width, err = parseInt("width"); err != nil {
+		return opts, false, err
+	}
+	if opts.Height, err = parseInt("height"); err != nil {
+		return opts, false, err
+	}
+	if opts.Quality, err = parseInt("quality"); err != nil {
+		return opts, false, err
+	}
+	if fit := query.Get("fit"); fit != "" {
+		opts.Fit = mill.Fit(fit)
+		transform = true
+	}
+	if format := query.Get("format"); format != "" {
+		opts.Format = mill.Format(strings.ToLower(format))
+		if opts.Format == "jpg" {
+			opts.Format = mill.JPEG
 		}
+		transform = true
 	}
-	reader, err := file.Reader(ctx)
+	if crop := query.Get("crop"); crop != "" {
+		// crop=x,y,width,height
+		parts := strings.Split(crop, ",")
+		if len(parts) != 4 {
+			return opts, false, fmt.Errorf("parse crop: expected x,y,width,height")
+		}
+		var values [4]int
+		for i, part := range parts {
+			if values[i], err = strconv.Atoi(strings.TrimSpace(part)); err != nil {
+				return opts, false, fmt.Errorf("parse crop: %w", err)
+			}
+		}
+		opts.Crop = goimage.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3])
+		transform = true
+	}
+	if !transform {
+		return opts, false, nil
+	}
+	return opts, true, opts.Validate()
+}
+
+// serveTransformedImage serves the image transformed on the fly. Results are cached on disk, so repeated requests
+// don't decode the image again
+func (g *gateway) serveTransformedImage(ctx context.Context, w http.ResponseWriter, r *http.Request, opts mill.ImageTransformOpts) {
+	id, err := g.getImageFileId(ctx, r)
 	if err != nil {
-		return nil, fmt.Errorf("get image reader: %w", err)
+		log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error getting image: %s", err)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
 	}
-	return &getImageReaderResult{file: file, reader: reader}, nil
+
+	cacheKey := imageCacheKey(id.FileId.String(), opts)
+	data, format, ok := g.imageCache.Get(cacheKey)
+	if !ok {
+		file, reader, err := g.getImageFile(ctx, id, func(image files.Image) (files.File, error) {
+			// crop coordinates and cover boxes are relative to the original, so only plain downscaling could use a smaller variant
+			if opts.Crop.Empty() && opts.Height == 0 && opts.Width > 0 {
+				return image.GetFileForWidth(opts.Width)
+			}
+			return image.GetOriginalFile()
+		})
+		if err != nil {
+			log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error getting image: %s", err)
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		data, format, err = mill.TransformImage(reader, opts)
+		if errors.Is(err, mill.ErrInvalidTransform) || errors.Is(err, mill.ErrFormatSupportNotEnabled) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, mill.ErrImageTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			log.With("path", cleanUpPathForLogging(r.URL.Path)).Errorf("error transforming image %s: %s", file.Meta().Media, err)
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if err = g.imageCache.Put(cacheKey, data, format); err != nil {
+			log.Warnf("cache transformed image: %s", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/"+string(format))
+	w.Header().Set("Content-Disposition", "inline")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
 }
 
 func cleanUpPathForLogging(input string) string {
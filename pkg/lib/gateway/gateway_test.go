@@ -1,10 +1,15 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	goimage "image"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -19,7 +24,12 @@ import (
 	"github.com/anyproto/anytype-heart/core/files"
 	"github.com/anyproto/anytype-heart/core/files/fileobject/mock_fileobject"
 	"github.com/anyproto/anytype-heart/core/files/mock_files"
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/storage"
 	"github.com/anyproto/anytype-heart/tests/testutil"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
 )
 
 func TestCleanUpPathForLogging(t *testing.T) {
@@ -41,6 +51,35 @@ func TestCleanUpPathForLogging(t *testing.T) {
 	})
 }
 
+func TestHiddenObject(t *testing.T) {
+	// given
+	fx := newFixture(t)
+	const fileObjectId = "fileObjectId"
+	fullFileId := domain.FullFileId{
+		SpaceId: "space1",
+		FileId:  "fileId1",
+	}
+	fx.objectStore.AddObjects(t, []objectstore.TestObject{{
+		bundle.RelationKeyId:                  pbtypes.String(fileObjectId),
+		bundle.RelationKeySpaceId:             pbtypes.String("space1"),
+		bundle.RelationKeyIsHiddenFromReaders: pbtypes.Bool(true),
+	}})
+	require.NoError(t, fx.objectStore.SetReaderSpace("space1", true))
+	fx.fileObjectService.EXPECT().GetFileIdFromObjectWaitLoad(mock.Anything, fileObjectId).Return(fullFileId, nil)
+
+	for _, path := range []string{"/file/", "/image/"} {
+		t.Run(path, func(t *testing.T) {
+			// when
+			resp, err := http.Get("http://" + fx.Addr() + path + fileObjectId)
+
+			// then
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	}
+}
+
 func TestGetImage(t *testing.T) {
 	t.Run("file object id is provided", func(t *testing.T) {
 		fx := newFixture(t)
@@ -81,12 +120,159 @@ func TestGetImage(t *testing.T) {
 	})
 }
 
+func TestGetTransformedImage(t *testing.T) {
+	fx := newFixture(t)
+
+	const fileObjectId = "fileObjectId"
+	fullFileId := domain.FullFileId{
+		SpaceId: "space1",
+		FileId:  "fileId1",
+	}
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, png.Encode(buf, goimage.NewGray(goimage.Rect(0, 0, 200, 100))))
+	imageData := buf.Bytes()
+
+	fx.fileObjectService.EXPECT().GetFileIdFromObjectWaitLoad(mock.Anything, fileObjectId).Return(fullFileId, nil)
+	file := mock_files.NewMockFile(t)
+	file.EXPECT().Reader(mock.Anything).Return(bytes.NewReader(imageData), nil).Once()
+	image := mock_files.NewMockImage(t)
+	image.EXPECT().GetOriginalFile().Return(file, nil).Once()
+	fx.fileService.EXPECT().ImageByHash(mock.Anything, fullFileId).Return(image, nil).Once()
+
+	get := func(t *testing.T, query string) *http.Response {
+		resp, err := http.Get("http://" + fx.Addr() + "/image/" + fileObjectId + "?" + query)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			resp.Body.Close()
+		})
+		return resp
+	}
+
+	t.Run("crop and convert", func(t *testing.T) {
+		resp := get(t, "crop=10,10,50,20&format=jpeg&quality=80")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+
+		cfg, err := jpeg.DecodeConfig(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, 50, cfg.Width)
+		assert.Equal(t, 20, cfg.Height)
+	})
+
+	t.Run("second request is served from cache", func(t *testing.T) {
+		resp := get(t, "crop=10,10,50,20&format=jpeg&quality=80")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		resp := get(t, "fit=stretch&width=10&height=10")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestParseImageTransformOpts(t *testing.T) {
+	t.Run("only width is not a transformation", func(t *testing.T) {
+		_, transform, err := parseImageTransformOpts(url.Values{"width": {"100"}})
+		require.NoError(t, err)
+		assert.False(t, transform)
+	})
+
+	t.Run("all parameters", func(t *testing.T) {
+		opts, transform, err := parseImageTransformOpts(url.Values{
+			"width":   {"100"},
+			"height":  {"50"},
+			"fit":     {"cover"},
+			"format":  {"jpg"},
+			"quality": {"70"},
+			"crop":    {"1,2,30,40"},
+		})
+		require.NoError(t, err)
+		assert.True(t, transform)
+		assert.Equal(t, mill.ImageTransformOpts{
+			Crop:    goimage.Rect(1, 2, 31, 42),
+			Width:   100,
+			Height:  50,
+			Fit:     mill.FitCover,
+			Format:  mill.JPEG,
+			Quality: 70,
+		}, opts)
+	})
+
+	t.Run("malformed crop", func(t *testing.T) {
+		_, _, err := parseImageTransformOpts(url.Values{"crop": {"1,2,3"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetFileRange(t *testing.T) {
+	fx := newFixture(t)
+
+	const fileData = "0123456789"
+	const fileObjectId = "fileObjectId"
+	fullFileId := domain.FullFileId{
+		SpaceId: "space1",
+		FileId:  "fileId1",
+	}
+
+	fx.fileObjectService.EXPECT().GetFileIdFromObjectWaitLoad(mock.Anything, fileObjectId).Return(fullFileId, nil)
+	file := mock_files.NewMockFile(t)
+	file.EXPECT().Reader(mock.Anything).Return(strings.NewReader(fileData), nil)
+	file.EXPECT().Meta().Return(&files.FileMeta{
+		Media: "video/mp4",
+		Name:  "recording.mp4",
+		Size:  int64(len(fileData)),
+	})
+	file.EXPECT().Info().Return(&storage.FileInfo{Hash: "contentHash"})
+	fx.fileService.EXPECT().FileByHash(mock.Anything, fullFileId).Return(file, nil)
+
+	get := func(t *testing.T, header http.Header) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, "http://"+fx.Addr()+"/file/"+fileObjectId, nil)
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			resp.Body.Close()
+		})
+		return resp
+	}
+
+	t.Run("range", func(t *testing.T) {
+		resp := get(t, http.Header{"Range": {"bytes=2-5"}})
+
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "bytes 2-5/10", resp.Header.Get("Content-Range"))
+		assert.Equal(t, `"contentHash"`, resp.Header.Get("ETag"))
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "2345", string(data))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		resp := get(t, http.Header{"If-None-Match": {`"contentHash"`}})
+
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+}
+
 type fixture struct {
 	*gateway
 	fileService       *mock_files.MockService
 	fileObjectService *mock_fileobject.MockService
+	objectStore       *objectstore.StoreFixture
+}
+
+type tempDirProvider struct {
+	dir string
 }
 
+func (p *tempDirProvider) Init(*app.App) error { return nil }
+
+func (p *tempDirProvider) Name() string { return "tempDirProvider" }
+
+func (p *tempDirProvider) TempDir() string { return p.dir }
+
 func newFixture(t *testing.T) *fixture {
 	a := new(app.App)
 
@@ -97,6 +283,9 @@ func newFixture(t *testing.T) *fixture {
 	ctx := context.Background()
 	a.Register(testutil.PrepareMock(ctx, a, fileService))
 	a.Register(testutil.PrepareMock(ctx, a, fileObjectService))
+	a.Register(&tempDirProvider{dir: t.TempDir()})
+	objectStore := objectstore.NewStoreFixture(t)
+	a.Register(objectStore)
 	a.Register(gw)
 	err := a.Start(ctx)
 	assert.NoError(t, err)
@@ -110,6 +299,7 @@ func newFixture(t *testing.T) *fixture {
 		gateway:           gw,
 		fileService:       fileService,
 		fileObjectService: fileObjectService,
+		objectStore:       objectStore,
 	}
 }
 
@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
+)
+
+// imageCache is an on-disk LRU cache of transformed images bounded by the total size of files.
+// Every entry is written to a file with a unique name, so files are read, written and removed without holding the lock.
+// Format of an entry is kept as the file extension, so the cache could be restored from the directory after restart
+type imageCache struct {
+	dir      string
+	maxBytes int64
+	loadOnce sync.Once
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type imageCacheEntry struct {
+	key  string
+	name string
+	size int64
+}
+
+func newImageCache(dir string, maxBytes int64) *imageCache {
+	return &imageCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func imageCacheKey(fileId string, opts mill.ImageTransformOpts) string {
+	sum := sha256.Sum256([]byte(fileId + "/" + opts.Key()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *imageCache) Get(key string) (data []byte, format mill.Format, ok bool) {
+	c.loadOnce.Do(c.load)
+
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, "", false
+	}
+	c.lru.MoveToFront(el)
+	name := el.Value.(*imageCacheEntry).name
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		// the entry could be evicted while the file was read
+		c.mu.Lock()
+		if c.entries[key] == el {
+			c.detach(el)
+		}
+		c.mu.Unlock()
+		if !os.IsNotExist(err) {
+			log.Warnf("read cached image: %s", err)
+			c.removeFiles([]string{name})
+		}
+		return nil, "", false
+	}
+	return data, mill.Format(strings.TrimPrefix(filepath.Ext(name), ".")), true
+}
+
+func (c *imageCache) Put(key string, data []byte, format mill.Format) error {
+	if int64(len(data)) > c.maxBytes {
+		return nil
+	}
+	c.loadOnce.Do(c.load)
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	// random suffix of the temp file makes the name unique, so the file of the replaced entry could be removed later
+	name := key + "-" + strings.TrimPrefix(filepath.Base(tmp.Name()), "tmp-") + "." + string(format)
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, name))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write cached image: %w", err)
+	}
+
+	c.mu.Lock()
+	var removed []string
+	if el, ok := c.entries[key]; ok {
+		removed = append(removed, c.detach(el))
+	}
+	c.entries[key] = c.lru.PushFront(&imageCacheEntry{key: key, name: name, size: int64(len(data))})
+	c.size += int64(len(data))
+	removed = append(removed, c.evict()...)
+	c.mu.Unlock()
+
+	c.removeFiles(removed)
+	return nil
+}
+
+// load restores entries left from the previous run, the most recently modified files are considered the most recently used
+func (c *imageCache) load() {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type fileInfo struct {
+		name    string
+		size    int64
+		modTime int64
+	}
+	var (
+		infos   []fileInfo
+		removed []string
+	)
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(name, "tmp-") {
+			removed = append(removed, name)
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, fileInfo{name: name, size: info.Size(), modTime: info.ModTime().UnixNano()})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].modTime > infos[j].modTime
+	})
+
+	c.mu.Lock()
+	for _, info := range infos {
+		key, _, _ := strings.Cut(strings.TrimSuffix(info.name, filepath.Ext(info.name)), "-")
+		if _, ok := c.entries[key]; ok {
+			// older file of the replaced entry
+			removed = append(removed, info.name)
+			continue
+		}
+		c.entries[key] = c.lru.PushBack(&imageCacheEntry{key: key, name: info.name, size: info.size})
+		c.size += info.size
+	}
+	removed = append(removed, c.evict()...)
+	c.mu.Unlock()
+
+	c.removeFiles(removed)
+}
+
+// evict detaches least recently used entries until the cache fits into maxBytes and returns names of their files
+func (c *imageCache) evict() (removed []string) {
+	for c.size > c.maxBytes {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+		removed = append(removed, c.detach(el))
+	}
+	return
+}
+
+// detach removes the entry from the index and returns name of its file, which should be removed after unlocking
+func (c *imageCache) detach(el *list.Element) (name string) {
+	entry := el.Value.(*imageCacheEntry)
+	c.lru.Remove(el)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	return entry.name
+}
+
+func (c *imageCache) removeFiles(names []string) {
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+			log.Warnf("remove cached image: %s", err)
+		}
+	}
+}
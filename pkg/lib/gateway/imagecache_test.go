@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/mill"
+)
+
+func TestImageCache(t *testing.T) {
+	t.Run("least recently used entries are evicted", func(t *testing.T) {
+		// given
+		cache := newImageCache(t.TempDir(), 10)
+		require.NoError(t, cache.Put("a", []byte("aaaa"), mill.PNG))
+		require.NoError(t, cache.Put("b", []byte("bbbb"), mill.JPEG))
+		_, _, ok := cache.Get("a")
+		require.True(t, ok)
+
+		// when
+		require.NoError(t, cache.Put("c", []byte("cccc"), mill.WEBP))
+
+		// then
+		_, _, ok = cache.Get("b")
+		assert.False(t, ok)
+		data, format, ok := cache.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "aaaa", string(data))
+		assert.Equal(t, mill.PNG, format)
+		_, format, ok = cache.Get("c")
+		assert.True(t, ok)
+		assert.Equal(t, mill.WEBP, format)
+	})
+
+	t.Run("entries are restored from disk", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		require.NoError(t, newImageCache(dir, 10).Put("a", []byte("aaaa"), mill.PNG))
+
+		// when
+		data, format, ok := newImageCache(dir, 10).Get("a")
+
+		// then
+		assert.True(t, ok)
+		assert.Equal(t, "aaaa", string(data))
+		assert.Equal(t, mill.PNG, format)
+	})
+
+	t.Run("too large entry is not cached", func(t *testing.T) {
+		cache := newImageCache(t.TempDir(), 2)
+		require.NoError(t, cache.Put("a", []byte("aaaa"), mill.PNG))
+		_, _, ok := cache.Get("a")
+		assert.False(t, ok)
+	})
+	t.Run("replaced entry removes the old file", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		cache := newImageCache(dir, 10)
+		require.NoError(t, cache.Put("a", []byte("aaaa"), mill.PNG))
+
+		// when
+		require.NoError(t, cache.Put("a", []byte("bb"), mill.JPEG))
+
+		// then
+		dirEntries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, dirEntries, 1)
+		data, format, ok := newImageCache(dir, 10).Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "bb", string(data))
+		assert.Equal(t, mill.JPEG, format)
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		// given
+		cache := newImageCache(t.TempDir(), 20)
+		var wg sync.WaitGroup
+
+		// when
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				key := fmt.Sprintf("k%d", i%3)
+				assert.NoError(t, cache.Put(key, []byte("data"), mill.PNG))
+				if data, _, ok := cache.Get(key); ok {
+					assert.Equal(t, "data", string(data))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		assert.LessOrEqual(t, cache.size, int64(20))
+		assert.Equal(t, len(cache.entries), cache.lru.Len())
+	})
+}
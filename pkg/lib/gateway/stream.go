@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// lazySeekReader postpones seeks of the underlying reader until the next Read.
+// http.ServeContent seeks to the end to find out the content size and then seeks back to the start of the range,
+// for DAG-backed readers every seek could fetch blocks from the network, so we answer the size from file info
+// and seek the underlying reader only once, right to the requested range
+type lazySeekReader struct {
+	reader io.ReadSeeker
+	size   int64
+
+	offset     int64
+	realOffset int64
+}
+
+func newLazySeekReader(reader io.ReadSeeker, size int64) io.ReadSeeker {
+	if size <= 0 {
+		return reader
+	}
+	return &lazySeekReader{reader: reader, size: size}
+}
+
+func (r *lazySeekReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.offset != r.realOffset {
+		offset, err := r.reader.Seek(r.offset, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+		r.realOffset = offset
+	}
+	n, err := r.reader.Read(p)
+	r.offset += int64(n)
+	r.realOffset = r.offset
+	return n, err
+}
+
+func (r *lazySeekReader) Seek(offset int64, whence int) (int64, error) {
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = offset
+	case io.SeekCurrent:
+		newOffset = r.offset + offset
+	case io.SeekEnd:
+		newOffset = r.size + offset
+	default:
+		return 0, fmt.Errorf("unrecognized whence: %d", whence)
+	}
+	if newOffset < 0 {
+		return 0, fmt.Errorf("negative offset: %d", newOffset)
+	}
+	r.offset = newOffset
+	return newOffset, nil
+}
+
+// idleTimeoutReader cancels the stream when no read is completed during the timeout,
+// e.g. when fetching of a block hangs or the client stopped reading paused media, so the request slot is released
+type idleTimeoutReader struct {
+	io.ReadSeeker
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func newIdleTimeoutReader(reader io.ReadSeeker, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutReader {
+	return &idleTimeoutReader{ReadSeeker: reader, timeout: timeout, timer: time.AfterFunc(timeout, cancel)}
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(p)
+	r.timer.Reset(r.timeout)
+	return n, err
+}
+
+func (r *idleTimeoutReader) Stop() {
+	r.timer.Stop()
+}
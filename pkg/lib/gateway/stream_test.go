@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingSeeker struct {
+	io.ReadSeeker
+	seeks int
+}
+
+func (c *countingSeeker) Seek(offset int64, whence int) (int64, error) {
+	c.seeks++
+	return c.ReadSeeker.Seek(offset, whence)
+}
+
+func TestLazySeekReader(t *testing.T) {
+	t.Run("size is answered without seeking underlying reader", func(t *testing.T) {
+		// given
+		underlying := &countingSeeker{ReadSeeker: strings.NewReader("0123456789")}
+		r := newLazySeekReader(underlying, 10)
+
+		// when
+		size, err := r.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		_, err = r.Seek(4, io.SeekStart)
+		require.NoError(t, err)
+		data, err := io.ReadAll(io.LimitReader(r, 3))
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, int64(10), size)
+		assert.Equal(t, "456", string(data))
+		assert.Equal(t, 1, underlying.seeks)
+	})
+
+	t.Run("sequential reads don't seek", func(t *testing.T) {
+		// given
+		underlying := &countingSeeker{ReadSeeker: strings.NewReader("0123456789")}
+		r := newLazySeekReader(underlying, 10)
+
+		// when
+		data, err := io.ReadAll(r)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(data))
+		assert.Equal(t, 0, underlying.seeks)
+	})
+
+	t.Run("unknown size falls back to underlying reader", func(t *testing.T) {
+		underlying := strings.NewReader("0123456789")
+		assert.Equal(t, io.ReadSeeker(underlying), newLazySeekReader(underlying, 0))
+	})
+}
+
+func TestIdleTimeoutReader(t *testing.T) {
+	t.Run("stream is canceled without reads", func(t *testing.T) {
+		// given
+		ctx, cancel := context.WithCancel(context.Background())
+		r := newIdleTimeoutReader(strings.NewReader("0123456789"), 10*time.Millisecond, cancel)
+		defer r.Stop()
+
+		// when
+		_, err := r.Read(make([]byte, 2))
+		require.NoError(t, err)
+
+		// then
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("stream is not canceled")
+		}
+	})
+
+	t.Run("stopped reader doesn't cancel stream", func(t *testing.T) {
+		// given
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := newIdleTimeoutReader(strings.NewReader("0123456789"), 10*time.Millisecond, cancel)
+
+		// when
+		r.Stop()
+		time.Sleep(20 * time.Millisecond)
+
+		// then
+		assert.NoError(t, ctx.Err())
+	})
+}
@@ -2,6 +2,7 @@ package filestore
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -33,6 +34,7 @@ var (
 	isImportedBase  = dsCtx.NewKey("/" + filesPrefix + "/is_imported")
 	fileOrigin      = dsCtx.NewKey("/" + filesPrefix + "/origin")
 	fileImportType  = dsCtx.NewKey("/" + filesPrefix + "/importType")
+	pdfTextBase     = dsCtx.NewKey("/" + filesPrefix + "/pdf_text")
 
 	indexMillSourceOpts = localstore.Index{
 		Prefix: filesPrefix,
@@ -114,6 +116,9 @@ type FileStore interface {
 	GetFileSize(fileId domain.FileId) (int, error)
 	GetFileOrigin(fileId domain.FileId) (objectorigin.ObjectOrigin, error)
 	SetFileOrigin(fileId domain.FileId, origin objectorigin.ObjectOrigin) error
+	// SetPdfText stores text of PDF pages extracted once when the file is indexed
+	SetPdfText(fileId domain.FileId, pages []string) error
+	GetPdfText(fileId domain.FileId) ([]string, error)
 }
 
 func New() FileStore {
@@ -480,7 +485,7 @@ func (s *dsFileStore) DeleteFile(fileId domain.FileId) error {
 		if err != nil {
 			return err
 		}
-		return nil
+		return txn.Delete(pdfTextBase.ChildString(fileId.String()).Bytes())
 	})
 }
 
@@ -581,6 +586,26 @@ func (s *dsFileStore) SetFileOrigin(fileId domain.FileId, origin objectorigin.Ob
 	return nil
 }
 
+func (s *dsFileStore) SetPdfText(fileId domain.FileId, pages []string) error {
+	raw, err := json.Marshal(pages)
+	if err != nil {
+		return fmt.Errorf("marshal pdf text: %w", err)
+	}
+	return s.updateTxn(func(txn *badger.Txn) error {
+		return badgerhelper.SetValueTxn(txn, pdfTextBase.ChildString(fileId.String()).Bytes(), raw)
+	})
+}
+
+func (s *dsFileStore) GetPdfText(fileId domain.FileId) ([]string, error) {
+	pages, err := badgerhelper.GetValue(s.db, pdfTextBase.ChildString(fileId.String()).Bytes(), func(raw []byte) (pages []string, err error) {
+		return pages, json.Unmarshal(raw, &pages)
+	})
+	if badgerhelper.IsNotFound(err) {
+		return nil, localstore.ErrNotFound
+	}
+	return pages, err
+}
+
 func (s *dsFileStore) Close(ctx context.Context) (err error) {
 	return nil
 }
@@ -87,6 +87,26 @@ func TestAddAndDeleteVariants(t *testing.T) {
 
 }
 
+func TestPdfText(t *testing.T) {
+	store := newFixture(t)
+
+	_, err := store.GetPdfText("fileId1")
+	require.ErrorIs(t, err, localstore.ErrNotFound)
+
+	err = store.SetPdfText("fileId1", []string{"first page", ""})
+	require.NoError(t, err)
+
+	pages, err := store.GetPdfText("fileId1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first page", ""}, pages)
+
+	err = store.DeleteFile("fileId1")
+	require.NoError(t, err)
+
+	_, err = store.GetPdfText("fileId1")
+	require.ErrorIs(t, err, localstore.ErrNotFound)
+}
+
 func TestConflictResolution(t *testing.T) {
 	t.Run("add same file concurrently", func(t *testing.T) {
 		store := newFixture(t)
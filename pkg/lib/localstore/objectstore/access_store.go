@@ -0,0 +1,84 @@
+package objectstore
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/samber/lo"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/util/badgerhelper"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+// AccessStore keeps spaces where the account can't edit objects. Objects hidden from readers of these spaces
+// are excluded from queries, so they don't appear in search, subscriptions and exports
+type AccessStore interface {
+	SetReaderSpace(spaceId string, isReader bool) error
+	ReaderSpaceIds() []string
+	// IsHidden reports whether the object is hidden from the account
+	IsHidden(details *types.Struct) bool
+}
+
+func (s *dsObjectStore) SetReaderSpace(spaceId string, isReader bool) error {
+	s.readerSpacesLock.Lock()
+	defer s.readerSpacesLock.Unlock()
+	if err := s.loadReaderSpaces(); err != nil {
+		return err
+	}
+	if _, ok := s.readerSpaces[spaceId]; ok == isReader {
+		return nil
+	}
+	key := readerSpaces.ChildString(spaceId).Bytes()
+	if isReader {
+		if err := badgerhelper.SetValue(s.db, key, nil); err != nil {
+			return fmt.Errorf("save reader space: %w", err)
+		}
+		s.readerSpaces[spaceId] = struct{}{}
+		return nil
+	}
+	if err := badgerhelper.DeleteValue(s.db, key); err != nil {
+		return fmt.Errorf("delete reader space: %w", err)
+	}
+	delete(s.readerSpaces, spaceId)
+	return nil
+}
+
+func (s *dsObjectStore) ReaderSpaceIds() []string {
+	s.readerSpacesLock.Lock()
+	defer s.readerSpacesLock.Unlock()
+	if err := s.loadReaderSpaces(); err != nil {
+		log.Errorf("load reader spaces: %v", err)
+	}
+	return lo.Keys(s.readerSpaces)
+}
+
+func (s *dsObjectStore) IsHidden(details *types.Struct) bool {
+	if !pbtypes.GetBool(details, bundle.RelationKeyIsHiddenFromReaders.String()) {
+		return false
+	}
+	s.readerSpacesLock.Lock()
+	defer s.readerSpacesLock.Unlock()
+	if err := s.loadReaderSpaces(); err != nil {
+		log.Errorf("load reader spaces: %v", err)
+	}
+	_, ok := s.readerSpaces[pbtypes.GetString(details, bundle.RelationKeySpaceId.String())]
+	return ok
+}
+
+// loadReaderSpaces reads the spaces on the first access, so queries filter hidden objects
+// even before the spaces are loaded. Use under readerSpacesLock
+func (s *dsObjectStore) loadReaderSpaces() error {
+	if s.readerSpaces != nil {
+		return nil
+	}
+	spaceIds := map[string]struct{}{}
+	err := iterateKeysByPrefix(s.db, readerSpaces.Bytes(), func(key []byte) {
+		spaceIds[extractIdFromKey(string(key))] = struct{}{}
+	})
+	if err != nil {
+		return fmt.Errorf("iterate reader spaces: %w", err)
+	}
+	s.readerSpaces = spaceIds
+	return nil
+}
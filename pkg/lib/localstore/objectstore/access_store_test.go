@@ -0,0 +1,65 @@
+package objectstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
+	"github.com/anyproto/anytype-heart/pkg/lib/database"
+	"github.com/anyproto/anytype-heart/util/pbtypes"
+)
+
+func TestAccessStore(t *testing.T) {
+	visible := TestObject{
+		bundle.RelationKeyId:      pbtypes.String("id1"),
+		bundle.RelationKeySpaceId: pbtypes.String("space1"),
+	}
+	hidden := TestObject{
+		bundle.RelationKeyId:                  pbtypes.String("id2"),
+		bundle.RelationKeySpaceId:             pbtypes.String("space1"),
+		bundle.RelationKeyIsHiddenFromReaders: pbtypes.Bool(true),
+	}
+	hiddenInOtherSpace := TestObject{
+		bundle.RelationKeyId:                  pbtypes.String("id3"),
+		bundle.RelationKeySpaceId:             pbtypes.String("space2"),
+		bundle.RelationKeyIsHiddenFromReaders: pbtypes.Bool(true),
+	}
+
+	t.Run("set and unset reader space", func(t *testing.T) {
+		s := NewStoreFixture(t)
+
+		require.NoError(t, s.SetReaderSpace("space1", true))
+		assert.Equal(t, []string{"space1"}, s.ReaderSpaceIds())
+
+		require.NoError(t, s.SetReaderSpace("space1", false))
+		assert.Empty(t, s.ReaderSpaceIds())
+	})
+
+	t.Run("hidden objects are not returned to readers", func(t *testing.T) {
+		s := NewStoreFixture(t)
+		s.AddObjects(t, []TestObject{visible, hidden, hiddenInOtherSpace})
+		require.NoError(t, s.SetReaderSpace("space1", true))
+
+		recs, err := s.Query(database.Query{})
+		require.NoError(t, err)
+		assertRecordsEqual(t, []TestObject{visible, hiddenInOtherSpace}, recs)
+
+		recs, err = s.QueryByID([]string{"id1", "id2"})
+		require.NoError(t, err)
+		assertRecordsEqual(t, []TestObject{visible}, recs)
+
+		assert.True(t, s.IsHidden(makeDetails(hidden)))
+		assert.False(t, s.IsHidden(makeDetails(hiddenInOtherSpace)))
+	})
+
+	t.Run("hidden objects are returned to editors", func(t *testing.T) {
+		s := NewStoreFixture(t)
+		s.AddObjects(t, []TestObject{visible, hidden})
+
+		recs, err := s.Query(database.Query{})
+		require.NoError(t, err)
+		assertRecordsEqual(t, []TestObject{visible, hidden}, recs)
+	})
+}
@@ -72,6 +72,9 @@ func (s *dsObjectStore) DeleteObject(id domain.FullID) error {
 		if err != nil {
 			return err
 		}
+		if err = updateSyncedBlockTargets(txn, id.ObjectID, nil); err != nil {
+			return err
+		}
 		err = txn.Commit()
 		if err != nil {
 			return fmt.Errorf("delete object info: %w", err)
@@ -1484,6 +1484,64 @@ func (_c *MockObjectStore_GetSpaceName_Call) RunAndReturn(run func(string) strin
 	return _c
 }
 
+// GetSyncedBlockReferences provides a mock function with given fields: targetId
+func (_m *MockObjectStore) GetSyncedBlockReferences(targetId string) ([]string, error) {
+	ret := _m.Called(targetId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSyncedBlockReferences")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(targetId)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(targetId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(targetId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockObjectStore_GetSyncedBlockReferences_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSyncedBlockReferences'
+type MockObjectStore_GetSyncedBlockReferences_Call struct {
+	*mock.Call
+}
+
+// GetSyncedBlockReferences is a helper method to define mock.On call
+//   - targetId string
+func (_e *MockObjectStore_Expecter) GetSyncedBlockReferences(targetId interface{}) *MockObjectStore_GetSyncedBlockReferences_Call {
+	return &MockObjectStore_GetSyncedBlockReferences_Call{Call: _e.mock.On("GetSyncedBlockReferences", targetId)}
+}
+
+func (_c *MockObjectStore_GetSyncedBlockReferences_Call) Run(run func(targetId string)) *MockObjectStore_GetSyncedBlockReferences_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockObjectStore_GetSyncedBlockReferences_Call) Return(_a0 []string, _a1 error) *MockObjectStore_GetSyncedBlockReferences_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockObjectStore_GetSyncedBlockReferences_Call) RunAndReturn(run func(string) ([]string, error)) *MockObjectStore_GetSyncedBlockReferences_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetUniqueKeyById provides a mock function with given fields: id
 func (_m *MockObjectStore) GetUniqueKeyById(id string) (domain.UniqueKey, error) {
 	ret := _m.Called(id)
@@ -2999,6 +3057,53 @@ func (_c *MockObjectStore_UpdateObjectSnippet_Call) RunAndReturn(run func(string
 	return _c
 }
 
+// UpdateSyncedBlockTargets provides a mock function with given fields: id, targetIds
+func (_m *MockObjectStore) UpdateSyncedBlockTargets(id string, targetIds []string) error {
+	ret := _m.Called(id, targetIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSyncedBlockTargets")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(id, targetIds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockObjectStore_UpdateSyncedBlockTargets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSyncedBlockTargets'
+type MockObjectStore_UpdateSyncedBlockTargets_Call struct {
+	*mock.Call
+}
+
+// UpdateSyncedBlockTargets is a helper method to define mock.On call
+//   - id string
+//   - targetIds []string
+func (_e *MockObjectStore_Expecter) UpdateSyncedBlockTargets(id interface{}, targetIds interface{}) *MockObjectStore_UpdateSyncedBlockTargets_Call {
+	return &MockObjectStore_UpdateSyncedBlockTargets_Call{Call: _e.mock.On("UpdateSyncedBlockTargets", id, targetIds)}
+}
+
+func (_c *MockObjectStore_UpdateSyncedBlockTargets_Call) Run(run func(id string, targetIds []string)) *MockObjectStore_UpdateSyncedBlockTargets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockObjectStore_UpdateSyncedBlockTargets_Call) Return(_a0 error) *MockObjectStore_UpdateSyncedBlockTargets_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockObjectStore_UpdateSyncedBlockTargets_Call) RunAndReturn(run func(string, []string) error) *MockObjectStore_UpdateSyncedBlockTargets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdatePendingLocalDetails provides a mock function with given fields: id, proc
 func (_m *MockObjectStore) UpdatePendingLocalDetails(id string, proc func(*types.Struct) (*types.Struct, error)) error {
 	ret := _m.Called(id, proc)
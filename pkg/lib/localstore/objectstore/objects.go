@@ -44,6 +44,8 @@ var (
 	pagesSnippetBase       = ds.NewKey("/" + pagesPrefix + "/snippet")
 	pagesInboundLinksBase  = ds.NewKey("/" + pagesPrefix + "/inbound")
 	pagesOutboundLinksBase = ds.NewKey("/" + pagesPrefix + "/outbound")
+	syncedInboundBase      = ds.NewKey("/" + pagesPrefix + "/synced/inbound")
+	syncedOutboundBase     = ds.NewKey("/" + pagesPrefix + "/synced/outbound")
 	indexQueueBase         = ds.NewKey("/" + pagesPrefix + "/index")
 	bundledChecksums       = ds.NewKey("/" + pagesPrefix + "/checksum")
 	indexedHeadsState      = ds.NewKey("/" + pagesPrefix + "/headsstate")
@@ -53,10 +55,12 @@ var (
 
 	spacePrefix   = "space"
 	virtualSpaces = ds.NewKey("/" + spacePrefix + "/virtual")
+	readerSpaces  = ds.NewKey("/" + spacePrefix + "/reader")
 
 	ErrObjectNotFound = errors.New("object not found")
 
 	_ ObjectStore = (*dsObjectStore)(nil)
+	_ AccessStore = (*dsObjectStore)(nil)
 )
 
 func New() ObjectStore {
@@ -127,6 +131,7 @@ type ObjectStore interface {
 	// set discardLocalDetailsChanges to true in case the caller doesn't have local details in the State
 	UpdateObjectDetails(id string, details *types.Struct) error
 	UpdateObjectLinks(id string, links []string) error
+	UpdateSyncedBlockTargets(id string, targetIds []string) error
 	UpdateObjectSnippet(id string, snippet string) error
 	UpdatePendingLocalDetails(id string, proc func(details *types.Struct) (*types.Struct, error)) error
 	ModifyObjectDetails(id string, proc func(details *types.Struct) (*types.Struct, error)) error
@@ -141,6 +146,7 @@ type ObjectStore interface {
 
 	GetInboundLinksByID(id string) ([]string, error)
 	GetOutboundLinksByID(id string) ([]string, error)
+	GetSyncedBlockReferences(targetId string) ([]string, error)
 	GetWithLinksInfoByID(spaceID string, id string) (*model.ObjectInfoWithLinks, error)
 
 	SetActiveView(objectId, blockId, viewId string) error
@@ -200,6 +206,9 @@ type dsObjectStore struct {
 	onChangeCallback      func(record database.Record)
 	subscriptions         []database.Subscription
 	onLinksUpdateCallback func(info LinksUpdateInfo)
+
+	readerSpacesLock sync.Mutex
+	readerSpaces     map[string]struct{}
 }
 
 func (s *dsObjectStore) Run(context.Context) (err error) {
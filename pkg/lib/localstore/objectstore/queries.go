@@ -464,6 +464,9 @@ func (s *dsObjectStore) QueryByID(ids []string) (records []database.Record, err
 				log.Errorf("QueryByIds failed to extract details: %s", id)
 				continue
 			}
+			if s.IsHidden(details.Details) {
+				continue
+			}
 			records = append(records, database.Record{Details: details.Details})
 		}
 		return nil
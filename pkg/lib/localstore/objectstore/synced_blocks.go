@@ -0,0 +1,58 @@
+package objectstore
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+	ds "github.com/ipfs/go-datastore"
+
+	"github.com/anyproto/anytype-heart/util/slice"
+)
+
+// UpdateSyncedBlockTargets saves ids of objects whose blocks are shown by synced blocks of the object
+func (s *dsObjectStore) UpdateSyncedBlockTargets(id string, targetIds []string) error {
+	return s.updateTxn(func(txn *badger.Txn) error {
+		return updateSyncedBlockTargets(txn, id, targetIds)
+	})
+}
+
+// GetSyncedBlockReferences returns ids of objects with synced blocks showing blocks of the target object
+func (s *dsObjectStore) GetSyncedBlockReferences(targetId string) ([]string, error) {
+	var ids []string
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		ids, err = listIDsByPrefix(txn, syncedInboundBase.ChildString(targetId).Bytes())
+		return err
+	})
+	return ids, err
+}
+
+func updateSyncedBlockTargets(txn *badger.Txn, id string, targetIds []string) error {
+	existing, err := listIDsByPrefix(txn, syncedOutboundBase.ChildString(id).Bytes())
+	if err != nil {
+		return fmt.Errorf("list synced block targets: %w", err)
+	}
+	removed, added := slice.DifferenceRemovedAdded(existing, targetIds)
+	for _, k := range syncedBlockKeys(id, added) {
+		if err = txn.Set(k.Bytes(), nil); err != nil {
+			return fmt.Errorf("set synced block target %s: %w", k, err)
+		}
+	}
+	for _, k := range syncedBlockKeys(id, removed) {
+		if err = txn.Delete(k.Bytes()); err != nil {
+			return fmt.Errorf("delete synced block target %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func syncedBlockKeys(id string, targetIds []string) []ds.Key {
+	keys := make([]ds.Key, 0, 2*len(targetIds))
+	for _, targetId := range targetIds {
+		keys = append(keys,
+			syncedOutboundBase.ChildString(id).ChildString(targetId),
+			syncedInboundBase.ChildString(targetId).ChildString(id),
+		)
+	}
+	return keys
+}
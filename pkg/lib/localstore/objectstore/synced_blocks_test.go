@@ -0,0 +1,51 @@
+package objectstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/core/domain"
+)
+
+func TestSyncedBlockReferences(t *testing.T) {
+	t.Run("references are added and removed", func(t *testing.T) {
+		// given
+		s := NewStoreFixture(t)
+		require.NoError(t, s.UpdateSyncedBlockTargets("id1", []string{"id2", "id3"}))
+		require.NoError(t, s.UpdateSyncedBlockTargets("id4", []string{"id2"}))
+
+		// when
+		err := s.UpdateSyncedBlockTargets("id1", []string{"id3"})
+
+		// then
+		require.NoError(t, err)
+		s.assertSyncedBlockReferences(t, "id2", []string{"id4"})
+		s.assertSyncedBlockReferences(t, "id3", []string{"id1"})
+	})
+
+	t.Run("references of deleted object are removed", func(t *testing.T) {
+		// given
+		s := NewStoreFixture(t)
+		s.AddObjects(t, []TestObject{makeObjectWithName("id1", "name1")})
+		require.NoError(t, s.UpdateSyncedBlockTargets("id1", []string{"id2"}))
+
+		// when
+		err := s.DeleteObject(domain.FullID{SpaceID: "space1", ObjectID: "id1"})
+
+		// then
+		require.NoError(t, err)
+		s.assertSyncedBlockReferences(t, "id2", nil)
+	})
+}
+
+func (fx *StoreFixture) assertSyncedBlockReferences(t *testing.T, id string, refs []string) {
+	got, err := fx.GetSyncedBlockReferences(id)
+	assert.NoError(t, err)
+	if len(refs) == 0 {
+		assert.Empty(t, got)
+		return
+	}
+	assert.Equal(t, refs, got)
+}
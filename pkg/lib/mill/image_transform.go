@@ -0,0 +1,184 @@
+package mill
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// Fit defines how image is placed into the box of both width and height
+type Fit string
+
+const (
+	// FitContain scales image to fit into the box, keeping aspect ratio. Result could be smaller than the box
+	FitContain Fit = "contain"
+	// FitCover scales image to cover the box, keeping aspect ratio, and crops the overflow around the center
+	FitCover Fit = "cover"
+	// FitFill stretches image to the box, ignoring aspect ratio
+	FitFill Fit = "fill"
+)
+
+const (
+	maxTransformSize = 8192
+	// maxTransformSourcePixels limits decoded size of the source, 64 megapixels take 256MB as RGBA
+	maxTransformSourcePixels = 64 * 1024 * 1024
+	defaultTransformQuality  = 85
+)
+
+var (
+	ErrInvalidTransform = errors.New("invalid image transformation")
+	ErrImageTooLarge    = errors.New("image is too large to transform")
+)
+
+// ImageTransformOpts describes transformation applied to the image. Zero values mean "keep as is"
+type ImageTransformOpts struct {
+	// Crop is applied first, in pixels of the source image after EXIF orientation is applied
+	Crop    image.Rectangle
+	Width   int
+	Height  int
+	Fit     Fit
+	Format  Format
+	Quality int
+}
+
+func (o ImageTransformOpts) Validate() error {
+	if o.Width < 0 || o.Height < 0 || o.Width > maxTransformSize || o.Height > maxTransformSize {
+		return fmt.Errorf("%w: size must be between 0 and %d", ErrInvalidTransform, maxTransformSize)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("%w: quality must be between 1 and 100", ErrInvalidTransform)
+	}
+	if o.Crop != (image.Rectangle{}) && (o.Crop.Empty() || o.Crop.Min.X < 0 || o.Crop.Min.Y < 0) {
+		return fmt.Errorf("%w: crop rectangle is empty", ErrInvalidTransform)
+	}
+	switch o.Fit {
+	case "", FitContain, FitCover, FitFill:
+	default:
+		return fmt.Errorf("%w: unknown fit %s", ErrInvalidTransform, o.Fit)
+	}
+	switch o.Format {
+	case "", JPEG, PNG, WEBP:
+	default:
+		return fmt.Errorf("%w: unsupported output format %s", ErrInvalidTransform, o.Format)
+	}
+	return nil
+}
+
+// Key returns string that identifies the transformation, e.g. to cache its results
+func (o ImageTransformOpts) Key() string {
+	return fmt.Sprintf("c%d,%d,%d,%d-w%d-h%d-f%s-t%s-q%d",
+		o.Crop.Min.X, o.Crop.Min.Y, o.Crop.Max.X, o.Crop.Max.Y, o.Width, o.Height, o.Fit, o.Format, o.Quality)
+}
+
+// TransformImage crops, resizes and converts the image. Images are never upscaled, for fill and cover fits the box
+// is shrunk to fit into the source keeping its aspect ratio. Sources larger than maxTransformSourcePixels are rejected
+// before decoding, only the header is read for them. Output format defaults to the source one, GIF and ICO become PNG
+func TransformImage(r io.ReadSeeker, opts ImageTransformOpts) (data []byte, format Format, err error) {
+	if err = opts.Validate(); err != nil {
+		return nil, "", err
+	}
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image config: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxTransformSourcePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+	img, formatStr, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image: %w", err)
+	}
+	format = Format(formatStr)
+	if format == JPEG {
+		if _, err = r.Seek(0, io.SeekStart); err != nil {
+			return nil, "", err
+		}
+		if exifData, err := getExifData(r); err == nil && exifData != nil {
+			if orientation, err := getJpegOrientation(exifData); err == nil && orientation > 1 {
+				img = reverseOrientation(img, orientation)
+			}
+		}
+	}
+
+	if opts.Crop != (image.Rectangle{}) {
+		bounds := img.Bounds()
+		crop := opts.Crop.Add(bounds.Min).Intersect(bounds)
+		if crop.Empty() {
+			return nil, "", fmt.Errorf("%w: crop rectangle is outside of the image", ErrInvalidTransform)
+		}
+		img = imaging.Crop(img, crop)
+	}
+
+	img = resizeImage(img, opts)
+
+	if opts.Format != "" {
+		format = opts.Format
+	} else if format != JPEG && format != WEBP {
+		format = PNG
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = defaultTransformQuality
+	}
+
+	buf := bytes.NewBuffer(nil)
+	switch format {
+	case JPEG:
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: quality})
+	case WEBP:
+		err = encodeWEBP(buf, img, quality)
+	default:
+		err = png.Encode(buf, img)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("encode %s: %w", format, err)
+	}
+	return buf.Bytes(), format, nil
+}
+
+func resizeImage(img image.Image, opts ImageTransformOpts) image.Image {
+	srcWidth, srcHeight := img.Bounds().Dx(), img.Bounds().Dy()
+	width, height := opts.Width, opts.Height
+	if width == 0 && height == 0 {
+		return img
+	}
+	if width == 0 || height == 0 {
+		// only one side is given, so the other one follows aspect ratio
+		if width > srcWidth || height > srcHeight {
+			return img
+		}
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	}
+	switch opts.Fit {
+	case FitFill:
+		width, height = shrinkBox(width, height, srcWidth, srcHeight)
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	case FitCover:
+		width, height = shrinkBox(width, height, srcWidth, srcHeight)
+		return imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
+	default:
+		if width >= srcWidth && height >= srcHeight {
+			return img
+		}
+		return imaging.Fit(img, width, height, imaging.Lanczos)
+	}
+}
+
+// shrinkBox scales the box down keeping its aspect ratio, so it fits into the source and the image is not upscaled
+func shrinkBox(width, height, srcWidth, srcHeight int) (int, int) {
+	scale := min(1, float64(srcWidth)/float64(width), float64(srcHeight)/float64(height))
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
@@ -0,0 +1,118 @@
+package mill
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/mill/testdata"
+)
+
+func TestTransformImage(t *testing.T) {
+	transform := func(t *testing.T, opts ImageTransformOpts) (image.Config, Format) {
+		file, err := os.Open(testdata.Images[0].Path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		data, format, err := TransformImage(file, opts)
+		require.NoError(t, err)
+		cfg, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, string(format), decodedFormat)
+		return cfg, format
+	}
+
+	t.Run("exif orientation is applied", func(t *testing.T) {
+		cfg, format := transform(t, ImageTransformOpts{})
+		assert.Equal(t, JPEG, format)
+		assert.Equal(t, 1800, cfg.Width)
+		assert.Equal(t, 1200, cfg.Height)
+	})
+
+	t.Run("width keeps aspect ratio", func(t *testing.T) {
+		cfg, _ := transform(t, ImageTransformOpts{Width: 300})
+		assert.Equal(t, 300, cfg.Width)
+		assert.Equal(t, 200, cfg.Height)
+	})
+
+	t.Run("contain", func(t *testing.T) {
+		cfg, _ := transform(t, ImageTransformOpts{Width: 300, Height: 300, Fit: FitContain})
+		assert.Equal(t, 300, cfg.Width)
+		assert.Equal(t, 200, cfg.Height)
+	})
+
+	t.Run("cover", func(t *testing.T) {
+		cfg, _ := transform(t, ImageTransformOpts{Width: 300, Height: 300, Fit: FitCover})
+		assert.Equal(t, 300, cfg.Width)
+		assert.Equal(t, 300, cfg.Height)
+	})
+
+	t.Run("cover box larger than the source is shrunk", func(t *testing.T) {
+		cfg, _ := transform(t, ImageTransformOpts{Width: 4000, Height: 2000, Fit: FitCover})
+		assert.Equal(t, 1800, cfg.Width)
+		assert.Equal(t, 900, cfg.Height)
+	})
+
+	t.Run("fill doesn't upscale", func(t *testing.T) {
+		cfg, _ := transform(t, ImageTransformOpts{Width: 3600, Height: 1200, Fit: FitFill})
+		assert.Equal(t, 1800, cfg.Width)
+		assert.Equal(t, 600, cfg.Height)
+	})
+
+	t.Run("crop and convert to png", func(t *testing.T) {
+		cfg, format := transform(t, ImageTransformOpts{Crop: image.Rect(100, 100, 200, 150), Format: PNG})
+		assert.Equal(t, PNG, format)
+		assert.Equal(t, 100, cfg.Width)
+		assert.Equal(t, 50, cfg.Height)
+	})
+
+	t.Run("crop outside of the image", func(t *testing.T) {
+		file, err := os.Open(testdata.Images[0].Path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		_, _, err = TransformImage(file, ImageTransformOpts{Crop: image.Rect(5000, 5000, 5100, 5100)})
+		assert.ErrorIs(t, err, ErrInvalidTransform)
+	})
+
+	t.Run("too large source", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+		data := buf.Bytes()
+		// patch the size in the IHDR chunk, so only the header claims a huge image
+		binary.BigEndian.PutUint32(data[16:], 10000)
+		binary.BigEndian.PutUint32(data[20:], 10000)
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+		// only the header is passed, pixels of too large images must not be read
+		_, _, err := TransformImage(bytes.NewReader(data[:33]), ImageTransformOpts{Width: 100})
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+	})
+
+	t.Run("non jpeg source is encoded as png", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, 40, 20))))
+
+		data, format, err := TransformImage(bytes.NewReader(buf.Bytes()), ImageTransformOpts{Width: 20})
+		require.NoError(t, err)
+		assert.Equal(t, PNG, format)
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 10, cfg.Height)
+	})
+}
+
+func TestImageTransformOpts_Validate(t *testing.T) {
+	assert.NoError(t, ImageTransformOpts{Width: 100, Fit: FitCover, Format: WEBP, Quality: 80}.Validate())
+	assert.ErrorIs(t, ImageTransformOpts{Width: -1}.Validate(), ErrInvalidTransform)
+	assert.ErrorIs(t, ImageTransformOpts{Quality: 101}.Validate(), ErrInvalidTransform)
+	assert.ErrorIs(t, ImageTransformOpts{Fit: "stretch"}.Validate(), ErrInvalidTransform)
+	assert.ErrorIs(t, ImageTransformOpts{Format: "gif"}.Validate(), ErrInvalidTransform)
+}
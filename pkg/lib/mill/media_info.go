@@ -0,0 +1,479 @@
+package mill
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/anyproto/anytype-heart/util/jsonutil"
+)
+
+// MediaInfoSchema describes technical parameters of audio and video files
+type MediaInfoSchema struct {
+	// Duration in seconds
+	Duration float64 `json:"duration"`
+	Codec    string  `json:"codec,omitempty"`
+	// Bitrate in bits per second
+	Bitrate    int `json:"bitrate,omitempty"`
+	SampleRate int `json:"sample_rate,omitempty"`
+	Channels   int `json:"channels,omitempty"`
+	Width      int `json:"width,omitempty"`
+	Height     int `json:"height,omitempty"`
+}
+
+var errMediaFormat = errors.New("unknown media container format")
+
+// maxMP4Depth limits nesting of MP4 boxes, real files have boxes nested up to 6 levels deep
+const maxMP4Depth = 16
+
+// MediaInfo extracts duration, codec and bitrate from containers that could be parsed without external tools:
+// MP4/MOV, MP3, WAV, FLAC and OGG (Vorbis, Opus)
+type MediaInfo struct{}
+
+const MediaInfoId = "/media/info"
+
+func (m *MediaInfo) ID() string {
+	return MediaInfoId
+}
+
+func (m *MediaInfo) Pin() bool {
+	return false
+}
+
+func (m *MediaInfo) AcceptMedia(media string) error {
+	if strings.HasPrefix(media, "audio/") || strings.HasPrefix(media, "video/") {
+		return nil
+	}
+	return ErrMediaTypeNotSupported
+}
+
+func (m *MediaInfo) Options(add map[string]interface{}) (string, error) {
+	return hashOpts(make(map[string]string), add)
+}
+
+func (m *MediaInfo) Mill(r io.ReadSeeker, name string) (*Result, error) {
+	info, err := ParseMediaInfo(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := jsonutil.MarshalSafely(info)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{File: noopCloser(bytes.NewReader(b))}, nil
+}
+
+// ParseMediaInfo detects container format by its signature and reads media parameters
+func ParseMediaInfo(r io.ReadSeeker) (*MediaInfoSchema, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 12)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return nil, errMediaFormat
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var info *MediaInfoSchema
+	switch {
+	case string(header[4:8]) == "ftyp" || string(header[4:8]) == "moov" || string(header[4:8]) == "mdat":
+		info, err = parseMP4(r, size)
+	case string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		info, err = parseWAV(r)
+	case string(header[0:4]) == "fLaC":
+		info, err = parseFLAC(r)
+	case string(header[0:4]) == "OggS":
+		info, err = parseOGG(r, size)
+	case string(header[0:3]) == "ID3" || (header[0] == 0xFF && header[1]&0xE0 == 0xE0):
+		info, err = parseMP3(r, size)
+	default:
+		return nil, errMediaFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Bitrate == 0 && info.Duration > 0 {
+		info.Bitrate = int(float64(size*8) / info.Duration)
+	}
+	return info, nil
+}
+
+// parseMP4 walks ISO BMFF boxes: moov/mvhd contains duration, trak/tkhd - video dimensions,
+// trak/mdia/minf/stbl/stsd - codec of the track
+func parseMP4(r io.ReadSeeker, size int64) (*MediaInfoSchema, error) {
+	info := &MediaInfoSchema{}
+	var (
+		videoCodec, audioCodec string
+		handler                string
+		width, height          int
+	)
+	var walk func(start, end int64, depth int) error
+	walk = func(start, end int64, depth int) error {
+		if depth > maxMP4Depth {
+			return fmt.Errorf("boxes are nested deeper than %d levels", maxMP4Depth)
+		}
+		for pos := start; pos+8 <= end; {
+			if _, err := r.Seek(pos, io.SeekStart); err != nil {
+				return err
+			}
+			var hdr [8]byte
+			if _, err := io.ReadFull(r, hdr[:]); err != nil {
+				return err
+			}
+			boxSize := int64(binary.BigEndian.Uint32(hdr[0:4]))
+			boxType := string(hdr[4:8])
+			dataStart := pos + 8
+			switch boxSize {
+			case 0:
+				boxSize = end - pos
+			case 1:
+				var ext [8]byte
+				if _, err := io.ReadFull(r, ext[:]); err != nil {
+					return err
+				}
+				boxSize = int64(binary.BigEndian.Uint64(ext[:]))
+				dataStart += 8
+			}
+			if boxSize < 8 || pos+boxSize > end {
+				return nil
+			}
+			boxEnd := pos + boxSize
+
+			switch boxType {
+			case "moov", "mdia", "minf", "stbl":
+				if err := walk(dataStart, boxEnd, depth+1); err != nil {
+					return err
+				}
+			case "trak":
+				handler, width, height = "", 0, 0
+				if err := walk(dataStart, boxEnd, depth+1); err != nil {
+					return err
+				}
+				if handler == "vide" && width > 0 {
+					info.Width, info.Height = width, height
+				}
+			case "mvhd":
+				data, err := readBox(r, boxEnd-dataStart, 32)
+				if err != nil {
+					return err
+				}
+				var timescale, duration uint64
+				if data[0] == 1 {
+					timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+					duration = binary.BigEndian.Uint64(data[24:32])
+				} else {
+					timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+					duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+				}
+				if timescale > 0 {
+					info.Duration = float64(duration) / float64(timescale)
+				}
+			case "tkhd":
+				data, err := readBox(r, boxEnd-dataStart, 84)
+				if err != nil {
+					return err
+				}
+				// width and height are the last two 16.16 fixed point values
+				width = int(binary.BigEndian.Uint32(data[len(data)-8:]) >> 16)
+				height = int(binary.BigEndian.Uint32(data[len(data)-4:]) >> 16)
+			case "hdlr":
+				data, err := readBox(r, boxEnd-dataStart, 12)
+				if err != nil {
+					return err
+				}
+				handler = string(data[8:12])
+			case "stsd":
+				data, err := readBox(r, boxEnd-dataStart, 16)
+				if err != nil {
+					return err
+				}
+				codec := strings.TrimSpace(string(data[12:16]))
+				switch handler {
+				case "vide":
+					videoCodec = codec
+				case "soun":
+					audioCodec = codec
+				}
+			}
+			pos = boxEnd
+		}
+		return nil
+	}
+	if err := walk(0, size, 0); err != nil {
+		return nil, fmt.Errorf("parse mp4: %w", err)
+	}
+	info.Codec = joinCodecs(videoCodec, audioCodec)
+	return info, nil
+}
+
+func readBox(r io.Reader, size int64, minSize int) ([]byte, error) {
+	if size < int64(minSize) || size > 1<<20 {
+		return nil, fmt.Errorf("invalid box size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func joinCodecs(codecs ...string) string {
+	var nonEmpty []string
+	for _, c := range codecs {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+func parseWAV(r io.ReadSeeker) (*MediaInfoSchema, error) {
+	if _, err := r.Seek(12, io.SeekStart); err != nil {
+		return nil, err
+	}
+	info := &MediaInfoSchema{}
+	var byteRate uint32
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, fmt.Errorf("parse wav: %w", err)
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		switch string(hdr[0:4]) {
+		case "fmt ":
+			data, err := readBox(r, chunkSize, 16)
+			if err != nil {
+				return nil, fmt.Errorf("parse wav: %w", err)
+			}
+			if binary.LittleEndian.Uint16(data[0:2]) == 1 {
+				info.Codec = "pcm"
+			} else {
+				info.Codec = fmt.Sprintf("wav/0x%04x", binary.LittleEndian.Uint16(data[0:2]))
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(data[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(data[4:8]))
+			byteRate = binary.LittleEndian.Uint32(data[8:12])
+			info.Bitrate = int(byteRate) * 8
+			if chunkSize%2 == 1 {
+				if _, err = r.Seek(1, io.SeekCurrent); err != nil {
+					return nil, err
+				}
+			}
+		case "data":
+			if byteRate == 0 {
+				return nil, fmt.Errorf("parse wav: data chunk before fmt")
+			}
+			info.Duration = float64(chunkSize) / float64(byteRate)
+			return info, nil
+		default:
+			if _, err := r.Seek(chunkSize+chunkSize%2, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func parseFLAC(r io.ReadSeeker) (*MediaInfoSchema, error) {
+	// "fLaC" marker, then metadata block header; STREAMINFO is always the first block
+	var data [4 + 4 + 34]byte
+	if _, err := io.ReadFull(r, data[:]); err != nil {
+		return nil, fmt.Errorf("parse flac: %w", err)
+	}
+	if data[4]&0x7F != 0 {
+		return nil, fmt.Errorf("parse flac: first metadata block is not streaminfo")
+	}
+	streamInfo := data[8:]
+	// 20 bits sample rate, 3 bits channels-1, 5 bits bits per sample-1, 36 bits total samples
+	packed := binary.BigEndian.Uint64(streamInfo[10:18])
+	sampleRate := packed >> 44
+	channels := (packed>>41)&0x7 + 1
+	totalSamples := packed & 0xFFFFFFFFF
+	info := &MediaInfoSchema{
+		Codec:      "flac",
+		SampleRate: int(sampleRate),
+		Channels:   int(channels),
+	}
+	if sampleRate > 0 {
+		info.Duration = float64(totalSamples) / float64(sampleRate)
+	}
+	return info, nil
+}
+
+// parseOGG reads identification header of the first logical stream and the granule position of the last page
+func parseOGG(r io.ReadSeeker, size int64) (*MediaInfoSchema, error) {
+	page := make([]byte, 27+255+64)
+	n, err := io.ReadFull(r, page)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("parse ogg: %w", err)
+	}
+	page = page[:n]
+	if len(page) < 27 {
+		return nil, fmt.Errorf("parse ogg: page is too short")
+	}
+	segments := int(page[26])
+	if len(page) < 27+segments {
+		return nil, fmt.Errorf("parse ogg: page is too short")
+	}
+	packet := page[27+segments:]
+
+	info := &MediaInfoSchema{}
+	// granule position is counted in samples for vorbis and always at 48kHz for opus
+	var granuleRate int
+	switch {
+	case len(packet) >= 28 && string(packet[0:7]) == "\x01vorbis":
+		info.Codec = "vorbis"
+		info.Channels = int(packet[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+		info.Bitrate = int(int32(binary.LittleEndian.Uint32(packet[20:24])))
+		granuleRate = info.SampleRate
+	case len(packet) >= 16 && string(packet[0:8]) == "OpusHead":
+		info.Codec = "opus"
+		info.Channels = int(packet[9])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+		granuleRate = 48000
+	default:
+		return nil, fmt.Errorf("parse ogg: unsupported codec")
+	}
+	if info.Bitrate < 0 {
+		info.Bitrate = 0
+	}
+
+	tailSize := int64(65536)
+	if tailSize > size {
+		tailSize = size
+	}
+	if _, err = r.Seek(size-tailSize, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tail := make([]byte, tailSize)
+	if _, err = io.ReadFull(r, tail); err != nil {
+		return nil, fmt.Errorf("parse ogg: %w", err)
+	}
+	if last := bytes.LastIndex(tail, []byte("OggS")); last != -1 && last+14 <= len(tail) && granuleRate > 0 {
+		granule := int64(binary.LittleEndian.Uint64(tail[last+6 : last+14]))
+		if granule > 0 {
+			info.Duration = float64(granule) / float64(granuleRate)
+		}
+	}
+	return info, nil
+}
+
+var (
+	mp3Bitrates = [2][3][16]int{
+		// MPEG-1, layers I, II, III
+		{
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		},
+		// MPEG-2 and 2.5, layers I, II, III
+		{
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		},
+	}
+	mp3SampleRates = [3][3]int{
+		{44100, 48000, 32000}, // MPEG-1
+		{22050, 24000, 16000}, // MPEG-2
+		{11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// parseMP3 reads the first frame header. Duration is taken from the Xing/Info header of VBR files,
+// for CBR files it is calculated from the size of audio data and bitrate
+func parseMP3(r io.ReadSeeker, size int64) (*MediaInfoSchema, error) {
+	var offset int64
+	var id3 [10]byte
+	if _, err := io.ReadFull(r, id3[:]); err != nil {
+		return nil, fmt.Errorf("parse mp3: %w", err)
+	}
+	if string(id3[0:3]) == "ID3" {
+		// tag size is stored as syncsafe integer
+		tagSize := int64(id3[6]&0x7F)<<21 | int64(id3[7]&0x7F)<<14 | int64(id3[8]&0x7F)<<7 | int64(id3[9]&0x7F)
+		offset = 10 + tagSize
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 4+32+12)
+	n, err := io.ReadFull(r, frame)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("parse mp3: %w", err)
+	}
+	frame = frame[:n]
+	if len(frame) < 4 || frame[0] != 0xFF || frame[1]&0xE0 != 0xE0 {
+		return nil, fmt.Errorf("parse mp3: frame sync not found")
+	}
+
+	versionBits := (frame[1] >> 3) & 0x3
+	layerBits := (frame[1] >> 1) & 0x3
+	bitrateIdx := frame[2] >> 4
+	sampleRateIdx := (frame[2] >> 2) & 0x3
+	channelMode := frame[3] >> 6
+	if versionBits == 1 || layerBits == 0 || sampleRateIdx == 3 {
+		return nil, fmt.Errorf("parse mp3: invalid frame header")
+	}
+
+	var versionRow, sampleRateRow int
+	switch versionBits {
+	case 3:
+		versionRow, sampleRateRow = 0, 0
+	case 2:
+		versionRow, sampleRateRow = 1, 1
+	default:
+		versionRow, sampleRateRow = 1, 2
+	}
+	layer := 4 - int(layerBits)
+	info := &MediaInfoSchema{
+		Codec:      fmt.Sprintf("mp%d", layer),
+		Bitrate:    mp3Bitrates[versionRow][layer-1][bitrateIdx] * 1000,
+		SampleRate: mp3SampleRates[sampleRateRow][sampleRateIdx],
+		Channels:   2,
+	}
+	if channelMode == 3 {
+		info.Channels = 1
+	}
+
+	samplesPerFrame := 1152
+	switch {
+	case layer == 1:
+		samplesPerFrame = 384
+	case layer == 3 && versionRow == 1:
+		samplesPerFrame = 576
+	}
+
+	// Xing/Info header is placed after side information of the first frame
+	sideInfo := 32
+	switch {
+	case versionRow == 0 && info.Channels == 1, versionRow == 1 && info.Channels == 2:
+		sideInfo = 17
+	case versionRow == 1 && info.Channels == 1:
+		sideInfo = 9
+	}
+	if xing := 4 + sideInfo; len(frame) >= xing+12 {
+		tag := string(frame[xing : xing+4])
+		flags := binary.BigEndian.Uint32(frame[xing+4 : xing+8])
+		if (tag == "Xing" || tag == "Info") && flags&0x1 != 0 {
+			frames := binary.BigEndian.Uint32(frame[xing+8 : xing+12])
+			info.Duration = float64(frames) * float64(samplesPerFrame) / float64(info.SampleRate)
+			if info.Duration > 0 {
+				info.Bitrate = int(float64((size-offset)*8) / info.Duration)
+			}
+			return info, nil
+		}
+	}
+	if info.Bitrate > 0 {
+		info.Duration = float64((size-offset)*8) / float64(info.Bitrate)
+	}
+	return info, nil
+}
@@ -0,0 +1,138 @@
+package mill
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func box(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(b[0:4], uint32(8+len(data)))
+	copy(b[4:8], boxType)
+	return append(b, data...)
+}
+
+func u32be(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func TestParseMediaInfo(t *testing.T) {
+	t.Run("mp4", func(t *testing.T) {
+		// given
+		mvhd := append(append(make([]byte, 12), u32be(1000)...), u32be(90500)...)
+		mvhd = append(mvhd, make([]byte, 80)...)
+		tkhd := make([]byte, 84)
+		binary.BigEndian.PutUint32(tkhd[76:80], 1920<<16)
+		binary.BigEndian.PutUint32(tkhd[80:84], 1080<<16)
+		videoTrak := box("trak",
+			box("tkhd", tkhd),
+			box("mdia",
+				box("hdlr", make([]byte, 8), []byte("vide"), make([]byte, 12)),
+				box("minf", box("stbl", box("stsd", make([]byte, 4), u32be(1), u32be(16), []byte("avc1"), make([]byte, 8)))),
+			),
+		)
+		audioTrak := box("trak",
+			box("tkhd", make([]byte, 84)),
+			box("mdia",
+				box("hdlr", make([]byte, 8), []byte("soun"), make([]byte, 12)),
+				box("minf", box("stbl", box("stsd", make([]byte, 4), u32be(1), u32be(16), []byte("mp4a"), make([]byte, 8)))),
+			),
+		)
+		data := append(box("ftyp", []byte("isom"), make([]byte, 4)), box("moov", box("mvhd", mvhd), videoTrak, audioTrak)...)
+
+		// when
+		info, err := ParseMediaInfo(bytes.NewReader(data))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 90.5, info.Duration)
+		assert.Equal(t, "avc1, mp4a", info.Codec)
+		assert.Equal(t, 1920, info.Width)
+		assert.Equal(t, 1080, info.Height)
+		assert.NotZero(t, info.Bitrate)
+	})
+
+	t.Run("mp4 with too deep nesting", func(t *testing.T) {
+		// given
+		nested := box("mvhd", make([]byte, 100))
+		for i := 0; i <= maxMP4Depth; i++ {
+			nested = box("moov", nested)
+		}
+		data := append(box("ftyp", []byte("isom"), make([]byte, 4)), nested...)
+
+		// when
+		_, err := ParseMediaInfo(bytes.NewReader(data))
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("wav", func(t *testing.T) {
+		// given
+		fmtChunk := make([]byte, 16)
+		binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+		binary.LittleEndian.PutUint16(fmtChunk[2:4], 2)
+		binary.LittleEndian.PutUint32(fmtChunk[4:8], 44100)
+		binary.LittleEndian.PutUint32(fmtChunk[8:12], 44100*4)
+		var buf bytes.Buffer
+		buf.WriteString("RIFF\x00\x00\x00\x00WAVE")
+		buf.WriteString("fmt ")
+		buf.Write(binary.LittleEndian.AppendUint32(nil, 16))
+		buf.Write(fmtChunk)
+		buf.WriteString("data")
+		buf.Write(binary.LittleEndian.AppendUint32(nil, 44100*4*3))
+
+		// when
+		info, err := ParseMediaInfo(bytes.NewReader(buf.Bytes()))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, info.Duration)
+		assert.Equal(t, "pcm", info.Codec)
+		assert.Equal(t, 2, info.Channels)
+		assert.Equal(t, 44100*32, info.Bitrate)
+	})
+
+	t.Run("flac", func(t *testing.T) {
+		// given
+		streamInfo := make([]byte, 34)
+		packed := uint64(48000)<<44 | uint64(1)<<41 | uint64(15)<<36 | uint64(48000*10)
+		binary.BigEndian.PutUint64(streamInfo[10:18], packed)
+		data := append([]byte("fLaC\x80\x00\x00\x22"), streamInfo...)
+
+		// when
+		info, err := ParseMediaInfo(bytes.NewReader(data))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, info.Duration)
+		assert.Equal(t, 48000, info.SampleRate)
+		assert.Equal(t, 2, info.Channels)
+	})
+
+	t.Run("mp3 cbr", func(t *testing.T) {
+		// given: MPEG-1 layer III, 128 kbps, 44100 Hz, stereo
+		frame := []byte{0xFF, 0xFB, 0x90, 0x00}
+		data := append(frame, make([]byte, 128000/8*2-len(frame))...)
+
+		// when
+		info, err := ParseMediaInfo(bytes.NewReader(data))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "mp3", info.Codec)
+		assert.Equal(t, 128000, info.Bitrate)
+		assert.Equal(t, 44100, info.SampleRate)
+		assert.Equal(t, 2.0, info.Duration)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := ParseMediaInfo(bytes.NewReader([]byte("just some plain text")))
+		assert.ErrorIs(t, err, errMediaFormat)
+	})
+}
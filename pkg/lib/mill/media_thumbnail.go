@@ -0,0 +1,87 @@
+package mill
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dhowden/tag"
+)
+
+var ErrNoMediaThumbnail = errors.New("media has no embedded thumbnail")
+
+// MediaThumbnail makes thumbnail from the picture embedded into media file: cover art of ID3, Vorbis comments or
+// MP4 covr atom, which is also used as a poster frame of MP4/MOV videos.
+// Frames are not decoded from the video stream: it needs a video decoder, which the pure Go build doesn't have,
+// so videos without embedded cover get no thumbnail and clients show their own preview
+type MediaThumbnail struct {
+	Opts ImageResizeOpts
+}
+
+const MediaThumbnailId = "/video/thumbnail"
+
+func (m *MediaThumbnail) ID() string {
+	return MediaThumbnailId
+}
+
+func (m *MediaThumbnail) Pin() bool {
+	return false
+}
+
+func (m *MediaThumbnail) AcceptMedia(media string) error {
+	if strings.HasPrefix(media, "audio/") || strings.HasPrefix(media, "video/") {
+		return nil
+	}
+	return ErrMediaTypeNotSupported
+}
+
+func (m *MediaThumbnail) Options(add map[string]interface{}) (string, error) {
+	return hashOpts(m.Opts, add)
+}
+
+func (m *MediaThumbnail) Mill(r io.ReadSeeker, name string) (*Result, error) {
+	r = &positionSeeker{ReadSeeker: r}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	metadata, err := tag.ReadFrom(r)
+	if errors.Is(err, tag.ErrNoTagsFound) {
+		return nil, ErrNoMediaThumbnail
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read media tags: %w", err)
+	}
+	picture := metadata.Picture()
+	if picture == nil || len(picture.Data) == 0 {
+		return nil, ErrNoMediaThumbnail
+	}
+	resize := &ImageResize{Opts: m.Opts}
+	return resize.Mill(bytes.NewReader(picture.Data), name)
+}
+
+// positionSeeker tracks read position itself, so relative seeks work for buffered readers,
+// whose underlying position is ahead of the data actually read
+type positionSeeker struct {
+	io.ReadSeeker
+	pos int64
+}
+
+func (p *positionSeeker) Read(b []byte) (n int, err error) {
+	n, err = p.ReadSeeker.Read(b)
+	p.pos += int64(n)
+	return
+}
+
+func (p *positionSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		offset, whence = p.pos+offset, io.SeekStart
+	}
+	pos, err := p.ReadSeeker.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	p.pos = pos
+	return pos, nil
+}
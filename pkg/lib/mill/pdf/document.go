@@ -0,0 +1,340 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+)
+
+var (
+	ErrFormat    = errors.New("not a pdf document")
+	ErrEncrypted = errors.New("pdf document is encrypted")
+)
+
+const (
+	maxTreeDepth      = 64
+	maxDecodedStream  = 64 << 20
+	pdfHeaderSearchAt = 1024
+)
+
+var objHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// Document is a minimal PDF reader: it locates objects by scanning the file instead of trusting
+// cross-reference tables, which makes it tolerant to broken or incrementally updated files
+type Document struct {
+	objects   map[int]object
+	trailer   dict
+	pages     []page
+	encrypted bool
+}
+
+type page struct {
+	dict      dict
+	resources dict
+	mediaBox  []float64
+}
+
+// Info contains document information dictionary entries
+type Info struct {
+	Title   string
+	Author  string
+	Subject string
+	Creator string
+}
+
+func Open(data []byte) (*Document, error) {
+	if idx := bytes.Index(data[:min(len(data), pdfHeaderSearchAt)], []byte("%PDF-")); idx < 0 {
+		return nil, ErrFormat
+	}
+	d := &Document{
+		objects: map[int]object{},
+		trailer: dict{},
+	}
+	d.scanObjects(data)
+	d.scanTrailers(data)
+	if _, ok := d.trailer["Encrypt"]; ok {
+		d.encrypted = true
+	} else {
+		d.loadObjectStreams()
+	}
+	root, _ := d.resolve(d.trailer["Root"]).(dict)
+	if root == nil {
+		return nil, fmt.Errorf("%w: catalog not found", ErrFormat)
+	}
+	pages, _ := d.resolve(root["Pages"]).(dict)
+	d.walkPages(pages, nil, nil, map[ref]bool{}, 0)
+	return d, nil
+}
+
+func (d *Document) scanObjects(data []byte) {
+	for _, m := range objHeaderRe.FindAllSubmatchIndex(data, -1) {
+		// header must start at the line beginning or after a delimiter
+		if m[0] > 0 && !isWhitespace(data[m[0]-1]) && !isDelimiter(data[m[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		l := &lexer{data: data, pos: m[1]}
+		obj, ok := l.object()
+		if !ok {
+			continue
+		}
+		// later definitions come from incremental updates and override previous ones
+		d.objects[num] = obj
+		if s, ok := obj.(*stream); ok && s.dict["Type"] == name("XRef") {
+			d.mergeTrailer(s.dict)
+		}
+	}
+}
+
+func (d *Document) scanTrailers(data []byte) {
+	marker := []byte("trailer")
+	for pos := 0; ; {
+		idx := bytes.Index(data[pos:], marker)
+		if idx < 0 {
+			return
+		}
+		l := &lexer{data: data, pos: pos + idx + len(marker)}
+		if t, ok := l.object(); ok {
+			if td, ok := t.(dict); ok {
+				d.mergeTrailer(td)
+			}
+		}
+		pos += idx + len(marker)
+	}
+}
+
+func (d *Document) mergeTrailer(t dict) {
+	for _, key := range []name{"Root", "Info", "Encrypt"} {
+		if v, ok := t[key]; ok {
+			d.trailer[key] = v
+		}
+	}
+}
+
+func (d *Document) loadObjectStreams() {
+	var streams []*stream
+	for _, obj := range d.objects {
+		if s, ok := obj.(*stream); ok && s.dict["Type"] == name("ObjStm") {
+			streams = append(streams, s)
+		}
+	}
+	for _, s := range streams {
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+		n, okN := intValue(d.resolve(s.dict["N"]), len(data))
+		first, okFirst := intValue(d.resolve(s.dict["First"]), len(data))
+		if !okN || !okFirst {
+			continue
+		}
+		header := &lexer{data: data[:first]}
+		for i := 0; i < n; i++ {
+			numObj, ok1 := header.token()
+			offsetObj, ok2 := header.token()
+			if !ok1 || !ok2 {
+				break
+			}
+			num, isNum := intValue(numObj, math.MaxInt32)
+			offset, isOffset := intValue(offsetObj, len(data)-first-1)
+			if !isNum || !isOffset {
+				continue
+			}
+			if _, exists := d.objects[num]; exists {
+				continue
+			}
+			l := &lexer{data: data, pos: first + offset}
+			if obj, ok := l.object(); ok {
+				d.objects[num] = obj
+			}
+		}
+	}
+}
+
+func (d *Document) resolve(obj object) object {
+	for i := 0; i < maxTreeDepth; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[r.num]
+	}
+	return nil
+}
+
+func (d *Document) walkPages(node dict, inheritedResources dict, inheritedBox []float64, visited map[ref]bool, depth int) {
+	if node == nil || depth > maxTreeDepth {
+		return
+	}
+	resources, _ := d.resolve(node["Resources"]).(dict)
+	if resources == nil {
+		resources = inheritedResources
+	}
+	box := d.numbers(node["MediaBox"])
+	if len(box) != 4 {
+		box = inheritedBox
+	}
+	kids, hasKids := d.resolve(node["Kids"]).([]object)
+	if !hasKids || node["Type"] == name("Page") {
+		d.pages = append(d.pages, page{dict: node, resources: resources, mediaBox: box})
+		return
+	}
+	for _, kid := range kids {
+		if r, ok := kid.(ref); ok {
+			if visited[r] {
+				continue
+			}
+			visited[r] = true
+		}
+		kidDict, _ := d.resolve(kid).(dict)
+		d.walkPages(kidDict, resources, box, visited, depth+1)
+	}
+}
+
+func (d *Document) numbers(obj object) []float64 {
+	arr, _ := d.resolve(obj).([]object)
+	res := make([]float64, 0, len(arr))
+	for _, v := range arr {
+		if f, ok := d.resolve(v).(float64); ok {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func (d *Document) NumPages() int {
+	return len(d.pages)
+}
+
+func (d *Document) Encrypted() bool {
+	return d.encrypted
+}
+
+// PageSize returns page width and height in points, defaulting to US Letter
+func (d *Document) PageSize(i int) (width, height float64) {
+	if i < 0 || i >= len(d.pages) || len(d.pages[i].mediaBox) != 4 {
+		return 612, 792
+	}
+	box := d.pages[i].mediaBox
+	width, height = box[2]-box[0], box[3]-box[1]
+	if width < 0 {
+		width = -width
+	}
+	if height < 0 {
+		height = -height
+	}
+	if width == 0 || height == 0 {
+		return 612, 792
+	}
+	return width, height
+}
+
+func (d *Document) Info() Info {
+	info, _ := d.resolve(d.trailer["Info"]).(dict)
+	if info == nil || d.encrypted {
+		return Info{}
+	}
+	return Info{
+		Title:   d.text(info["Title"]),
+		Author:  d.text(info["Author"]),
+		Subject: d.text(info["Subject"]),
+		Creator: d.text(info["Creator"]),
+	}
+}
+
+func (d *Document) text(obj object) string {
+	s, _ := d.resolve(obj).(string)
+	return decodeTextString(s)
+}
+
+// decodeTextString decodes PDF text string, which is either UTF-16BE with BOM or PDFDocEncoding
+func decodeTextString(s string) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return decodeUTF16(s[2:])
+	}
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		runes = append(runes, rune(s[i]))
+	}
+	return string(runes)
+}
+
+func decodeUTF16(s string) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func (d *Document) filters(s *stream) []name {
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		return []name{f}
+	case []object:
+		var res []name
+		for _, v := range f {
+			if n, ok := d.resolve(v).(name); ok {
+				res = append(res, n)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// decode applies stream filters. Image filters like DCTDecode are left for the caller
+func (d *Document) decode(s *stream) ([]byte, error) {
+	data := s.data
+	for _, f := range d.filters(s) {
+		switch f {
+		case "FlateDecode", "Fl":
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("flate: %w", err)
+			}
+			// truncated streams are common, so keep everything that was read
+			decoded, err := io.ReadAll(io.LimitReader(zr, maxDecodedStream))
+			if err != nil && len(decoded) == 0 {
+				return nil, fmt.Errorf("flate: %w", err)
+			}
+			data = decoded
+		case "ASCIIHexDecode", "AHx":
+			l := &lexer{data: append(append([]byte{'<'}, data...), '>')}
+			data = []byte(l.hexString())
+		default:
+			return data, fmt.Errorf("unsupported filter %s", f)
+		}
+	}
+	return data, nil
+}
+
+func (d *Document) pageContents(i int) []byte {
+	var parts []object
+	switch c := d.resolve(d.pages[i].dict["Contents"]).(type) {
+	case *stream:
+		parts = []object{c}
+	case []object:
+		parts = c
+	}
+	var buf bytes.Buffer
+	for _, part := range parts {
+		s, ok := d.resolve(part).(*stream)
+		if !ok {
+			continue
+		}
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,218 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flate(t testing.TB, data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func buildPdf(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R /Info 2 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func streamObject(dictEntries, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dictEntries, len(data), data)
+}
+
+func TestDocument(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <0069>
+endbfchar
+1 beginbfrange
+<0010> <0012> <0430>
+endbfrange
+endcmap`
+	page1 := "BT /F1 12 Tf 72 700 Td (Hello) Tj [(wor) -50 (ld) -300 (again)] TJ 0 -14 Td (Second \\(line\\)) Tj ET"
+	page2 := "BT /F2 12 Tf 1 0 0 1 72 700 Tm <00010002> Tj 1 0 0 1 72 680 Tm <001000110012> Tj ET"
+
+	data := buildPdf(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title <FEFF04220435043A04410442> /Author (John Doe) >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 /MediaBox [0 0 595 842] /Resources << /Font << /F1 6 0 R /F2 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 8 0 R >>",
+		"<< /Type /Page /Parent 3 0 R /Contents [9 0 R] /MediaBox [0 0 842 595] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 10 0 R >>",
+		streamObject("", page1),
+		streamObject("/Filter /FlateDecode", flate(t, page2)),
+		streamObject("/Filter /FlateDecode", flate(t, cmap)),
+	)
+
+	// when
+	doc, err := Open(data)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.NumPages())
+	assert.False(t, doc.Encrypted())
+	assert.Equal(t, Info{Title: "Текст", Author: "John Doe"}, doc.Info())
+
+	width, height := doc.PageSize(0)
+	assert.Equal(t, 595.0, width)
+	assert.Equal(t, 842.0, height)
+	width, height = doc.PageSize(1)
+	assert.Equal(t, 842.0, width)
+	assert.Equal(t, 595.0, height)
+
+	text, err := doc.PageText(0)
+	require.NoError(t, err)
+	assert.Equal(t, "Helloworld again\nSecond (line)", text)
+
+	text, err = doc.PageText(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi\nабв", text)
+
+	_, ok := doc.PageImage(0)
+	assert.False(t, ok)
+}
+
+func TestDocumentObjectStream(t *testing.T) {
+	// given: page tree is stored inside compressed object stream
+	pages := "<< /Type /Pages /Kids [5 0 R] /Count 1 >> "
+	objects := pages + "<< /Type /Page /Parent 4 0 R >>"
+	header := fmt.Sprintf("4 0 5 %d ", len(pages))
+	data := buildPdf(
+		"<< /Type /Catalog /Pages 4 0 R >>",
+		"<< >>",
+		streamObject(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), flate(t, header+objects)),
+	)
+
+	// when
+	doc, err := Open(data)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, doc.NumPages())
+}
+
+func TestDocumentEncrypted(t *testing.T) {
+	// given
+	data := buildPdf(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title (secret) >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+	)
+	data = bytes.Replace(data, []byte("/Info 2 0 R"), []byte("/Info 2 0 R /Encrypt 5 0 R"), 1)
+
+	// when
+	doc, err := Open(data)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, doc.Encrypted())
+	assert.Equal(t, 1, doc.NumPages())
+	assert.Equal(t, Info{}, doc.Info())
+	_, err = doc.PageText(0)
+	assert.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestOpenNotPdf(t *testing.T) {
+	_, err := Open([]byte("plain text"))
+	assert.ErrorIs(t, err, ErrFormat)
+}
+
+func TestOpenMalformed(t *testing.T) {
+	objectStream := func(header, objects, first string) []byte {
+		return buildPdf(
+			"<< /Type /Catalog /Pages 4 0 R >>",
+			"<< >>",
+			streamObject("/Type /ObjStm /N 2 /First "+first, header+objects),
+		)
+	}
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"unterminated hex string", []byte("%PDF-0 0 0 obj<<0<")},
+		{"unterminated stream", []byte("%PDF-1.7\n1 0 obj << /Length 5 >> stream")},
+		{"negative length", buildPdf(streamObject("/Length -10", "abc"))},
+		{"negative first", objectStream("4 0 ", "<< >>", "-3")},
+		{"first out of range", objectStream("4 0 ", "<< >>", "1e300")},
+		{"negative offset", objectStream("4 -5 ", "<< >>", "5")},
+		{"huge offset", objectStream("4 1e18 ", "<< >>", "5")},
+		{"deep nesting", append([]byte("%PDF-1.7\n1 0 obj "), bytes.Repeat([]byte("["), 1_000_000)...)},
+		{"huge image", buildPdf(
+			"<< /Type /Catalog /Pages 3 0 R >>",
+			"<< >>",
+			"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+			"<< /Type /Page /Resources << /XObject << /Im1 5 0 R >> >> >>",
+			streamObject("/Subtype /Image /Width 1e18 /Height 1e18 /BitsPerComponent 8 /ColorSpace /DeviceGray", "abc"),
+		)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				readDocument(tc.data)
+			})
+		})
+	}
+}
+
+// readDocument calls every reading method, like the file mill and the fulltext indexer do
+func readDocument(data []byte) {
+	doc, err := Open(data)
+	if err != nil {
+		return
+	}
+	doc.Info()
+	for i := 0; i < min(doc.NumPages(), 16); i++ {
+		doc.PageSize(i)
+		_, _ = doc.PageText(i)
+		doc.PageImage(i)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	page := "BT /F1 12 Tf 72 700 Td (Hello) Tj [(wor) -50 (ld)] TJ <00010002> Tj ET"
+	f.Add(buildPdf(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title <FEFF0422> /Author (John Doe) >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 /MediaBox [0 0 595 842] /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /ToUnicode 7 0 R >>",
+		streamObject("/Filter /FlateDecode", flate(f, page)),
+		streamObject("", "1 begincodespacerange <0000> <FFFF> endcodespacerange 1 beginbfchar <0001> <0048> endbfchar 1 beginbfrange <0010> <0012> <0430> endbfrange"),
+	))
+	pages := "<< /Type /Pages /Kids [5 0 R] /Count 1 >> "
+	header := fmt.Sprintf("4 0 5 %d ", len(pages))
+	f.Add(buildPdf(
+		"<< /Type /Catalog /Pages 4 0 R >>",
+		"<< >>",
+		streamObject(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), flate(f, header+pages+"<< /Type /Page >>")),
+	))
+	f.Add(buildPdf(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Page /Resources << /XObject << /Im1 5 0 R >> >> >>",
+		streamObject("/Subtype /Image /Width 2 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceRGB", "abcdef"),
+	))
+	f.Add([]byte("%PDF-0 0 0 obj<<0<"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		readDocument(data)
+	})
+}
@@ -0,0 +1,104 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+)
+
+const (
+	maxImageSide   = 1 << 14
+	maxImagePixels = 50_000_000
+)
+
+// PageImage returns the largest image placed on the page. Only JPEG images and 8-bit
+// RGB or grayscale Flate images are supported, which covers most of scanned documents
+func (d *Document) PageImage(i int) (image.Image, bool) {
+	if d.encrypted || i < 0 || i >= len(d.pages) {
+		return nil, false
+	}
+	xobjects, _ := d.resolve(d.pages[i].resources["XObject"]).(dict)
+	var (
+		best     image.Image
+		bestArea int
+	)
+	for _, obj := range xobjects {
+		s, ok := d.resolve(obj).(*stream)
+		if !ok || d.resolve(s.dict["Subtype"]) != name("Image") {
+			continue
+		}
+		img := d.decodeImage(s)
+		if img == nil {
+			continue
+		}
+		if area := img.Bounds().Dx() * img.Bounds().Dy(); area > bestArea {
+			best, bestArea = img, area
+		}
+	}
+	return best, best != nil
+}
+
+func (d *Document) decodeImage(s *stream) image.Image {
+	filters := d.filters(s)
+	if len(filters) > 0 && (filters[len(filters)-1] == "DCTDecode" || filters[len(filters)-1] == "DCT") {
+		data := s.data
+		if len(filters) > 1 {
+			head := &stream{dict: dict{"Filter": toObjects(filters[:len(filters)-1])}, data: s.data}
+			decoded, err := d.decode(head)
+			if err != nil {
+				return nil
+			}
+			data = decoded
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || cfg.Width > maxImageSide || cfg.Height > maxImageSide || cfg.Width*cfg.Height > maxImagePixels {
+			return nil
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		return img
+	}
+
+	w, okW := intValue(d.resolve(s.dict["Width"]), maxImageSide)
+	h, okH := intValue(d.resolve(s.dict["Height"]), maxImageSide)
+	bpc, _ := d.resolve(s.dict["BitsPerComponent"]).(float64)
+	if bpc != 8 || !okW || !okH || w == 0 || h == 0 || w*h > maxImagePixels {
+		return nil
+	}
+	if _, hasParams := s.dict["DecodeParms"]; hasParams {
+		// predictors are not supported
+		return nil
+	}
+	var components int
+	switch d.resolve(s.dict["ColorSpace"]) {
+	case name("DeviceRGB"):
+		components = 3
+	case name("DeviceGray"):
+		components = 1
+	default:
+		return nil
+	}
+	data, err := d.decode(s)
+	if err != nil || len(data) < w*h*components {
+		return nil
+	}
+	if components == 1 {
+		return &image.Gray{Pix: data[:w*h], Stride: w, Rect: image.Rect(0, 0, w, h)}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for p := 0; p < w*h; p++ {
+		img.SetRGBA(p%w, p/w, color.RGBA{R: data[p*3], G: data[p*3+1], B: data[p*3+2], A: 0xFF})
+	}
+	return img
+}
+
+func toObjects(names []name) []object {
+	res := make([]object, len(names))
+	for i, n := range names {
+		res[i] = n
+	}
+	return res
+}
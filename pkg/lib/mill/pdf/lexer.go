@@ -0,0 +1,350 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// object is one of: nil, bool, float64, name, string, []object, dict, ref, *stream or keyword
+type object interface{}
+
+type name string
+
+type keyword string
+
+type dict map[name]object
+
+type ref struct {
+	num int
+	gen int
+}
+
+type stream struct {
+	dict dict
+	data []byte
+}
+
+// maxNesting limits nesting of arrays and dictionaries, so crafted documents can't exhaust the stack
+const maxNesting = 256
+
+type lexer struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// intValue converts a number read from the document to a non-negative int not greater than limit
+func intValue(obj object, limit int) (int, bool) {
+	f, ok := obj.(float64)
+	if !ok || f != f || f < 0 || f > float64(limit) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isWhitespace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// token reads next primitive token. Composite objects are started by keyword tokens "[", "]", "<<" and ">>"
+func (l *lexer) token() (object, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch c {
+	case '[', ']', '{', '}':
+		l.pos++
+		return keyword(c), true
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return keyword("<<"), true
+		}
+		return l.hexString(), true
+	case '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return keyword(">>"), true
+		}
+		l.pos++
+		return keyword(">"), true
+	case '(':
+		return l.literalString(), true
+	case '/':
+		l.pos++
+		return name(l.regular()), true
+	case ')':
+		l.pos++
+		return keyword(")"), true
+	}
+	word := l.regular()
+	if word == "" {
+		l.pos++
+		return keyword(c), true
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, true
+	}
+	switch word {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return keyword(word), true
+}
+
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if !bytes.ContainsRune(l.data[start:l.pos], '#') {
+		return word
+	}
+	// names could contain #xx escapes
+	var b []byte
+	for i := 0; i < len(word); i++ {
+		if word[i] == '#' && i+2 < len(word) {
+			if v, err := strconv.ParseUint(word[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(v))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, word[i])
+	}
+	return string(b)
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func (l *lexer) hexString() string {
+	l.pos++
+	var (
+		b       []byte
+		pending = -1
+	)
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		v, ok := unhex(l.data[l.pos])
+		l.pos++
+		if !ok {
+			continue
+		}
+		if pending < 0 {
+			pending = int(v)
+		} else {
+			b = append(b, byte(pending<<4)|v)
+			pending = -1
+		}
+	}
+	if pending >= 0 {
+		b = append(b, byte(pending<<4))
+	}
+	if l.pos < len(l.data) {
+		l.pos++
+	}
+	return string(b)
+}
+
+func (l *lexer) literalString() string {
+	l.pos++
+	var (
+		b     []byte
+		depth = 1
+	)
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(b)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return string(b)
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// object reads a complete object, resolving arrays, dictionaries, references and streams
+func (l *lexer) object() (object, bool) {
+	tok, ok := l.token()
+	if !ok {
+		return nil, false
+	}
+	return l.complete(tok)
+}
+
+func (l *lexer) complete(tok object) (object, bool) {
+	switch t := tok.(type) {
+	case keyword:
+		if (t == "[" || t == "<<") && l.depth >= maxNesting {
+			return nil, false
+		}
+		switch t {
+		case "[":
+			l.depth++
+			defer func() { l.depth-- }()
+			var arr []object
+			for {
+				next, ok := l.token()
+				if !ok || next == keyword("]") {
+					return arr, ok
+				}
+				obj, ok := l.complete(next)
+				if !ok {
+					return arr, false
+				}
+				arr = append(arr, obj)
+			}
+		case "<<":
+			l.depth++
+			defer func() { l.depth-- }()
+			d := dict{}
+			for {
+				next, ok := l.token()
+				if !ok || next == keyword(">>") {
+					break
+				}
+				key, isName := next.(name)
+				value, ok := l.object()
+				if !ok {
+					return d, false
+				}
+				if isName {
+					d[key] = value
+				}
+			}
+			return l.maybeStream(d), true
+		}
+	case float64:
+		// integer could be the start of "num gen R" reference
+		save := l.pos
+		gen, ok := l.token()
+		if g, isNum := gen.(float64); ok && isNum {
+			if kw, ok := l.token(); ok && kw == keyword("R") {
+				return ref{num: int(t), gen: int(g)}, true
+			}
+		}
+		l.pos = save
+	}
+	return tok, true
+}
+
+func (l *lexer) maybeStream(d dict) object {
+	save := l.pos
+	l.skipSpace()
+	if l.pos >= len(l.data) || !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		l.pos = save
+		return d
+	}
+	l.pos += len("stream")
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+	if length, ok := intValue(d["Length"], len(l.data)-start); ok {
+		end := start + length
+		rest := bytes.TrimLeft(l.data[end:min(end+16, len(l.data))], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			l.pos = end
+			return &stream{dict: d, data: l.data[start:end]}
+		}
+	}
+	// length is indirect or broken, so look for the end marker
+	idx := bytes.Index(l.data[start:], []byte("endstream"))
+	if idx < 0 {
+		l.pos = len(l.data)
+		return &stream{dict: d, data: l.data[start:]}
+	}
+	end := start + idx
+	l.pos = end + len("endstream")
+	data := l.data[start:end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return &stream{dict: d, data: data}
+}
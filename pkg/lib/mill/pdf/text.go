@@ -0,0 +1,305 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// tjSpacing is the TJ adjustment, in thousandths of text space unit, treated as word gap
+const tjSpacing = -200
+
+type font struct {
+	cmap *cmap
+	// twoByte is set for composite fonts without ToUnicode map, their codes can't be mapped to text
+	twoByte bool
+}
+
+func (f *font) decode(s string) string {
+	if f == nil {
+		return decodeSimple(s)
+	}
+	if f.cmap != nil {
+		return f.cmap.decode(s)
+	}
+	if f.twoByte {
+		return ""
+	}
+	return decodeSimple(s)
+}
+
+// decodeSimple treats codes of simple fonts as Latin-1, which matches standard encodings for ASCII range
+func decodeSimple(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 && c != '\t' {
+			continue
+		}
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// PageText extracts text drawn by text showing operators of the page, one line per text line
+func (d *Document) PageText(i int) (string, error) {
+	if d.encrypted {
+		return "", ErrEncrypted
+	}
+	if i < 0 || i >= len(d.pages) {
+		return "", nil
+	}
+	fonts := map[name]*font{}
+	fontDicts, _ := d.resolve(d.pages[i].resources["Font"]).(dict)
+
+	var (
+		out      strings.Builder
+		operands []object
+		current  *font
+		lastY    float64
+	)
+	newLine := func() {
+		s := out.String()
+		if len(s) > 0 && s[len(s)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	show := func(s string) {
+		out.WriteString(current.decode(s))
+	}
+
+	l := &lexer{data: d.pageContents(i)}
+	for {
+		tok, ok := l.token()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(keyword)
+		if !isOp || op == "[" || op == "<<" {
+			obj, _ := l.complete(tok)
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if fontName, ok := operands[len(operands)-2].(name); ok {
+					current = d.font(fonts, fontDicts, fontName)
+				}
+			}
+		case "Tj", "'":
+			if op == "'" {
+				newLine()
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(string); ok {
+					show(s)
+				}
+			}
+		case `"`:
+			newLine()
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(string); ok {
+					show(s)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].([]object)
+				for _, item := range arr {
+					switch v := item.(type) {
+					case string:
+						show(v)
+					case float64:
+						if v < tjSpacing {
+							out.WriteByte(' ')
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty, ok := operands[len(operands)-1].(float64); ok && ty != 0 {
+					newLine()
+				}
+			}
+		case "T*":
+			newLine()
+		case "Tm":
+			if len(operands) >= 6 {
+				if y, ok := operands[len(operands)-1].(float64); ok && y != lastY {
+					lastY = y
+					newLine()
+				}
+			}
+		case "ET":
+			newLine()
+		case "ID":
+			// skip binary data of inline image
+			end := bytes.Index(l.data[l.pos:], []byte("EI"))
+			if end < 0 {
+				l.pos = len(l.data)
+			} else {
+				l.pos += end + 2
+			}
+		}
+		operands = operands[:0]
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func (d *Document) font(cache map[name]*font, fontDicts dict, fontName name) *font {
+	if f, ok := cache[fontName]; ok {
+		return f
+	}
+	f := &font{}
+	fd, _ := d.resolve(fontDicts[fontName]).(dict)
+	if fd != nil {
+		f.twoByte = d.resolve(fd["Subtype"]) == name("Type0")
+		if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+			if data, err := d.decode(s); err == nil {
+				f.cmap = parseCMap(data)
+			}
+		}
+	}
+	cache[fontName] = f
+	return f
+}
+
+type cmap struct {
+	// codeLengths lists code byte lengths declared by codespace ranges
+	codeLengths []int
+	mapping     map[string]string
+}
+
+func (c *cmap) decode(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		matched := false
+		for _, n := range c.codeLengths {
+			if n > len(s) {
+				continue
+			}
+			if dst, ok := c.mapping[s[:n]]; ok {
+				b.WriteString(dst)
+				s = s[n:]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			n := 1
+			if len(c.codeLengths) > 0 && c.codeLengths[0] <= len(s) {
+				n = c.codeLengths[0]
+			}
+			s = s[n:]
+		}
+	}
+	return b.String()
+}
+
+func parseCMap(data []byte) *cmap {
+	c := &cmap{mapping: map[string]string{}}
+	lengths := map[int]bool{}
+	l := &lexer{data: data}
+	var operands []object
+	for {
+		tok, ok := l.token()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(keyword)
+		if !isOp || op == "[" || op == "<<" {
+			obj, _ := l.complete(tok)
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].(string); ok && len(lo) > 0 {
+					lengths[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(string)
+				dst, ok2 := operands[i+1].(string)
+				if ok1 && ok2 {
+					c.mapping[src] = decodeUTF16(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				c.addRange(operands[i], operands[i+1], operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+	for n := 4; n >= 1; n-- {
+		if lengths[n] {
+			c.codeLengths = append(c.codeLengths, n)
+		}
+	}
+	if len(c.codeLengths) == 0 {
+		c.codeLengths = []int{2, 1}
+	}
+	return c
+}
+
+const maxBFRange = 0xFFFF
+
+func (c *cmap) addRange(loObj, hiObj, dstObj object) {
+	lo, ok1 := loObj.(string)
+	hi, ok2 := hiObj.(string)
+	if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+		return
+	}
+	from, to := codeValue(lo), codeValue(hi)
+	if to < from || to-from > maxBFRange {
+		return
+	}
+	switch dst := dstObj.(type) {
+	case string:
+		base := []rune(decodeUTF16(dst))
+		if len(base) == 0 {
+			return
+		}
+		for code := from; code <= to; code++ {
+			r := make([]rune, len(base))
+			copy(r, base)
+			r[len(r)-1] += rune(code - from)
+			if !utf8.ValidRune(r[len(r)-1]) {
+				continue
+			}
+			c.mapping[codeString(code, len(lo))] = string(r)
+		}
+	case []object:
+		for i, item := range dst {
+			code := from + uint32(i)
+			if code > to {
+				break
+			}
+			if s, ok := item.(string); ok {
+				c.mapping[codeString(code, len(lo))] = decodeUTF16(s)
+			}
+		}
+	}
+}
+
+func codeValue(s string) uint32 {
+	var v uint32
+	for i := 0; i < len(s); i++ {
+		v = v<<8 | uint32(s[i])
+	}
+	return v
+}
+
+func codeString(v uint32, n int) string {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return string(b)
+}
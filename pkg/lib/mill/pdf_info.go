@@ -0,0 +1,101 @@
+package mill
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/anyproto/anytype-heart/pkg/lib/mill/pdf"
+	"github.com/anyproto/anytype-heart/util/jsonutil"
+)
+
+// maxPdfSize limits size of PDF documents, they are parsed in memory
+var maxPdfSize int64 = 256 * 1024 * 1024
+
+var ErrPdfTooLarge = errors.New("pdf document is too large")
+
+// PdfInfoSchema describes metadata and text of PDF document
+type PdfInfoSchema struct {
+	Pages   int    `json:"pages"`
+	Title   string `json:"title,omitempty"`
+	Author  string `json:"author,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Creator string `json:"creator,omitempty"`
+	// Text contains extracted text of every page, empty for encrypted documents
+	Text []string `json:"text,omitempty"`
+}
+
+// PdfInfo extracts page count, document information and per-page text
+type PdfInfo struct{}
+
+const PdfInfoId = "/pdf/info"
+
+func (m *PdfInfo) ID() string {
+	return PdfInfoId
+}
+
+func (m *PdfInfo) Pin() bool {
+	return false
+}
+
+func (m *PdfInfo) AcceptMedia(media string) error {
+	return accepts([]string{"application/pdf"}, media)
+}
+
+func (m *PdfInfo) Options(add map[string]interface{}) (string, error) {
+	return hashOpts(make(map[string]string), add)
+}
+
+func (m *PdfInfo) Mill(r io.ReadSeeker, name string) (*Result, error) {
+	info, err := ParsePdfInfo(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := jsonutil.MarshalSafely(info)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{File: noopCloser(bytes.NewReader(b))}, nil
+}
+
+// ParsePdfInfo reads PDF document. Text of encrypted documents is not extracted, but page count is still returned
+func ParsePdfInfo(r io.ReadSeeker) (*PdfInfoSchema, error) {
+	doc, err := openPdf(r)
+	if err != nil {
+		return nil, err
+	}
+	meta := doc.Info()
+	info := &PdfInfoSchema{
+		Pages:   doc.NumPages(),
+		Title:   meta.Title,
+		Author:  meta.Author,
+		Subject: meta.Subject,
+		Creator: meta.Creator,
+	}
+	if doc.Encrypted() {
+		return info, nil
+	}
+	info.Text = make([]string, doc.NumPages())
+	for i := range info.Text {
+		text, err := doc.PageText(i)
+		if err != nil && !errors.Is(err, pdf.ErrEncrypted) {
+			return nil, err
+		}
+		info.Text[i] = text
+	}
+	return info, nil
+}
+
+func openPdf(r io.ReadSeeker) (*pdf.Document, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxPdfSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxPdfSize {
+		return nil, ErrPdfTooLarge
+	}
+	return pdf.Open(data)
+}
@@ -0,0 +1,73 @@
+package mill
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPdf() []byte {
+	content := "BT /F1 12 Tf 72 700 Td (First page) Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title (Report) /Author (Jane) >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R /Info 2 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestPdfInfo(t *testing.T) {
+	// when
+	info, err := ParsePdfInfo(bytes.NewReader(testPdf()))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &PdfInfoSchema{
+		Pages:  2,
+		Title:  "Report",
+		Author: "Jane",
+		Text:   []string{"First page", ""},
+	}, info)
+}
+
+func TestPdfInfo_TooLarge(t *testing.T) {
+	// given
+	data := testPdf()
+	defer func(size int64) { maxPdfSize = size }(maxPdfSize)
+	maxPdfSize = int64(len(data) - 1)
+
+	// when
+	_, err := ParsePdfInfo(bytes.NewReader(data))
+
+	// then
+	require.ErrorIs(t, err, ErrPdfTooLarge)
+}
+
+func TestPdfThumbnail(t *testing.T) {
+	// given
+	m := &PdfThumbnail{Opts: ImageResizeOpts{Width: "100", Quality: "80"}}
+
+	// when
+	res, err := m.Mill(bytes.NewReader(testPdf()), "report.pdf")
+
+	// then
+	require.NoError(t, err)
+	cfg, format, err := image.DecodeConfig(res.File)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 100, cfg.Width)
+	assert.Equal(t, 129, cfg.Height)
+}
@@ -0,0 +1,127 @@
+package mill
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+var ErrNoPdfThumbnail = errors.New("pdf has no pages to render thumbnail")
+
+const (
+	pdfPreviewWidth  = 612
+	pdfPreviewMargin = 36
+)
+
+// PdfThumbnail makes thumbnail of the first page of PDF document. Vector graphics are not rendered:
+// the largest image of the page is used when present, otherwise the page text is drawn on blank page
+type PdfThumbnail struct {
+	Opts ImageResizeOpts
+}
+
+const PdfThumbnailId = "/pdf/thumbnail"
+
+func (m *PdfThumbnail) ID() string {
+	return PdfThumbnailId
+}
+
+func (m *PdfThumbnail) Pin() bool {
+	return false
+}
+
+func (m *PdfThumbnail) AcceptMedia(media string) error {
+	return accepts([]string{"application/pdf"}, media)
+}
+
+func (m *PdfThumbnail) Options(add map[string]interface{}) (string, error) {
+	return hashOpts(m.Opts, add)
+}
+
+func (m *PdfThumbnail) Mill(r io.ReadSeeker, name string) (*Result, error) {
+	doc, err := openPdf(r)
+	if err != nil {
+		return nil, err
+	}
+	if doc.NumPages() == 0 || doc.Encrypted() {
+		return nil, ErrNoPdfThumbnail
+	}
+	img, ok := doc.PageImage(0)
+	if !ok {
+		text, err := doc.PageText(0)
+		if err != nil {
+			return nil, err
+		}
+		width, height := doc.PageSize(0)
+		previewHeight := max(min(int(pdfPreviewWidth*height/width), 4*pdfPreviewWidth), pdfPreviewWidth/4)
+		img = renderTextPage(text, pdfPreviewWidth, previewHeight)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		return nil, err
+	}
+	resize := &ImageResize{Opts: m.Opts}
+	return resize.Mill(bytes.NewReader(buf.Bytes()), name)
+}
+
+// renderTextPage draws text with monospace font on white page, wrapping long lines
+func renderTextPage(text string, width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+	}
+	maxChars := (width - 2*pdfPreviewMargin) / face.Advance
+	y := pdfPreviewMargin + face.Ascent
+	for _, line := range wrapLines(text, maxChars) {
+		if y > height-pdfPreviewMargin {
+			break
+		}
+		drawer.Dot = fixed.P(pdfPreviewMargin, y)
+		drawer.DrawString(line)
+		y += face.Height
+	}
+	return img
+}
+
+func wrapLines(text string, maxChars int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > maxChars {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:maxChars]))
+				word = string(runes[maxChars:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= maxChars:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -31,6 +31,40 @@ func GetMill(id string, opts map[string]string) (mill.Mill, error) {
 		}, nil
 	case "/image/exif":
 		return &mill.ImageExif{}, nil
+	case "/video/thumbnail":
+		width := opts["width"]
+		if width == "" {
+			return nil, fmt.Errorf("missing width")
+		}
+		quality := opts["quality"]
+		if quality == "" {
+			quality = "75"
+		}
+		return &mill.MediaThumbnail{
+			Opts: mill.ImageResizeOpts{
+				Width:   width,
+				Quality: quality,
+			},
+		}, nil
+	case "/media/info":
+		return &mill.MediaInfo{}, nil
+	case "/pdf/thumbnail":
+		width := opts["width"]
+		if width == "" {
+			return nil, fmt.Errorf("missing width")
+		}
+		quality := opts["quality"]
+		if quality == "" {
+			quality = "75"
+		}
+		return &mill.PdfThumbnail{
+			Opts: mill.ImageResizeOpts{
+				Width:   width,
+				Quality: quality,
+			},
+		}, nil
+	case "/pdf/info":
+		return &mill.PdfInfo{}, nil
 
 	default:
 		return nil, nil
@@ -11,8 +11,12 @@ import (
 
 	headsync "github.com/anyproto/any-sync/commonspace/headsync"
 
+	list "github.com/anyproto/any-sync/commonspace/object/acl/list"
+
 	mock "github.com/stretchr/testify/mock"
 
+	model "github.com/anyproto/anytype-heart/pkg/lib/pb/model"
+
 	objectcache "github.com/anyproto/anytype-heart/core/block/object/objectcache"
 
 	objecttreebuilder "github.com/anyproto/any-sync/commonspace/objecttreebuilder"
@@ -1463,6 +1467,51 @@ func (_c *MockSpace_LoadObjectsIgnoreErrs_Call) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// MyPermissions provides a mock function with given fields:
+func (_m *MockSpace) MyPermissions() model.ParticipantPermissions {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MyPermissions")
+	}
+
+	var r0 model.ParticipantPermissions
+	if rf, ok := ret.Get(0).(func() model.ParticipantPermissions); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.ParticipantPermissions)
+	}
+
+	return r0
+}
+
+// MockSpace_MyPermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MyPermissions'
+type MockSpace_MyPermissions_Call struct {
+	*mock.Call
+}
+
+// MyPermissions is a helper method to define mock.On call
+func (_e *MockSpace_Expecter) MyPermissions() *MockSpace_MyPermissions_Call {
+	return &MockSpace_MyPermissions_Call{Call: _e.mock.On("MyPermissions")}
+}
+
+func (_c *MockSpace_MyPermissions_Call) Run(run func()) *MockSpace_MyPermissions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSpace_MyPermissions_Call) Return(_a0 model.ParticipantPermissions) *MockSpace_MyPermissions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSpace_MyPermissions_Call) RunAndReturn(run func() model.ParticipantPermissions) *MockSpace_MyPermissions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Remove provides a mock function with given fields: ctx, objectID
 func (_m *MockSpace) Remove(ctx context.Context, objectID string) error {
 	ret := _m.Called(ctx, objectID)
@@ -1510,6 +1559,39 @@ func (_c *MockSpace_Remove_Call) RunAndReturn(run func(context.Context, string)
 	return _c
 }
 
+// SetMyPermissions provides a mock function with given fields: permissions
+func (_m *MockSpace) SetMyPermissions(permissions list.AclPermissions) {
+	_m.Called(permissions)
+}
+
+// MockSpace_SetMyPermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMyPermissions'
+type MockSpace_SetMyPermissions_Call struct {
+	*mock.Call
+}
+
+// SetMyPermissions is a helper method to define mock.On call
+//   - permissions list.AclPermissions
+func (_e *MockSpace_Expecter) SetMyPermissions(permissions interface{}) *MockSpace_SetMyPermissions_Call {
+	return &MockSpace_SetMyPermissions_Call{Call: _e.mock.On("SetMyPermissions", permissions)}
+}
+
+func (_c *MockSpace_SetMyPermissions_Call) Run(run func(permissions list.AclPermissions)) *MockSpace_SetMyPermissions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(list.AclPermissions))
+	})
+	return _c
+}
+
+func (_c *MockSpace_SetMyPermissions_Call) Return() *MockSpace_SetMyPermissions_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSpace_SetMyPermissions_Call) RunAndReturn(run func(list.AclPermissions)) *MockSpace_SetMyPermissions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Storage provides a mock function with given fields:
 func (_m *MockSpace) Storage() spacestorage.SpaceStorage {
 	ret := _m.Called()
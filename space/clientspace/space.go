@@ -3,12 +3,15 @@ package clientspace
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/anyproto/any-sync/accountservice"
 	"github.com/anyproto/any-sync/app/logger"
 	"github.com/anyproto/any-sync/commonspace"
 	"github.com/anyproto/any-sync/commonspace/headsync"
+	"github.com/anyproto/any-sync/commonspace/object/acl/aclrecordproto"
+	"github.com/anyproto/any-sync/commonspace/object/acl/list"
 	"github.com/anyproto/any-sync/commonspace/objecttreebuilder"
 	"github.com/anyproto/any-sync/commonspace/spacestorage"
 	"github.com/anyproto/any-sync/net/peer"
@@ -23,6 +26,7 @@ import (
 	"github.com/anyproto/anytype-heart/core/domain"
 	"github.com/anyproto/anytype-heart/pkg/lib/bundle"
 	coresb "github.com/anyproto/anytype-heart/pkg/lib/core/smartblock"
+	"github.com/anyproto/anytype-heart/pkg/lib/pb/model"
 	"github.com/anyproto/anytype-heart/pkg/lib/threads"
 	"github.com/anyproto/anytype-heart/space/internal/objectprovider"
 	"github.com/anyproto/anytype-heart/space/spacecore"
@@ -53,6 +57,10 @@ type Space interface {
 
 	IsReadOnly() bool
 	IsPersonal() bool
+	// MyPermissions returns space-wide permissions of the current account
+	MyPermissions() model.ParticipantPermissions
+	// SetMyPermissions is called on ACL updates under the ACL lock
+	SetMyPermissions(permissions list.AclPermissions)
 
 	Close(ctx context.Context) error
 }
@@ -84,6 +92,8 @@ type space struct {
 
 	myIdentity crypto.PubKey
 	common     commonspace.Space
+	// myPermissions are cached, so they could be read under locks of objects without taking the ACL lock
+	myPermissions atomic.Pointer[model.ParticipantPermissions]
 
 	loadMandatoryObjectsCh  chan struct{}
 	loadMandatoryObjectsErr error
@@ -112,6 +122,11 @@ func BuildSpace(ctx context.Context, deps SpaceDeps) (Space, error) {
 		myIdentity:             deps.AccountService.Account().SignKey.GetPublic(),
 		loadMandatoryObjectsCh: make(chan struct{}),
 	}
+	if acl := deps.CommonSpace.Acl(); acl != nil {
+		acl.RLock()
+		sp.SetMyPermissions(acl.AclState().Permissions(sp.myIdentity))
+		acl.RUnlock()
+	}
 	sp.Cache = objectcache.New(deps.AccountService, deps.ObjectFactory, deps.PersonalSpaceId, sp)
 	sp.ObjectProvider = objectprovider.NewObjectProvider(deps.CommonSpace.Id(), deps.PersonalSpaceId, sp.Cache)
 	var err error
@@ -355,3 +370,28 @@ func (s *space) migrationProfileObject(ctx context.Context) error {
 func (s *space) IsReadOnly() bool {
 	return !s.CommonSpace().Acl().AclState().Permissions(s.myIdentity).CanWrite()
 }
+
+func (s *space) MyPermissions() model.ParticipantPermissions {
+	if s.IsPersonal() || s.CommonSpace().Acl() == nil {
+		return model.ParticipantPermissions_Owner
+	}
+	if permissions := s.myPermissions.Load(); permissions != nil {
+		return *permissions
+	}
+	return model.ParticipantPermissions_NoPermissions
+}
+
+func (s *space) SetMyPermissions(permissions list.AclPermissions) {
+	var converted model.ParticipantPermissions
+	switch aclrecordproto.AclUserPermissions(permissions) {
+	case aclrecordproto.AclUserPermissions_Owner:
+		converted = model.ParticipantPermissions_Owner
+	case aclrecordproto.AclUserPermissions_Writer, aclrecordproto.AclUserPermissions_Admin:
+		converted = model.ParticipantPermissions_Writer
+	case aclrecordproto.AclUserPermissions_Reader:
+		converted = model.ParticipantPermissions_Reader
+	default:
+		converted = model.ParticipantPermissions_NoPermissions
+	}
+	s.myPermissions.Store(&converted)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/anyproto/any-sync/util/crypto"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
 	"github.com/anyproto/anytype-heart/space/clientspace"
 	"github.com/anyproto/anytype-heart/space/internal/components/aclnotifications"
 	"github.com/anyproto/anytype-heart/space/internal/components/invitemigrator"
@@ -49,6 +50,7 @@ type aclObjectManager struct {
 	notificationService aclnotifications.AclNotification
 	participantWatcher  participantwatcher.ParticipantWatcher
 	inviteMigrator      invitemigrator.InviteMigrator
+	accessStore         objectstore.AccessStore
 
 	ownerMetadata []byte
 	lastIndexed   string
@@ -92,6 +94,7 @@ func (a *aclObjectManager) Init(ap *app.App) (err error) {
 		a.statService = debugstat.NewNoOp()
 	}
 	a.inviteMigrator = app.MustComponent[invitemigrator.InviteMigrator](ap)
+	a.accessStore = app.MustComponent[objectstore.AccessStore](ap)
 	a.statService.AddProvider(a)
 	a.waitLoad = make(chan struct{})
 	a.wait = make(chan struct{})
@@ -171,9 +174,15 @@ func (a *aclObjectManager) processAcl() (err error) {
 	)
 	defer func() {
 		if err == nil {
+			spaceId := common.Id()
 			permissions := aclState.Permissions(aclState.AccountKey().GetPublic())
+			a.sp.SetMyPermissions(permissions)
+			// objects hidden from readers are filtered from queries of spaces where the account can't write
+			if err := a.accessStore.SetReaderSpace(spaceId, !permissions.CanWrite()); err != nil {
+				log.Error("set reader space", zap.Error(err))
+			}
 			accountStatus := getAccountStatus(aclState, upToDate)
-			a.notificationService.AddRecords(acl, permissions, common.Id(), accountStatus, a.status.GetLocalStatus())
+			a.notificationService.AddRecords(acl, permissions, spaceId, accountStatus, a.status.GetLocalStatus())
 		}
 	}()
 	a.mx.Lock()
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/anyproto/anytype-heart/pkg/lib/localstore/objectstore"
 	"github.com/anyproto/anytype-heart/space/clientspace"
 	"github.com/anyproto/anytype-heart/space/clientspace/mock_clientspace"
 	"github.com/anyproto/anytype-heart/space/internal/components/aclnotifications/mock_aclnotifications"
@@ -55,6 +56,7 @@ func TestAclObjectManager(t *testing.T) {
 		fx.mockStatus.EXPECT().SetAclIsEmpty(true).Return(nil)
 		fx.mockCommonSpace.EXPECT().Id().Return("spaceId")
 		fx.mockStatus.EXPECT().GetLocalStatus().Return(spaceinfo.LocalStatusOk)
+		fx.mockSpace.EXPECT().SetMyPermissions(list.AclPermissionsOwner)
 		fx.mockAclNotification.EXPECT().AddRecords(acl, list.AclPermissionsOwner, "spaceId", spaceinfo.AccountStatusActive, spaceinfo.LocalStatusOk)
 		fx.run(t)
 		<-fx.aclObjectManager.wait
@@ -62,6 +64,7 @@ func TestAclObjectManager(t *testing.T) {
 		defer fx.aclObjectManager.mx.Unlock()
 		require.Equal(t, acl.Head().Id, fx.aclObjectManager.lastIndexed)
 		require.Equal(t, fx.aclObjectManager, acl.updater)
+		require.Empty(t, fx.accessStore.ReaderSpaceIds())
 	})
 	t.Run("participant", func(t *testing.T) {
 		a := list.NewAclExecutor("spaceId")
@@ -99,6 +102,7 @@ func TestAclObjectManager(t *testing.T) {
 		fx.mockStatus.EXPECT().SetAclIsEmpty(false).Return(nil)
 		fx.mockCommonSpace.EXPECT().Id().Return("spaceId")
 		fx.mockStatus.EXPECT().GetLocalStatus().Return(spaceinfo.LocalStatusOk)
+		fx.mockSpace.EXPECT().SetMyPermissions(list.AclPermissionsReader)
 		fx.mockAclNotification.EXPECT().AddRecords(acl, list.AclPermissionsReader, "spaceId", spaceinfo.AccountStatusActive, spaceinfo.LocalStatusOk)
 		fx.run(t)
 		<-fx.aclObjectManager.wait
@@ -106,6 +110,7 @@ func TestAclObjectManager(t *testing.T) {
 		defer fx.aclObjectManager.mx.Unlock()
 		require.Equal(t, acl.Head().Id, fx.aclObjectManager.lastIndexed)
 		require.Equal(t, fx.aclObjectManager, acl.updater)
+		require.Equal(t, []string{"spaceId"}, fx.accessStore.ReaderSpaceIds())
 	})
 	t.Run("participant removed", func(t *testing.T) {
 		a := list.NewAclExecutor("spaceId")
@@ -139,6 +144,7 @@ func TestAclObjectManager(t *testing.T) {
 		fx.mockStatus.EXPECT().SetAclIsEmpty(false).Return(nil)
 		fx.mockCommonSpace.EXPECT().Id().Return("spaceId")
 		fx.mockStatus.EXPECT().GetLocalStatus().Return(spaceinfo.LocalStatusOk)
+		fx.mockSpace.EXPECT().SetMyPermissions(list.AclPermissionsNone)
 		fx.mockAclNotification.EXPECT().AddRecords(acl, list.AclPermissionsNone, "spaceId", spaceinfo.AccountStatusDeleted, spaceinfo.LocalStatusOk)
 		fx.run(t)
 		<-fx.aclObjectManager.wait
@@ -146,6 +152,7 @@ func TestAclObjectManager(t *testing.T) {
 		defer fx.aclObjectManager.mx.Unlock()
 		require.Equal(t, acl.Head().Id, fx.aclObjectManager.lastIndexed)
 		require.Equal(t, fx.aclObjectManager, acl.updater)
+		require.Equal(t, []string{"spaceId"}, fx.accessStore.ReaderSpaceIds())
 	})
 }
 
@@ -186,6 +193,7 @@ func newFixture(t *testing.T) *fixture {
 		Register(testutil.PrepareMock(ctx, fx.a, fx.mockLoader)).
 		Register(testutil.PrepareMock(ctx, fx.a, fx.mockParticipantWatcher)).
 		Register(testutil.PrepareMock(ctx, fx.a, fx.mockAclNotification)).
+		Register(objectstore.NewStoreFixture(t)).
 		Register(fx)
 	return fx
 }
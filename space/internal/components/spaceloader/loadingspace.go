@@ -12,6 +12,7 @@ import (
 	"github.com/anyproto/any-sync/commonspace/spacesyncproto"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/anytype-heart/metrics/tracing"
 	"github.com/anyproto/anytype-heart/space/clientspace"
 )
 
@@ -42,6 +43,7 @@ type loadingSpace struct {
 
 func (s *spaceLoader) newLoadingSpace(ctx context.Context, stopIfMandatoryFail, disableRemoteLoad bool, aclHeadId string) *loadingSpace {
 	ls := &loadingSpace{
+		ID:                   s.status.SpaceId(),
 		stopIfMandatoryFail:  stopIfMandatoryFail,
 		disableRemoteLoad:    disableRemoteLoad,
 		retryTimeout:         loadingRetryTimeout,
@@ -82,6 +84,11 @@ func (ls *loadingSpace) loadRetry(ctx context.Context) {
 }
 
 func (ls *loadingSpace) load(ctx context.Context) (ok bool) {
+	ctx, span := tracing.Start(ctx, "space.Load", tracing.SpaceId(ls.ID))
+	var err error
+	defer func() {
+		tracing.End(span, err)
+	}()
 	sp, err := ls.spaceServiceProvider.open(ctx)
 	if errors.Is(err, spacesyncproto.ErrSpaceMissing) {
 		return ls.disableRemoteLoad
@@ -79,6 +79,7 @@ type Queue[T Item] struct {
 
 type options struct {
 	retryPauseDuration time.Duration
+	retryBackoff       func(item Item) time.Duration
 	ctx                context.Context
 }
 
@@ -91,6 +92,16 @@ func WithRetryPause(duration time.Duration) Option {
 	}
 }
 
+// WithRetryBackoff delays the retry of the item by the duration returned by backoff, other items are handled meanwhile.
+// The item is saved to the storage before the retry, so the handler could keep the attempts counter in the item
+func WithRetryBackoff[T Item](backoff func(item T) time.Duration) Option {
+	return func(o *options) {
+		o.retryBackoff = func(item Item) time.Duration {
+			return backoff(item.(T))
+		}
+	}
+}
+
 func WithContext(ctx context.Context) Option {
 	return func(o *options) {
 		o.ctx = ctx
@@ -187,7 +198,21 @@ func (q *Queue[T]) handleNext() error {
 		// We don't need to check that the item has been removed from queue here, it will be checked on next iteration
 		// So just notify waiters that the item has been processed
 		q.notifyWaiters()
+		var putErr error
+		if q.options.retryBackoff != nil {
+			// Item is updated under the lock, so an item removed concurrently is not written back to the storage
+			if _, exists := q.set[it.Key()]; exists {
+				putErr = q.storage.Put(it)
+			}
+		}
 		q.lock.Unlock()
+		if putErr != nil {
+			return fmt.Errorf("update item in storage: %w", putErr)
+		}
+		if q.options.retryBackoff != nil {
+			q.retryAfter(it, q.options.retryBackoff(it))
+			break
+		}
 		addErr := q.batcher.Add(q.ctx, it)
 		if addErr != nil {
 			return fmt.Errorf("add to queue: %w", addErr)
@@ -206,6 +231,24 @@ func (q *Queue[T]) handleNext() error {
 	return nil
 }
 
+func (q *Queue[T]) retryAfter(it T, delay time.Duration) {
+	if delay <= 0 {
+		if err := q.batcher.Add(q.ctx, it); err != nil {
+			q.logger.Warn("add to queue", zap.Error(err))
+		}
+		return
+	}
+	go func() {
+		select {
+		case <-time.After(delay):
+			if err := q.batcher.Add(q.ctx, it); err != nil && q.ctx.Err() == nil {
+				q.logger.Warn("add to queue", zap.Error(err))
+			}
+		case <-q.ctx.Done():
+		}
+	}()
+}
+
 func (q *Queue[T]) restore() error {
 	items, err := q.storage.List()
 	if err != nil {
@@ -274,6 +317,12 @@ func (q *Queue[T]) Add(item T) error {
 	if err != nil {
 		return err
 	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	// Item could be removed while it was added to the batcher
+	if _, ok := q.set[item.Key()]; !ok {
+		return nil
+	}
 	return q.storage.Put(item)
 }
 
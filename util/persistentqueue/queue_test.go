@@ -407,6 +407,81 @@ func TestWithHandlerTickPeriod(t *testing.T) {
 	})
 }
 
+func TestWithRetryBackoff(t *testing.T) {
+	t.Run("delayed item doesn't block others and is persisted", func(t *testing.T) {
+		db := newInMemoryBadger(t)
+		log := logging.Logger("test")
+		storage := NewBadgerStorage[*testItem](db, []byte("test_queue/"), makeTestItem)
+
+		backoff := 50 * time.Millisecond
+		var handled atomic.Int32
+		q := New[*testItem](storage, log.Desugar(), func(ctx context.Context, item *testItem) (Action, error) {
+			if item.Id == "1" && item.Data != "retried" {
+				item.Data = "retried"
+				return ActionRetry, nil
+			}
+			handled.Add(1)
+			return ActionDone, nil
+		}, WithRetryBackoff(func(item *testItem) time.Duration {
+			return backoff
+		}))
+		t.Cleanup(func() {
+			q.Close()
+			db.Close()
+		})
+
+		err := q.Add(&testItem{Id: "1", Timestamp: 1, Data: "data1"})
+		require.NoError(t, err)
+		err = q.Add(&testItem{Id: "2", Timestamp: 2, Data: "data2"})
+		require.NoError(t, err)
+
+		q.Run()
+
+		time.Sleep(backoff / 2)
+		assert.Equal(t, int32(1), handled.Load())
+		items, err := storage.List()
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "retried", items[0].Data)
+
+		time.Sleep(backoff)
+		assert.Equal(t, int32(2), handled.Load())
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("item removed during handling is not written back", func(t *testing.T) {
+		db := newInMemoryBadger(t)
+		log := logging.Logger("test")
+		storage := NewBadgerStorage[*testItem](db, []byte("test_queue/"), makeTestItem)
+
+		var q *Queue[*testItem]
+		handled := make(chan struct{})
+		q = New[*testItem](storage, log.Desugar(), func(ctx context.Context, item *testItem) (Action, error) {
+			defer close(handled)
+			require.NoError(t, q.Remove(item.Key()))
+			return ActionRetry, nil
+		}, WithRetryBackoff(func(item *testItem) time.Duration {
+			return time.Hour
+		}))
+		t.Cleanup(func() {
+			q.Close()
+			db.Close()
+		})
+
+		err := q.Add(&testItem{Id: "1", Timestamp: 1, Data: "data1"})
+		require.NoError(t, err)
+
+		q.Run()
+		<-handled
+		time.Sleep(10 * time.Millisecond)
+
+		items, err := storage.List()
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.False(t, q.Has("1"))
+	})
+}
+
 type testContextKeyType string
 
 const testContextKey testContextKeyType = "testKey"
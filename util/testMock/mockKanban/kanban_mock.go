@@ -41,18 +41,18 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Grouper mocks base method.
-func (m *MockService) Grouper(arg0, arg1 string) (kanban.Grouper, error) {
+func (m *MockService) Grouper(arg0, arg1 string, arg2 kanban.GrouperParams) (kanban.Grouper, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Grouper", arg0, arg1)
+	ret := m.ctrl.Call(m, "Grouper", arg0, arg1, arg2)
 	ret0, _ := ret[0].(kanban.Grouper)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Grouper indicates an expected call of Grouper.
-func (mr *MockServiceMockRecorder) Grouper(arg0, arg1 any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Grouper(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grouper", reflect.TypeOf((*MockService)(nil).Grouper), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grouper", reflect.TypeOf((*MockService)(nil).Grouper), arg0, arg1, arg2)
 }
 
 // Init mocks base method.
@@ -442,6 +442,21 @@ func (mr *MockObjectStoreMockRecorder) GetSpaceName(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpaceName", reflect.TypeOf((*MockObjectStore)(nil).GetSpaceName), arg0)
 }
 
+// GetSyncedBlockReferences mocks base method.
+func (m *MockObjectStore) GetSyncedBlockReferences(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncedBlockReferences", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncedBlockReferences indicates an expected call of GetSyncedBlockReferences.
+func (mr *MockObjectStoreMockRecorder) GetSyncedBlockReferences(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncedBlockReferences", reflect.TypeOf((*MockObjectStore)(nil).GetSyncedBlockReferences), arg0)
+}
+
 // GetUniqueKeyById mocks base method.
 func (m *MockObjectStore) GetUniqueKeyById(arg0 string) (domain.UniqueKey, error) {
 	m.ctrl.T.Helper()
@@ -864,6 +879,20 @@ func (mr *MockObjectStoreMockRecorder) UpdateObjectSnippet(arg0, arg1 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateObjectSnippet", reflect.TypeOf((*MockObjectStore)(nil).UpdateObjectSnippet), arg0, arg1)
 }
 
+// UpdateSyncedBlockTargets mocks base method.
+func (m *MockObjectStore) UpdateSyncedBlockTargets(arg0 string, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSyncedBlockTargets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSyncedBlockTargets indicates an expected call of UpdateSyncedBlockTargets.
+func (mr *MockObjectStoreMockRecorder) UpdateSyncedBlockTargets(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSyncedBlockTargets", reflect.TypeOf((*MockObjectStore)(nil).UpdateSyncedBlockTargets), arg0, arg1)
+}
+
 // UpdatePendingLocalDetails mocks base method.
 func (m *MockObjectStore) UpdatePendingLocalDetails(arg0 string, arg1 func(*types.Struct) (*types.Struct, error)) error {
 	m.ctrl.T.Helper()